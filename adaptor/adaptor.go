@@ -0,0 +1,161 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package adaptor implements Schnorr adaptor signatures that complete to
+// ordinary Ed25519 signatures.
+//
+// A pre-signature is made for a message and an adaptor point T = t*B. Anyone
+// can check it against the public key and T with PreVerify, but only someone
+// who knows the adaptor secret t can turn it into a valid Ed25519 signature,
+// with Adapt. Conversely, once the signature is published, anyone holding
+// the pre-signature learns t from it, with Extract. This ties the release of
+// a signature to the release of a secret, which is what atomic swaps and
+// payment channels are built on.
+//
+// With nonce r, the pre-signature is (R, s') where R = r*B + T, k is the
+// Ed25519 challenge SHA-512(R || A || M), and s' = r + k*a. The completed
+// signature is (R, s' + t), which satisfies [s' + t]B = R + [k]A.
+package adaptor
+
+import (
+	"crypto/sha512"
+	"errors"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+)
+
+// PreSignatureSize is the size of a pre-signature, the point R followed by
+// the scalar s'.
+const PreSignatureSize = 64
+
+// nonceDomain separates the pre-signature nonce derivation from the one of
+// Ed25519, so that a pre-signature and a signature of the same message never
+// share a nonce.
+const nonceDomain = "gtank/ed25519/adaptor-v1 nonce"
+
+// NewAdaptor returns the adaptor point t*B for the secret t, encoded.
+func NewAdaptor(secret *ed25519.Scalar) []byte {
+	return new(ed25519.Point).ScalarBaseMult(secret.Bytes()).Bytes()
+}
+
+// PreSign returns a pre-signature of message by privateKey for the adaptor
+// point T. The nonce is derived deterministically from the key, T and the
+// message. PreSign returns an error if T is not a canonical encoding of a
+// point in the prime-order subgroup, and panics if len(privateKey) is not
+// ed25519.PrivateKeySize.
+func PreSign(privateKey ed25519.PrivateKey, message, T []byte) ([]byte, error) {
+	if l := len(privateKey); l != ed25519.PrivateKeySize {
+		panic("adaptor: bad private key length: " + strconv.Itoa(l))
+	}
+	Tp, err := new(ed25519.Point).SetBytesPrimeOrder(T)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha512.Sum512(privateKey.Seed())
+	a, _ := ed25519.NewScalar().SetBytesWithClamping(h[:32])
+	A := privateKey[ed25519.SeedSize:]
+
+	// r = SHA-512(domain || prefix || T || M) mod L
+	nh := sha512.New()
+	nh.Write([]byte(nonceDomain))
+	nh.Write(h[32:])
+	nh.Write(T)
+	nh.Write(message)
+	r, _ := ed25519.NewScalar().SetUniformBytes(nh.Sum(nil))
+
+	R := new(ed25519.Point).ScalarBaseMult(r.Bytes())
+	R.Add(R, Tp)
+	Rb := R.Bytes()
+
+	k := challenge(Rb, A, message)
+	s := ed25519.NewScalar().MultiplyAdd(k, a, r)
+	return append(Rb, s.Bytes()...), nil
+}
+
+// PreVerify reports whether preSig is a valid pre-signature of message by
+// publicKey for the adaptor point T, that is, whether completing it with the
+// discrete log of T yields a valid Ed25519 signature. T must be in the
+// prime-order subgroup: with a small-order component, the completed signature
+// would be off by it, and rejected.
+func PreVerify(publicKey ed25519.PublicKey, message, T, preSig []byte) bool {
+	if len(preSig) != PreSignatureSize {
+		return false
+	}
+	A, err := new(ed25519.Point).SetBytes(publicKey)
+	if err != nil {
+		return false
+	}
+	Tp, err := new(ed25519.Point).SetBytesPrimeOrder(T)
+	if err != nil {
+		return false
+	}
+	R, err := new(ed25519.Point).SetBytes(preSig[:32])
+	if err != nil {
+		return false
+	}
+	s, err := ed25519.NewScalar().SetCanonicalBytes(preSig[32:])
+	if err != nil {
+		return false
+	}
+
+	// [s']B - [k]A = R - T
+	k := challenge(preSig[:32], publicKey, message)
+	lhs := new(ed25519.Point).VartimeMultiScalarMult(
+		[][]byte{s.Bytes(), k.Bytes()},
+		[]*ed25519.Point{ed25519.NewGeneratorPoint(), new(ed25519.Point).Neg(A)})
+	rhs := new(ed25519.Point).Sub(R, Tp)
+	return lhs.Equal(rhs) == 1
+}
+
+// Adapt completes preSig into an Ed25519 signature with the adaptor secret t.
+// The result is only valid if preSig passes PreVerify for t*B, which Adapt
+// doesn't check. It returns an error if preSig is malformed.
+func Adapt(preSig []byte, secret *ed25519.Scalar) ([]byte, error) {
+	if len(preSig) != PreSignatureSize {
+		return nil, errors.New("adaptor: bad pre-signature length")
+	}
+	s, err := ed25519.NewScalar().SetCanonicalBytes(preSig[32:])
+	if err != nil {
+		return nil, err
+	}
+	s.Add(s, secret)
+
+	sig := make([]byte, ed25519.SignatureSize)
+	copy(sig, preSig[:32])
+	copy(sig[32:], s.Bytes())
+	return sig, nil
+}
+
+// Extract returns the adaptor secret t = s - s' from the signature sig that
+// preSig was completed into. It returns an error if the two don't share R,
+// which means sig wasn't produced from preSig.
+func Extract(sig, preSig []byte) (*ed25519.Scalar, error) {
+	if len(sig) != ed25519.SignatureSize || len(preSig) != PreSignatureSize {
+		return nil, errors.New("adaptor: bad signature length")
+	}
+	if string(sig[:32]) != string(preSig[:32]) {
+		return nil, errors.New("adaptor: signature doesn't match pre-signature")
+	}
+	s, err := ed25519.NewScalar().SetCanonicalBytes(sig[32:])
+	if err != nil {
+		return nil, err
+	}
+	sPre, err := ed25519.NewScalar().SetCanonicalBytes(preSig[32:])
+	if err != nil {
+		return nil, err
+	}
+	return s.Sub(s, sPre), nil
+}
+
+// challenge returns the Ed25519 challenge SHA-512(R || A || M) mod L.
+func challenge(R, A, message []byte) *ed25519.Scalar {
+	h := sha512.New()
+	h.Write(R)
+	h.Write(A)
+	h.Write(message)
+	k, _ := ed25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	return k
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package adaptor
+
+import (
+	stded25519 "crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func TestAdaptorSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := ed25519.NewRandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	T := NewAdaptor(secret)
+	message := []byte("swap 1 BTC for 10 XMR")
+
+	preSig, err := PreSign(priv, message, T)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !PreVerify(pub, message, T, preSig) {
+		t.Fatal("valid pre-signature rejected")
+	}
+	if ed25519.Verify(pub, message, preSig) {
+		t.Error("pre-signature is a valid signature")
+	}
+
+	sig, err := Adapt(preSig, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, message, sig) || !stded25519.Verify(stded25519.PublicKey(pub), message, sig) {
+		t.Fatal("completed signature rejected")
+	}
+
+	got, err := Extract(sig, preSig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Equal(secret) != 1 {
+		t.Error("extracted the wrong secret")
+	}
+}
+
+func TestPreVerifyRejects(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	secret, _ := ed25519.NewRandomScalar(rand.Reader)
+	other, _ := ed25519.NewRandomScalar(rand.Reader)
+	T := NewAdaptor(secret)
+	message := []byte("message")
+	preSig, _ := PreSign(priv, message, T)
+
+	if PreVerify(pub, message, NewAdaptor(other), preSig) {
+		t.Error("pre-signature accepted for the wrong adaptor")
+	}
+	if PreVerify(pub, []byte("other"), T, preSig) {
+		t.Error("pre-signature accepted for the wrong message")
+	}
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	if PreVerify(otherPub, message, T, preSig) {
+		t.Error("pre-signature accepted for the wrong key")
+	}
+
+	// Completing with the wrong secret gives an invalid signature.
+	sig, _ := Adapt(preSig, other)
+	if ed25519.Verify(pub, message, sig) {
+		t.Error("signature completed with the wrong secret accepted")
+	}
+
+	sig2, _ := Adapt(preSig, secret)
+	otherPre, _ := PreSign(priv, []byte("other"), T)
+	if _, err := Extract(sig2, otherPre); err == nil {
+		t.Error("extracted from an unrelated pre-signature")
+	}
+}
+
+func TestAdaptorTorsion(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	secret, _ := ed25519.NewRandomScalar(rand.Reader)
+	message := []byte("message")
+
+	// T' = t*B + T8, where T8 has order 8. Its discrete log with respect to B
+	// doesn't exist, so no secret completes a pre-signature for it.
+	order8, _ := new(ed25519.Point).SetBytes([]byte{
+		0xc7, 0x17, 0x6a, 0x70, 0x3d, 0x4d, 0xd8, 0x4f, 0xba, 0x3c, 0x0b, 0x76, 0x0d, 0x10, 0x67, 0x0f,
+		0x2a, 0x20, 0x53, 0xfa, 0x2c, 0x39, 0xcc, 0xc6, 0x4e, 0xc7, 0xfd, 0x77, 0x92, 0xac, 0x03, 0x7a})
+	Tp, _ := new(ed25519.Point).SetBytes(NewAdaptor(secret))
+	T := Tp.Add(Tp, order8).Bytes()
+
+	if _, err := PreSign(priv, message, T); err == nil {
+		t.Error("pre-signed for an adaptor point with a torsion component")
+	}
+
+	// A pre-signature built as PreSign would for T', R = r*B + T' and
+	// s' = r + k*a, satisfies the pre-verification equation.
+	h := sha512.Sum512(priv.Seed())
+	a, _ := ed25519.NewScalar().SetBytesWithClamping(h[:32])
+	r, _ := ed25519.NewRandomScalar(rand.Reader)
+	R := new(ed25519.Point).ScalarBaseMult(r.Bytes())
+	Rb := R.Add(R, Tp).Bytes()
+	s := ed25519.NewScalar().MultiplyAdd(challenge(Rb, pub, message), a, r)
+	preSig := append(Rb, s.Bytes()...)
+
+	if PreVerify(pub, message, T, preSig) {
+		t.Error("pre-signature accepted for an adaptor point with a torsion component")
+	}
+	sig, _ := Adapt(preSig, secret)
+	if ed25519.Verify(pub, message, sig) {
+		t.Error("completed signature unexpectedly valid")
+	}
+}
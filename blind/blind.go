@@ -0,0 +1,167 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package blind implements blind Schnorr signatures that unblind to ordinary
+// Ed25519 signatures.
+//
+// A user obtains a signature of a message from a signer that learns neither
+// the message nor the signature: seeing the signature later, the signer can't
+// tell which session produced it. This is the building block of
+// privacy-preserving tokens, where an issuer vouches for a user without being
+// able to track where the token is spent.
+//
+// The protocol has three moves:
+//
+//  1. The signer picks a nonce r and sends the commitment R' = r*B.
+//  2. The user picks blinding factors alpha and beta, computes
+//     R = R' + alpha*B + beta*A and k = SHA-512(R || A || M), and sends the
+//     blinded challenge c = k + beta.
+//  3. The signer replies with s' = r + c*a, and the user unblinds it to
+//     s = s' + alpha. (R, s) is an Ed25519 signature of M by A.
+//
+// Blind Schnorr signatures are only secure if the signer completes each
+// session before opening the next: with many sessions open at once, the ROS
+// attack lets a user obtain one more signature than the number of sessions.
+// Signers must serialize sessions, or cap their concurrency at a small number.
+package blind
+
+import (
+	"crypto/sha512"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+)
+
+// Sizes of the protocol messages, in bytes.
+const (
+	// CommitmentSize is the size of the signer's commitment R'.
+	CommitmentSize = 32
+	// ChallengeSize is the size of the user's blinded challenge c.
+	ChallengeSize = 32
+	// ResponseSize is the size of the signer's response s'.
+	ResponseSize = 32
+)
+
+// SignerSession is the signer's side of one signing session. It must be used
+// for a single response.
+type SignerSession struct {
+	r          ed25519.Scalar
+	commitment []byte
+	done       bool
+}
+
+// NewSignerSession starts a signing session with a nonce read from rand.
+func NewSignerSession(rand io.Reader) (*SignerSession, error) {
+	r, err := ed25519.NewRandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	s := &SignerSession{r: *r}
+	s.commitment = new(ed25519.Point).ScalarBaseMult(r.Bytes()).Bytes()
+	return s, nil
+}
+
+// Commitment returns the commitment R' to send to the user.
+func (s *SignerSession) Commitment() []byte {
+	return append([]byte{}, s.commitment...)
+}
+
+// Respond returns the response s' to the user's blinded challenge, signed with
+// privateKey. It returns an error if challenge is not a canonical scalar, or
+// if the session was already used: answering two challenges with the same
+// nonce reveals the private key. It panics if len(privateKey) is not
+// ed25519.PrivateKeySize.
+func (s *SignerSession) Respond(privateKey ed25519.PrivateKey, challenge []byte) ([]byte, error) {
+	if l := len(privateKey); l != ed25519.PrivateKeySize {
+		panic("blind: bad private key length: " + strconv.Itoa(l))
+	}
+	if s.done {
+		return nil, errors.New("blind: session already used")
+	}
+	c, err := ed25519.NewScalar().SetCanonicalBytes(challenge)
+	if err != nil {
+		return nil, err
+	}
+	s.done = true
+
+	h := sha512.Sum512(privateKey.Seed())
+	a, _ := ed25519.NewScalar().SetBytesWithClamping(h[:32])
+	resp := ed25519.NewScalar().MultiplyAdd(c, a, &s.r)
+	s.r.Set(ed25519.NewScalar())
+	return resp.Bytes(), nil
+}
+
+// Request is the user's side of one signing session.
+type Request struct {
+	publicKey ed25519.PublicKey
+	message   []byte
+	alpha     ed25519.Scalar
+	nonce     []byte
+	challenge []byte
+}
+
+// NewRequest blinds message for signing by publicKey, given the signer's
+// commitment, with blinding factors read from rand. It returns an error if
+// publicKey or commitment is not a valid point encoding.
+func NewRequest(rand io.Reader, publicKey ed25519.PublicKey, message, commitment []byte) (*Request, error) {
+	A, err := new(ed25519.Point).SetBytes(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	Rc, err := new(ed25519.Point).SetBytes(commitment)
+	if err != nil {
+		return nil, err
+	}
+	alpha, err := ed25519.NewRandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	beta, err := ed25519.NewRandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	// R = R' + alpha*B + beta*A
+	R := new(ed25519.Point).CombinedMult(alpha.Bytes(), beta.Bytes(), A)
+	R.Add(R, Rc)
+	req := &Request{
+		publicKey: append(ed25519.PublicKey{}, publicKey...),
+		message:   append([]byte{}, message...),
+		alpha:     *alpha,
+		nonce:     R.Bytes(),
+	}
+
+	// c = SHA-512(R || A || M) + beta
+	h := sha512.New()
+	h.Write(req.nonce)
+	h.Write(publicKey)
+	h.Write(message)
+	k, _ := ed25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	req.challenge = k.Add(k, beta).Bytes()
+	return req, nil
+}
+
+// Challenge returns the blinded challenge c to send to the signer.
+func (req *Request) Challenge() []byte {
+	return append([]byte{}, req.challenge...)
+}
+
+// Unblind turns the signer's response into an Ed25519 signature of the
+// message. It returns an error if the result is not a valid signature, which
+// means the signer misbehaved.
+func (req *Request) Unblind(response []byte) ([]byte, error) {
+	s, err := ed25519.NewScalar().SetCanonicalBytes(response)
+	if err != nil {
+		return nil, err
+	}
+	s.Add(s, &req.alpha)
+
+	sig := append(append(make([]byte, 0, ed25519.SignatureSize), req.nonce...), s.Bytes()...)
+	if !ed25519.Verify(req.publicKey, req.message, sig) {
+		return nil, errors.New("blind: invalid response")
+	}
+	return sig, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package blind
+
+import (
+	"bytes"
+	stded25519 "crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func TestBlindSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("token 42")
+
+	session, err := NewSignerSession(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := NewRequest(rand.Reader, pub, message, session.Commitment())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := session.Respond(priv, req.Challenge())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := req.Unblind(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stded25519.Verify(stded25519.PublicKey(pub), message, sig) {
+		t.Error("unblinded signature rejected by crypto/ed25519")
+	}
+
+	// The signer saw neither R nor s.
+	if bytes.Equal(sig[:32], session.Commitment()) || bytes.Equal(sig[32:], resp) {
+		t.Error("signature is not blinded")
+	}
+
+	if _, err := session.Respond(priv, req.Challenge()); err == nil {
+		t.Error("session was used twice")
+	}
+}
+
+func TestUnblindRejectsBadResponse(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	_, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	message := []byte("token")
+
+	session, _ := NewSignerSession(rand.Reader)
+	req, _ := NewRequest(rand.Reader, pub, message, session.Commitment())
+	resp, err := session.Respond(otherPriv, req.Challenge())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := req.Unblind(resp); err == nil {
+		t.Error("response with the wrong key accepted")
+	}
+
+	session, _ = NewSignerSession(rand.Reader)
+	req, _ = NewRequest(rand.Reader, pub, message, session.Commitment())
+	resp, _ = session.Respond(priv, req.Challenge())
+	resp[0] ^= 1
+	if _, err := req.Unblind(resp); err == nil {
+		t.Error("tampered response accepted")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+// BytesMontgomery returns the 32-byte little-endian encoding of the
+// u-coordinate of the curve25519 point birationally equivalent to v, as used
+// by X25519. The identity maps to u = 0.
+func (v *Point) BytesMontgomery() []byte {
+	// u = (1 + y) / (1 - y) = (Z + Y) / (Z - Y)
+	var n, d, u radix51.FieldElement
+	n.Add(&v.p.Z, &v.p.Y)
+	d.Sub(&v.p.Z, &v.p.Y)
+	u.Mul(&n, d.Invert(&d))
+
+	out := make([]byte, 32)
+	u.ToBytes(out)
+	return out
+}
+
+// ExtendedCoordinates returns the extended coordinates (X:Y:Z:T) of v, with
+// x = X/Z, y = Y/Z and xy = T/Z, each as a canonical 32-byte little-endian
+// field element. The coordinates of a point are not unique: they are only
+// defined up to a common nonzero factor.
+func (v *Point) ExtendedCoordinates() (X, Y, Z, T []byte) {
+	X, Y, Z, T = make([]byte, 32), make([]byte, 32), make([]byte, 32), make([]byte, 32)
+	v.p.X.ToBytes(X)
+	v.p.Y.ToBytes(Y)
+	v.p.Z.ToBytes(Z)
+	v.p.T.ToBytes(T)
+	return X, Y, Z, T
+}
+
+var errInvalidCoordinates = errors.New("ed25519: invalid extended coordinates")
+
+// SetExtendedCoordinates sets v to the point with extended coordinates
+// (X:Y:Z:T), each a canonical 32-byte little-endian field element, and returns
+// v. If the coordinates are not canonical or don't describe a point on the
+// curve, SetExtendedCoordinates returns nil and an error, and v is unchanged.
+func (v *Point) SetExtendedCoordinates(X, Y, Z, T []byte) (*Point, error) {
+	var p group.ExtendedGroupElement
+	if !setCanonical(&p.X, X) || !setCanonical(&p.Y, Y) ||
+		!setCanonical(&p.Z, Z) || !setCanonical(&p.T, T) {
+		return nil, errInvalidCoordinates
+	}
+	if p.IsValid() != 1 {
+		return nil, errInvalidCoordinates
+	}
+	v.p.Set(&p)
+	return v, nil
+}
+
+// setCanonical sets v to the 32-byte little-endian field element x, and
+// reports whether x was the canonical encoding of a value less than p.
+func setCanonical(v *radix51.FieldElement, x []byte) bool {
+	if len(x) != 32 {
+		return false
+	}
+	v.FromBytes(x)
+	var buf [32]byte
+	v.ToBytes(buf[:])
+	return subtle.ConstantTimeCompare(buf[:], x) == 1
+}
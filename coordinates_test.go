@@ -0,0 +1,100 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"testing"
+
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+func TestBytesMontgomery(t *testing.T) {
+	nine := make([]byte, 32)
+	nine[0] = 9
+	if got := NewGeneratorPoint().BytesMontgomery(); !bytes.Equal(got, nine) {
+		t.Errorf("B maps to u = %x, want 9", got)
+	}
+	if got := NewIdentityPoint().BytesMontgomery(); !bytes.Equal(got, make([]byte, 32)) {
+		t.Errorf("the identity maps to u = %x, want 0", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		clamped := ClampScalarBytes([32]byte(randomScalar(t)))
+		k := clamped[:]
+
+		priv, err := ecdh.X25519().NewPrivateKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := priv.PublicKey().Bytes()
+		got := new(Point).ScalarBaseMult(k).BytesMontgomery()
+		if !bytes.Equal(got, want) {
+			t.Errorf("k = %x: got u = %x, want %x", k, got, want)
+		}
+	}
+}
+
+func TestExtendedCoordinatesRoundTrip(t *testing.T) {
+	P := new(Point).ScalarBaseMult(randomScalar(t))
+	X, Y, Z, T := P.ExtendedCoordinates()
+	Q, err := new(Point).SetExtendedCoordinates(X, Y, Z, T)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.Equal(Q) != 1 {
+		t.Error("point did not survive ExtendedCoordinates round trip")
+	}
+
+	// Scaling all coordinates by the same factor gives the same point.
+	var f, c radix51.FieldElement
+	f.FromBytes(randomScalar(t))
+	scale := func(x []byte) []byte {
+		out := make([]byte, 32)
+		c.FromBytes(x)
+		c.Mul(&c, &f)
+		c.ToBytes(out)
+		return out
+	}
+	Q, err = new(Point).SetExtendedCoordinates(scale(X), scale(Y), scale(Z), scale(T))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.Equal(Q) != 1 {
+		t.Error("scaled coordinates decoded to a different point")
+	}
+}
+
+func TestSetExtendedCoordinatesInvalid(t *testing.T) {
+	X, Y, Z, T := NewGeneratorPoint().ExtendedCoordinates()
+	zero := make([]byte, 32)
+
+	// p itself is the non-canonical encoding of zero.
+	p := bytes.Repeat([]byte{0xff}, 32)
+	p[0] = 0xed
+	p[31] = 0x7f
+
+	tests := []struct {
+		name       string
+		X, Y, Z, T []byte
+	}{
+		{"wrong T", X, Y, Z, Y},
+		{"off curve", Y, X, Z, T},
+		{"zero Z", zero, zero, zero, zero},
+		{"non-canonical", p, Z, Z, p},
+		{"short", X[:31], Y, Z, T},
+	}
+	for _, tt := range tests {
+		if _, err := new(Point).SetExtendedCoordinates(tt.X, tt.Y, tt.Z, tt.T); err == nil {
+			t.Errorf("%s: accepted invalid coordinates", tt.name)
+		}
+	}
+
+	// The identity with X = 0 and T = 0 written canonically is fine.
+	if _, err := new(Point).SetExtendedCoordinates(zero, Z, Z, zero); err != nil {
+		t.Errorf("rejected the identity: %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cose
+
+import "github.com/fxamacker/cbor/v2"
+
+// encMode encodes with the core deterministic encoding of RFC 8949, Section
+// 4.2.1, which RFC 9052, Section 9 recommends. Nil byte strings are encoded
+// as empty ones, not as null, so that a nil payload or external AAD is the
+// same as an empty one.
+var encMode cbor.EncMode
+
+// decMode rejects duplicate map keys and indefinite-length items, which
+// deterministically encoded messages never contain.
+var decMode cbor.DecMode
+
+func init() {
+	opts := cbor.CoreDetEncOptions()
+	opts.NilContainers = cbor.NilContainerAsEmpty
+	var err error
+	if encMode, err = opts.EncMode(); err != nil {
+		panic("cose: " + err.Error())
+	}
+	decMode, err = cbor.DecOptions{
+		DupMapKey:   cbor.DupMapKeyEnforcedAPF,
+		IndefLength: cbor.IndefLengthForbidden,
+	}.DecMode()
+	if err != nil {
+		panic("cose: " + err.Error())
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cose implements the Ed25519 and X25519 parts of COSE, RFC 9052 and
+// RFC 9053: OKP COSE_Key structures, and COSE_Sign1 messages signed with
+// EdDSA. They're the CBOR counterparts of JOSE keys and signatures, used by
+// WebAuthn, C2PA and constrained devices.
+//
+// Ed25519 keys are ed25519.PrivateKey and ed25519.PublicKey, and X25519 keys
+// are the *ecdh.PrivateKey and *ecdh.PublicKey of this module's ecdh package.
+package cose
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/ecdh"
+)
+
+// COSE_Key values, from RFC 9053, Section 7.2.
+const (
+	ktyOKP     = 1
+	crvX25519  = 4
+	crvEd25519 = 6
+)
+
+// AlgEdDSA is the COSE algorithm identifier of EdDSA, RFC 9053, Section 2.2.
+const AlgEdDSA = -8
+
+// coseKey is an OKP COSE_Key, with the labels of RFC 9052, Section 7.1, and
+// RFC 9053, Section 7.2. Other parameters, such as kid, are ignored.
+type coseKey struct {
+	Kty int64  `cbor:"1,keyasint"`
+	Alg int64  `cbor:"3,keyasint,omitempty"`
+	Crv int64  `cbor:"-1,keyasint"`
+	X   []byte `cbor:"-2,keyasint"`
+	D   []byte `cbor:"-4,keyasint,omitempty"`
+}
+
+// MarshalKey returns the COSE_Key encoding of key, an OKP key as in RFC 9053,
+// Section 7.2. The key can be an ed25519.PrivateKey or ed25519.PublicKey, an
+// *ecdh.PrivateKey or *ecdh.PublicKey. Private keys include the public key
+// in x, and the private key in d: the seed for Ed25519.
+func MarshalKey(key any) ([]byte, error) {
+	k := coseKey{Kty: ktyOKP}
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		if len(key) != ed25519.PublicKeySize {
+			return nil, errors.New("cose: bad Ed25519 public key length")
+		}
+		k.Crv, k.X = crvEd25519, key
+	case ed25519.PrivateKey:
+		if len(key) != ed25519.PrivateKeySize {
+			return nil, errors.New("cose: bad Ed25519 private key length")
+		}
+		k.Crv, k.X = crvEd25519, key[ed25519.SeedSize:]
+		k.D = key.Seed()
+	case *ecdh.PublicKey:
+		k.Crv, k.X = crvX25519, key.Bytes()
+	case *ecdh.PrivateKey:
+		k.Crv, k.X = crvX25519, key.PublicKey().Bytes()
+		k.D = key.Bytes()
+	default:
+		return nil, errors.New("cose: unsupported key type")
+	}
+	return encMode.Marshal(k)
+}
+
+// ParseKey parses an OKP COSE_Key with the Ed25519 or X25519 curve. It
+// returns an ed25519.PrivateKey or ed25519.PublicKey, an *ecdh.PrivateKey or
+// *ecdh.PublicKey, depending on the curve and on whether d is present. For
+// private keys, x must be the public key of d. If the key has an alg, it
+// must be EdDSA for Ed25519 keys. Other parameters, like kid, are ignored.
+func ParseKey(data []byte) (any, error) {
+	var k coseKey
+	if err := decMode.Unmarshal(data, &k); err != nil {
+		return nil, errors.New("cose: invalid COSE_Key: " + err.Error())
+	}
+	if k.Kty != ktyOKP {
+		return nil, errors.New("cose: unsupported COSE_Key key type")
+	}
+	if len(k.X) != 32 {
+		return nil, errors.New("cose: invalid COSE_Key public key")
+	}
+	if k.D != nil && len(k.D) != 32 {
+		return nil, errors.New("cose: invalid COSE_Key private key")
+	}
+
+	var key any
+	var pub []byte
+	switch k.Crv {
+	case crvEd25519:
+		if k.Alg != 0 && k.Alg != AlgEdDSA {
+			return nil, errors.New("cose: Ed25519 COSE_Key for an algorithm other than EdDSA")
+		}
+		if k.D == nil {
+			return ed25519.PublicKey(k.X), nil
+		}
+		priv := ed25519.NewKeyFromSeed(k.D)
+		key, pub = priv, priv[ed25519.SeedSize:]
+	case crvX25519:
+		if k.D == nil {
+			return ecdh.X25519().NewPublicKey(k.X)
+		}
+		priv, err := ecdh.X25519().NewPrivateKey(k.D)
+		if err != nil {
+			return nil, err
+		}
+		key, pub = priv, priv.PublicKey().Bytes()
+	default:
+		return nil, errors.New("cose: unsupported COSE_Key curve")
+	}
+	if subtle.ConstantTimeCompare(pub, k.X) != 1 {
+		return nil, errors.New("cose: COSE_Key public key doesn't match the private key")
+	}
+	return key, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cose
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/ecdh"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// rfc8032Key returns the key of RFC 8032, Section 7.1, test 1.
+func rfc8032Key(t *testing.T) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(mustDecodeHex(t, "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"))
+}
+
+func TestKeyEd25519(t *testing.T) {
+	priv := rfc8032Key(t)
+	const (
+		x = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+		d = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+	)
+	// {1: 1, -1: 6, -2: x, -4: d}
+	wantPriv := "a4010120062158" + "20" + x + "2358" + "20" + d
+	wantPub := "a3010120062158" + "20" + x
+
+	out, err := MarshalKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(out); got != wantPriv {
+		t.Errorf("private key: got %s, want %s", got, wantPriv)
+	}
+	key, err := ParseKey(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equal(key) {
+		t.Errorf("round trip returned %T %x", key, key)
+	}
+
+	out, err = MarshalKey(ed25519.PublicKey(priv[ed25519.SeedSize:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(out); got != wantPub {
+		t.Errorf("public key: got %s, want %s", got, wantPub)
+	}
+	key, err = ParseKey(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub, ok := key.(ed25519.PublicKey); !ok || !pub.Equal(priv.Public()) {
+		t.Errorf("round trip returned %T %x", key, key)
+	}
+
+	// A WebAuthn-style key, with kid and alg.
+	key, err = ParseKey(mustDecodeHex(t, "a501010243313233032720062158"+"20"+x))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := key.(ed25519.PublicKey); !ok {
+		t.Errorf("got %T", key)
+	}
+
+	for name, s := range map[string]string{
+		"kty":      "a3010220062158" + "20" + x,
+		"crv":      "a3010120072158" + "20" + x,
+		"alg":      "a4010103262006215820" + x,
+		"short x":  "a3010120062158" + "1f" + x[:62],
+		"x type":   "a3010120062178" + "20" + strings.Repeat("61", 32),
+		"mismatch": "a4010120062158" + "20" + d + "2358" + "20" + d,
+		"not map":  "83010203",
+		"trailing": wantPub + "00",
+		"dup kty":  "a4010120062158" + "20" + x + "0101",
+	} {
+		if _, err := ParseKey(mustDecodeHex(t, s)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestKeyX25519(t *testing.T) {
+	priv, err := ecdh.X25519().GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []any{priv, priv.PublicKey()} {
+		out, err := MarshalKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := ParseKey(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var equal bool
+		switch key := key.(type) {
+		case *ecdh.PrivateKey:
+			equal = key.Equal(k)
+		case *ecdh.PublicKey:
+			equal = key.Equal(k)
+		}
+		if !equal {
+			t.Errorf("round trip of %x returned %T", out, key)
+		}
+	}
+	if _, err := MarshalKey("not a key"); err == nil {
+		t.Error("string marshaled")
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cose
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gtank/ed25519"
+)
+
+// tagSign1 is the CBOR tag of COSE_Sign1 messages, RFC 9052, Section 4.2.
+const tagSign1 = 18
+
+// sign1Message is a COSE_Sign1 structure. A nil Payload is a detached one.
+type sign1Message struct {
+	_           struct{} `cbor:",toarray"`
+	Protected   []byte
+	Unprotected map[any]any
+	Payload     []byte
+	Signature   []byte
+}
+
+// protectedHeader holds the header parameters of RFC 9052, Section 3.1 that
+// VerifySign1 looks at.
+type protectedHeader struct {
+	Alg  int64           `cbor:"1,keyasint,omitempty"`
+	Crit cbor.RawMessage `cbor:"2,keyasint,omitempty"`
+}
+
+// Sign1 returns a tagged COSE_Sign1 message with payload, signed by priv
+// with EdDSA, as in RFC 9052, Section 4.2. The protected header holds only
+// the algorithm, and the unprotected header is empty. externalAAD is
+// authenticated but not included in the message, and can be nil.
+func Sign1(priv ed25519.PrivateKey, payload, externalAAD []byte) ([]byte, error) {
+	if l := len(priv); l != ed25519.PrivateKeySize {
+		panic("cose: bad private key length: " + strconv.Itoa(l))
+	}
+	protected, err := encMode.Marshal(protectedHeader{Alg: AlgEdDSA})
+	if err != nil {
+		return nil, err
+	}
+	toBeSigned, err := sigStructure(protected, payload, externalAAD)
+	if err != nil {
+		return nil, err
+	}
+	return encMode.Marshal(cbor.Tag{Number: tagSign1, Content: sign1Message{
+		Protected:   protected,
+		Unprotected: map[any]any{},
+		Payload:     payload,
+		Signature:   ed25519.Sign(priv, toBeSigned),
+	}})
+}
+
+// VerifySign1 checks that msg is a COSE_Sign1 message signed by pub with
+// EdDSA, and returns its payload. The message can be tagged or untagged.
+//
+// The algorithm must be in the protected header, so that it's covered by
+// the signature. Messages with critical header parameters, which VerifySign1
+// doesn't implement, and with detached payloads, are rejected.
+func VerifySign1(msg []byte, pub ed25519.PublicKey, externalAAD []byte) ([]byte, error) {
+	if l := len(pub); l != ed25519.PublicKeySize {
+		panic("cose: bad public key length: " + strconv.Itoa(l))
+	}
+	var tag cbor.RawTag
+	if err := decMode.Unmarshal(msg, &tag); err == nil {
+		if tag.Number != tagSign1 {
+			return nil, errors.New("cose: unexpected tag " + strconv.FormatUint(tag.Number, 10))
+		}
+		msg = tag.Content
+	}
+	var m sign1Message
+	if err := decMode.Unmarshal(msg, &m); err != nil {
+		return nil, errors.New("cose: invalid COSE_Sign1 structure: " + err.Error())
+	}
+	if m.Unprotected == nil || m.Signature == nil {
+		return nil, errors.New("cose: invalid COSE_Sign1 structure")
+	}
+	if m.Payload == nil {
+		return nil, errors.New("cose: detached COSE_Sign1 payloads are not supported")
+	}
+
+	// An empty protected header is encoded as a zero-length byte string.
+	var header protectedHeader
+	if len(m.Protected) != 0 {
+		if err := decMode.Unmarshal(m.Protected, &header); err != nil {
+			return nil, errors.New("cose: invalid protected header")
+		}
+	}
+	if header.Alg != AlgEdDSA {
+		return nil, errors.New("cose: COSE_Sign1 algorithm is not EdDSA")
+	}
+	if header.Crit != nil {
+		return nil, errors.New("cose: unsupported critical header parameters")
+	}
+
+	// The signature covers the protected header as it was received.
+	toBeSigned, err := sigStructure(m.Protected, m.Payload, externalAAD)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(pub, toBeSigned, m.Signature) {
+		return nil, errors.New("cose: invalid COSE_Sign1 signature")
+	}
+	return m.Payload, nil
+}
+
+// sigStructure returns the Sig_structure of a COSE_Sign1 message, the input
+// of the signature, RFC 9052, Section 4.4.
+func sigStructure(protected, payload, externalAAD []byte) ([]byte, error) {
+	return encMode.Marshal([]any{"Signature1", protected, externalAAD, payload})
+}
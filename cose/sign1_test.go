@@ -0,0 +1,113 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cose
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+// COSE_Sign1 messages with the content of the examples of RFC 9052, signed
+// by the RFC 8032 test 1 key, without and with external AAD. Computed with
+// libsodium.
+const (
+	testSign1 = "d28443a10127a054546869732069732074686520636f6e74656e742e5840" +
+		"6354488f9f290e36cd80e23762e664a5cb03e4267c66a8cffaef7c66d89a40bf" +
+		"2cbb8222432a08e5ee410d8b540c6931d26fb6af673f7e2100655d8bae765c04"
+	testSign1AAD = "d28443a10127a054546869732069732074686520636f6e74656e742e5840" +
+		"aa0e29d45e315ee58384dceb8a2953123199a9570865963a2c5c4792fe16545f" +
+		"43e53faab34d332e58fc88e88f3d6fae3dcf4d9f7c3f34dc405f163e4bb22c0c"
+	testExternalAAD = "11aa22bb33cc44dd55006699"
+)
+
+var testPayload = []byte("This is the content.")
+
+// testSign1Kid is the COSE_Sign1 message of the COSE WG eddsa-sig-01
+// example: the same payload, signature and key, with the unprotected header
+// {4: '11'} naming the key.
+const testSign1Kid = "d28443a10127a10442313154546869732069732074686520636f6e74656e742e5840" +
+	"6354488f9f290e36cd80e23762e664a5cb03e4267c66a8cffaef7c66d89a40bf" +
+	"2cbb8222432a08e5ee410d8b540c6931d26fb6af673f7e2100655d8bae765c04"
+
+func TestSign1(t *testing.T) {
+	priv := rfc8032Key(t)
+	for _, tc := range []struct{ aad, want string }{
+		{"", testSign1},
+		{testExternalAAD, testSign1AAD},
+	} {
+		msg, err := Sign1(priv, testPayload, mustDecodeHex(t, tc.aad))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(msg); got != tc.want {
+			t.Errorf("aad %q: got %s, want %s", tc.aad, got, tc.want)
+		}
+	}
+}
+
+func TestVerifySign1(t *testing.T) {
+	priv := rfc8032Key(t)
+	pub := ed25519.PublicKey(priv[ed25519.SeedSize:])
+
+	payload, err := VerifySign1(mustDecodeHex(t, testSign1), pub, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != string(testPayload) {
+		t.Errorf("payload = %q", payload)
+	}
+	if _, err := VerifySign1(mustDecodeHex(t, testSign1AAD), pub, mustDecodeHex(t, testExternalAAD)); err != nil {
+		t.Errorf("with external AAD: %v", err)
+	}
+	if _, err := VerifySign1(mustDecodeHex(t, testSign1Kid), pub, nil); err != nil {
+		t.Errorf("eddsa-sig-01: %v", err)
+	}
+	// Untagged.
+	if _, err := VerifySign1(mustDecodeHex(t, testSign1[2:]), pub, nil); err != nil {
+		t.Errorf("untagged: %v", err)
+	}
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	body := testSign1[len("d28443a10127a0"):]
+	for name, tc := range map[string]struct {
+		msg, aad string
+		pub      ed25519.PublicKey
+	}{
+		"aad":          {testSign1, testExternalAAD, pub},
+		"missing aad":  {testSign1AAD, "", pub},
+		"key":          {testSign1, "", other},
+		"tag":          {"d1" + testSign1[2:], "", pub},
+		"alg":          {"d28443a10126a0" + body, "", pub},
+		"unprotected":  {"d28440a10127" + body, "", pub},
+		"crit":         {"d28447a201270281187b" + "a0" + body, "", pub},
+		"detached":     {"d28443a10127a0f65840" + testSign1[len(testSign1)-128:], "", pub},
+		"three items":  {"d28343a10127a0" + body[:len(body)-132], "", pub},
+		"payload":      {strings.Replace(testSign1, "5468697320", "5468617420", 1), "", pub},
+		"trailing":     {testSign1 + "00", "", pub},
+		"not an array": {"d2a0", "", pub},
+		"indefinite":   {"d29f43a10127a0" + body + "ff", "", pub},
+	} {
+		msg, err := hex.DecodeString(tc.msg)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, err := VerifySign1(msg, tc.pub, mustDecodeHex(t, tc.aad)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	// Round trip with a fresh key and an empty payload.
+	pub, priv, _ = ed25519.GenerateKey(nil)
+	msg, err := Sign1(priv, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload, err := VerifySign1(msg, pub, nil); err != nil || len(payload) != 0 {
+		t.Errorf("empty payload: %q, %v", payload, err)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"sync"
+)
+
+type curve25519Curve struct {
+	*elliptic.CurveParams
+}
+
+var curve25519Once sync.Once
+var curve25519Params = &elliptic.CurveParams{Name: "curve25519"}
+var curve25519 = curve25519Curve{curve25519Params}
+
+// curve25519 is the Montgomery curve v^2 = u^3 + A*u^2 + u, with A = 486662,
+// over the same field as edwards25519. Its base point is the one of RFC 7748,
+// which corresponds to the edwards25519 generator. As with Ed25519, B is
+// irrelevant, so we pretend it is A.
+func initCurve25519Params() {
+	curve25519Params.P, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed", 16)
+	curve25519Params.N, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	curve25519Params.B = new(big.Int).Set(montgomeryA)
+	curve25519Params.Gx = big.NewInt(9)
+	curve25519Params.Gy, _ = new(big.Int).SetString("14781619447589544791020593568409986887264606134616475288964881837755586237401", 10)
+	curve25519Params.BitSize = 256
+}
+
+// Curve25519 returns a Curve that implements the Montgomery form of
+// curve25519, v^2 = u^3 + 486662*u^2 + u, with points as affine (u, v) pairs.
+//
+// It's meant for tooling that wants to work with the Montgomery model through
+// the elliptic.Curve interface, not for Diffie-Hellman, which X25519 does on
+// u-coordinates alone. The group operations map their inputs to edwards25519,
+// compute there, and map the results back.
+//
+// As usual for elliptic.Curve, (0, 0) stands for the point at infinity, so
+// the point of order two (0, 0) of curve25519 can't be represented: it's
+// read as the point at infinity, and results equal to it are returned as
+// (0, 0) too.
+func Curve25519() elliptic.Curve {
+	curve25519Once.Do(initCurve25519Params)
+	return curve25519
+}
+
+// Params returns the parameters for the curve.
+func (curve curve25519Curve) Params() *elliptic.CurveParams {
+	return curve.CurveParams
+}
+
+// IsOnCurve reports whether the given (u, v) lies on the curve, with
+// coordinates in [0, p).
+func (curve curve25519Curve) IsOnCurve(u, v *big.Int) bool {
+	return isOnMontgomery(u, v)
+}
+
+// toEdwards returns the edwards25519 point equivalent to (u, v), mapping
+// the point at infinity (0, 0) to the identity.
+func (curve curve25519Curve) toEdwards(u, v *big.Int) (x, y *big.Int) {
+	if u.Sign() == 0 && v.Sign() == 0 {
+		return big.NewInt(0), big.NewInt(1)
+	}
+	return MontgomeryToEdwards(u, v)
+}
+
+// fromEdwards returns the curve25519 point equivalent to (x, y), mapping the
+// identity to (0, 0).
+func (curve curve25519Curve) fromEdwards(x, y *big.Int) (u, v *big.Int) {
+	return EdwardsToMontgomery(x, y)
+}
+
+// Add returns the sum of (u1, v1) and (u2, v2).
+func (curve curve25519Curve) Add(u1, v1, u2, v2 *big.Int) (u, v *big.Int) {
+	x1, y1 := curve.toEdwards(u1, v1)
+	x2, y2 := curve.toEdwards(u2, v2)
+	return curve.fromEdwards(Ed25519().Add(x1, y1, x2, y2))
+}
+
+// Double returns 2*(u, v).
+func (curve curve25519Curve) Double(u1, v1 *big.Int) (u, v *big.Int) {
+	x, y := curve.toEdwards(u1, v1)
+	return curve.fromEdwards(Ed25519().Double(x, y))
+}
+
+// ScalarMult returns k*(u, v), where k is a number in big-endian form. Like
+// Ed25519().ScalarMult, it's exact for any k, also for points outside the
+// prime-order subgroup.
+func (curve curve25519Curve) ScalarMult(u1, v1 *big.Int, k []byte) (u, v *big.Int) {
+	x, y := curve.toEdwards(u1, v1)
+	return curve.fromEdwards(Ed25519().ScalarMult(x, y, k))
+}
+
+// ScalarBaseMult returns k*G, where G is the base point of the curve and k is
+// an integer in big-endian form.
+func (curve curve25519Curve) ScalarBaseMult(k []byte) (u, v *big.Int) {
+	return curve.fromEdwards(Ed25519().ScalarBaseMult(k))
+}
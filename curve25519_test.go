@@ -0,0 +1,74 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestCurve25519(t *testing.T) {
+	curve := Curve25519()
+	params := curve.Params()
+	if !curve.IsOnCurve(params.Gx, params.Gy) {
+		t.Fatal("base point is not on the curve")
+	}
+	x, y := MontgomeryToEdwards(params.Gx, params.Gy)
+	if x.Cmp(Ed25519().Params().Gx) != 0 || y.Cmp(Ed25519().Params().Gy) != 0 {
+		t.Error("base point doesn't correspond to the edwards25519 generator")
+	}
+	if curve.IsOnCurve(params.Gx, new(big.Int).Add(params.Gy, bigOne)) {
+		t.Error("off-curve point accepted")
+	}
+
+	// The group law is consistent with X25519 on u-coordinates.
+	for i := 0; i < 8; i++ {
+		k := make([]byte, 32)
+		rand.Read(k)
+		k[0] &= 248
+		k[31] &= 127
+		k[31] |= 64
+		be := reverseBytes(append([]byte{}, k...))
+
+		u, v := curve.ScalarBaseMult(be)
+		if !curve.IsOnCurve(u, v) {
+			t.Fatal("ScalarBaseMult result is not on the curve")
+		}
+		if got := CoordinateToLittleEndian(u); !bytes.Equal(got, X25519Base(k)) {
+			t.Errorf("u = %x, want %x", got, X25519Base(k))
+		}
+		u2, v2 := curve.ScalarMult(params.Gx, params.Gy, be)
+		if u.Cmp(u2) != 0 || v.Cmp(v2) != 0 {
+			t.Error("ScalarMult and ScalarBaseMult disagree")
+		}
+
+		du, dv := curve.Double(u, v)
+		au, av := curve.Add(u, v, u, v)
+		if du.Cmp(au) != 0 || dv.Cmp(av) != 0 {
+			t.Error("Double and Add disagree")
+		}
+		if !curve.IsOnCurve(du, dv) {
+			t.Error("Double result is not on the curve")
+		}
+	}
+
+	// (0, 0) is the point at infinity.
+	zero := big.NewInt(0)
+	u, v := curve.Add(params.Gx, params.Gy, zero, zero)
+	if u.Cmp(params.Gx) != 0 || v.Cmp(params.Gy) != 0 {
+		t.Error("G + infinity != G")
+	}
+	u, v = curve.ScalarBaseMult(params.N.Bytes())
+	if u.Sign() != 0 || v.Sign() != 0 {
+		t.Errorf("N*G = (%v, %v), want infinity", u, v)
+	}
+	negGy := new(big.Int).Sub(params.P, params.Gy)
+	u, v = curve.Add(params.Gx, params.Gy, params.Gx, negGy)
+	if u.Sign() != 0 || v.Sign() != 0 {
+		t.Errorf("G - G = (%v, %v), want infinity", u, v)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/hkdf"
+	"crypto/sha512"
+)
+
+// DeriveScalar deterministically derives a scalar from a high-entropy
+// secret with HKDF-SHA-512 (RFC 5869). The salt is optional, and info
+// separates the scalars derived from the same secret: different info strings
+// give independent scalars.
+//
+// HKDF is expanded to 64 bytes, which are reduced modulo L as by
+// SetUniformBytes, so the result is uniform and can be used anywhere a
+// random scalar can.
+func DeriveScalar(secret, salt, info []byte) (*Scalar, error) {
+	okm, err := hkdf.Key(sha512.New, secret, salt, string(info), 64)
+	if err != nil {
+		return nil, err
+	}
+	return NewScalar().SetUniformBytes(okm)
+}
+
+// DeriveClampedScalarBytes is like DeriveScalar, but it expands HKDF to 32
+// bytes and applies ClampScalarBytes instead of reducing. The result is a
+// private scalar in the RFC 7748 form, for Point.ScalarMult and X25519, where
+// the multiple-of-8 property protects against small-order points.
+func DeriveClampedScalarBytes(secret, salt, info []byte) ([32]byte, error) {
+	okm, err := hkdf.Key(sha512.New, secret, salt, string(info), 32)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return ClampScalarBytes([32]byte(okm)), nil
+}
+
+// DeriveSharedKey returns a length-byte key shared with the owner of the
+// X25519 public key pub, derived from the X25519 output of the private key
+// priv with HKDF-SHA-512. Raw X25519 outputs are not uniformly random, and
+// shouldn't be used as keys directly.
+//
+// The label is the HKDF info, and separates the keys derived for different
+// purposes. Both public keys are the salt, in lexicographic order, so that
+// both parties derive the same key and it's bound to them, as in libsodium's
+// crypto_kx. It returns an error if pub is of low order, or if length is
+// larger than 255 * 64. It panics if len(priv) or len(pub) is not
+// X25519Size.
+func DeriveSharedKey(priv, pub, label []byte, length int) ([]byte, error) {
+	shared, err := (&X25519Options{Contributory: true}).X25519(priv, pub)
+	if err != nil {
+		return nil, err
+	}
+	salt := X25519Base(priv)
+	if bytes.Compare(salt, pub) < 0 {
+		salt = append(salt, pub...)
+	} else {
+		salt = append(append([]byte{}, pub...), salt...)
+	}
+	return hkdf.Key(sha512.New, shared, salt, string(label), length)
+}
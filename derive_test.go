@@ -0,0 +1,79 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestDeriveScalar(t *testing.T) {
+	secret, salt := []byte("master secret"), []byte("salt")
+
+	s, err := DeriveScalar(secret, salt, []byte("signing key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a6399d864cac1c8fef4cf55260c5145172d475ed97be60bfdfce887980355408"
+	if got := hex.EncodeToString(s.Bytes()); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	other, err := DeriveScalar(secret, salt, []byte("encryption key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Equal(other) == 1 {
+		t.Error("different info strings gave the same scalar")
+	}
+
+	k, err := DeriveClampedScalarBytes(secret, salt, []byte("signing key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "70e969e381090ea0dd1919b30f7d18c2473e849db6f74f06ac0a6c1bb7e06758"
+	if got := hex.EncodeToString(k[:]); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestDeriveSharedKey(t *testing.T) {
+	// The key pairs of RFC 7748, Section 6.1.
+	alicePriv := mustDecodeHex(t, "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	alicePub := mustDecodeHex(t, "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a")
+	bobPriv := mustDecodeHex(t, "5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+	bobPub := mustDecodeHex(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+	label := []byte("test label")
+
+	a, err := DeriveSharedKey(alicePriv, bobPub, label, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "25779e8014ae2178676307edf5928af55aadf9eb5037557bb2b4a1f6691802cc"
+	if got := hex.EncodeToString(a); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	b, err := DeriveSharedKey(bobPriv, alicePub, label, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("parties derived different keys: %x, %x", a, b)
+	}
+
+	if other, _ := DeriveSharedKey(alicePriv, bobPub, []byte("other label"), 32); bytes.Equal(a, other) {
+		t.Error("different labels gave the same key")
+	}
+	if long, _ := DeriveSharedKey(alicePriv, bobPub, label, 100); !bytes.Equal(long[:32], a) || len(long) != 100 {
+		t.Errorf("got %x, want a 100-byte extension of %x", long, a)
+	}
+	if _, err := DeriveSharedKey(alicePriv, make([]byte, X25519Size), label, 32); err == nil {
+		t.Error("low order public key accepted")
+	}
+	if _, err := DeriveSharedKey(alicePriv, bobPub, label, 255*64+1); err == nil {
+		t.Error("too long key accepted")
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dhkem implements DHKEM(X25519, HKDF-SHA256), the X25519 key
+// encapsulation mechanism of HPKE, as specified in RFC 9180, Section 4.1.
+//
+// Encap and PrivateKey.Decap can be used with any HPKE implementation that
+// accepts an external KEM. PrivateKey also implements ecdh.KeyExchanger, so
+// it can be passed to hpke.NewDHKEMPrivateKey to use it with crypto/hpke.
+package dhkem
+
+import (
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gtank/ed25519/internal/group"
+)
+
+// Sizes of DHKEM(X25519, HKDF-SHA256) values, in bytes, named as in RFC 9180.
+const (
+	// Nsecret is the size of the shared secret.
+	Nsecret = 32
+	// Nenc is the size of the encapsulated key.
+	Nenc = 32
+	// Npk is the size of a public key.
+	Npk = 32
+	// Nsk is the size of a private key.
+	Nsk = 32
+)
+
+// ID is the HPKE KEM identifier of DHKEM(X25519, HKDF-SHA256).
+const ID = 0x0020
+
+var suiteID = []byte{'K', 'E', 'M', ID >> 8, ID & 0xff}
+
+var basepoint = [32]byte{9}
+
+// PrivateKey is a DHKEM(X25519, HKDF-SHA256) private key.
+type PrivateKey struct {
+	priv [Nsk]byte
+	pub  [Npk]byte
+}
+
+// GenerateKey generates a private key using entropy from rand.
+func GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	ikm := make([]byte, Nsk)
+	if _, err := io.ReadFull(rand, ikm); err != nil {
+		return nil, err
+	}
+	return DeriveKeyPair(ikm)
+}
+
+// DeriveKeyPair deterministically derives a private key from the input
+// keying material ikm, which must have at least Nsk bytes of entropy, as in
+// RFC 9180, Section 7.1.3.
+func DeriveKeyPair(ikm []byte) (*PrivateKey, error) {
+	prk, err := labeledExtract(nil, "dkp_prk", ikm)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := labeledExpand(prk, "sk", nil, Nsk)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKey(sk)
+}
+
+// NewPrivateKey returns the private key encoded by key, an X25519 scalar, as
+// in DeserializePrivateKey. It returns an error if len(key) is not Nsk.
+func NewPrivateKey(key []byte) (*PrivateKey, error) {
+	if len(key) != Nsk {
+		return nil, errors.New("dhkem: bad private key length: " + strconv.Itoa(len(key)))
+	}
+	k := new(PrivateKey)
+	copy(k.priv[:], key)
+	group.X25519(&k.pub, &k.priv, &basepoint)
+	return k, nil
+}
+
+// Bytes returns the encoding of k, as in SerializePrivateKey. It's clamped,
+// as RFC 9180, Section 7.1.2 requires, so it might not match the input of
+// NewPrivateKey.
+func (k *PrivateKey) Bytes() []byte {
+	out := append([]byte{}, k.priv[:]...)
+	out[0] &= 248
+	out[31] &= 127
+	out[31] |= 64
+	return out
+}
+
+// PublicKeyBytes returns the encoding of the public key of k, as in
+// SerializePublicKey.
+func (k *PrivateKey) PublicKeyBytes() []byte {
+	return append([]byte{}, k.pub[:]...)
+}
+
+// PublicKey returns the public key of k, implementing ecdh.KeyExchanger.
+func (k *PrivateKey) PublicKey() *ecdh.PublicKey {
+	pub, err := ecdh.X25519().NewPublicKey(k.pub[:])
+	if err != nil {
+		panic("dhkem: internal error: invalid public key")
+	}
+	return pub
+}
+
+// Curve returns ecdh.X25519(), implementing ecdh.KeyExchanger.
+func (k *PrivateKey) Curve() ecdh.Curve {
+	return ecdh.X25519()
+}
+
+// ECDH returns the X25519 shared secret of k and remote, implementing
+// ecdh.KeyExchanger. It returns an error if the secret is all zeroes, which
+// means remote is of low order.
+func (k *PrivateKey) ECDH(remote *ecdh.PublicKey) ([]byte, error) {
+	if remote.Curve() != ecdh.X25519() {
+		return nil, errors.New("dhkem: public key is not an X25519 key")
+	}
+	return k.dh(remote.Bytes())
+}
+
+// dh returns the X25519 shared secret of k and pub, rejecting the all-zero
+// output as RFC 9180, Section 7.1.4 requires.
+func (k *PrivateKey) dh(pub []byte) ([]byte, error) {
+	if len(pub) != Npk {
+		return nil, errors.New("dhkem: bad public key length: " + strconv.Itoa(len(pub)))
+	}
+	var u, shared, zero [32]byte
+	copy(u[:], pub)
+	group.X25519(&shared, &k.priv, &u)
+	if subtle.ConstantTimeCompare(shared[:], zero[:]) == 1 {
+		return nil, errors.New("dhkem: low order public key")
+	}
+	return shared[:], nil
+}
+
+// Encap generates a shared secret for the public key pkR, and its
+// encapsulation enc, to be sent to the owner of pkR. The ephemeral key is
+// derived from Nsk bytes read from rand, so the test vectors of RFC 9180 can
+// be reproduced by passing their ikmE.
+func Encap(rand io.Reader, pkR []byte) (sharedSecret, enc []byte, err error) {
+	skE, err := GenerateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	dh, err := skE.dh(pkR)
+	if err != nil {
+		return nil, nil, err
+	}
+	enc = skE.PublicKeyBytes()
+	sharedSecret, err = extractAndExpand(dh, append(append([]byte{}, enc...), pkR...))
+	if err != nil {
+		return nil, nil, err
+	}
+	return sharedSecret, enc, nil
+}
+
+// Decap recovers the shared secret from the encapsulation enc produced by
+// Encap for the public key of k.
+func (k *PrivateKey) Decap(enc []byte) ([]byte, error) {
+	dh, err := k.dh(enc)
+	if err != nil {
+		return nil, err
+	}
+	return extractAndExpand(dh, append(append([]byte{}, enc...), k.pub[:]...))
+}
+
+func extractAndExpand(dh, kemContext []byte) ([]byte, error) {
+	prk, err := labeledExtract(nil, "eae_prk", dh)
+	if err != nil {
+		return nil, err
+	}
+	return labeledExpand(prk, "shared_secret", kemContext, Nsecret)
+}
+
+// labeledExtract is LabeledExtract of RFC 9180, Section 4.
+func labeledExtract(salt []byte, label string, ikm []byte) ([]byte, error) {
+	labeled := append([]byte("HPKE-v1"), suiteID...)
+	labeled = append(labeled, label...)
+	labeled = append(labeled, ikm...)
+	return hkdf.Extract(sha256.New, labeled, salt)
+}
+
+// labeledExpand is LabeledExpand of RFC 9180, Section 4.
+func labeledExpand(prk []byte, label string, info []byte, length int) ([]byte, error) {
+	labeled := binary.BigEndian.AppendUint16(nil, uint16(length))
+	labeled = append(labeled, "HPKE-v1"...)
+	labeled = append(labeled, suiteID...)
+	labeled = append(labeled, label...)
+	labeled = append(labeled, info...)
+	return hkdf.Expand(sha256.New, prk, string(labeled), length)
+}
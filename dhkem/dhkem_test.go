@@ -0,0 +1,120 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dhkem
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/hpke"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// RFC 9180, Appendix A.1.
+func TestRFC9180(t *testing.T) {
+	ikmE := decodeHex(t, "7268600d403fce431561aef583ee1613527cff655c1343f29812e66706df3234")
+	ikmR := decodeHex(t, "6db9df30aa07dd42ee5e8181afdb977e538f5e1fec8a06223f33f7013e525037")
+	pkRm := decodeHex(t, "3948cfe0ad1ddb695d780e59077195da6c56506b027329794ab02bca80815c4d")
+	enc := decodeHex(t, "37fda3567bdbd628e88668c3c8d7e97d1d1253b6d4ea6d44c150f741f1bf4431")
+	sharedSecret := decodeHex(t, "fe0e18c9f024ce43799ae393c7e8fe8fce9d218875e8227b0187c04e7d2ea1fc")
+
+	skR, err := DeriveKeyPair(ikmR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(skR.PublicKeyBytes(), pkRm) {
+		t.Errorf("pkRm = %x, want %x", skR.PublicKeyBytes(), pkRm)
+	}
+
+	ss, gotEnc, err := Encap(bytes.NewReader(ikmE), pkRm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gotEnc, enc) {
+		t.Errorf("enc = %x, want %x", gotEnc, enc)
+	}
+	if !bytes.Equal(ss, sharedSecret) {
+		t.Errorf("Encap shared secret = %x, want %x", ss, sharedSecret)
+	}
+	ss, err = skR.Decap(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ss, sharedSecret) {
+		t.Errorf("Decap shared secret = %x, want %x", ss, sharedSecret)
+	}
+}
+
+func TestCryptoHPKE(t *testing.T) {
+	k, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// DeriveKeyPair and serialization agree with crypto/hpke.
+	kem := hpke.DHKEM(ecdh.X25519())
+	ikm := make([]byte, 32)
+	rand.Read(ikm)
+	want, err := kem.DeriveKeyPair(ikm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DeriveKeyPair(ikm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantBytes, _ := want.Bytes()
+	if !bytes.Equal(got.Bytes(), wantBytes) || !bytes.Equal(got.PublicKeyBytes(), want.PublicKey().Bytes()) {
+		t.Errorf("DeriveKeyPair disagrees with crypto/hpke")
+	}
+
+	// A message sealed by crypto/hpke opens with k as its KeyExchanger.
+	priv, err := hpke.NewDHKEMPrivateKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := kem.NewPublicKey(k.PublicKeyBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := []byte("dhkem test")
+	ct, err := hpke.Seal(pub, hpke.HKDFSHA256(), hpke.ChaCha20Poly1305(), info, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt, err := hpke.Open(priv, hpke.HKDFSHA256(), hpke.ChaCha20Poly1305(), info, ct)
+	if err != nil || string(pt) != "hello" {
+		t.Errorf("Open got %q, %v", pt, err)
+	}
+}
+
+func TestLowOrder(t *testing.T) {
+	k, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero := make([]byte, Npk)
+	if _, _, err := Encap(rand.Reader, zero); err == nil {
+		t.Error("encapsulated to u = 0")
+	}
+	if _, err := k.Decap(zero); err == nil {
+		t.Error("decapsulated u = 0")
+	}
+	if _, err := k.Decap(zero[:31]); err == nil {
+		t.Error("decapsulated a short encapsulation")
+	}
+	if _, err := NewPrivateKey(zero[:31]); err == nil {
+		t.Error("short private key accepted")
+	}
+}
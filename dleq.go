@@ -0,0 +1,103 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import "io"
+
+// DLEQProofSize is the size of a proof produced by ProveDLEQ, the challenge c
+// followed by the response s.
+const DLEQProofSize = 64
+
+// dleqDomain is the transcript label of DLEQ proofs.
+const dleqDomain = "gtank/ed25519/dleq-v1"
+
+// ProveDLEQ returns a Chaum-Pedersen proof that A = x*G and B = x*H have the
+// same discrete log x with respect to G and H, without revealing x. A and B
+// are computed from x, and the caller is expected to publish them with the
+// proof.
+//
+// The proof is made non-interactive with the Fiat-Shamir transform, over a
+// transcript of context and the four points. context must identify the
+// protocol and session the proof belongs to, so that it can't be replayed in
+// another one. The nonce is derived from x, the transcript and randomness
+// read from rand, so that a broken rand doesn't leak x.
+func ProveDLEQ(rand io.Reader, x *Scalar, G, H *Point, context []byte) ([]byte, error) {
+	A := new(Point).ScalarMult(x.Bytes(), G)
+	B := new(Point).ScalarMult(x.Bytes(), H)
+
+	entropy := make([]byte, 32)
+	if _, err := io.ReadFull(rand, entropy); err != nil {
+		return nil, err
+	}
+	t := dleqTranscript(context, G, H, A, B)
+	k := nonceScalar(t, x, entropy)
+
+	R1 := new(Point).ScalarMult(k.Bytes(), G)
+	R2 := new(Point).ScalarMult(k.Bytes(), H)
+	c := dleqChallenge(t, R1, R2)
+
+	// s = k - c*x
+	s := NewScalar().Mul(c, x)
+	s.Sub(k, s)
+	return append(c.Bytes(), s.Bytes()...), nil
+}
+
+// VerifyDLEQ reports whether proof shows that A and B have the same discrete
+// log with respect to G and H, for context, as produced by ProveDLEQ.
+//
+// All four points must be in the prime-order subgroup, where the discrete log
+// is unique modulo L; VerifyDLEQ returns false otherwise.
+func VerifyDLEQ(G, H, A, B *Point, proof, context []byte) bool {
+	if len(proof) != DLEQProofSize {
+		return false
+	}
+	for _, p := range []*Point{G, H, A, B} {
+		if p.IsTorsionFree() != 1 {
+			return false
+		}
+	}
+	c, err := NewScalar().SetCanonicalBytes(proof[:32])
+	if err != nil {
+		return false
+	}
+	s, err := NewScalar().SetCanonicalBytes(proof[32:])
+	if err != nil {
+		return false
+	}
+
+	// R1 = s*G + c*A, R2 = s*H + c*B
+	R1 := new(Point).VartimeMultiScalarMult([][]byte{s.Bytes(), c.Bytes()}, []*Point{G, A})
+	R2 := new(Point).VartimeMultiScalarMult([][]byte{s.Bytes(), c.Bytes()}, []*Point{H, B})
+	return dleqChallenge(dleqTranscript(context, G, H, A, B), R1, R2).Equal(c) == 1
+}
+
+// dleqTranscript returns the transcript of a DLEQ proof statement.
+func dleqTranscript(context []byte, G, H, A, B *Point) *Transcript {
+	t := NewTranscript(dleqDomain)
+	t.AppendMessage("context", context)
+	t.AppendPoint("G", G)
+	t.AppendPoint("H", H)
+	t.AppendPoint("A", A)
+	t.AppendPoint("B", B)
+	return t
+}
+
+// dleqChallenge appends the commitments to t and returns the Fiat-Shamir
+// challenge of a DLEQ proof.
+func dleqChallenge(t *Transcript, R1, R2 *Point) *Scalar {
+	t.AppendPoint("R1", R1)
+	t.AppendPoint("R2", R2)
+	return t.ChallengeScalar("challenge")
+}
+
+// nonceScalar returns the nonce of a Fiat-Shamir proof of knowledge of x,
+// derived from a fork of the statement transcript t, x and entropy. t itself
+// is left untouched, so that the challenge doesn't depend on the secrets.
+func nonceScalar(t *Transcript, x *Scalar, entropy []byte) *Scalar {
+	nt := t.Clone()
+	nt.AppendScalar("witness", x)
+	nt.AppendMessage("entropy", entropy)
+	return nt.ChallengeScalar("nonce")
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestDLEQ(t *testing.T) {
+	x := randomScalarElement(t)
+	G := NewGeneratorPoint()
+	H := HashToPoint([]byte("H"), []byte("gtank/ed25519/dleq-test_XMD:SHA-512_ELL2_RO_"))
+	A := new(Point).ScalarMult(x.Bytes(), G)
+	B := new(Point).ScalarMult(x.Bytes(), H)
+	context := []byte("test session 1")
+
+	proof, err := ProveDLEQ(rand.Reader, x, G, H, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proof) != DLEQProofSize {
+		t.Fatalf("got %d bytes, want %d", len(proof), DLEQProofSize)
+	}
+	if !VerifyDLEQ(G, H, A, B, proof, context) {
+		t.Fatal("valid proof rejected")
+	}
+
+	if VerifyDLEQ(G, H, A, B, proof, []byte("test session 2")) {
+		t.Error("proof accepted for another context")
+	}
+	if VerifyDLEQ(H, G, B, A, proof, context) {
+		t.Error("proof accepted for swapped bases")
+	}
+	y := randomScalarElement(t)
+	if VerifyDLEQ(G, H, A, new(Point).ScalarMult(y.Bytes(), H), proof, context) {
+		t.Error("proof accepted for a different discrete log")
+	}
+	for _, i := range []int{0, 40} {
+		bad := append([]byte{}, proof...)
+		bad[i] ^= 1
+		if VerifyDLEQ(G, H, A, B, bad, context) {
+			t.Errorf("proof with byte %d flipped accepted", i)
+		}
+	}
+
+	// A small-order component in B would make the logs differ outside the
+	// prime-order subgroup.
+	T, _ := new(Point).SetBytes(order8Bytes)
+	if VerifyDLEQ(G, H, A, new(Point).Add(B, T), proof, context) {
+		t.Error("B with a small-order component accepted")
+	}
+}
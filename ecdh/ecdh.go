@@ -0,0 +1,153 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ecdh implements X25519 Diffie-Hellman with the API of the standard
+// library's crypto/ecdh, backed by this module's implementation.
+//
+// crypto/ecdh.Curve can't be implemented outside the standard library, so
+// this package mirrors it instead: code written against crypto/ecdh and
+// ecdh.X25519() works unchanged after switching the import, which is useful
+// to audit or benchmark one implementation against the other. Keys have the
+// same encodings, and ECDH returns the same shared secrets and errors.
+package ecdh
+
+import (
+	"crypto"
+	cryptorand "crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"io"
+
+	"github.com/gtank/ed25519"
+)
+
+// Curve is the interface of crypto/ecdh.Curve. Its only implementation is
+// X25519.
+type Curve interface {
+	// GenerateKey generates a random PrivateKey, using entropy from rand.
+	// If rand is nil, crypto/rand.Reader is used.
+	GenerateKey(rand io.Reader) (*PrivateKey, error)
+
+	// NewPrivateKey checks that key is valid and returns a PrivateKey. For
+	// X25519, this only checks the scalar length.
+	NewPrivateKey(key []byte) (*PrivateKey, error)
+
+	// NewPublicKey checks that key is valid and returns a PublicKey. For
+	// X25519, this only checks the u-coordinate length. Adversarially
+	// selected public keys can cause ECDH to return an error.
+	NewPublicKey(key []byte) (*PublicKey, error)
+}
+
+type x25519Curve struct{}
+
+var x25519 = &x25519Curve{}
+
+// X25519 returns a Curve which implements the X25519 function over
+// curve25519, as specified in RFC 7748, Section 5. Multiple invocations of
+// this function return the same value, so it can be used for equality checks
+// and switch statements.
+func X25519() Curve { return x25519 }
+
+func (c *x25519Curve) String() string { return "X25519" }
+
+func (c *x25519Curve) GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	key := make([]byte, ed25519.X25519Size)
+	if _, err := io.ReadFull(rand, key); err != nil {
+		return nil, err
+	}
+	return c.NewPrivateKey(key)
+}
+
+func (c *x25519Curve) NewPrivateKey(key []byte) (*PrivateKey, error) {
+	if len(key) != ed25519.X25519Size {
+		return nil, errors.New("ecdh: invalid private key size")
+	}
+	return &PrivateKey{
+		privateKey: append([]byte{}, key...),
+		publicKey:  &PublicKey{publicKey: ed25519.X25519Base(key)},
+	}, nil
+}
+
+func (c *x25519Curve) NewPublicKey(key []byte) (*PublicKey, error) {
+	if len(key) != ed25519.X25519Size {
+		return nil, errors.New("ecdh: invalid public key")
+	}
+	return &PublicKey{publicKey: append([]byte{}, key...)}, nil
+}
+
+// PublicKey is an ECDH public key, usually a peer's ECDH share sent over the
+// wire.
+type PublicKey struct {
+	publicKey []byte
+}
+
+// Bytes returns a copy of the encoding of the public key.
+func (k *PublicKey) Bytes() []byte {
+	return append([]byte{}, k.publicKey...)
+}
+
+// Curve returns the curve of k.
+func (k *PublicKey) Curve() Curve { return x25519 }
+
+// Equal returns whether x represents the same public key as k.
+func (k *PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(*PublicKey)
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare(k.publicKey, xx.publicKey) == 1
+}
+
+// PrivateKey is an ECDH private key, usually kept secret.
+type PrivateKey struct {
+	privateKey []byte
+	publicKey  *PublicKey
+}
+
+// ECDH performs an ECDH exchange and returns the shared secret. The remote
+// key must use the same curve as k.
+//
+// As in crypto/ecdh, the result is never the all-zero value: ECDH returns an
+// error if remote is of low order.
+func (k *PrivateKey) ECDH(remote *PublicKey) ([]byte, error) {
+	if remote.Curve() != k.Curve() {
+		return nil, errors.New("ecdh: private key and public key curves do not match")
+	}
+	out := ed25519.X25519(k.privateKey, remote.publicKey)
+	if subtle.ConstantTimeCompare(out, make([]byte, len(out))) == 1 {
+		return nil, errors.New("ecdh: bad X25519 remote ECDH input: low order point")
+	}
+	return out, nil
+}
+
+// Bytes returns a copy of the encoding of the private key.
+func (k *PrivateKey) Bytes() []byte {
+	return append([]byte{}, k.privateKey...)
+}
+
+// Curve returns the curve of k.
+func (k *PrivateKey) Curve() Curve { return x25519 }
+
+// Equal returns whether x represents the same private key as k.
+func (k *PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(*PrivateKey)
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare(k.privateKey, xx.privateKey) == 1
+}
+
+// Public implements the implicit interface of all standard library private
+// keys.
+func (k *PrivateKey) Public() crypto.PublicKey {
+	return k.PublicKey()
+}
+
+// PublicKey returns the public key corresponding to k.
+func (k *PrivateKey) PublicKey() *PublicKey {
+	return k.publicKey
+}
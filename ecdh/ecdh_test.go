@@ -0,0 +1,99 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecdh
+
+import (
+	"bytes"
+	stdecdh "crypto/ecdh"
+	"crypto/rand"
+	"testing"
+)
+
+func TestECDH(t *testing.T) {
+	alice, err := X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := X25519().GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1, err := alice.ECDH(bob.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := bob.ECDH(alice.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s1, s2) {
+		t.Errorf("shared secrets differ: %x, %x", s1, s2)
+	}
+}
+
+// Keys and shared secrets match crypto/ecdh.
+func TestCryptoECDH(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		std, err := stdecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, err := X25519().NewPrivateKey(std.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(k.Bytes(), std.Bytes()) || !bytes.Equal(k.PublicKey().Bytes(), std.PublicKey().Bytes()) {
+			t.Fatal("key encodings differ from crypto/ecdh")
+		}
+
+		peer, _ := stdecdh.X25519().GenerateKey(rand.Reader)
+		want, err := std.ECDH(peer.PublicKey())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := X25519().NewPublicKey(peer.PublicKey().Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := k.ECDH(pub)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("got %x, %v, want %x", got, err, want)
+		}
+	}
+}
+
+func TestLowOrder(t *testing.T) {
+	k, _ := X25519().GenerateKey(rand.Reader)
+	for _, u := range [][]byte{make([]byte, 32), append([]byte{1}, make([]byte, 31)...)} {
+		pub, err := X25519().NewPublicKey(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := k.ECDH(pub); err == nil {
+			t.Errorf("u = %x accepted", u)
+		}
+	}
+	if _, err := X25519().NewPublicKey(make([]byte, 31)); err == nil {
+		t.Error("short public key accepted")
+	}
+	if _, err := X25519().NewPrivateKey(make([]byte, 33)); err == nil {
+		t.Error("long private key accepted")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	k, _ := X25519().GenerateKey(rand.Reader)
+	k2, _ := X25519().NewPrivateKey(k.Bytes())
+	other, _ := X25519().GenerateKey(rand.Reader)
+	if !k.Equal(k2) || k.Equal(other) {
+		t.Error("PrivateKey.Equal")
+	}
+	if !k.PublicKey().Equal(k2.Public()) || k.PublicKey().Equal(other.PublicKey()) {
+		t.Error("PublicKey.Equal")
+	}
+	if k.Curve() != X25519() || k.PublicKey().Curve() != X25519() {
+		t.Error("Curve")
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ecies implements authenticated hybrid encryption to Ed25519 public
+// keys, so that identity keys can receive encrypted messages.
+//
+// The recipient's key is converted to its X25519 equivalent, and a fresh
+// ephemeral X25519 key pair is generated for every message. The key of the
+// ChaCha20-Poly1305 AEAD is derived from their shared secret with
+// HKDF-SHA-512, salted with both public keys. The ciphertext is
+//
+//	ephemeral public key || ChaCha20-Poly1305(plaintext, aad)
+//
+// Like any anonymous public-key encryption, it authenticates the contents,
+// but not the sender.
+package ecies
+
+import (
+	"crypto/cipher"
+	"crypto/hkdf"
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/internal/group"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Overhead is the number of bytes a ciphertext is longer than its plaintext:
+// the ephemeral public key, and the Poly1305 tag.
+const Overhead = 32 + chacha20poly1305.Overhead
+
+const info = "gtank/ed25519/ecies-v1 X25519 HKDF-SHA-512 ChaCha20-Poly1305"
+
+var basepoint = [32]byte{9}
+
+var errDecrypt = errors.New("ecies: decryption failed")
+
+// Encrypt encrypts and authenticates plaintext to publicKey, and
+// authenticates aad, which must be passed to Decrypt unchanged. It returns an
+// error if publicKey is not a valid point of prime order. It panics if
+// len(publicKey) is not ed25519.PublicKeySize.
+func Encrypt(publicKey ed25519.PublicKey, plaintext, aad []byte) ([]byte, error) {
+	return encrypt(cryptorand.Reader, publicKey, plaintext, aad)
+}
+
+func encrypt(rand io.Reader, publicKey ed25519.PublicKey, plaintext, aad []byte) ([]byte, error) {
+	if l := len(publicKey); l != ed25519.PublicKeySize {
+		panic("ecies: bad public key length: " + strconv.Itoa(l))
+	}
+	pub, err := ed25519.PublicKeyToX25519(publicKey)
+	if err != nil {
+		return nil, errors.New("ecies: invalid public key")
+	}
+	var recipient, ephemeralPriv, ephemeralPub [32]byte
+	copy(recipient[:], pub)
+	if _, err := io.ReadFull(rand, ephemeralPriv[:]); err != nil {
+		return nil, err
+	}
+	group.X25519(&ephemeralPub, &ephemeralPriv, &basepoint)
+
+	aead, err := newAEAD(&ephemeralPriv, &recipient, &ephemeralPub, &recipient)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 32, Overhead+len(plaintext))
+	copy(out, ephemeralPub[:])
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	return aead.Seal(out, nonce, plaintext, aad), nil
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt for the public key of
+// privateKey, and returns the plaintext. It returns an error if the
+// ciphertext was not encrypted to privateKey, or if it or aad were modified.
+// It panics if len(privateKey) is not ed25519.PrivateKeySize.
+func Decrypt(privateKey ed25519.PrivateKey, ciphertext, aad []byte) ([]byte, error) {
+	if l := len(privateKey); l != ed25519.PrivateKeySize {
+		panic("ecies: bad private key length: " + strconv.Itoa(l))
+	}
+	if len(ciphertext) < Overhead {
+		return nil, errDecrypt
+	}
+	pub, err := ed25519.PublicKeyToX25519(ed25519.PublicKey(privateKey[ed25519.SeedSize:]))
+	if err != nil {
+		return nil, errors.New("ecies: invalid private key")
+	}
+	var recipient, priv, ephemeralPub [32]byte
+	copy(recipient[:], pub)
+	copy(priv[:], ed25519.PrivateKeyToX25519(privateKey))
+	copy(ephemeralPub[:], ciphertext)
+
+	aead, err := newAEAD(&priv, &ephemeralPub, &ephemeralPub, &recipient)
+	if err != nil {
+		return nil, errDecrypt
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	plaintext, err := aead.Open(nil, nonce, ciphertext[32:], aad)
+	if err != nil {
+		return nil, errDecrypt
+	}
+	return plaintext, nil
+}
+
+// newAEAD derives the AEAD from the X25519 shared secret of priv and pub,
+// and the ephemeral and recipient public keys. Each key is used for a single
+// message, so the nonce can be fixed.
+func newAEAD(priv, pub, ephemeralPub, recipient *[32]byte) (cipher.AEAD, error) {
+	var shared, zero [32]byte
+	group.X25519(&shared, priv, pub)
+	if subtle.ConstantTimeCompare(shared[:], zero[:]) == 1 {
+		return nil, errors.New("ecies: low order public key")
+	}
+	salt := append(ephemeralPub[:], recipient[:]...)
+	key, err := hkdf.Key(sha512.New, shared[:], salt, info, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecies
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aad := []byte("header")
+	for _, plaintext := range [][]byte{nil, []byte("hello"), bytes.Repeat([]byte{42}, 1000)} {
+		ct, err := Encrypt(pub, plaintext, aad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ct) != len(plaintext)+Overhead {
+			t.Errorf("ciphertext is %d bytes, want %d", len(ct), len(plaintext)+Overhead)
+		}
+		got, err := Decrypt(priv, ct, aad)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("got %x, %v, want %x", got, err, plaintext)
+		}
+
+		for _, i := range []int{0, 32, len(ct) - 1} {
+			bad := append([]byte{}, ct...)
+			bad[i] ^= 1
+			if _, err := Decrypt(priv, bad, aad); err == nil {
+				t.Errorf("ciphertext modified at %d decrypted", i)
+			}
+		}
+		if _, err := Decrypt(priv, ct, nil); err == nil {
+			t.Error("decrypted with the wrong additional data")
+		}
+	}
+
+	// Every encryption uses a fresh ephemeral key.
+	ct1, _ := Encrypt(pub, []byte("hello"), nil)
+	ct2, _ := Encrypt(pub, []byte("hello"), nil)
+	if bytes.Equal(ct1, ct2) {
+		t.Error("encryption is deterministic")
+	}
+
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := Decrypt(other, ct1, nil); err == nil {
+		t.Error("decrypted with the wrong key")
+	}
+	if _, err := Decrypt(priv, ct1[:Overhead-1], nil); err == nil {
+		t.Error("short ciphertext decrypted")
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	// The identity, and points with a small-order component, are rejected.
+	identity := ed25519.NewIdentityPoint().Bytes()
+	if _, err := Encrypt(identity, nil, nil); err == nil {
+		t.Error("encrypted to the identity")
+	}
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	A, _ := new(ed25519.Point).SetBytes(pub)
+	T, _ := new(ed25519.Point).SetBytes([]byte{
+		0xc7, 0x17, 0x6a, 0x70, 0x3d, 0x4d, 0xd8, 0x4f, 0xba, 0x3c, 0x0b, 0x76, 0x0d, 0x10, 0x67, 0x0f,
+		0x2a, 0x20, 0x53, 0xfa, 0x2c, 0x39, 0xcc, 0xc6, 0x4e, 0xc7, 0xfd, 0x77, 0x92, 0xac, 0x03, 0x7a,
+	})
+	if _, err := Encrypt(new(ed25519.Point).Add(A, T).Bytes(), nil, nil); err == nil {
+		t.Error("encrypted to a key with a torsion component")
+	}
+}
@@ -8,7 +8,9 @@
 // by the Ed25519 signature scheme.
 //
 // Because of the Curve interface, this package takes input in affine (x,y)
-// pairs instead of the more standard compressed Edwards y.
+// pairs instead of the more standard compressed Edwards y. Compress and
+// Decompress convert between the two, and the Point type works with the
+// compressed form directly.
 package ed25519
 
 import (
@@ -23,6 +25,10 @@ import (
 var bigZero *big.Int
 var bigOne *big.Int
 
+// curveExponent is 8*N, the order of the full curve group. k*P only depends
+// on k modulo curveExponent, for any point P.
+var curveExponent *big.Int
+
 type ed25519Curve struct {
 	*elliptic.CurveParams
 }
@@ -44,6 +50,7 @@ func initEd25519Params() {
 	ed25519Params.BitSize = 256
 	bigZero = big.NewInt(0)
 	bigOne = big.NewInt(1)
+	curveExponent = new(big.Int).Lsh(ed25519Params.N, 3)
 }
 
 // Ed25519 returns a Curve that implements Ed25519.
@@ -89,6 +96,26 @@ func (curve ed25519Curve) Add(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
 	return p2.Add(&p1, &p2).ToAffine()
 }
 
+// Sub returns the difference of (x1, y1) and (x2, y2) on the Ed25519 curve.
+func Sub(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
+	var p1, p2 group.ExtendedGroupElement
+
+	p1.FromAffine(x1, y1)
+	p2.FromAffine(x2, y2)
+
+	return p2.Sub(&p1, &p2).ToAffine()
+}
+
+// Neg returns -(x1, y1) on the Ed25519 curve. On a twisted Edwards curve this
+// is (-x1, y1).
+func Neg(x1, y1 *big.Int) (x, y *big.Int) {
+	var p group.ExtendedGroupElement
+
+	p.FromAffine(x1, y1)
+
+	return p.Neg(&p).ToAffine()
+}
+
 // Double returns 2*(x,y).
 func (curve ed25519Curve) Double(x1, y1 *big.Int) (x, y *big.Int) {
 	p := new(group.ProjectiveGroupElement).FromAffine(x1, y1)
@@ -96,7 +123,10 @@ func (curve ed25519Curve) Double(x1, y1 *big.Int) (x, y *big.Int) {
 	return p.DoubleZ1(p).ToAffine()
 }
 
-// ScalarMult returns k*(Bx,By) where k is a number in big-endian form.
+// ScalarMult returns k*(Bx,By) where k is a number in big-endian form. k is
+// unsigned and may be of any length; the result is exactly k*(Bx,By), also
+// for points with a small-order component. Use ScalarMultSigned for negative
+// multipliers.
 func (curve ed25519Curve) ScalarMult(x1, y1 *big.Int, k []byte) (x, y *big.Int) {
 	// if either coordinate is nil, return the identity point
 	if x1 == nil || y1 == nil {
@@ -133,12 +163,13 @@ func (curve ed25519Curve) ScalarMult(x1, y1 *big.Int, k []byte) (x, y *big.Int)
 }
 
 // scalarFromBytes converts a big-endian value to a fixed-size little-endian
-// representation. If the value is larger than the scalar group order, we
-// reduce it before returning.
+// representation. If the value is at least 8*N, we reduce it modulo 8*N
+// before returning. Reducing modulo N instead would give the wrong result
+// for points outside the prime-order subgroup.
 func (curve ed25519Curve) scalarFromBytes(out *[32]byte, in []byte) {
 	scalar := new(big.Int).SetBytes(in)
-	if scalar.Cmp(curve.N) >= 0 {
-		scalar.Mod(scalar, curve.N)
+	if scalar.Cmp(curveExponent) >= 0 {
+		scalar.Mod(scalar, curveExponent)
 	}
 	buf := make([]byte, 32)
 	scBytes := scalar.Bytes()
@@ -153,5 +184,38 @@ func (curve ed25519Curve) scalarFromBytes(out *[32]byte, in []byte) {
 // arbitrary-point ScalarMult is the availability of precomputed multiples of
 // the base point.
 func (curve ed25519Curve) ScalarBaseMult(k []byte) (x, y *big.Int) {
-	panic("not yet implemented")
+	var p group.ExtendedGroupElement
+	var s [32]byte
+
+	curve.scalarFromBytes(&s, k)
+
+	return p.ScalarBaseMult(&s).ToAffine()
+}
+
+// CombinedMult returns k1*G + k2*(Xx, Xy), where G is the base point of the
+// curve and k1 and k2 are integers in big-endian form. This is the shape of
+// most verification equations. Both products are computed in one pass that
+// shares a single chain of doublings, and the result is only converted back
+// to affine coordinates once.
+func CombinedMult(Xx, Xy *big.Int, k1, k2 []byte) (x, y *big.Int) {
+	curve := Ed25519().(ed25519Curve)
+
+	var p, r group.ExtendedGroupElement
+	var s1, s2 [32]byte
+
+	curve.scalarFromBytes(&s1, k1)
+	curve.scalarFromBytes(&s2, k2)
+	p.FromAffine(Xx, Xy)
+
+	return r.CombinedMult(&s1, &s2, &p).ToAffine()
+}
+
+// ScalarMultSigned returns k*(x, y) for any integer k, including negative
+// ones: -k*(x, y) is the negation of k*(x, y), and k*(x, y) only depends on k
+// modulo 8*N, for every point on the curve. This is the same result any other
+// correct implementation of the group gives, whatever its scalar encoding.
+func ScalarMultSigned(x, y, k *big.Int) (rx, ry *big.Int) {
+	curve := Ed25519()
+	e := new(big.Int).Mod(k, curveExponent)
+	return curve.ScalarMult(x, y, e.Bytes())
 }
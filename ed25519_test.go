@@ -5,7 +5,10 @@
 package ed25519
 
 import (
+	"bytes"
 	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
 	"io"
 	"math/big"
 	"testing"
@@ -143,6 +146,20 @@ func BenchmarkScalarMult(b *testing.B) {
 	}
 }
 
+func BenchmarkCombinedMult(b *testing.B) {
+	c := Ed25519()
+	Gx, Gy := c.Params().Gx, c.Params().Gy
+
+	k := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, k); err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		_, _ = CombinedMult(Gx, Gy, k, k)
+	}
+}
+
 // // Test vector generated by instrumenting x/crypto/ed25519 GenerateKey
 // // seed: c240344fcc6615dda52da98149377ad2b13fdba2bc39a50ba9f3afb2cbd4abaa
 // // expanded: f04154b9d80963bb4c76214ece8a1049bdd16fbfc5003aff9835a59643ace276
@@ -176,71 +193,71 @@ func BenchmarkScalarMult(b *testing.B) {
 // 	}
 // }
 
-// func TestScalarMultBaseIdentity(t *testing.T) {
-// 	var c = Ed25519()
-// 	var one = new(big.Int).Set(bigOne)
-// 	Ax, Ay := c.ScalarBaseMult(one.Bytes())
+func TestScalarMultBaseIdentity(t *testing.T) {
+	var c = Ed25519()
+	var one = new(big.Int).Set(bigOne)
+	Ax, Ay := c.ScalarBaseMult(one.Bytes())
 
-// 	if Ax.Cmp(c.Params().Gx) != 0 || Ay.Cmp(c.Params().Gy) != 0 {
-// 		t.Errorf("precomputed 1*B != B")
-// 	}
+	if Ax.Cmp(c.Params().Gx) != 0 || Ay.Cmp(c.Params().Gy) != 0 {
+		t.Errorf("precomputed 1*B != B")
+	}
 
-// 	Ax, Ay = c.ScalarMult(Ax, Ay, one.Bytes())
+	Ax, Ay = c.ScalarMult(Ax, Ay, one.Bytes())
 
-// 	if Ax.Cmp(c.Params().Gx) != 0 || Ay.Cmp(c.Params().Gy) != 0 {
-// 		t.Errorf("arbitrary 1*B != B")
-// 	}
-// }
+	if Ax.Cmp(c.Params().Gx) != 0 || Ay.Cmp(c.Params().Gy) != 0 {
+		t.Errorf("arbitrary 1*B != B")
+	}
+}
 
-// func TestScalarMultBaseInfinity(t *testing.T) {
-// 	c := Ed25519()
-// 	a, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000000")
-// 	if len(a) != 32 {
-// 		t.Errorf("failed decoding")
-// 	}
+func TestScalarMultBaseInfinity(t *testing.T) {
+	c := Ed25519()
+	a, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000000")
+	if len(a) != 32 {
+		t.Errorf("failed decoding")
+	}
 
-// 	Ax, Ay := c.ScalarBaseMult(a)
+	Ax, Ay := c.ScalarBaseMult(a)
 
-// 	if !c.IsOnCurve(Ax, Ay) {
-// 		t.Error("scalarmultbase result was off-curve")
-// 	}
+	if !c.IsOnCurve(Ax, Ay) {
+		t.Error("scalarmultbase result was off-curve")
+	}
 
-// 	if Ax.Cmp(bigZero) != 0 || Ay.Cmp(bigOne) != 0 {
-// 		t.Error("scalarmultbase by 0 was not point at infinity")
-// 	}
-// }
+	if Ax.Cmp(bigZero) != 0 || Ay.Cmp(bigOne) != 0 {
+		t.Error("scalarmultbase by 0 was not point at infinity")
+	}
+}
 
-// func TestScalarMultsAgreeAtInfinity(t *testing.T) {
-// 	c := Ed25519()
-// 	a, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000000")
-// 	if len(a) != 32 {
-// 		t.Errorf("failed decoding")
-// 	}
+func TestScalarMultsAgreeAtInfinity(t *testing.T) {
+	c := Ed25519()
+	a, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000000")
+	if len(a) != 32 {
+		t.Errorf("failed decoding")
+	}
 
-// 	Ax, Ay := c.ScalarBaseMult(a)
-// 	Bx, By := c.ScalarMult(c.Params().Gx, c.Params().Gy, a)
+	Ax, Ay := c.ScalarBaseMult(a)
+	Bx, By := c.ScalarMult(c.Params().Gx, c.Params().Gy, a)
 
-// 	if Ax.Cmp(Bx) != 0 || Ay.Cmp(By) != 0 {
-// 		t.Error("scalarmultbase disagrees with scalarmult")
-// 	}
-// }
+	if Ax.Cmp(Bx) != 0 || Ay.Cmp(By) != 0 {
+		t.Error("scalarmultbase disagrees with scalarmult")
+	}
+}
 
-// func TestScalarMultsAgreeElsewhere(t *testing.T) {
-// 	c := Ed25519()
-// 	// head -c 32 /dev/urandom | sha256sum
-// 	a, _ := hex.DecodeString("c07eea55b3322f15099b6cf4d2b7e99d3d0fa6807f6fc7a46b5f7cb78daad4e0")
+func TestScalarMultsAgreeElsewhere(t *testing.T) {
+	c := Ed25519()
+	// head -c 32 /dev/urandom | sha256sum
+	a, _ := hex.DecodeString("c07eea55b3322f15099b6cf4d2b7e99d3d0fa6807f6fc7a46b5f7cb78daad4e0")
 
-// 	Ax, Ay := c.ScalarBaseMult(a)
-// 	Bx, By := c.ScalarMult(c.Params().Gx, c.Params().Gy, a)
+	Ax, Ay := c.ScalarBaseMult(a)
+	Bx, By := c.ScalarMult(c.Params().Gx, c.Params().Gy, a)
 
-// 	if Ax.Cmp(Bx) != 0 || Ay.Cmp(By) != 0 {
-// 		t.Error("scalarmultbase disagrees with scalarmult")
-// 	}
+	if Ax.Cmp(Bx) != 0 || Ay.Cmp(By) != 0 {
+		t.Error("scalarmultbase disagrees with scalarmult")
+	}
 
-// 	if !c.IsOnCurve(Bx, By) {
-// 		t.Error("scalarmult is returning off-curve points")
-// 	}
-// }
+	if !c.IsOnCurve(Bx, By) {
+		t.Error("scalarmult is returning off-curve points")
+	}
+}
 
 // // TEST INTERFACE
 
@@ -283,84 +300,64 @@ func BenchmarkScalarMult(b *testing.B) {
 // 	}
 // }
 
-// // TEST APPLICATION
-
-// func generateKey(r io.Reader) (sk *[32]byte, pk []byte, err error) {
-// 	if r == nil {
-// 		r = rand.Reader
-// 	}
-
-// 	ed := Ed25519()
+// TEST APPLICATION
 
-// 	sk = new([32]byte)
-// 	_, err = io.ReadFull(r, sk[:])
-// 	if err != nil {
-// 		return nil, nil, err
-// 	}
-
-// 	digest := sha512.Sum512(sk[:])
-// 	digest[0] &= 248
-// 	digest[31] &= 127
-// 	digest[31] |= 64
-
-// 	// take the first half of expanded bytes & reverse; big.Int expects big-endian
-// 	reverseDigest := reverse32(digest[:32])
-// 	scalar := new(big.Int).SetBytes(reverseDigest)
-
-// 	// A = a*B
-// 	Ax, Ay := ed.ScalarBaseMult(scalar.Bytes())
-
-// 	// This works with the standard elliptic package marshaling
-// 	publicKey := elliptic.Marshal(ed, Ax, Ay)
+// generateKey derives an Ed25519 key pair through the elliptic.Curve methods,
+// as RFC 8032, Section 5.1.5 describes. It also returns the clamped secret
+// scalar the public key is derived from.
+func generateKey(r io.Reader) (sk, expanded *[32]byte, pk []byte, err error) {
+	ed := Ed25519()
 
-// 	// but we can also render the compressed Edwards format
-// 	compressedEdwardsY := make([]byte, 32)
+	sk = new([32]byte)
+	_, err = io.ReadFull(r, sk[:])
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-// 	// x, y here will be big-endian byte strings
-// 	x, y := publicKey[1:33], publicKey[33:]
+	digest := sha512.Sum512(sk[:])
+	expanded = new([32]byte)
+	copy(expanded[:], digest[:32])
+	*expanded = ClampScalarBytes(*expanded)
 
-// 	// RFC 8032: To form the encoding of the point, copy the least significant
-// 	// bit of the x-coordinate to the most significant bit of the final octet.
-// 	copy(compressedEdwardsY[:], y)
-// 	compressedEdwardsY[0] |= x[31] << 7
-// 	compressedEdwardsY = reverse32(compressedEdwardsY)
+	// A = a*B, where a is the first half of the digest as a little-endian
+	// integer.
+	Ax, Ay := ed.ScalarBaseMult(ScalarFromLittleEndian(expanded[:]).Bytes())
 
-// 	return sk, compressedEdwardsY, err
-// }
+	return sk, expanded, Compress(Ax, Ay), nil
+}
 
-// func reverse32(b []byte) []byte {
-// 	var tmp = make([]byte, 32)
-// 	for i := 0; i <= 31; i++ {
-// 		tmp[i] = b[31-i]
-// 	}
-// 	return tmp
-// }
+// Test vector generated by instrumenting x/crypto/ed25519 GenerateKey().
+var genKeyTest = struct {
+	seed, expanded, public string
+}{
+	seed:     "c240344fcc6615dda52da98149377ad2b13fdba2bc39a50ba9f3afb2cbd4abaa",
+	expanded: "f04154b9d80963bb4c76214ece8a1049bdd16fbfc5003aff9835a59643ace276",
+	public:   "65a8343a83ec15e55050f12fc22f2c81a4fe7327c8da1524441f9ce5e5bc27dd",
+}
 
-// // Test vector generated by instrumenting x/crypto/ed25519 GenerateKey(). These
-// // are raw values. The edwards code interprets them as little-endian, so they
-// // need to be reversed before use with big.Int.
-// var genKeyTest = struct {
-// 	seed, expanded, public string
-// }{
-// 	seed:     "c240344fcc6615dda52da98149377ad2b13fdba2bc39a50ba9f3afb2cbd4abaa",
-// 	expanded: "f04154b9d80963bb4c76214ece8a1049bdd16fbfc5003aff9835a59643ace276",
-// 	public:   "65a8343a83ec15e55050f12fc22f2c81a4fe7327c8da1524441f9ce5e5bc27dd",
-// }
+func TestEdDSAGenerateKey(t *testing.T) {
+	fakeRandom, _ := hex.DecodeString(genKeyTest.seed)
+	fakeReader := bytes.NewBuffer(fakeRandom)
 
-// func TestEdDSAGenerateKey(t *testing.T) {
-// 	fakeRandom, _ := hex.DecodeString(genKeyTest.seed)
-// 	fakeReader := bytes.NewBuffer(fakeRandom)
+	sk, expanded, pk, err := generateKey(fakeReader)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-// 	sk, pk, err := generateKey(fakeReader)
-// 	if err != nil {
-// 		t.Fatal(err)
-// 	}
+	expectedExpanded, _ := hex.DecodeString(genKeyTest.expanded)
+	expectedPK, _ := hex.DecodeString(genKeyTest.public)
+	if !bytes.Equal(sk[:], fakeRandom) || !bytes.Equal(expanded[:], expectedExpanded) || !bytes.Equal(pk, expectedPK) {
+		t.Error("generateKey output did not match test vector")
+	}
 
-// 	expectedPK, _ := hex.DecodeString(genKeyTest.public)
-// 	if !bytes.Equal(sk[:], fakeRandom) || !bytes.Equal(pk, expectedPK) {
-// 		t.Error("generateKey output did not match test vector")
-// 	}
-// }
+	pub, priv, err := GenerateKey(bytes.NewReader(fakeRandom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv.Seed(), fakeRandom) || !bytes.Equal(pub, expectedPK) {
+		t.Error("GenerateKey output did not match test vector")
+	}
+}
 
 // COMPARATIVE FIELD BENCHMARKS
 
@@ -400,3 +397,40 @@ func BenchmarkFeToBig(b *testing.B) {
 		sink = feOnes.ToBig()
 	}
 }
+
+func TestScalarMultSigned(t *testing.T) {
+	c := Ed25519().(ed25519Curve)
+	x, y := c.ScalarBaseMult(randomScalar(t))
+	k := new(big.Int).SetBytes(randomScalar(t))
+
+	// -k*P = -(k*P)
+	nx, ny := ScalarMultSigned(x, y, new(big.Int).Neg(k))
+	ex, ey := Neg(c.ScalarMult(x, y, k.Bytes()))
+	if nx.Cmp(ex) != 0 || ny.Cmp(ey) != 0 {
+		t.Error("-k*P != -(k*P)")
+	}
+
+	// On a point of order 8, multipliers that agree modulo N must not be
+	// conflated: (N+1)*T = (N mod 8 + 1)*T, not 1*T.
+	T, _ := new(Point).SetBytes(order8Bytes)
+	tx, ty := T.p.ToAffine()
+	nPlusOne := new(big.Int).Add(c.Params().N, bigOne)
+	gotX, gotY := c.ScalarMult(tx, ty, nPlusOne.Bytes())
+	small := new(big.Int).Mod(nPlusOne, big.NewInt(8))
+	wantX, wantY := c.ScalarMult(tx, ty, small.Bytes())
+	if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+		t.Error("(N+1)*T != ((N+1) mod 8)*T")
+	}
+	if gotX.Cmp(tx) == 0 && gotY.Cmp(ty) == 0 {
+		t.Error("(N+1)*T reduced to T")
+	}
+
+	// Multipliers that agree modulo 8*N give the same result on any point.
+	px, py := c.Add(x, y, tx, ty)
+	k2 := new(big.Int).Sub(k, new(big.Int).Lsh(c.Params().N, 4))
+	ax, ay := ScalarMultSigned(px, py, k)
+	bx, by := ScalarMultSigned(px, py, k2)
+	if ax.Cmp(bx) != 0 || ay.Cmp(by) != 0 {
+		t.Error("k*P != (k - 16N)*P")
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ed25519consts exposes the constants of edwards25519 and curve25519,
+// so that protocol code doesn't need to hardcode them.
+//
+// Field elements and scalars are returned as canonical 32-byte little-endian
+// encodings, the form taken by ed25519.Point.SetExtendedCoordinates and the
+// scalar arguments of the ed25519 package. Each call returns a fresh array.
+package ed25519consts
+
+import (
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+func encode(v *radix51.FieldElement) [32]byte {
+	var out [32]byte
+	v.ToBytes(out[:])
+	return out
+}
+
+// P returns the field prime p = 2^255 - 19.
+func P() [32]byte {
+	return [32]byte{
+		0xed, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+	}
+}
+
+// L returns the order of the prime-order subgroup,
+// 2^252 + 27742317777372353535851937790883648493.
+func L() [32]byte {
+	return [32]byte{
+		0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+		0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+	}
+}
+
+// Cofactor is the cofactor of edwards25519: the curve has 8*L points.
+const Cofactor = 8
+
+// D returns the curve constant d = -121665/121666 of
+// -x^2 + y^2 = 1 + d*x^2*y^2.
+func D() [32]byte {
+	return encode(group.D)
+}
+
+// D2 returns 2*d, which appears in the extended coordinate addition formulas.
+func D2() [32]byte {
+	return encode(new(radix51.FieldElement).Add(group.D, group.D))
+}
+
+// SqrtM1 returns the non-negative square root of -1 in the field,
+// 2^((p-1)/4).
+func SqrtM1() [32]byte {
+	return encode(radix51.SqrtM1)
+}
+
+// MontgomeryA returns the coefficient A = 486662 of curve25519,
+// v^2 = u^3 + A*u^2 + u.
+func MontgomeryA() [32]byte {
+	return [32]byte{0x06, 0x6d, 0x07}
+}
+
+// Basepoint returns the extended coordinates (X:Y:Z:T) of the standard base
+// point, with Z = 1.
+func Basepoint() (X, Y, Z, T [32]byte) {
+	B := group.Basepoint()
+	return encode(&B.X), encode(&B.Y), encode(&B.Z), encode(&B.T)
+}
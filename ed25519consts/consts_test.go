@@ -0,0 +1,73 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519consts
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func toBig(b [32]byte) *big.Int {
+	var be [32]byte
+	for i := range b {
+		be[31-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be[:])
+}
+
+func TestConstants(t *testing.T) {
+	params := ed25519.Ed25519().Params()
+	p := params.P
+
+	if toBig(P()).Cmp(p) != 0 {
+		t.Error("wrong P")
+	}
+	if toBig(L()).Cmp(params.N) != 0 {
+		t.Error("wrong L")
+	}
+
+	d := toBig(D())
+	if d.Cmp(params.B) != 0 {
+		t.Error("D disagrees with the curve parameters")
+	}
+	// d * 121666 = -121665
+	check := new(big.Int).Mul(d, big.NewInt(121666))
+	check.Add(check, big.NewInt(121665))
+	if check.Mod(check, p).Sign() != 0 {
+		t.Error("d != -121665/121666")
+	}
+
+	d2 := new(big.Int).Add(d, d)
+	if toBig(D2()).Cmp(d2.Mod(d2, p)) != 0 {
+		t.Error("D2 != 2*D")
+	}
+
+	i := toBig(SqrtM1())
+	i2 := new(big.Int).Mul(i, i)
+	i2.Add(i2, big.NewInt(1))
+	if i2.Mod(i2, p).Sign() != 0 || i.Bit(0) != 0 {
+		t.Error("SqrtM1 is not the non-negative square root of -1")
+	}
+
+	if toBig(MontgomeryA()).Cmp(big.NewInt(486662)) != 0 {
+		t.Error("wrong MontgomeryA")
+	}
+}
+
+func TestBasepoint(t *testing.T) {
+	X, Y, Z, T := Basepoint()
+	B, err := new(ed25519.Point).SetExtendedCoordinates(X[:], Y[:], Z[:], T[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if B.Equal(ed25519.NewGeneratorPoint()) != 1 {
+		t.Error("Basepoint is not the generator")
+	}
+	if toBig(Z).Cmp(big.NewInt(1)) != 0 {
+		t.Error("Basepoint Z != 1")
+	}
+}
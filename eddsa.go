@@ -0,0 +1,321 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto"
+	stded25519 "crypto/ed25519"
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// Sizes of Ed25519 keys and signatures, in bytes. They match crypto/ed25519,
+// and so do the encodings.
+const (
+	// PublicKeySize is the size of a public key, the compressed point A.
+	PublicKeySize = 32
+	// PrivateKeySize is the size of a private key, the seed followed by the
+	// public key.
+	PrivateKeySize = 64
+	// SignatureSize is the size of a signature, the compressed point R
+	// followed by the scalar S.
+	SignatureSize = 64
+	// SeedSize is the size of the seed private keys are derived from, as
+	// in RFC 8032.
+	SeedSize = 32
+)
+
+// PublicKey is an Ed25519 public key.
+type PublicKey []byte
+
+// PrivateKey is an Ed25519 private key: the 32-byte RFC 8032 seed, followed
+// by the 32-byte public key.
+type PrivateKey []byte
+
+// Public returns the public key corresponding to priv, implementing
+// crypto.Signer. It's returned as a crypto/ed25519.PublicKey, which has the
+// same encoding, because that's the type crypto/x509, crypto/tls and other
+// consumers of crypto.Signer recognize as an Ed25519 key.
+func (priv PrivateKey) Public() crypto.PublicKey {
+	publicKey := make([]byte, PublicKeySize)
+	copy(publicKey, priv[SeedSize:])
+	return stded25519.PublicKey(publicKey)
+}
+
+// Equal reports whether priv and x have the same value. x can be a
+// PrivateKey or a crypto/ed25519.PrivateKey.
+func (priv PrivateKey) Equal(x crypto.PrivateKey) bool {
+	var xx []byte
+	switch x := x.(type) {
+	case PrivateKey:
+		xx = x
+	case stded25519.PrivateKey:
+		xx = x
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare(priv, xx) == 1
+}
+
+// Equal reports whether pub and x have the same value. x can be a PublicKey
+// or a crypto/ed25519.PublicKey.
+func (pub PublicKey) Equal(x crypto.PublicKey) bool {
+	switch x := x.(type) {
+	case PublicKey:
+		return bytes.Equal(pub, x)
+	case stded25519.PublicKey:
+		return bytes.Equal(pub, x)
+	default:
+		return false
+	}
+}
+
+// Sign signs the given message with priv, implementing crypto.Signer. rand
+// is ignored, since signing is deterministic.
+//
+// If opts is an *Options or a *crypto/ed25519.Options, it selects the variant
+// as in SignWithOptions. The latter is what callers that only know the
+// crypto/ed25519.PublicKey returned by Public will pass. Otherwise, as with
+// crypto/ed25519, opts.HashFunc() selects Ed25519 if it's crypto.Hash(0), and
+// Ed25519ph with an empty context, over a SHA-512 digest, if it's
+// crypto.SHA512.
+func (priv PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	switch o := opts.(type) {
+	case *Options:
+		return SignWithOptions(priv, message, o)
+	case *stded25519.Options:
+		return SignWithOptions(priv, message, &Options{Hash: o.Hash, Context: o.Context})
+	}
+	switch opts.HashFunc() {
+	case crypto.Hash(0):
+		return Sign(priv, message), nil
+	case crypto.SHA512:
+		return SignWithOptions(priv, message, &Options{Hash: crypto.SHA512})
+	default:
+		return nil, errors.New("ed25519: cannot sign hashed message")
+	}
+}
+
+// Seed returns the private key seed corresponding to priv. It's the form RFC
+// 8032 private keys are usually stored and transmitted in.
+func (priv PrivateKey) Seed() []byte {
+	return append([]byte{}, priv[:SeedSize]...)
+}
+
+// GenerateKey generates a public/private key pair using entropy from rand. If
+// rand is nil, crypto/rand.Reader is used.
+func GenerateKey(rand io.Reader) (PublicKey, PrivateKey, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, nil, err
+	}
+
+	priv := NewKeyFromSeed(seed)
+	return PublicKey(priv[SeedSize:]), priv, nil
+}
+
+// NewKeyFromSeed calculates a private key from a seed, as in RFC 8032,
+// Section 5.1.5. It panics if len(seed) is not SeedSize.
+func NewKeyFromSeed(seed []byte) PrivateKey {
+	if l := len(seed); l != SeedSize {
+		panic("ed25519: bad seed length: " + strconv.Itoa(l))
+	}
+
+	h := sha512.Sum512(seed)
+	s, _ := NewScalar().SetBytesWithClamping(h[:32])
+	A := new(Point).ScalarBaseMult(s.Bytes())
+
+	priv := make([]byte, PrivateKeySize)
+	copy(priv, seed)
+	copy(priv[SeedSize:], A.Bytes())
+	return priv
+}
+
+// Sign signs the message with privateKey and returns a signature, as in RFC
+// 8032, Section 5.1.6. Signing is deterministic: the nonce is derived from
+// the private key and the message. It panics if len(privateKey) is not
+// PrivateKeySize.
+func Sign(privateKey PrivateKey, message []byte) []byte {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	return sign(privateKey, message, nil, nil)
+}
+
+// SignTo is like Sign, but writes the signature to dst instead of allocating
+// it. It doesn't allocate, and panics if len(dst) is not SignatureSize or
+// len(privateKey) is not PrivateKeySize.
+func SignTo(dst []byte, privateKey PrivateKey, message []byte) {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	if l := len(dst); l != SignatureSize {
+		panic("ed25519: bad signature buffer length: " + strconv.Itoa(l))
+	}
+	var e ExpandedPrivateKey
+	e.expand(privateKey)
+	e.signTo(dst, message, nil, nil)
+}
+
+// sign implements RFC 8032 signing for all variants. dom is the dom2 prefix
+// of Ed25519ph and Ed25519ctx, and empty for plain Ed25519. z is the
+// randomness of hedged signing, and empty for deterministic signing.
+func sign(privateKey PrivateKey, message, dom, z []byte) []byte {
+	var e ExpandedPrivateKey
+	e.expand(privateKey)
+	signature := make([]byte, SignatureSize)
+	e.signTo(signature, message, dom, z)
+	return signature
+}
+
+// Verify reports whether sig is a valid signature of message by publicKey, as
+// in RFC 8032, Section 5.1.7. It uses the cofactorless equation
+// [S]B = R + [k]A, like crypto/ed25519, and rejects non-canonical S. It
+// panics if len(publicKey) is not PublicKeySize.
+//
+// Unlike crypto/ed25519, it also rejects public keys that are non-canonical
+// encodings, as Point.SetBytes does. VerifierOptions selects other rules.
+func Verify(publicKey PublicKey, message, sig []byte) bool {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	return verify(publicKey, message, sig, nil) == nil
+}
+
+// Errors returned internally by verify, to tell the failure modes apart in
+// tests.
+var (
+	errInvalidSignatureLength = errors.New("ed25519: invalid signature length")
+	errInvalidPublicKey       = errors.New("ed25519: invalid public key")
+	errNonCanonicalS          = errors.New("ed25519: non-canonical signature scalar")
+	errSignatureMismatch      = errors.New("ed25519: invalid signature")
+)
+
+// verify implements RFC 8032 verification for all variants, with dom as in
+// sign.
+func verify(publicKey PublicKey, message, sig, dom []byte) error {
+	return verifyWithRules(publicKey, message, sig, dom, RulesRFC8032, false)
+}
+
+// challenge returns k = SHA-512(dom || R || A || M) mod L.
+func challenge(dom, R, A, message []byte) *Scalar {
+	return new(Scalar).setChallenge(dom, R, A, message)
+}
+
+// setChallenge sets k = SHA-512(dom || R || A || M) mod L, and returns k. It
+// doesn't allocate.
+func (k *Scalar) setChallenge(dom, R, A, message []byte) *Scalar {
+	var digest [sha512.Size]byte
+	h := sha512.New()
+	h.Write(dom)
+	h.Write(R)
+	h.Write(A)
+	h.Write(message)
+	k.s.FromUniformBytes(h.Sum(digest[:0]))
+	return k
+}
+
+// dom2 returns the prefix RFC 8032 hashes before the nonce and challenge
+// inputs of Ed25519ph and Ed25519ctx, to separate them from plain Ed25519 and
+// from each other. It panics if context is longer than 255 bytes.
+func dom2(phflag byte, context string) []byte {
+	if len(context) > 255 {
+		panic("ed25519: context too long")
+	}
+	dom := []byte("SigEd25519 no Ed25519 collisions")
+	dom = append(dom, phflag, byte(len(context)))
+	return append(dom, context...)
+}
+
+// Options can be used with PrivateKey.Sign, SignWithOptions or
+// VerifyWithOptions to select an Ed25519 variant. It mirrors the Options of
+// crypto/ed25519.
+type Options struct {
+	// Hash can be zero for regular Ed25519, or crypto.SHA512 for Ed25519ph.
+	Hash crypto.Hash
+
+	// Context, if not empty, selects Ed25519ctx or provides the context
+	// string for Ed25519ph. It can be at most 255 bytes in length.
+	Context string
+
+	// Randomness, if not empty, is mixed into the nonce when signing, as in
+	// SignHedged. It's ignored by verification.
+	Randomness []byte
+}
+
+// HashFunc returns o.Hash.
+func (o *Options) HashFunc() crypto.Hash { return o.Hash }
+
+// dom returns the dom2 prefix for the variant o selects, or an error if o is
+// not a valid combination of options.
+func (o *Options) dom() ([]byte, error) {
+	if len(o.Context) > 255 {
+		return nil, errors.New("ed25519: bad context length: " + strconv.Itoa(len(o.Context)))
+	}
+	switch {
+	case o.Hash == crypto.SHA512:
+		return dom2(1, o.Context), nil
+	case o.Hash != crypto.Hash(0):
+		return nil, errors.New("ed25519: expected opts.Hash zero (unhashed message, for standard Ed25519) or SHA-512 (for Ed25519ph)")
+	case o.Context != "":
+		return dom2(0, o.Context), nil
+	default:
+		return nil, nil
+	}
+}
+
+// domFor is like dom, but it also checks that message is a SHA-512 digest if
+// o selects Ed25519ph.
+func (o *Options) domFor(message []byte) ([]byte, error) {
+	dom, err := o.dom()
+	if err != nil {
+		return nil, err
+	}
+	if o.Hash == crypto.SHA512 && len(message) != sha512.Size {
+		return nil, errors.New("ed25519: bad Ed25519ph message hash length")
+	}
+	return dom, nil
+}
+
+// SignWithOptions signs message with privateKey using the variant selected
+// by opts: Ed25519, Ed25519ctx if opts.Context is not empty, or Ed25519ph if
+// opts.Hash is crypto.SHA512, in which case message must be the SHA-512
+// digest of the actual message. Contexts bind a signature to the protocol it
+// was made for, so that it can't be replayed in another one. It panics if
+// len(privateKey) is not PrivateKeySize.
+func SignWithOptions(privateKey PrivateKey, message []byte, opts *Options) ([]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	dom, err := opts.domFor(message)
+	if err != nil {
+		return nil, err
+	}
+	return sign(privateKey, message, dom, opts.Randomness), nil
+}
+
+// VerifyWithOptions reports whether sig is a valid signature of message by
+// publicKey, for the variant selected by opts as in SignWithOptions. A valid
+// signature is indicated by returning a nil error. It panics if
+// len(publicKey) is not PublicKeySize.
+func VerifyWithOptions(publicKey PublicKey, message, sig []byte, opts *Options) error {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	dom, err := opts.domFor(message)
+	if err != nil {
+		return err
+	}
+	return verify(publicKey, message, sig, dom)
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto"
+	stded25519 "crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 8032, Section 7.1.
+var rfc8032Vectors = []struct {
+	seed, public, message, signature string
+}{
+	{
+		"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+		"",
+		"e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b",
+	},
+	{
+		"4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+		"3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+		"72",
+		"92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00",
+	},
+	{
+		"c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+		"fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+		"af82",
+		"6291d657deec24024827e69c3abe01a30ce548a284743a445e3680d7db5ac3ac18ff9b538d16f290ae67f760984dc6594a7c15e9716ed28dc027beceea1ec40a",
+	},
+}
+
+func TestSignVerifyRFC8032(t *testing.T) {
+	for i, tt := range rfc8032Vectors {
+		seed, _ := hex.DecodeString(tt.seed)
+		public, _ := hex.DecodeString(tt.public)
+		message, _ := hex.DecodeString(tt.message)
+		want, _ := hex.DecodeString(tt.signature)
+
+		priv := NewKeyFromSeed(seed)
+		if !bytes.Equal(priv[SeedSize:], public) {
+			t.Errorf("test %d: got public key %x, want %x", i+1, priv[SeedSize:], public)
+		}
+		if !bytes.Equal(priv.Seed(), seed) {
+			t.Errorf("test %d: Seed didn't return the seed", i+1)
+		}
+		if sig := Sign(priv, message); !bytes.Equal(sig, want) {
+			t.Errorf("test %d: got signature %x, want %x", i+1, sig, want)
+		}
+		if !Verify(public, message, want) {
+			t.Errorf("test %d: valid signature rejected", i+1)
+		}
+		if Verify(public, append(message, 0), want) {
+			t.Errorf("test %d: signature accepted for the wrong message", i+1)
+		}
+	}
+}
+
+func TestSignVerifyMatchesStdlib(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		pub, priv, err := GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		message := randomScalar(t)
+
+		stdPriv := stded25519.NewKeyFromSeed(priv.Seed())
+		if !bytes.Equal(stdPriv, priv) {
+			t.Fatalf("private key %x differs from crypto/ed25519 %x", priv, stdPriv)
+		}
+		sig := Sign(priv, message)
+		if !bytes.Equal(sig, stded25519.Sign(stdPriv, message)) {
+			t.Errorf("signature differs from crypto/ed25519")
+		}
+		if !Verify(pub, message, sig) {
+			t.Errorf("valid signature rejected")
+		}
+	}
+}
+
+func TestVerifyRejectsMalleableS(t *testing.T) {
+	seed, _ := hex.DecodeString(rfc8032Vectors[0].seed)
+	priv := NewKeyFromSeed(seed)
+	pub := PublicKey(priv[SeedSize:])
+	sig := Sign(priv, nil)
+
+	// S + L is the same scalar modulo L, so a verifier that doesn't check
+	// canonicity would accept it.
+	S, _ := NewScalar().SetCanonicalBytes(sig[32:])
+	malleable := append([]byte{}, sig...)
+	copy(malleable[32:], ScalarToLittleEndian(new(big.Int).Add(S.BigInt(), Ed25519().Params().N)))
+	if err := verify(pub, nil, malleable, nil); err != errNonCanonicalS {
+		t.Errorf("got %v, want errNonCanonicalS", err)
+	}
+
+	if err := verify(pub, nil, sig[:63], nil); err != errInvalidSignatureLength {
+		t.Errorf("got %v, want errInvalidSignatureLength", err)
+	}
+}
+
+var _ crypto.Signer = PrivateKey(nil)
+
+func TestPrivateKeySigner(t *testing.T) {
+	pub, priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(priv.Public()) || !priv.Equal(priv) || priv.Equal(pub) {
+		t.Error("Equal is wrong")
+	}
+	if !priv.Equal(stded25519.NewKeyFromSeed(priv.Seed())) {
+		t.Error("private key is not equal to the crypto/ed25519 one")
+	}
+
+	if _, err := priv.Sign(nil, []byte("message"), crypto.SHA256); err == nil {
+		t.Error("signed a pre-hashed message")
+	}
+
+	// A self-signed certificate exercises crypto.Signer through crypto/x509.
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, priv.Public(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		t.Error(err)
+	}
+	if !Verify(pub, cert.RawTBSCertificate, cert.Signature) {
+		t.Error("certificate signature rejected by Verify")
+	}
+}
+
+func TestSignWithOptionsContext(t *testing.T) {
+	// RFC 8032, Section 7.2, the "foo" context test.
+	seed, _ := hex.DecodeString("0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6")
+	public, _ := hex.DecodeString("dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292")
+	message, _ := hex.DecodeString("f726936d19c800494e3fdaff20b276a8")
+	want, _ := hex.DecodeString("55a4cc2f70a54e04288c5f4cd1e45a7bb520b36292911876cada7323198dd87a8b36950b95130022907a7fb7c4e9b2d5f6cca685a587b4b21f4b888e4e7edb0d")
+	opts := &Options{Context: "foo"}
+
+	priv := NewKeyFromSeed(seed)
+	sig, err := SignWithOptions(priv, message, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, want) {
+		t.Errorf("got %x, want %x", sig, want)
+	}
+	if sig2, _ := priv.Sign(nil, message, opts); !bytes.Equal(sig2, want) {
+		t.Error("PrivateKey.Sign ignored the options")
+	}
+	if err := VerifyWithOptions(public, message, sig, opts); err != nil {
+		t.Error(err)
+	}
+	if err := VerifyWithOptions(public, message, sig, &Options{Context: "bar"}); err == nil {
+		t.Error("signature accepted under a different context")
+	}
+	if Verify(public, message, sig) {
+		t.Error("Ed25519ctx signature accepted as an Ed25519 signature")
+	}
+}
+
+func TestSignWithOptionsMatchesStdlib(t *testing.T) {
+	_, priv, _ := GenerateKey(nil)
+	std := stded25519.NewKeyFromSeed(priv.Seed())
+	digest := make([]byte, 64)
+	for _, opts := range []Options{
+		{},
+		{Context: "protocol v1"},
+		{Hash: crypto.SHA512},
+		{Hash: crypto.SHA512, Context: "protocol v1"},
+	} {
+		sig, err := SignWithOptions(priv, digest, &opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := std.Sign(nil, digest, &stded25519.Options{Hash: opts.Hash, Context: opts.Context})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, want) {
+			t.Errorf("%+v: signature differs from crypto/ed25519", opts)
+		}
+	}
+
+	for _, opts := range []Options{
+		{Hash: crypto.SHA256},
+		{Context: string(make([]byte, 256))},
+	} {
+		if _, err := SignWithOptions(priv, digest, &opts); err == nil {
+			t.Errorf("%+v: accepted invalid options", opts)
+		}
+	}
+	if _, err := SignWithOptions(priv, digest[:32], &Options{Hash: crypto.SHA512}); err == nil {
+		t.Error("Ed25519ph accepted a 32-byte digest")
+	}
+}
+
+func TestPrivateKeySignStdlibOptions(t *testing.T) {
+	_, priv, _ := GenerateKey(nil)
+	pub := priv.Public().(stded25519.PublicKey)
+	message := []byte("message")
+	digest := sha512.Sum512(message)
+	for _, tt := range []struct {
+		msg  []byte
+		opts *stded25519.Options
+	}{
+		{message, &stded25519.Options{}},
+		{message, &stded25519.Options{Context: "protocol v1"}},
+		{digest[:], &stded25519.Options{Hash: crypto.SHA512}},
+		{digest[:], &stded25519.Options{Hash: crypto.SHA512, Context: "protocol v1"}},
+	} {
+		sig, err := priv.Sign(nil, tt.msg, tt.opts)
+		if err != nil {
+			t.Fatalf("%+v: %v", tt.opts, err)
+		}
+		if err := stded25519.VerifyWithOptions(pub, tt.msg, sig, tt.opts); err != nil {
+			t.Errorf("%+v: %v", tt.opts, err)
+		}
+		other := &stded25519.Options{Hash: tt.opts.Hash, Context: "protocol v2"}
+		if err := stded25519.VerifyWithOptions(pub, tt.msg, sig, other); err == nil {
+			t.Errorf("%+v: signature accepted under a different context", tt.opts)
+		}
+	}
+
+	if _, err := priv.Sign(nil, digest[:], &stded25519.Options{Hash: crypto.SHA256}); err == nil {
+		t.Error("signed with an unsupported hash")
+	}
+	// Like crypto/ed25519, a plain crypto.SHA512 selects Ed25519ph.
+	sig, err := priv.Sign(nil, digest[:], crypto.SHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stded25519.VerifyWithOptions(pub, digest[:], sig, &stded25519.Options{Hash: crypto.SHA512}); err != nil {
+		t.Errorf("crypto.SHA512: %v", err)
+	}
+	if _, err := priv.Sign(nil, digest[:32], crypto.SHA512); err == nil {
+		t.Error("Ed25519ph accepted a 32-byte digest")
+	}
+}
+
+func TestSignToVerifyAllocations(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewExpandedPrivateKey(priv)
+	k, err := NewPrecomputedPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("allocations")
+
+	sig := make([]byte, SignatureSize)
+	SignTo(sig, priv, message)
+	if !bytes.Equal(sig, Sign(priv, message)) {
+		t.Error("SignTo and Sign disagree")
+	}
+	esig := make([]byte, SignatureSize)
+	e.SignTo(esig, message)
+	if !bytes.Equal(esig, sig) {
+		t.Error("ExpandedPrivateKey.SignTo and Sign disagree")
+	}
+
+	if n := testing.AllocsPerRun(10, func() { SignTo(sig, priv, message) }); n != 0 {
+		t.Errorf("SignTo: %v allocations", n)
+	}
+	if n := testing.AllocsPerRun(10, func() { e.SignTo(sig, message) }); n != 0 {
+		t.Errorf("ExpandedPrivateKey.SignTo: %v allocations", n)
+	}
+	if n := testing.AllocsPerRun(10, func() {
+		if !k.Verify(message, sig) {
+			t.Fatal("valid signature rejected")
+		}
+	}); n != 0 {
+		t.Errorf("PrecomputedPublicKey.Verify: %v allocations", n)
+	}
+}
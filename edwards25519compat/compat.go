@@ -0,0 +1,67 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package edwards25519compat converts between the types of the ed25519
+// package and those of filippo.io/edwards25519, for programs that use both.
+//
+// Points are converted through their extended coordinates, so no square root
+// is computed and nothing is compressed on the way. Scalars are converted
+// through their canonical 32-byte little-endian encoding, which both
+// packages share.
+package edwards25519compat
+
+import (
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+
+	"github.com/gtank/ed25519"
+)
+
+// PointFromFilippo returns p as an ed25519.Point.
+func PointFromFilippo(p *edwards25519.Point) *ed25519.Point {
+	X, Y, Z, T := p.ExtendedCoordinates()
+	v, err := new(ed25519.Point).SetExtendedCoordinates(X.Bytes(), Y.Bytes(), Z.Bytes(), T.Bytes())
+	if err != nil {
+		// filippo.io/edwards25519 points are always valid.
+		panic("edwards25519compat: invalid point: " + err.Error())
+	}
+	return v
+}
+
+// PointToFilippo returns p as an edwards25519.Point.
+func PointToFilippo(p *ed25519.Point) *edwards25519.Point {
+	var X, Y, Z, T field.Element
+	x, y, z, t := p.ExtendedCoordinates()
+	for _, c := range []struct {
+		e *field.Element
+		b []byte
+	}{{&X, x}, {&Y, y}, {&Z, z}, {&T, t}} {
+		if _, err := c.e.SetBytes(c.b); err != nil {
+			panic("edwards25519compat: invalid coordinate: " + err.Error())
+		}
+	}
+	v, err := new(edwards25519.Point).SetExtendedCoordinates(&X, &Y, &Z, &T)
+	if err != nil {
+		panic("edwards25519compat: invalid point: " + err.Error())
+	}
+	return v
+}
+
+// ScalarFromFilippo returns s as an ed25519.Scalar.
+func ScalarFromFilippo(s *edwards25519.Scalar) *ed25519.Scalar {
+	v, err := ed25519.NewScalar().SetCanonicalBytes(s.Bytes())
+	if err != nil {
+		panic("edwards25519compat: invalid scalar: " + err.Error())
+	}
+	return v
+}
+
+// ScalarToFilippo returns s as an edwards25519.Scalar.
+func ScalarToFilippo(s *ed25519.Scalar) *edwards25519.Scalar {
+	v, err := edwards25519.NewScalar().SetCanonicalBytes(s.Bytes())
+	if err != nil {
+		panic("edwards25519compat: invalid scalar: " + err.Error())
+	}
+	return v
+}
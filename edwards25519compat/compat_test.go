@@ -0,0 +1,59 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519compat
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"filippo.io/edwards25519"
+
+	"github.com/gtank/ed25519"
+)
+
+func randomFilippoScalar(t *testing.T) *edwards25519.Scalar {
+	b := make([]byte, 64)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	s, err := edwards25519.NewScalar().SetUniformBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestPointConversion(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		s := randomFilippoScalar(t)
+		fp := new(edwards25519.Point).ScalarBaseMult(s)
+
+		p := PointFromFilippo(fp)
+		if !bytes.Equal(p.Bytes(), fp.Bytes()) {
+			t.Errorf("got %x, want %x", p.Bytes(), fp.Bytes())
+		}
+		// Both packages agree on k*B for the same scalar.
+		want := new(ed25519.Point).ScalarBaseMult(ScalarFromFilippo(s).Bytes())
+		if p.Equal(want) != 1 {
+			t.Error("ScalarBaseMult results disagree")
+		}
+
+		if PointToFilippo(p).Equal(fp) != 1 {
+			t.Error("point did not survive the round trip")
+		}
+	}
+}
+
+func TestScalarConversion(t *testing.T) {
+	s := randomFilippoScalar(t)
+	v := ScalarFromFilippo(s)
+	if !bytes.Equal(v.Bytes(), s.Bytes()) {
+		t.Errorf("got %x, want %x", v.Bytes(), s.Bytes())
+	}
+	if ScalarToFilippo(v).Equal(s) != 1 {
+		t.Error("scalar did not survive the round trip")
+	}
+}
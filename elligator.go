@@ -0,0 +1,76 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"errors"
+
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+// PointToRepresentative returns the Elligator 2 representative of p, a 32-byte
+// string that RepresentativeToPoint maps back to p. Only about half of all
+// points have a representative; for the others, ok is false. Callers that
+// need one, such as when generating ephemeral keys for obfs4-style protocols,
+// should generate a new point until ok is true.
+//
+// The representative is a 254-bit little-endian integer, so the top two bits
+// of the last byte are always zero. They must be filled with random bits
+// before the representative is sent, for it to be indistinguishable from a
+// uniform random string. RepresentativeToPoint ignores them.
+//
+// The distribution of representatives is only uniform if p is uniform over
+// the whole curve. Points in the prime-order subgroup, such as ScalarBaseMult
+// results, have distinguishable representatives unless a random small-order
+// point is added to them first.
+func PointToRepresentative(p *Point) (representative []byte, ok bool) {
+	var r radix51.FieldElement
+	if p.p.ToRepresentative(&r) != 1 {
+		return nil, false
+	}
+	out := make([]byte, 32)
+	r.ToBytes(out)
+	return out, true
+}
+
+// RepresentativeToPoint returns the point represented by the 32-byte string
+// representative, using the Elligator 2 map from RFC 9380, Section 6.7.1. It
+// ignores the top two bits of the last byte. Every 32-byte string maps to a
+// point, so the only error is an input of the wrong length.
+func RepresentativeToPoint(representative []byte) (*Point, error) {
+	if len(representative) != 32 {
+		return nil, errors.New("ed25519: invalid representative length")
+	}
+	var buf [32]byte
+	copy(buf[:], representative)
+	buf[31] &= 0x3f
+
+	var r radix51.FieldElement
+	r.FromBytes(buf[:])
+
+	v := new(Point)
+	v.p.MapToCurveElligator2(&r)
+	return v, nil
+}
+
+// RepresentativeToMontgomery returns the curve25519 u-coordinate the
+// Elligator 2 map, with Z = 2 as in RFC 9380, Appendix G.2.1, sends the
+// 32-byte string representative to. The representative is a little-endian
+// field element: its top bit is ignored, and values of p or more are reduced.
+// This is the map of hash_to_point in Signal's XEdDSA specification, which
+// picks the sign of the Edwards point separately. The only error is an input
+// of the wrong length.
+func RepresentativeToMontgomery(representative []byte) ([]byte, error) {
+	if len(representative) != 32 {
+		return nil, errors.New("ed25519: invalid representative length")
+	}
+	var r, u radix51.FieldElement
+	r.FromBytes(representative)
+	group.MapToCurve25519U(&u, &r)
+	out := make([]byte, 32)
+	u.ToBytes(out)
+	return out, nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+func TestRepresentativeRoundTrip(t *testing.T) {
+	found := 0
+	for i := 0; i < 64; i++ {
+		P := new(Point).ScalarBaseMult(randomScalar(t))
+		r, ok := PointToRepresentative(P)
+		if !ok {
+			continue
+		}
+		found++
+
+		if r[31]&0xc0 != 0 {
+			t.Errorf("representative %x has top bits set", r)
+		}
+		// The top two bits are ignored when decoding.
+		r[31] |= 0xc0
+		Q, err := RepresentativeToPoint(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if P.Equal(Q) != 1 {
+			t.Errorf("representative %x did not map back to its point", r)
+		}
+	}
+	// About half of all points have a representative.
+	if found < 10 || found > 54 {
+		t.Errorf("%d of 64 points had a representative", found)
+	}
+}
+
+func TestRepresentativeFromRandom(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		r := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, r); err != nil {
+			t.Fatal(err)
+		}
+		r[31] &= 0x3f
+
+		P, err := RepresentativeToPoint(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := PointToRepresentative(P)
+		if !ok {
+			t.Fatalf("no representative for the image of %x", r)
+		}
+		if !bytes.Equal(got, r) {
+			t.Errorf("got representative %x, want %x", got, r)
+		}
+	}
+}
+
+func TestRepresentativeIdentity(t *testing.T) {
+	B := basepoint(t)
+	I := new(Point).Sub(B, B)
+	r, ok := PointToRepresentative(I)
+	if !ok || !bytes.Equal(r, make([]byte, 32)) {
+		t.Errorf("got %x, %v for the identity, want zero", r, ok)
+	}
+
+	// (0, -1) is the image of (0, 0) on curve25519, which the map can't
+	// reach.
+	var minusOne [32]byte
+	minusOne[0] = 0xec
+	for i := 1; i < 31; i++ {
+		minusOne[i] = 0xff
+	}
+	minusOne[31] = 0x7f
+	T, err := new(Point).SetBytes(minusOne[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := PointToRepresentative(T); ok {
+		t.Error("found a representative for (0, -1)")
+	}
+
+	if _, err := RepresentativeToPoint(r[:31]); err == nil {
+		t.Error("decoded a short representative")
+	}
+}
+
+func TestRepresentativeToMontgomery(t *testing.T) {
+	// Computed with a math/big implementation of the XEdDSA map.
+	r, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	want, _ := hex.DecodeString("5f3520001c6c9936a31206afe7c7ac224e8861619bf98872444915899d95f46e")
+	if u, err := RepresentativeToMontgomery(r); err != nil || !bytes.Equal(u, want) {
+		t.Errorf("got %x, %v, want %x", u, err, want)
+	}
+
+	// It's the u-coordinate of the point RepresentativeToPoint returns.
+	for i := 0; i < 20; i++ {
+		if _, err := io.ReadFull(rand.Reader, r); err != nil {
+			t.Fatal(err)
+		}
+		r[31] &= 0x3f
+		u, err := RepresentativeToMontgomery(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		P, _ := RepresentativeToPoint(r)
+		if !bytes.Equal(u, P.BytesMontgomery()) {
+			t.Errorf("%x: u-coordinate doesn't match RepresentativeToPoint", r)
+		}
+	}
+
+	// The top bit is ignored, and p + 1 is reduced to 1.
+	one := make([]byte, 32)
+	one[0] = 1
+	pPlusOne, _ := hex.DecodeString("eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	u1, _ := RepresentativeToMontgomery(one)
+	u2, _ := RepresentativeToMontgomery(pPlusOne)
+	one[31] |= 0x80
+	u3, _ := RepresentativeToMontgomery(one)
+	if !bytes.Equal(u1, u2) || !bytes.Equal(u1, u3) {
+		t.Error("representative not reduced modulo p")
+	}
+	if _, err := RepresentativeToMontgomery(r[:31]); err == nil {
+		t.Error("short representative accepted")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+// Compress returns the 32-byte compressed Edwards-Y encoding of the affine
+// point (x, y), as specified in RFC 8032, Section 5.1.2. This is the
+// encoding used for Ed25519 public keys. Compress panics if (x, y) is not on
+// the curve.
+func Compress(x, y *big.Int) []byte {
+	if !Ed25519().IsOnCurve(x, y) {
+		panic("ed25519: Compress called with off-curve point")
+	}
+
+	var p group.ExtendedGroupElement
+	p.FromAffine(x, y)
+
+	out := make([]byte, 32)
+	p.ToBytes(out)
+	return out
+}
+
+// Decompress parses a 32-byte compressed Edwards-Y encoding into the affine
+// point (x, y) used by the elliptic.Curve methods. It rejects non-canonical
+// encodings, as Point.SetBytes does.
+func Decompress(data []byte) (x, y *big.Int, err error) {
+	var p group.ExtendedGroupElement
+	if err := p.FromBytes(data); err != nil {
+		return nil, nil, err
+	}
+	x, y = p.ToAffine()
+	return x, y, nil
+}
+
+// MarshalCompressed encodes the affine point (x, y) in the 33-byte form of
+// SEC 1, Version 2.0, Section 2.3.3: a 0x02 or 0x03 byte for the parity of y,
+// then x as a 32-byte big-endian integer. elliptic.MarshalCompressed assumes
+// a short Weierstrass curve and can't be used with this one.
+// MarshalCompressed panics if (x, y) is not on the curve.
+//
+// Compress is the standard encoding of edwards25519 points. This one only
+// exists for protocols that are generic over SEC 1 encodings.
+func MarshalCompressed(x, y *big.Int) []byte {
+	if !Ed25519().IsOnCurve(x, y) {
+		panic("ed25519: MarshalCompressed called with off-curve point")
+	}
+	out := make([]byte, 33)
+	out[0] = byte(2 + y.Bit(0))
+	x.FillBytes(out[1:])
+	return out
+}
+
+// UnmarshalCompressed parses a point encoded by MarshalCompressed, recovering
+// y from the curve equation y^2 = (1 + x^2) / (1 - d*x^2). On error, such as
+// a malformed encoding or an x that isn't on the curve, x and y are nil.
+func UnmarshalCompressed(data []byte) (x, y *big.Int) {
+	if len(data) != 33 || (data[0] != 2 && data[0] != 3) {
+		return nil, nil
+	}
+	x = new(big.Int).SetBytes(data[1:])
+	if x.Cmp(Ed25519().Params().P) >= 0 {
+		return nil, nil
+	}
+
+	var fx, x2, u, w, fy radix51.FieldElement
+	fx.FromBig(x)
+	x2.Square(&fx)
+	u.Add(radix51.One, &x2)
+	w.Mul(group.D, &x2)
+	w.Sub(radix51.One, &w)
+	if _, wasSquare := fy.SqrtRatio(&u, &w); wasSquare != 1 {
+		return nil, nil
+	}
+
+	// SqrtRatio returns the even root. The zero root has no odd counterpart.
+	odd := int(data[0] & 1)
+	if odd == 1 && fy.Equal(radix51.Zero) == 1 {
+		return nil, nil
+	}
+	fy.CondNeg(&fy, odd)
+
+	return x, fy.ToBig()
+}
+
+// Errors returned by UnmarshalPoint. They can be compared against with ==.
+var (
+	ErrInvalidLength = errors.New("ed25519: invalid point encoding length")
+	ErrInvalidFormat = errors.New("ed25519: invalid point encoding format")
+	ErrNonCanonical  = errors.New("ed25519: non-canonical field element")
+	ErrNotOnCurve    = errors.New("ed25519: point is not on the curve")
+)
+
+// UnmarshalPoint parses a point in the 65-byte uncompressed form produced by
+// elliptic.Marshal: a 0x04 byte followed by x and y as 32-byte big-endian
+// integers. Unlike elliptic.Unmarshal, which returns nil on any failure, it
+// reports why the input was rejected: ErrInvalidLength, ErrInvalidFormat if
+// the first byte isn't 0x04, ErrNonCanonical if a coordinate is not less than
+// p, or ErrNotOnCurve.
+func UnmarshalPoint(data []byte) (x, y *big.Int, err error) {
+	if len(data) != 65 {
+		return nil, nil, ErrInvalidLength
+	}
+	if data[0] != 4 {
+		return nil, nil, ErrInvalidFormat
+	}
+
+	c := Ed25519()
+	x = new(big.Int).SetBytes(data[1:33])
+	y = new(big.Int).SetBytes(data[33:])
+	if x.Cmp(c.Params().P) >= 0 || y.Cmp(c.Params().P) >= 0 {
+		return nil, nil, ErrNonCanonical
+	}
+	if !c.IsOnCurve(x, y) {
+		return nil, nil, ErrNotOnCurve
+	}
+	return x, y, nil
+}
+
+// The elliptic.Curve methods take big-endian big.Int values and byte slices,
+// while RFC 8032 and RFC 7748 encode scalars and coordinates as little-endian
+// byte strings. The following helpers convert between the two, so callers
+// don't have to reverse bytes by hand.
+
+// ScalarFromLittleEndian interprets b as a little-endian unsigned integer,
+// such as an RFC 8032 scalar. The result's Bytes method gives the big-endian
+// form taken by ScalarMult and ScalarBaseMult.
+func ScalarFromLittleEndian(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// ScalarToLittleEndian returns k as a 32-byte little-endian integer, the form
+// taken by the Point and Scalar methods. It panics if k is negative or does
+// not fit in 32 bytes.
+func ScalarToLittleEndian(k *big.Int) []byte {
+	if k.Sign() < 0 || k.BitLen() > 256 {
+		panic("ed25519: scalar out of range")
+	}
+	return reverseBytes(k.FillBytes(make([]byte, 32)))
+}
+
+// CoordinateFromLittleEndian parses a 32-byte little-endian field element,
+// the encoding RFC 7748 uses for Montgomery u-coordinates. Unlike
+// Point.SetBytes and EdwardsToMontgomeryBytes, it doesn't ignore the top bit:
+// it returns ErrInvalidLength if b is not 32 bytes long, and ErrNonCanonical
+// if the value is not less than p.
+func CoordinateFromLittleEndian(b []byte) (*big.Int, error) {
+	if len(b) != 32 {
+		return nil, ErrInvalidLength
+	}
+	x := ScalarFromLittleEndian(b)
+	if x.Cmp(Ed25519().Params().P) >= 0 {
+		return nil, ErrNonCanonical
+	}
+	return x, nil
+}
+
+// CoordinateToLittleEndian returns the 32-byte little-endian encoding of the
+// field element x. It panics if x is not in [0, p).
+func CoordinateToLittleEndian(x *big.Int) []byte {
+	if x.Sign() < 0 || x.Cmp(Ed25519().Params().P) >= 0 {
+		panic("ed25519: coordinate out of range")
+	}
+	return reverseBytes(x.FillBytes(make([]byte, 32)))
+}
+
+// reverseBytes reverses b in place and returns it.
+func reverseBytes(b []byte) []byte {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
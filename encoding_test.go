@@ -0,0 +1,185 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestCompressBasepoint(t *testing.T) {
+	c := Ed25519()
+	if !bytes.Equal(Compress(c.Params().Gx, c.Params().Gy), basepointBytes) {
+		t.Error("wrong encoding of the base point")
+	}
+
+	x, y, err := Decompress(basepointBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Cmp(c.Params().Gx) != 0 || y.Cmp(c.Params().Gy) != 0 {
+		t.Error("wrong decoding of the base point")
+	}
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	c := Ed25519()
+	for i := 0; i < 10; i++ {
+		k := randomScalar(t)
+		x, y := c.ScalarBaseMult(ScalarFromLittleEndian(k).Bytes())
+
+		enc := Compress(x, y)
+		if !bytes.Equal(enc, new(Point).ScalarBaseMult(k).Bytes()) {
+			t.Fatal("Compress disagrees with Point.Bytes")
+		}
+
+		x2, y2, err := Decompress(enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x.Cmp(x2) != 0 || y.Cmp(y2) != 0 {
+			t.Error("point did not survive Compress/Decompress round trip")
+		}
+	}
+}
+
+var nonCanonicalEncodings = []string{
+	// y = p, a non-canonical encoding of (sqrt(-1), 0)
+	"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	// y = p + 1, a non-canonical encoding of the identity
+	"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	// y = 1 with the sign bit set, a "negative zero" x
+	"0100000000000000000000000000000000000000000000000000000000000080",
+	// y = -1 with the sign bit set, same as above for the point of order 2
+	"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+}
+
+func TestDecompressNonCanonical(t *testing.T) {
+	for _, s := range nonCanonicalEncodings {
+		b, _ := hex.DecodeString(s)
+		if _, _, err := Decompress(b); err == nil {
+			t.Errorf("accepted non-canonical encoding %s", s)
+		}
+		if _, err := new(Point).SetBytes(b); err == nil {
+			t.Errorf("Point accepted non-canonical encoding %s", s)
+		}
+	}
+}
+
+func TestMarshalCompressed(t *testing.T) {
+	c := Ed25519()
+	for i := 0; i < 10; i++ {
+		x, y := c.ScalarBaseMult(randomScalar(t))
+		data := MarshalCompressed(x, y)
+		if len(data) != 33 || data[0] != byte(2+y.Bit(0)) {
+			t.Fatalf("bad encoding %x", data)
+		}
+		x2, y2 := UnmarshalCompressed(data)
+		if x2 == nil || x.Cmp(x2) != 0 || y.Cmp(y2) != 0 {
+			t.Error("point did not survive MarshalCompressed round trip")
+		}
+	}
+}
+
+func TestUnmarshalCompressedInvalid(t *testing.T) {
+	c := Ed25519()
+	good := MarshalCompressed(c.Params().Gx, c.Params().Gy)
+
+	wrongPrefix := append([]byte{4}, good[1:]...)
+	xTooLarge := append([]byte{2}, bytes.Repeat([]byte{0xff}, 32)...)
+	// A point of order 4, (sqrt(-1), 0), has y = 0, so it has no odd form.
+	T, err := new(Point).SetBytes(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	order4 := MarshalCompressed(T.p.ToAffine())
+	order4[0] = 3
+
+	for _, data := range [][]byte{good[:32], wrongPrefix, xTooLarge, order4} {
+		if x, y := UnmarshalCompressed(data); x != nil || y != nil {
+			t.Errorf("unmarshaled invalid encoding %x", data)
+		}
+	}
+
+	// x = 1 is not the x-coordinate of any point on the curve.
+	offCurve := make([]byte, 33)
+	offCurve[0], offCurve[32] = 2, 1
+	if x, _ := UnmarshalCompressed(offCurve); x != nil {
+		t.Error("unmarshaled an off-curve x")
+	}
+}
+
+func TestUnmarshalPoint(t *testing.T) {
+	c := Ed25519()
+	x, y := c.ScalarBaseMult(randomScalar(t))
+	data := elliptic.Marshal(c, x, y)
+
+	x2, y2, err := UnmarshalPoint(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Cmp(x2) != 0 || y.Cmp(y2) != 0 {
+		t.Error("point did not survive Marshal/UnmarshalPoint round trip")
+	}
+
+	wrongFormat := append([]byte{}, data...)
+	wrongFormat[0] = 2
+
+	// x + p is a non-canonical encoding of x.
+	nonCanonical := append([]byte{}, data...)
+	new(big.Int).Add(x, c.Params().P).FillBytes(nonCanonical[1:33])
+
+	offCurve := append([]byte{}, data...)
+	offCurve[64] ^= 1
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"short", data[:64], ErrInvalidLength},
+		{"wrong format", wrongFormat, ErrInvalidFormat},
+		{"non-canonical", nonCanonical, ErrNonCanonical},
+		{"off curve", offCurve, ErrNotOnCurve},
+	}
+	for _, tt := range tests {
+		if _, _, err := UnmarshalPoint(tt.data); err != tt.err {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestLittleEndianHelpers(t *testing.T) {
+	k := randomScalar(t)
+	if got := ScalarToLittleEndian(ScalarFromLittleEndian(k)); !bytes.Equal(got, k) {
+		t.Errorf("scalar round trip: got %x, want %x", got, k)
+	}
+	if got := ScalarFromLittleEndian([]byte{1, 2}); got.Int64() != 0x0201 {
+		t.Errorf("got %v, want 0x0201", got)
+	}
+
+	// The RFC 7748 u-coordinate of the base point is 9.
+	nine := make([]byte, 32)
+	nine[0] = 9
+	u, err := CoordinateFromLittleEndian(nine)
+	if err != nil || u.Int64() != 9 {
+		t.Errorf("got %v, %v; want 9", u, err)
+	}
+	if got := CoordinateToLittleEndian(u); !bytes.Equal(got, nine) {
+		t.Errorf("got %x, want %x", got, nine)
+	}
+
+	p := CoordinateToLittleEndian(new(big.Int).Sub(Ed25519().Params().P, big.NewInt(1)))
+	p[0]++
+	if _, err := CoordinateFromLittleEndian(p); err != ErrNonCanonical {
+		t.Errorf("p: got %v, want ErrNonCanonical", err)
+	}
+	if _, err := CoordinateFromLittleEndian(nine[:31]); err != ErrInvalidLength {
+		t.Errorf("31 bytes: got %v, want ErrInvalidLength", err)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/sha512"
+	"strconv"
+
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/scalar"
+)
+
+// ExpandedPrivateKey is a private key together with the values RFC 8032
+// derives from its seed before signing: the secret scalar s, the nonce prefix,
+// and the public key. Sign and PrivateKey.Sign hash the seed and clamp the
+// scalar on every call, which ExpandedPrivateKey does only once, so signing
+// many messages with the same key does less work.
+//
+// Its signatures are identical to those of Sign. The zero value is not a
+// valid key; use NewExpandedPrivateKey.
+type ExpandedPrivateKey struct {
+	s         Scalar
+	prefix    [32]byte
+	publicKey [PublicKeySize]byte
+}
+
+// NewExpandedPrivateKey returns the ExpandedPrivateKey for privateKey. It
+// panics if len(privateKey) is not PrivateKeySize.
+func NewExpandedPrivateKey(privateKey PrivateKey) *ExpandedPrivateKey {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	e := new(ExpandedPrivateKey)
+	e.expand(privateKey)
+	return e
+}
+
+// expand sets e to the expansion of privateKey, as in RFC 8032, Section
+// 5.1.5. The public key is taken from privateKey, not recomputed.
+func (e *ExpandedPrivateKey) expand(privateKey PrivateKey) {
+	h := sha512.Sum512(privateKey[:SeedSize])
+	e.s.SetBytesWithClamping(h[:32])
+	copy(e.prefix[:], h[32:])
+	copy(e.publicKey[:], privateKey[SeedSize:])
+}
+
+// Public returns the public key corresponding to e.
+func (e *ExpandedPrivateKey) Public() PublicKey {
+	return append(PublicKey{}, e.publicKey[:]...)
+}
+
+// Sign signs the message with e and returns a signature, like Sign.
+func (e *ExpandedPrivateKey) Sign(message []byte) []byte {
+	signature := make([]byte, SignatureSize)
+	e.signTo(signature, message, nil, nil)
+	return signature
+}
+
+// SignTo signs the message with e like Sign, but writes the signature to dst
+// instead of allocating it. It doesn't allocate, and panics if len(dst) is
+// not SignatureSize.
+func (e *ExpandedPrivateKey) SignTo(dst, message []byte) {
+	if l := len(dst); l != SignatureSize {
+		panic("ed25519: bad signature buffer length: " + strconv.Itoa(l))
+	}
+	e.signTo(dst, message, nil, nil)
+}
+
+// SignWithOptions signs message with e using the variant selected by opts,
+// like SignWithOptions.
+func (e *ExpandedPrivateKey) SignWithOptions(message []byte, opts *Options) ([]byte, error) {
+	dom, err := opts.domFor(message)
+	if err != nil {
+		return nil, err
+	}
+	signature := make([]byte, SignatureSize)
+	e.signTo(signature, message, dom, opts.Randomness)
+	return signature, nil
+}
+
+// signTo implements RFC 8032 signing, Section 5.1.6, with dom and z as in the
+// sign function, and writes the signature to dst, which must be SignatureSize
+// bytes long. Without z, it doesn't allocate.
+func (e *ExpandedPrivateKey) signTo(dst, message, dom, z []byte) {
+	var digest [sha512.Size]byte
+	// r = SHA-512(dom || prefix || M) mod L, where prefix is replaced by
+	// SHA-512(prefix || Z) when hedging. The replacement is 64 bytes long, so
+	// it can't collide with a deterministic input.
+	mh := sha512.New()
+	mh.Write(dom)
+	if len(z) == 0 {
+		mh.Write(e.prefix[:])
+	} else {
+		hedged := sha512.New()
+		hedged.Write(e.prefix[:])
+		hedged.Write(z)
+		mh.Write(hedged.Sum(digest[:0]))
+	}
+	mh.Write(message)
+	var r scalar.Scalar
+	r.FromUniformBytes(mh.Sum(digest[:0]))
+
+	var rBytes [32]byte
+	var R group.ExtendedGroupElement
+	r.ToBytes(rBytes[:])
+	R.ScalarBaseMult(&rBytes)
+	R.ToBytes(dst[:32])
+
+	// S = r + SHA-512(dom || R || A || M) * s mod L
+	var k Scalar
+	k.setChallenge(dom, dst[:32], e.publicKey[:], message)
+	var S scalar.Scalar
+	S.MulAdd(&k.s, &e.s.s, &r)
+	S.ToBytes(dst[32:])
+}
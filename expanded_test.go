@@ -0,0 +1,79 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+)
+
+func TestExpandedPrivateKey(t *testing.T) {
+	for i, tt := range rfc8032Vectors {
+		seed, _ := hex.DecodeString(tt.seed)
+		public, _ := hex.DecodeString(tt.public)
+		message, _ := hex.DecodeString(tt.message)
+		want, _ := hex.DecodeString(tt.signature)
+
+		e := NewExpandedPrivateKey(NewKeyFromSeed(seed))
+		if !bytes.Equal(e.Public(), public) {
+			t.Errorf("test %d: got public key %x, want %x", i+1, e.Public(), public)
+		}
+		if sig := e.Sign(message); !bytes.Equal(sig, want) {
+			t.Errorf("test %d: got signature %x, want %x", i+1, sig, want)
+		}
+	}
+
+	_, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewExpandedPrivateKey(priv)
+	message := []byte("expanded")
+	digest := sha512.Sum512(message)
+	for _, tt := range []struct {
+		opts    *Options
+		message []byte
+	}{
+		{&Options{}, message},
+		{&Options{Context: "foo"}, message},
+		{&Options{Hash: crypto.SHA512}, digest[:]},
+		{&Options{Hash: crypto.SHA512, Context: "foo"}, digest[:]},
+	} {
+		got, err := e.SignWithOptions(tt.message, tt.opts)
+		if err != nil {
+			t.Fatalf("%+v: %v", tt.opts, err)
+		}
+		want, _ := SignWithOptions(priv, tt.message, tt.opts)
+		if !bytes.Equal(got, want) {
+			t.Errorf("%+v: got signature %x, want %x", tt.opts, got, want)
+		}
+	}
+	if _, err := e.SignWithOptions(message, &Options{Hash: crypto.SHA512}); err == nil {
+		t.Error("Ed25519ph signature of a message that is not a digest")
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	_, priv, _ := GenerateKey(rand.Reader)
+	message := []byte("Hello, world!")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Sign(priv, message)
+	}
+}
+
+func BenchmarkExpandedPrivateKeySign(b *testing.B) {
+	_, priv, _ := GenerateKey(rand.Reader)
+	e := NewExpandedPrivateKey(priv)
+	message := []byte("Hello, world!")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Sign(message)
+	}
+}
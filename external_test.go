@@ -0,0 +1,74 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func TestSubNeg(t *testing.T) {
+	c := ed25519.Ed25519()
+	Gx, Gy := c.Params().Gx, c.Params().Gy
+	G2x, G2y := c.Double(Gx, Gy)
+
+	x, y := ed25519.Sub(G2x, G2y, Gx, Gy)
+	if x.Cmp(Gx) != 0 || y.Cmp(Gy) != 0 {
+		t.Error("2B-B != B")
+	}
+
+	Nx, Ny := ed25519.Neg(Gx, Gy)
+	if !c.IsOnCurve(Nx, Ny) {
+		t.Error("-B is not on the curve")
+	}
+	x, y = c.Add(Gx, Gy, Nx, Ny)
+	if x.Sign() != 0 || y.Cmp(big.NewInt(1)) != 0 {
+		t.Error("B+(-B) is not the identity")
+	}
+
+	x, y = ed25519.Sub(Gx, Gy, Nx, Ny)
+	if x.Cmp(G2x) != 0 || y.Cmp(G2y) != 0 {
+		t.Error("B-(-B) != 2B")
+	}
+}
+
+func TestCombinedMult(t *testing.T) {
+	c := ed25519.Ed25519()
+	Gx, Gy := c.Params().Gx, c.Params().Gy
+
+	a, _ := hex.DecodeString("c07eea55b3322f15099b6cf4d2b7e99d3d0fa6807f6fc7a46b5f7cb78daad4e0")
+	Px, Py := c.ScalarBaseMult(a)
+
+	k1 := make([]byte, 32)
+	k2 := make([]byte, 32)
+	for i := 0; i < 10; i++ {
+		if _, err := io.ReadFull(rand.Reader, k1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadFull(rand.Reader, k2); err != nil {
+			t.Fatal(err)
+		}
+
+		x, y := ed25519.CombinedMult(Px, Py, k1, k2)
+
+		ax, ay := c.ScalarBaseMult(k1)
+		bx, by := c.ScalarMult(Px, Py, k2)
+		ex, ey := c.Add(ax, ay, bx, by)
+		if x.Cmp(ex) != 0 || y.Cmp(ey) != 0 {
+			t.Errorf("CombinedMult disagrees with separate multiplications")
+		}
+	}
+
+	x, y := ed25519.CombinedMult(Gx, Gy, []byte{1}, []byte{1})
+	ex, ey := c.Double(Gx, Gy)
+	if x.Cmp(ex) != 0 || y.Cmp(ey) != 0 {
+		t.Errorf("1*G + 1*G != 2*G")
+	}
+}
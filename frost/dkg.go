@@ -0,0 +1,226 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frost
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/polynomial"
+)
+
+// DKG is one participant's state in a distributed key generation, which
+// produces the same kind of key packages as TrustedDealerKeygen without
+// anybody ever knowing the group secret.
+//
+// It's the Pedersen DKG of the FROST paper: every participant deals a
+// Feldman VSS of a random secret of its own, with a proof of knowledge of
+// that secret to prevent rogue-key attacks, and the group secret is the sum
+// of all of them. It takes two rounds:
+//
+//  1. Each participant broadcasts its Round1Package to all others.
+//  2. Each participant checks the packages it received with Round2, and
+//     sends each other participant its Round2Package over a confidential
+//     and authenticated channel.
+//
+// Finish then checks the received shares and returns the key packages.
+// Broadcasts must be reliable: all participants must see the same
+// Round1Packages, or they can end up with inconsistent keys.
+type DKG struct {
+	id         uint32
+	minSigners int
+	maxSigners int
+	f          *polynomial.Polynomial
+	round1     Round1Package
+	received   map[uint32][]*ed25519.Point
+}
+
+// Round1Package is what a participant broadcasts in the first round of a DKG:
+// the Feldman commitment to its polynomial, and a proof of knowledge of its
+// constant term.
+type Round1Package struct {
+	Identifier uint32
+	Commitment []*ed25519.Point
+	// Proof is a Schnorr signature R || z by the constant term, proving
+	// knowledge of it.
+	Proof []byte
+}
+
+// Round2Package is the secret share a participant sends to another in the
+// second round of a DKG.
+type Round2Package struct {
+	From, To uint32
+	Share    ed25519.Scalar
+}
+
+// NewDKG starts a distributed key generation for participant id, out of
+// maxSigners, with threshold minSigners. Identifiers are expected to be 1
+// through maxSigners, but any distinct nonzero values work.
+func NewDKG(rand io.Reader, id uint32, minSigners, maxSigners int) (*DKG, error) {
+	if minSigners < 2 || minSigners > maxSigners {
+		return nil, errors.New("frost: invalid threshold")
+	}
+	if id == 0 {
+		return nil, errors.New("frost: invalid identifier")
+	}
+	secret, err := ed25519.NewRandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	f, err := polynomial.NewRandom(rand, secret, minSigners-1)
+	if err != nil {
+		return nil, err
+	}
+	d := &DKG{id: id, minSigners: minSigners, maxSigners: maxSigners, f: f}
+
+	// Prove knowledge of a_0 with a Schnorr signature bound to id and C_0.
+	k, err := ed25519.NewRandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	commitment := f.Commitments()
+	R := new(ed25519.Point).ScalarBaseMult(k.Bytes()).Bytes()
+	c := dkgChallenge(id, commitment[0], R)
+	z := ed25519.NewScalar().MultiplyAdd(c, secret, k)
+	d.round1 = Round1Package{
+		Identifier: id,
+		Commitment: commitment,
+		Proof:      append(R, z.Bytes()...),
+	}
+	return d, nil
+}
+
+// dkgChallenge returns the challenge of the proof of knowledge of the
+// participant id, for the commitment C_0 and the nonce R. Unlike H1 to H5,
+// it's not fixed by RFC 9591, so it's derived from a transcript.
+func dkgChallenge(id uint32, c0 *ed25519.Point, R []byte) *ed25519.Scalar {
+	t := ed25519.NewTranscript(contextString + "dkg")
+	t.AppendScalar("identifier", identifierScalar(id))
+	t.AppendPoint("C_0", c0)
+	t.AppendMessage("R", R)
+	return t.ChallengeScalar("challenge")
+}
+
+// verify checks the shape of p and its proof of knowledge.
+func (p *Round1Package) verify(minSigners int) error {
+	blame := " of participant " + strconv.Itoa(int(p.Identifier))
+	if len(p.Commitment) != minSigners {
+		return errors.New("frost: wrong commitment length" + blame)
+	}
+	for _, C := range p.Commitment {
+		if C == nil || C.IsTorsionFree() != 1 {
+			return errors.New("frost: invalid commitment" + blame)
+		}
+	}
+	if len(p.Proof) != 64 {
+		return errors.New("frost: invalid proof" + blame)
+	}
+	R, err := new(ed25519.Point).SetBytes(p.Proof[:32])
+	if err != nil {
+		return errors.New("frost: invalid proof" + blame)
+	}
+	z, err := ed25519.NewScalar().SetCanonicalBytes(p.Proof[32:])
+	if err != nil {
+		return errors.New("frost: invalid proof" + blame)
+	}
+
+	// z*B = R + c*C_0
+	c := dkgChallenge(p.Identifier, p.Commitment[0], p.Proof[:32])
+	rhs := new(ed25519.Point).ScalarMult(c.Bytes(), p.Commitment[0])
+	rhs.Add(rhs, R)
+	if new(ed25519.Point).ScalarBaseMult(z.Bytes()).Equal(rhs) != 1 {
+		return errors.New("frost: invalid proof" + blame)
+	}
+	return nil
+}
+
+// Round1 returns the package to broadcast to the other participants.
+func (d *DKG) Round1() Round1Package {
+	return d.round1
+}
+
+// Round2 checks the first-round packages of the other participants, and
+// returns the shares to send to each of them. It returns an error naming the
+// first participant whose package is invalid, or if the packages don't come
+// from maxSigners-1 distinct other participants.
+func (d *DKG) Round2(packages []Round1Package) ([]Round2Package, error) {
+	if len(packages) != d.maxSigners-1 {
+		return nil, errors.New("frost: wrong number of round 1 packages")
+	}
+	received := make(map[uint32][]*ed25519.Point, len(packages))
+	for i := range packages {
+		p := &packages[i]
+		if p.Identifier == 0 || p.Identifier == d.id || received[p.Identifier] != nil {
+			return nil, errors.New("frost: invalid or duplicate identifier")
+		}
+		if err := p.verify(d.minSigners); err != nil {
+			return nil, err
+		}
+		received[p.Identifier] = p.Commitment
+	}
+	d.received = received
+
+	out := make([]Round2Package, 0, len(packages))
+	for _, p := range packages {
+		r := Round2Package{From: d.id, To: p.Identifier}
+		r.Share.Set(d.f.Evaluate(identifierScalar(p.Identifier)))
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Finish checks the shares received in the second round against the
+// commitments of their senders, and returns the participant's key package
+// and the public key package of the group. It returns an error naming the
+// first participant whose share is invalid, or if a share is missing.
+func (d *DKG) Finish(shares []Round2Package) (*KeyPackage, *PublicKeyPackage, error) {
+	if d.received == nil {
+		return nil, nil, errors.New("frost: Finish called before Round2")
+	}
+	if len(shares) != len(d.received) {
+		return nil, nil, errors.New("frost: wrong number of round 2 packages")
+	}
+
+	kp := &KeyPackage{Identifier: d.id, MinSigners: d.minSigners}
+	kp.SigningShare.Set(d.f.Evaluate(identifierScalar(d.id)))
+	seen := make(map[uint32]bool, len(shares))
+	for i := range shares {
+		s := &shares[i]
+		commitment, ok := d.received[s.From]
+		if !ok || seen[s.From] || s.To != d.id {
+			return nil, nil, errors.New("frost: unexpected round 2 package")
+		}
+		seen[s.From] = true
+		expected := commitmentAt(commitment, d.id)
+		if new(ed25519.Point).ScalarBaseMult(s.Share.Bytes()).Equal(expected) != 1 {
+			return nil, nil, errors.New("frost: invalid share of participant " + strconv.Itoa(int(s.From)))
+		}
+		kp.SigningShare.Add(&kp.SigningShare, &s.Share)
+	}
+
+	// The group's polynomial commitment is the sum of everyone's.
+	group := d.round1.Commitment
+	sum := make([]*ed25519.Point, len(group))
+	for j := range sum {
+		sum[j] = new(ed25519.Point).Set(group[j])
+		for _, commitment := range d.received {
+			sum[j].Add(sum[j], commitment[j])
+		}
+	}
+
+	pub := &PublicKeyPackage{
+		VerifyingShares: make(map[uint32][]byte, d.maxSigners),
+		GroupKey:        sum[0].Bytes(),
+	}
+	pub.VerifyingShares[d.id] = commitmentAt(sum, d.id).Bytes()
+	for id := range d.received {
+		pub.VerifyingShares[id] = commitmentAt(sum, id).Bytes()
+	}
+	kp.VerifyingShare = pub.VerifyingShares[d.id]
+	kp.GroupKey = append(ed25519.PublicKey{}, pub.GroupKey...)
+	return kp, pub, nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frost
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+// runDKG runs a DKG among n participants, optionally tampering with the share
+// sent from participant 1 to participant 2.
+func runDKG(t *testing.T, minSigners, n int, tamper bool) ([]KeyPackage, []*PublicKeyPackage, error) {
+	dkgs := make([]*DKG, n)
+	round1 := make([]Round1Package, n)
+	for i := range dkgs {
+		d, err := NewDKG(rand.Reader, uint32(i+1), minSigners, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dkgs[i] = d
+		round1[i] = d.Round1()
+	}
+
+	inbox := make([][]Round2Package, n)
+	for i, d := range dkgs {
+		others := append(append([]Round1Package{}, round1[:i]...), round1[i+1:]...)
+		out, err := d.Round2(others)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range out {
+			if tamper && p.From == 1 && p.To == 2 {
+				p.Share.Add(&p.Share, identifierScalar(1))
+			}
+			inbox[p.To-1] = append(inbox[p.To-1], p)
+		}
+	}
+
+	keys := make([]KeyPackage, n)
+	pubs := make([]*PublicKeyPackage, n)
+	for i, d := range dkgs {
+		kp, pub, err := d.Finish(inbox[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		keys[i], pubs[i] = *kp, pub
+	}
+	return keys, pubs, nil
+}
+
+func TestDKG(t *testing.T) {
+	keys, pubs, err := runDKG(t, 3, 5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range pubs {
+		if !bytes.Equal(pubs[i].GroupKey, pubs[0].GroupKey) || !bytes.Equal(keys[i].GroupKey, pubs[0].GroupKey) {
+			t.Fatalf("participant %d: different group key", i+1)
+		}
+		for id, share := range pubs[0].VerifyingShares {
+			if !bytes.Equal(pubs[i].VerifyingShares[id], share) {
+				t.Errorf("participant %d: different verifying share for %d", i+1, id)
+			}
+		}
+	}
+
+	message := []byte("no dealer")
+	commitments, shares := sign(t, []KeyPackage{keys[4], keys[0], keys[2]}, message)
+	sig, err := Aggregate(pubs[1], message, commitments, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pubs[1].GroupKey, message, sig) {
+		t.Error("signature rejected")
+	}
+}
+
+func TestDKGBlame(t *testing.T) {
+	_, _, err := runDKG(t, 2, 3, true)
+	if err == nil || err.Error() != "frost: invalid share of participant 1" {
+		t.Errorf("got %v, want participant 1 blamed", err)
+	}
+
+	d1, _ := NewDKG(rand.Reader, 1, 2, 3)
+	d2, _ := NewDKG(rand.Reader, 2, 2, 3)
+	d3, _ := NewDKG(rand.Reader, 3, 2, 3)
+	p2, p3 := d2.Round1(), d3.Round1()
+	p3.Proof = append([]byte{}, p2.Proof...)
+	if _, err := d1.Round2([]Round1Package{p2, p3}); err == nil {
+		t.Error("replayed proof of knowledge accepted")
+	}
+	if _, err := d1.Round2([]Round1Package{p2, p2}); err == nil {
+		t.Error("duplicate participant accepted")
+	}
+	if _, err := d1.Round2([]Round1Package{p2}); err == nil {
+		t.Error("missing participant accepted")
+	}
+}
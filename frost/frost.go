@@ -0,0 +1,390 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package frost implements FROST(Ed25519, SHA-512) threshold signatures, as
+// specified in RFC 9591.
+//
+// A group key is shared among n participants so that any t of them can
+// produce a signature, which is an ordinary Ed25519 signature for the group
+// key. Signing takes two rounds: each signer publishes a pair of nonce
+// commitments with Commit, and once the commitments of all signers are known,
+// a signature share with Sign. A coordinator then combines the shares with
+// Aggregate, which also identifies misbehaving signers.
+//
+// Participants are identified by nonzero uint32 values, which are the
+// x-coordinates of their shares, as in package scalarshare. Keys are
+// generated by a trusted dealer with TrustedDealerKeygen, or without one with
+// a DKG, and their shares can later be refreshed or moved to new participants
+// without changing the group key.
+package frost
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/polynomial"
+	"github.com/gtank/ed25519/scalarshare"
+)
+
+// contextString is the domain separator of the ciphersuite, RFC 9591, Section
+// 6.1.
+const contextString = "FROST-ED25519-SHA512-v1"
+
+// SignatureShareSize is the size of an encoded signature share, a scalar.
+const SignatureShareSize = 32
+
+// H1 through H5 of RFC 9591, Section 6.1. H2 has no prefix, so that the
+// challenge is the Ed25519 one.
+func h1(m []byte) *ed25519.Scalar {
+	return hashToScalar([]byte(contextString+"rho"), m)
+}
+
+func h2(m ...[]byte) *ed25519.Scalar {
+	return hashToScalar(nil, m...)
+}
+
+func h3(m ...[]byte) *ed25519.Scalar {
+	return hashToScalar([]byte(contextString+"nonce"), m...)
+}
+
+func h4(m []byte) []byte {
+	return hash([]byte(contextString+"msg"), m)
+}
+
+func h5(m []byte) []byte {
+	return hash([]byte(contextString+"com"), m)
+}
+
+func hash(prefix []byte, m ...[]byte) []byte {
+	h := sha512.New()
+	h.Write(prefix)
+	for _, b := range m {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+func hashToScalar(prefix []byte, m ...[]byte) *ed25519.Scalar {
+	s, _ := ed25519.NewScalar().SetUniformBytes(hash(prefix, m...))
+	return s
+}
+
+// identifierScalar returns the identifier id as a scalar.
+func identifierScalar(id uint32) *ed25519.Scalar {
+	var b [32]byte
+	binary.LittleEndian.PutUint32(b[:], id)
+	s, _ := ed25519.NewScalar().SetCanonicalBytes(b[:])
+	return s
+}
+
+// decodeElement implements DeserializeElement: x must be the canonical
+// encoding of a point of the prime-order subgroup other than the identity.
+func decodeElement(x []byte) (*ed25519.Point, error) {
+	p, err := new(ed25519.Point).SetBytesPrimeOrder(x)
+	if err != nil {
+		return nil, err
+	}
+	if p.IsIdentity() == 1 {
+		return nil, errors.New("frost: identity element")
+	}
+	return p, nil
+}
+
+// KeyPackage is the long-lived key material of one participant.
+type KeyPackage struct {
+	// Identifier is the participant's identifier, the x-coordinate of its
+	// share.
+	Identifier uint32
+	// SigningShare is the participant's secret share of the group key.
+	SigningShare ed25519.Scalar
+	// VerifyingShare is SigningShare*B, encoded.
+	VerifyingShare []byte
+	// GroupKey is the group's public key.
+	GroupKey ed25519.PublicKey
+	// MinSigners is the threshold t, the number of signers required.
+	MinSigners int
+}
+
+// PublicKeyPackage is the public key material of the group, which the
+// coordinator needs to check signature shares.
+type PublicKeyPackage struct {
+	// VerifyingShares maps each identifier to its verifying share.
+	VerifyingShares map[uint32][]byte
+	// GroupKey is the group's public key.
+	GroupKey ed25519.PublicKey
+}
+
+// TrustedDealerKeygen splits secret into maxSigners key packages, any
+// minSigners of which can sign for secret*B, following RFC 9591, Appendix C.
+// It also returns the public key package, and the Feldman VSS commitment that
+// participants can check their shares against with VerifyShare. The dealer
+// learns the secret, and must be trusted to erase it.
+func TrustedDealerKeygen(rand io.Reader, secret *ed25519.Scalar, minSigners, maxSigners int) ([]KeyPackage, *PublicKeyPackage, []*ed25519.Point, error) {
+	if minSigners < 2 || minSigners > maxSigners {
+		return nil, nil, nil, errors.New("frost: invalid threshold")
+	}
+	if uint64(maxSigners) >= 1<<32 {
+		return nil, nil, nil, errors.New("frost: too many participants")
+	}
+	f, err := polynomial.NewRandom(rand, secret, minSigners-1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	commitment := f.Commitments()
+	groupKey := commitment[0].Bytes()
+
+	keys := make([]KeyPackage, maxSigners)
+	pub := &PublicKeyPackage{
+		VerifyingShares: make(map[uint32][]byte, maxSigners),
+		GroupKey:        groupKey,
+	}
+	for i := range keys {
+		id := uint32(i + 1)
+		kp := &keys[i]
+		kp.Identifier = id
+		kp.SigningShare.Set(f.Evaluate(identifierScalar(id)))
+		kp.VerifyingShare = new(ed25519.Point).ScalarBaseMult(kp.SigningShare.Bytes()).Bytes()
+		kp.GroupKey = append(ed25519.PublicKey{}, groupKey...)
+		kp.MinSigners = minSigners
+		pub.VerifyingShares[id] = kp.VerifyingShare
+	}
+	return keys, pub, commitment, nil
+}
+
+// VerifyShare reports whether the signing share of kp is consistent with the
+// VSS commitment of the dealer, and whether kp's group key is the one the
+// commitment is for.
+func VerifyShare(kp *KeyPackage, commitment []*ed25519.Point) bool {
+	if len(commitment) == 0 {
+		return false
+	}
+	expected := commitmentAt(commitment, kp.Identifier)
+	if new(ed25519.Point).ScalarBaseMult(kp.SigningShare.Bytes()).Equal(expected) != 1 {
+		return false
+	}
+	return string(commitment[0].Bytes()) == string(kp.GroupKey)
+}
+
+// commitmentAt returns sum(id^j * C_j), the public value of the committed
+// polynomial at id.
+func commitmentAt(commitment []*ed25519.Point, id uint32) *ed25519.Point {
+	x := identifierScalar(id)
+	xj := identifierScalar(1)
+	scalars := make([][]byte, len(commitment))
+	for j := range commitment {
+		scalars[j] = xj.Bytes()
+		xj.Mul(xj, x)
+	}
+	return new(ed25519.Point).VartimeMultiScalarMult(scalars, commitment)
+}
+
+// SigningCommitments are the public nonce commitments a signer publishes in
+// the first round.
+type SigningCommitments struct {
+	Identifier uint32
+	Hiding     []byte
+	Binding    []byte
+}
+
+// SigningNonces are the secret nonces of one signer for one signature.
+type SigningNonces struct {
+	hiding, binding ed25519.Scalar
+	commitments     SigningCommitments
+	used            bool
+}
+
+// Commit runs the first round for kp: it generates a pair of nonces, mixing
+// randomness from rand with the signing share as in RFC 9591, Section 4.1,
+// and returns them with their commitments. The nonces must be used for a
+// single signature.
+func Commit(rand io.Reader, kp *KeyPackage) (*SigningNonces, SigningCommitments, error) {
+	n := &SigningNonces{}
+	secret := kp.SigningShare.Bytes()
+	for _, nonce := range []*ed25519.Scalar{&n.hiding, &n.binding} {
+		randomBytes := make([]byte, 32)
+		if _, err := io.ReadFull(rand, randomBytes); err != nil {
+			return nil, SigningCommitments{}, err
+		}
+		nonce.Set(h3(randomBytes, secret))
+	}
+	n.commitments = SigningCommitments{
+		Identifier: kp.Identifier,
+		Hiding:     new(ed25519.Point).ScalarBaseMult(n.hiding.Bytes()).Bytes(),
+		Binding:    new(ed25519.Point).ScalarBaseMult(n.binding.Bytes()).Bytes(),
+	}
+	return n, n.commitments, nil
+}
+
+// signingPackage is the state derived from the commitment list and message,
+// shared by Sign, VerifySignatureShare and Aggregate.
+type signingPackage struct {
+	ids            []uint32
+	bindingFactors map[uint32]*ed25519.Scalar
+	lambdas        map[uint32]*ed25519.Scalar
+	commitments    map[uint32]SigningCommitments
+	rBytes         []byte
+	challenge      *ed25519.Scalar
+}
+
+// newSigningPackage computes the binding factors, the group commitment and
+// the challenge, as in RFC 9591, Sections 4.4 to 4.6.
+func newSigningPackage(groupKey, message []byte, commitments []SigningCommitments) (*signingPackage, error) {
+	list := append([]SigningCommitments{}, commitments...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Identifier < list[j].Identifier })
+
+	sp := &signingPackage{
+		bindingFactors: make(map[uint32]*ed25519.Scalar, len(list)),
+		commitments:    make(map[uint32]SigningCommitments, len(list)),
+	}
+	var encoded []byte
+	hiding := make([]*ed25519.Point, len(list))
+	binding := make([]*ed25519.Point, len(list))
+	for i, c := range list {
+		if c.Identifier == 0 || (i > 0 && list[i-1].Identifier == c.Identifier) {
+			return nil, errors.New("frost: invalid or duplicate identifier")
+		}
+		var err error
+		if hiding[i], err = decodeElement(c.Hiding); err != nil {
+			return nil, errors.New("frost: invalid commitment of participant " + strconv.Itoa(int(c.Identifier)))
+		}
+		if binding[i], err = decodeElement(c.Binding); err != nil {
+			return nil, errors.New("frost: invalid commitment of participant " + strconv.Itoa(int(c.Identifier)))
+		}
+		sp.ids = append(sp.ids, c.Identifier)
+		sp.commitments[c.Identifier] = c
+		encoded = append(encoded, identifierScalar(c.Identifier).Bytes()...)
+		encoded = append(encoded, c.Hiding...)
+		encoded = append(encoded, c.Binding...)
+	}
+
+	prefix := append(append(append([]byte{}, groupKey...), h4(message)...), h5(encoded)...)
+	R := ed25519.NewIdentityPoint()
+	for i, id := range sp.ids {
+		rho := h1(append(append([]byte{}, prefix...), identifierScalar(id).Bytes()...))
+		sp.bindingFactors[id] = rho
+		R.Add(R, hiding[i])
+		R.Add(R, new(ed25519.Point).ScalarMult(rho.Bytes(), binding[i]))
+	}
+	sp.rBytes = R.Bytes()
+	sp.challenge = h2(sp.rBytes, groupKey, message)
+
+	coeffs := scalarshare.LagrangeCoefficients(sp.ids)
+	sp.lambdas = make(map[uint32]*ed25519.Scalar, len(sp.ids))
+	for i, id := range sp.ids {
+		sp.lambdas[id] = &coeffs[i]
+	}
+	return sp, nil
+}
+
+// Sign runs the second round: it returns the signature share of kp for
+// message, given the commitments of all signers, including its own. It
+// erases nonces. It returns an error if nonces were already used, if the
+// commitments don't include those of nonces, or if there are fewer than
+// MinSigners of them.
+func Sign(kp *KeyPackage, nonces *SigningNonces, message []byte, commitments []SigningCommitments) ([]byte, error) {
+	if nonces.used {
+		return nil, errors.New("frost: nonces already used")
+	}
+	if len(commitments) < kp.MinSigners {
+		return nil, errors.New("frost: not enough signers")
+	}
+	sp, err := newSigningPackage(kp.GroupKey, message, commitments)
+	if err != nil {
+		return nil, err
+	}
+	own, ok := sp.commitments[kp.Identifier]
+	if !ok || string(own.Hiding) != string(nonces.commitments.Hiding) ||
+		string(own.Binding) != string(nonces.commitments.Binding) {
+		return nil, errors.New("frost: own commitments missing from the list")
+	}
+
+	// z_i = d_i + e_i*rho_i + lambda_i*s_i*c
+	z := ed25519.NewScalar().MultiplyAdd(&nonces.binding, sp.bindingFactors[kp.Identifier], &nonces.hiding)
+	lc := ed25519.NewScalar().Mul(sp.lambdas[kp.Identifier], sp.challenge)
+	z.MultiplyAdd(lc, &kp.SigningShare, z)
+
+	zero := ed25519.NewScalar()
+	nonces.hiding.Set(zero)
+	nonces.binding.Set(zero)
+	nonces.used = true
+	return z.Bytes(), nil
+}
+
+// SignatureShare is the second-round output of one signer.
+type SignatureShare struct {
+	Identifier uint32
+	Share      []byte
+}
+
+// VerifySignatureShare reports whether share is a valid signature share for
+// message, given the commitments of all signers, as in RFC 9591, Section
+// 5.4.
+func VerifySignatureShare(pub *PublicKeyPackage, share SignatureShare, message []byte, commitments []SigningCommitments) bool {
+	sp, err := newSigningPackage(pub.GroupKey, message, commitments)
+	if err != nil {
+		return false
+	}
+	return sp.verifyShare(pub, share)
+}
+
+func (sp *signingPackage) verifyShare(pub *PublicKeyPackage, share SignatureShare) bool {
+	z, err := ed25519.NewScalar().SetCanonicalBytes(share.Share)
+	if err != nil {
+		return false
+	}
+	c, ok := sp.commitments[share.Identifier]
+	if !ok {
+		return false
+	}
+	Y, err := decodeElement(pub.VerifyingShares[share.Identifier])
+	if err != nil {
+		return false
+	}
+	D, _ := decodeElement(c.Hiding)
+	E, _ := decodeElement(c.Binding)
+
+	// z_i*B = D_i + rho_i*E_i + (c*lambda_i)*Y_i
+	lc := ed25519.NewScalar().Mul(sp.lambdas[share.Identifier], sp.challenge)
+	rhs := new(ed25519.Point).VartimeMultiScalarMult(
+		[][]byte{sp.bindingFactors[share.Identifier].Bytes(), lc.Bytes()},
+		[]*ed25519.Point{E, Y})
+	rhs.Add(rhs, D)
+	return new(ed25519.Point).ScalarBaseMult(z.Bytes()).Equal(rhs) == 1
+}
+
+// Aggregate combines the signature shares of all signers into an Ed25519
+// signature of message for the group key. If the result doesn't verify, it
+// checks each share and returns an error naming the first invalid one.
+func Aggregate(pub *PublicKeyPackage, message []byte, commitments []SigningCommitments, shares []SignatureShare) ([]byte, error) {
+	if len(shares) != len(commitments) {
+		return nil, errors.New("frost: number of shares and commitments differ")
+	}
+	sp, err := newSigningPackage(pub.GroupKey, message, commitments)
+	if err != nil {
+		return nil, err
+	}
+	z := ed25519.NewScalar()
+	for _, share := range shares {
+		zi, err := ed25519.NewScalar().SetCanonicalBytes(share.Share)
+		if err != nil {
+			return nil, errors.New("frost: malformed share of participant " + strconv.Itoa(int(share.Identifier)))
+		}
+		z.Add(z, zi)
+	}
+	sig := append(append(make([]byte, 0, ed25519.SignatureSize), sp.rBytes...), z.Bytes()...)
+	if ed25519.Verify(pub.GroupKey, message, sig) {
+		return sig, nil
+	}
+	for _, share := range shares {
+		if !sp.verifyShare(pub, share) {
+			return nil, errors.New("frost: invalid share of participant " + strconv.Itoa(int(share.Identifier)))
+		}
+	}
+	return nil, errors.New("frost: invalid signature")
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frost
+
+import (
+	"bytes"
+	stded25519 "crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/polynomial"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func decodeScalar(t *testing.T, s string) *ed25519.Scalar {
+	x, err := ed25519.NewScalar().SetCanonicalBytes(decodeHex(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+// TestRFC9591Vector checks the FROST(Ed25519, SHA-512) test vector of RFC
+// 9591, Appendix E.1, with participants 1 and 3 signing.
+func TestRFC9591Vector(t *testing.T) {
+	groupSecret := decodeScalar(t, "7b1c33d3f5291d85de664833beb1ad469f7fb6025a0ec78b3a790c6e13a98304")
+	coeff := decodeScalar(t, "178199860edd8c62f5212ee91eff1295d0d670ab4ed4506866bae57e7030b204")
+	groupKey := decodeHex(t, "15d21ccd7ee42959562fc8aa63224c8851fb3ec85a3faf66040d380fb9738673")
+	message := decodeHex(t, "74657374")
+
+	f := polynomial.New(groupSecret, coeff)
+	if !bytes.Equal(f.Commitments()[0].Bytes(), groupKey) {
+		t.Fatal("wrong group public key")
+	}
+
+	participants := []struct {
+		id                          uint32
+		share                       string
+		randomness                  string
+		hidingCommit, bindingCommit string
+		sigShare                    string
+	}{
+		{
+			id:            1,
+			share:         "929dcc590407aae7d388761cddb0c0db6f5627aea8e217f4a033f2ec83d93509",
+			randomness:    "0fd2e39e111cdc266f6c0f4d0fd45c947761f1f5d3cb583dfcb9bbaf8d4c9fec69cd85f631d5f7f2721ed5e40519b1366f340a87c2f6856363dbdcda348a7501",
+			hidingCommit:  "b5aa8ab305882a6fc69cbee9327e5a45e54c08af61ae77cb8207be3d2ce13de3",
+			bindingCommit: "67e98ab55aa310c3120418e5050c9cf76cf387cb20ac9e4b6fdb6f82a469f932",
+			sigShare:      "001719ab5a53ee1a12095cd088fd149702c0720ce5fd2f29dbecf24b7281b603",
+		},
+		{
+			id:            3,
+			share:         "d3cb090a075eb154e82fdb4b3cb507f110040905468bb9c46da8bdea643a9a02",
+			randomness:    "86d64a260059e495d0fb4fcc17ea3da7452391baa494d4b00321098ed2a0062f13e6b25afb2eba51716a9a7d44130c0dbae0004a9ef8d7b5550c8a0e07c61775",
+			hidingCommit:  "cfbdb165bd8aad6eb79deb8d287bcc0ab6658ae57fdcc98ed12c0669e90aec91",
+			bindingCommit: "7487bc41a6e712eea2f2af24681b58b1cf1da278ea11fe4e8b78398965f13552",
+			sigShare:      "bd86125de990acc5e1f13781d8e32c03a9bbd4c53539bbc106058bfd14326007",
+		},
+	}
+
+	pub := &PublicKeyPackage{VerifyingShares: map[uint32][]byte{}, GroupKey: groupKey}
+	var keys []*KeyPackage
+	var nonces []*SigningNonces
+	var commitments []SigningCommitments
+	for _, p := range participants {
+		kp := &KeyPackage{Identifier: p.id, GroupKey: groupKey, MinSigners: 2}
+		kp.SigningShare.Set(decodeScalar(t, p.share))
+		if kp.SigningShare.Equal(f.Evaluate(identifierScalar(p.id))) != 1 {
+			t.Errorf("participant %d: wrong share", p.id)
+		}
+		kp.VerifyingShare = new(ed25519.Point).ScalarBaseMult(kp.SigningShare.Bytes()).Bytes()
+		pub.VerifyingShares[p.id] = kp.VerifyingShare
+
+		n, c, err := Commit(bytes.NewReader(decodeHex(t, p.randomness)), kp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(c.Hiding) != p.hidingCommit || hex.EncodeToString(c.Binding) != p.bindingCommit {
+			t.Errorf("participant %d: wrong commitments", p.id)
+		}
+		keys = append(keys, kp)
+		nonces = append(nonces, n)
+		commitments = append(commitments, c)
+	}
+
+	var shares []SignatureShare
+	for i, p := range participants {
+		z, err := Sign(keys[i], nonces[i], message, commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(z) != p.sigShare {
+			t.Errorf("participant %d: got share %x, want %s", p.id, z, p.sigShare)
+		}
+		shares = append(shares, SignatureShare{Identifier: p.id, Share: z})
+	}
+	sig, err := Aggregate(pub, message, commitments, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stded25519.Verify(groupKey, message, sig) {
+		t.Error("signature rejected by crypto/ed25519")
+	}
+}
+
+// sign runs both rounds with the given signers.
+func sign(t *testing.T, keys []KeyPackage, message []byte) ([]SigningCommitments, []SignatureShare) {
+	nonces := make([]*SigningNonces, len(keys))
+	commitments := make([]SigningCommitments, len(keys))
+	for i := range keys {
+		var err error
+		if nonces[i], commitments[i], err = Commit(rand.Reader, &keys[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	shares := make([]SignatureShare, len(keys))
+	for i := range keys {
+		z, err := Sign(&keys[i], nonces[i], message, commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares[i] = SignatureShare{Identifier: keys[i].Identifier, Share: z}
+	}
+	return commitments, shares
+}
+
+func TestThresholdSigning(t *testing.T) {
+	secret, err := ed25519.NewRandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, pub, commitment, err := TrustedDealerKeygen(rand.Reader, secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range keys {
+		if !VerifyShare(&keys[i], commitment) {
+			t.Errorf("participant %d: valid share rejected", keys[i].Identifier)
+		}
+	}
+	message := []byte("threshold")
+
+	for _, signers := range [][]KeyPackage{keys[:3], {keys[4], keys[1], keys[2]}, keys} {
+		commitments, shares := sign(t, signers, message)
+		for _, share := range shares {
+			if !VerifySignatureShare(pub, share, message, commitments) {
+				t.Errorf("participant %d: valid share rejected", share.Identifier)
+			}
+		}
+		sig, err := Aggregate(pub, message, commitments, shares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ed25519.Verify(pub.GroupKey, message, sig) {
+			t.Error("signature rejected")
+		}
+	}
+
+	nonces, c, _ := Commit(rand.Reader, &keys[0])
+	if _, err := Sign(&keys[0], nonces, message, []SigningCommitments{c}); err == nil {
+		t.Error("signed with fewer than MinSigners")
+	}
+}
+
+func TestAggregateIdentifiesCheater(t *testing.T) {
+	secret, _ := ed25519.NewRandomScalar(rand.Reader)
+	keys, pub, commitment, err := TrustedDealerKeygen(rand.Reader, secret, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("threshold")
+	commitments, shares := sign(t, keys[:2], message)
+	shares[1].Share[0] ^= 1
+
+	if VerifySignatureShare(pub, shares[1], message, commitments) {
+		t.Error("tampered share accepted")
+	}
+	_, err = Aggregate(pub, message, commitments, shares)
+	if err == nil || err.Error() != "frost: invalid share of participant 2" {
+		t.Errorf("got %v, want participant 2 blamed", err)
+	}
+
+	keys[2].SigningShare.Add(&keys[2].SigningShare, identifierScalar(1))
+	if VerifyShare(&keys[2], commitment) {
+		t.Error("inconsistent share accepted")
+	}
+}
+
+func TestNoncesAreSingleUse(t *testing.T) {
+	secret, _ := ed25519.NewRandomScalar(rand.Reader)
+	keys, _, _, _ := TrustedDealerKeygen(rand.Reader, secret, 2, 2)
+	n0, c0, _ := Commit(rand.Reader, &keys[0])
+	_, c1, _ := Commit(rand.Reader, &keys[1])
+	commitments := []SigningCommitments{c0, c1}
+	if _, err := Sign(&keys[0], n0, []byte("a"), commitments); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Sign(&keys[0], n0, []byte("b"), commitments); err == nil {
+		t.Error("nonces were used twice")
+	}
+
+	n0, _, _ = Commit(rand.Reader, &keys[0])
+	if _, err := Sign(&keys[0], n0, []byte("a"), commitments); err == nil {
+		t.Error("signed with commitments of other nonces")
+	}
+	if _, err := Sign(&keys[0], n0, []byte("a"), []SigningCommitments{c0, c0}); err == nil {
+		t.Error("duplicate identifiers accepted")
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frost
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/polynomial"
+	"github.com/gtank/ed25519/scalarshare"
+)
+
+// Shares can be changed without changing the group key, in two ways.
+//
+// Refreshing keeps the participants and the threshold, and replaces all
+// shares with fresh ones: every participant deals a sharing of zero with
+// RefreshDeal, and adds what it receives to its share with RefreshComplete.
+// Shares from before a refresh can't be combined with shares from after it,
+// so an attacker has to compromise t participants between two refreshes.
+//
+// Resharing moves the key to a new set of participants, possibly with a new
+// threshold: at least t old participants each deal a sharing of their
+// Lagrange-weighted share with ReshareDeal, and the new participants combine
+// what they receive with ReshareComplete. The old shares should be erased
+// afterwards.
+//
+// In both cases, the commitments of every dealer must be broadcast reliably,
+// and shares must be sent over confidential and authenticated channels. The
+// Round1Packages of these protocols have no Proof, since the constant terms
+// are pinned down by the existing public key package instead.
+
+// RefreshDeal deals a sharing of zero among the participants ids, which must
+// include kp's own identifier, with kp's threshold. It returns the
+// commitment to broadcast, and one share for each participant, including
+// kp itself.
+func RefreshDeal(rand io.Reader, kp *KeyPackage, ids []uint32) (Round1Package, []Round2Package, error) {
+	return deal(rand, kp.Identifier, ed25519.NewScalar(), kp.MinSigners, ids)
+}
+
+// RefreshComplete checks the refresh packages dealt by every participant of
+// pub, including kp, and returns kp and pub with the refreshed shares. It
+// returns an error naming the first dealer whose package is invalid.
+func RefreshComplete(kp *KeyPackage, pub *PublicKeyPackage, commitments []Round1Package, shares []Round2Package) (*KeyPackage, *PublicKeyPackage, error) {
+	if len(commitments) != len(pub.VerifyingShares) {
+		return nil, nil, errors.New("frost: wrong number of refresh commitments")
+	}
+	expected := make(map[uint32]*ed25519.Point, len(commitments))
+	for _, c := range commitments {
+		if _, ok := pub.VerifyingShares[c.Identifier]; !ok {
+			return nil, nil, errors.New("frost: unexpected refresh dealer")
+		}
+		expected[c.Identifier] = ed25519.NewIdentityPoint()
+	}
+	sum, share, err := combineDeals(kp.Identifier, kp.MinSigners, expected, commitments, shares)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newKP := *kp
+	newKP.SigningShare.Add(&kp.SigningShare, share)
+	newPub := &PublicKeyPackage{
+		VerifyingShares: make(map[uint32][]byte, len(pub.VerifyingShares)),
+		GroupKey:        append(ed25519.PublicKey{}, pub.GroupKey...),
+	}
+	for id, y := range pub.VerifyingShares {
+		Y, err := new(ed25519.Point).SetBytes(y)
+		if err != nil {
+			return nil, nil, errors.New("frost: invalid verifying share of participant " + strconv.Itoa(int(id)))
+		}
+		newPub.VerifyingShares[id] = Y.Add(Y, commitmentAt(sum, id)).Bytes()
+	}
+	newKP.VerifyingShare = newPub.VerifyingShares[kp.Identifier]
+	return &newKP, newPub, nil
+}
+
+// ReshareDeal deals kp's contribution to resharing the group key among
+// newIDs with threshold newMinSigners. dealers are the identifiers of all
+// old participants taking part, and must include kp's own and number at
+// least kp.MinSigners. It returns the commitment to broadcast, and one share
+// for each new participant.
+func ReshareDeal(rand io.Reader, kp *KeyPackage, dealers []uint32, newMinSigners int, newIDs []uint32) (Round1Package, []Round2Package, error) {
+	if len(dealers) < kp.MinSigners {
+		return Round1Package{}, nil, errors.New("frost: not enough dealers")
+	}
+	lambda, err := lagrangeCoefficient(dealers, kp.Identifier)
+	if err != nil {
+		return Round1Package{}, nil, err
+	}
+	secret := ed25519.NewScalar().Mul(lambda, &kp.SigningShare)
+	return deal(rand, kp.Identifier, secret, newMinSigners, newIDs)
+}
+
+// ReshareComplete checks the packages dealt by the old participants, whose
+// public key package is old, and returns the key package of the new
+// participant id and the public key package of the new group. It returns an
+// error naming the first dealer whose package is invalid.
+func ReshareComplete(id uint32, newMinSigners int, newIDs []uint32, old *PublicKeyPackage, commitments []Round1Package, shares []Round2Package) (*KeyPackage, *PublicKeyPackage, error) {
+	dealers := make([]uint32, len(commitments))
+	for i, c := range commitments {
+		dealers[i] = c.Identifier
+	}
+	// Each dealer's constant term must be its verifying share, weighted by
+	// its Lagrange coefficient in the set of dealers.
+	expected := make(map[uint32]*ed25519.Point, len(commitments))
+	for _, dealer := range dealers {
+		lambda, err := lagrangeCoefficient(dealers, dealer)
+		if err != nil {
+			return nil, nil, err
+		}
+		Y, err := new(ed25519.Point).SetBytes(old.VerifyingShares[dealer])
+		if err != nil {
+			return nil, nil, errors.New("frost: unknown reshare dealer " + strconv.Itoa(int(dealer)))
+		}
+		expected[dealer] = Y.ScalarMult(lambda.Bytes(), Y)
+	}
+	sum, share, err := combineDeals(id, newMinSigners, expected, commitments, shares)
+	if err != nil {
+		return nil, nil, err
+	}
+	if string(sum[0].Bytes()) != string(old.GroupKey) {
+		return nil, nil, errors.New("frost: not enough dealers")
+	}
+
+	kp := &KeyPackage{Identifier: id, MinSigners: newMinSigners}
+	kp.SigningShare.Set(share)
+	pub := &PublicKeyPackage{
+		VerifyingShares: make(map[uint32][]byte, len(newIDs)),
+		GroupKey:        append(ed25519.PublicKey{}, old.GroupKey...),
+	}
+	for _, j := range newIDs {
+		pub.VerifyingShares[j] = commitmentAt(sum, j).Bytes()
+	}
+	kp.VerifyingShare = pub.VerifyingShares[id]
+	kp.GroupKey = append(ed25519.PublicKey{}, old.GroupKey...)
+	return kp, pub, nil
+}
+
+// deal shares secret among ids with a random polynomial of degree
+// minSigners-1, as dealer.
+func deal(rand io.Reader, dealer uint32, secret *ed25519.Scalar, minSigners int, ids []uint32) (Round1Package, []Round2Package, error) {
+	if minSigners < 2 || minSigners > len(ids) {
+		return Round1Package{}, nil, errors.New("frost: invalid threshold")
+	}
+	seen := make(map[uint32]bool, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			return Round1Package{}, nil, errors.New("frost: invalid or duplicate identifier")
+		}
+		seen[id] = true
+	}
+	f, err := polynomial.NewRandom(rand, secret, minSigners-1)
+	if err != nil {
+		return Round1Package{}, nil, err
+	}
+	shares := make([]Round2Package, len(ids))
+	for i, id := range ids {
+		shares[i] = Round2Package{From: dealer, To: id}
+		shares[i].Share.Set(f.Evaluate(identifierScalar(id)))
+	}
+	return Round1Package{Identifier: dealer, Commitment: f.Commitments()}, shares, nil
+}
+
+// combineDeals checks that every dealer in expected sent a commitment of
+// length minSigners whose constant term is the expected point, and a share
+// for id consistent with it. It returns the sum of the commitments and the
+// sum of the shares.
+func combineDeals(id uint32, minSigners int, expected map[uint32]*ed25519.Point, commitments []Round1Package, shares []Round2Package) ([]*ed25519.Point, *ed25519.Scalar, error) {
+	if len(commitments) != len(expected) || len(shares) != len(expected) {
+		return nil, nil, errors.New("frost: wrong number of packages")
+	}
+	received := make(map[uint32][]*ed25519.Point, len(commitments))
+	sum := make([]*ed25519.Point, minSigners)
+	for j := range sum {
+		sum[j] = ed25519.NewIdentityPoint()
+	}
+	for _, c := range commitments {
+		blame := " of participant " + strconv.Itoa(int(c.Identifier))
+		want, ok := expected[c.Identifier]
+		if !ok || received[c.Identifier] != nil {
+			return nil, nil, errors.New("frost: unexpected commitment" + blame)
+		}
+		if len(c.Commitment) != minSigners {
+			return nil, nil, errors.New("frost: wrong commitment length" + blame)
+		}
+		for _, C := range c.Commitment {
+			if C == nil || C.IsTorsionFree() != 1 {
+				return nil, nil, errors.New("frost: invalid commitment" + blame)
+			}
+		}
+		if c.Commitment[0].Equal(want) != 1 {
+			return nil, nil, errors.New("frost: invalid commitment" + blame)
+		}
+		received[c.Identifier] = c.Commitment
+		for j := range sum {
+			sum[j].Add(sum[j], c.Commitment[j])
+		}
+	}
+
+	share := ed25519.NewScalar()
+	seen := make(map[uint32]bool, len(shares))
+	for i := range shares {
+		s := &shares[i]
+		commitment, ok := received[s.From]
+		if !ok || seen[s.From] || s.To != id {
+			return nil, nil, errors.New("frost: unexpected share")
+		}
+		seen[s.From] = true
+		if new(ed25519.Point).ScalarBaseMult(s.Share.Bytes()).Equal(commitmentAt(commitment, id)) != 1 {
+			return nil, nil, errors.New("frost: invalid share of participant " + strconv.Itoa(int(s.From)))
+		}
+		share.Add(share, &s.Share)
+	}
+	return sum, share, nil
+}
+
+// lagrangeCoefficient returns the Lagrange coefficient of id in ids.
+func lagrangeCoefficient(ids []uint32, id uint32) (*ed25519.Scalar, error) {
+	index := -1
+	seen := make(map[uint32]bool, len(ids))
+	for i, x := range ids {
+		if x == 0 || seen[x] {
+			return nil, errors.New("frost: invalid or duplicate identifier")
+		}
+		seen[x] = true
+		if x == id {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, errors.New("frost: identifier " + strconv.Itoa(int(id)) + " not in the set")
+	}
+	return &scalarshare.LagrangeCoefficients(ids)[index], nil
+}
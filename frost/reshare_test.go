@@ -0,0 +1,138 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frost
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func newGroup(t *testing.T, minSigners, maxSigners int) ([]KeyPackage, *PublicKeyPackage) {
+	secret, err := ed25519.NewRandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, pub, _, err := TrustedDealerKeygen(rand.Reader, secret, minSigners, maxSigners)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys, pub
+}
+
+func checkSigning(t *testing.T, keys []KeyPackage, pub *PublicKeyPackage) {
+	t.Helper()
+	message := []byte("after")
+	commitments, shares := sign(t, keys, message)
+	sig, err := Aggregate(pub, message, commitments, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub.GroupKey, message, sig) {
+		t.Error("signature rejected")
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	keys, pub := newGroup(t, 2, 3)
+	ids := []uint32{1, 2, 3}
+
+	var commitments []Round1Package
+	inbox := make([][]Round2Package, len(keys))
+	for i := range keys {
+		c, shares, err := RefreshDeal(rand.Reader, &keys[i], ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitments = append(commitments, c)
+		for _, s := range shares {
+			inbox[s.To-1] = append(inbox[s.To-1], s)
+		}
+	}
+
+	refreshed := make([]KeyPackage, len(keys))
+	var newPub *PublicKeyPackage
+	for i := range keys {
+		kp, p, err := RefreshComplete(&keys[i], pub, commitments, inbox[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kp.SigningShare.Equal(&keys[i].SigningShare) == 1 {
+			t.Errorf("participant %d: share unchanged", i+1)
+		}
+		refreshed[i], newPub = *kp, p
+	}
+	if !bytes.Equal(newPub.GroupKey, pub.GroupKey) {
+		t.Fatal("group key changed")
+	}
+	checkSigning(t, refreshed[1:], newPub)
+
+	// An old share doesn't combine with a new one.
+	message := []byte("mixed")
+	c, shares := sign(t, []KeyPackage{keys[0], refreshed[1]}, message)
+	if _, err := Aggregate(newPub, message, c, shares); err == nil {
+		t.Error("old and new shares combined")
+	}
+
+	// A dealer can't shift the secret.
+	bad, badShares, _ := RefreshDeal(rand.Reader, &keys[0], ids)
+	bad.Commitment[0] = ed25519.NewGeneratorPoint()
+	commitments[0] = bad
+	inbox[1][0] = badShares[1]
+	if _, _, err := RefreshComplete(&keys[1], pub, commitments, inbox[1]); err == nil {
+		t.Error("refresh of a nonzero secret accepted")
+	}
+}
+
+func TestReshare(t *testing.T) {
+	keys, pub := newGroup(t, 2, 3)
+	dealers := []uint32{1, 3}
+	newIDs := []uint32{1, 2, 3, 4, 5}
+	const newMin = 3
+
+	var commitments []Round1Package
+	inbox := make(map[uint32][]Round2Package)
+	for _, kp := range []KeyPackage{keys[0], keys[2]} {
+		c, shares, err := ReshareDeal(rand.Reader, &kp, dealers, newMin, newIDs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitments = append(commitments, c)
+		for _, s := range shares {
+			inbox[s.To] = append(inbox[s.To], s)
+		}
+	}
+
+	var newKeys []KeyPackage
+	var newPub *PublicKeyPackage
+	for _, id := range newIDs {
+		kp, p, err := ReshareComplete(id, newMin, newIDs, pub, commitments, inbox[id])
+		if err != nil {
+			t.Fatal(err)
+		}
+		newKeys, newPub = append(newKeys, *kp), p
+	}
+	if !bytes.Equal(newPub.GroupKey, pub.GroupKey) {
+		t.Fatal("group key changed")
+	}
+	checkSigning(t, []KeyPackage{newKeys[4], newKeys[1], newKeys[2]}, newPub)
+
+	message := []byte("too few")
+	nonces, c, _ := Commit(rand.Reader, &newKeys[0])
+	_, c2, _ := Commit(rand.Reader, &newKeys[1])
+	if _, err := Sign(&newKeys[0], nonces, message, []SigningCommitments{c, c2}); err == nil {
+		t.Error("signed below the new threshold")
+	}
+
+	// A single old participant is below the old threshold.
+	if _, _, err := ReshareDeal(rand.Reader, &keys[0], []uint32{1}, newMin, newIDs); err == nil {
+		t.Error("resharing with too few dealers accepted")
+	}
+	if _, _, err := ReshareComplete(1, newMin, newIDs, pub, commitments[:1], inbox[1][:1]); err == nil {
+		t.Error("resharing from a single dealer accepted")
+	}
+}
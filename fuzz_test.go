@@ -0,0 +1,181 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// This file cross-checks the radix51 arithmetic against a straightforward
+// big.Int implementation of the affine formulas, which is too slow for real
+// use but simple enough to trust.
+
+var (
+	refP    = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	refD, _ = new(big.Int).SetString("37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+)
+
+func refInv(x *big.Int) *big.Int {
+	return new(big.Int).ModInverse(x, refP)
+}
+
+// refAdd adds two affine points with the complete twisted Edwards formulas,
+// x3 = (x1*y2 + y1*x2) / (1 + d*x1*x2*y1*y2) and
+// y3 = (y1*y2 + x1*x2) / (1 - d*x1*x2*y1*y2).
+func refAdd(x1, y1, x2, y2 *big.Int) (x3, y3 *big.Int) {
+	t := new(big.Int).Mul(x1, x2)
+	t.Mul(t, y1)
+	t.Mul(t, y2)
+	t.Mul(t, refD)
+	t.Mod(t, refP)
+
+	xn := new(big.Int).Add(new(big.Int).Mul(x1, y2), new(big.Int).Mul(y1, x2))
+	xd := new(big.Int).Add(big.NewInt(1), t)
+	yn := new(big.Int).Add(new(big.Int).Mul(y1, y2), new(big.Int).Mul(x1, x2))
+	yd := new(big.Int).Sub(big.NewInt(1), t)
+	yd.Mod(yd, refP)
+
+	x3 = xn.Mul(xn, refInv(xd))
+	y3 = yn.Mul(yn, refInv(yd))
+	return x3.Mod(x3, refP), y3.Mod(y3, refP)
+}
+
+// refScalarMult computes k*(x, y) by double-and-add, for a little-endian k.
+func refScalarMult(x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	rx, ry := big.NewInt(0), big.NewInt(1)
+	for i := len(k)*8 - 1; i >= 0; i-- {
+		rx, ry = refAdd(rx, ry, rx, ry)
+		if k[i/8]>>uint(i%8)&1 == 1 {
+			rx, ry = refAdd(rx, ry, x, y)
+		}
+	}
+	return rx, ry
+}
+
+// leToBig interprets a little-endian byte string as a big.Int. The reference
+// implementation has its own copy, rather than ScalarFromLittleEndian, so it
+// shares no code with the package under test.
+func leToBig(k []byte) *big.Int {
+	be := make([]byte, len(k))
+	for i := range k {
+		be[i] = k[len(k)-1-i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// refDecode decodes a compressed point per RFC 8032, Section 5.1.3.
+func refDecode(b []byte) (x, y *big.Int, ok bool) {
+	if len(b) != 32 {
+		return nil, nil, false
+	}
+	le := append([]byte{}, b...)
+	sign := le[31] >> 7
+	le[31] &= 0x7f
+	y = leToBig(le)
+	if y.Cmp(refP) >= 0 {
+		return nil, nil, false
+	}
+
+	// x^2 = (y^2 - 1) / (d*y^2 + 1)
+	y2 := new(big.Int).Mul(y, y)
+	u := new(big.Int).Sub(y2, big.NewInt(1))
+	v := new(big.Int).Mul(refD, y2)
+	v.Add(v, big.NewInt(1))
+	x2 := u.Mul(u, refInv(v))
+	x2.Mod(x2, refP)
+
+	x = new(big.Int).ModSqrt(x2, refP)
+	if x == nil {
+		return nil, nil, false
+	}
+	if x.Sign() == 0 && sign == 1 {
+		return nil, nil, false
+	}
+	if x.Bit(0) != uint(sign) {
+		x.Sub(refP, x)
+	}
+	return x, y, true
+}
+
+func FuzzPointDecode(f *testing.F) {
+	f.Add(basepointBytes)
+	f.Add(order8Bytes)
+	f.Add(make([]byte, 32))
+	f.Add(bytes.Repeat([]byte{0xff}, 32))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		P, err := new(Point).SetBytes(b)
+		x, y, ok := refDecode(b)
+		if (err == nil) != ok {
+			t.Fatalf("SetBytes error %v, reference ok %v", err, ok)
+		}
+		if !ok {
+			return
+		}
+		if !bytes.Equal(P.Bytes(), b) {
+			t.Errorf("Bytes() = %x, want %x", P.Bytes(), b)
+		}
+		px, py := P.p.ToAffine()
+		if px.Cmp(x) != 0 || py.Cmp(y) != 0 {
+			t.Error("decoded point disagrees with reference")
+		}
+	})
+}
+
+func FuzzAddCommutes(f *testing.F) {
+	f.Add(basepointBytes, basepointBytes)
+	f.Add(basepointBytes, order8Bytes)
+	f.Fuzz(func(t *testing.T, a, b []byte) {
+		P, err := new(Point).SetBytes(a)
+		if err != nil {
+			return
+		}
+		Q, err := new(Point).SetBytes(b)
+		if err != nil {
+			return
+		}
+
+		R := new(Point).Add(P, Q)
+		if R.Equal(new(Point).Add(Q, P)) != 1 {
+			t.Error("P + Q != Q + P")
+		}
+
+		px, py := P.p.ToAffine()
+		qx, qy := Q.p.ToAffine()
+		ex, ey := refAdd(px, py, qx, qy)
+		rx, ry := R.p.ToAffine()
+		if rx.Cmp(ex) != 0 || ry.Cmp(ey) != 0 {
+			t.Error("P + Q disagrees with reference")
+		}
+	})
+}
+
+func FuzzScalarMultMatchesBigInt(f *testing.F) {
+	f.Add(make([]byte, 32), basepointBytes)
+	f.Add(bytes.Repeat([]byte{0xff}, 32), basepointBytes)
+	f.Add(bytes.Repeat([]byte{0x5a}, 32), order8Bytes)
+	f.Fuzz(func(t *testing.T, k, b []byte) {
+		if len(k) != 32 {
+			return
+		}
+		P, err := new(Point).SetBytes(b)
+		if err != nil {
+			return
+		}
+
+		px, py := P.p.ToAffine()
+		ex, ey := refScalarMult(px, py, k)
+
+		rx, ry := new(Point).ScalarMult(k, P).p.ToAffine()
+		if rx.Cmp(ex) != 0 || ry.Cmp(ey) != 0 {
+			t.Error("ScalarMult disagrees with reference")
+		}
+		rx, ry = new(Point).ScalarMultPrecomputed(k, NewPrecomputedTable(P)).p.ToAffine()
+		if rx.Cmp(ex) != 0 || ry.Cmp(ey) != 0 {
+			t.Error("ScalarMultPrecomputed disagrees with reference")
+		}
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/sha512"
+	"io"
+	"math/big"
+
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/hash2curve"
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+// HashToPoint hashes msg to a point in the prime-order subgroup, using the
+// edwards25519_XMD:SHA-512_ELL2_RO_ suite from RFC 9380. The output is
+// indistinguishable from a random oracle, as required by most protocols
+// (VRFs, OPRFs, BLS-style signatures).
+//
+// dst is the domain separation tag, which must be unique to the protocol.
+// Tags longer than 255 bytes are hashed down as in RFC 9380, Section 5.3.3.
+func HashToPoint(msg, dst []byte) *Point {
+	u := hashToField(msg, dst, 2)
+
+	var p, q group.ExtendedGroupElement
+	p.MapToCurveElligator2(&u[0])
+	q.MapToCurveElligator2(&u[1])
+	p.Add(&p, &q)
+
+	v := new(Point)
+	v.p.MultByCofactor(&p)
+	return v
+}
+
+// EncodeToPoint is like HashToPoint but uses the nonuniform
+// edwards25519_XMD:SHA-512_ELL2_NU_ suite from RFC 9380. It's about twice as
+// fast, but its output is only a subset of the points, distinguishable from
+// random, so it must only be used where the protocol allows it.
+func EncodeToPoint(msg, dst []byte) *Point {
+	u := hashToField(msg, dst, 1)
+
+	var p group.ExtendedGroupElement
+	p.MapToCurveElligator2(&u[0])
+
+	v := new(Point)
+	v.p.MultByCofactor(&p)
+	return v
+}
+
+// fieldOrder is p = 2^255 - 19.
+var fieldOrder, _ = new(big.Int).SetString("57896044618658097711785492504343953926634992332820282019728792003956564819949", 10)
+
+// hashToField implements hash_to_field from RFC 9380, Section 5.2, with
+// expand_message_xmd and SHA-512. Each element is derived from
+// L = ceil((255 + 128) / 8) = 48 bytes, so that its bias is negligible.
+func hashToField(msg, dst []byte, count int) []radix51.FieldElement {
+	const L = 48
+	uniform, err := hash2curve.ExpandMessageXMD(sha512.New, msg, dst, count*L)
+	if err != nil {
+		panic("ed25519: " + err.Error())
+	}
+
+	u := make([]radix51.FieldElement, count)
+	for i := range u {
+		e := new(big.Int).SetBytes(uniform[i*L : (i+1)*L])
+		u[i].FromBig(e.Mod(e, fieldOrder))
+	}
+	return u
+}
+
+// randomPointDST is the domain separation tag NewRandomPoint hashes under.
+const randomPointDST = "gtank/ed25519/random-point-v1_XMD:SHA-512_ELL2_RO_"
+
+// NewRandomPoint returns a uniformly distributed point in the prime-order
+// subgroup, derived by hashing 64 bytes from rand to the curve. Unlike k*B
+// for a random k, nobody learns the discrete log of the result, so it's
+// suitable as an independent generator or a blinding element.
+func NewRandomPoint(rand io.Reader) (*Point, error) {
+	var seed [64]byte
+	if _, err := io.ReadFull(rand, seed[:]); err != nil {
+		return nil, err
+	}
+	return HashToPoint(seed[:], []byte(randomPointDST)), nil
+}
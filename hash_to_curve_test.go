@@ -0,0 +1,110 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// The expected outputs are the compressed encodings of the points in RFC 9380,
+// Appendix J.5.
+var hashToCurveMessages = []string{
+	"",
+	"abc",
+	"abcdef0123456789",
+	"q128_" + strings.Repeat("q", 128),
+	"a512_" + strings.Repeat("a", 512),
+}
+
+func TestHashToPoint(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-edwards25519_XMD:SHA-512_ELL2_RO_")
+	want := []string{
+		"21dc15e10253796df23a7699c8a383ea624cce88c52431f6be220b1a56c8a609",
+		"31558a26887f23fb8218f143e69d5f0af2e7831130bd5b432ef23883b895839a",
+		"a661c58eea707f2171dd1a8a641e41758ac842cfd31e64dabc7f0e143d0a0653",
+		"f7d2895eea2ef7b737ed56594f99e238a1eeb0dd672f98d239fafc55e315ca2e",
+		"95f9d827f3c0f8076af227f01fef51d0cc924fb1806a237fc2c566f204fcc26d",
+	}
+	for i, msg := range hashToCurveMessages {
+		got := hex.EncodeToString(HashToPoint([]byte(msg), dst).Bytes())
+		if got != want[i] {
+			t.Errorf("msg %q: got %s, want %s", msg, got, want[i])
+		}
+	}
+}
+
+func TestEncodeToPoint(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-edwards25519_XMD:SHA-512_ELL2_NU_")
+	want := []string{
+		"9b0f7f682dabce2190b14e21a175f39eb6a6b29fff2a9f5e72d5a4044d312e22",
+		"42fa27c8f5a1ae0aa38bb59d5938e5145622ba5dedd11d11736fa2f9502d7367",
+		"fb861a8e0a5a954a5c6836d379f1b07775134a6adaca0939e7dd1add246c8aaf",
+		"5034607af591cadcb883b05846079a27c2b46c29f474078b12baebf56efff6aa",
+		"371a8945427accbf317cc92c1607d3cd62325fb34134d391f28fb19ed3c390ac",
+	}
+	for i, msg := range hashToCurveMessages {
+		got := hex.EncodeToString(EncodeToPoint([]byte(msg), dst).Bytes())
+		if got != want[i] {
+			t.Errorf("msg %q: got %s, want %s", msg, got, want[i])
+		}
+	}
+}
+
+func TestHashToPointPrimeOrder(t *testing.T) {
+	P := HashToPoint([]byte("msg"), []byte("dst"))
+
+	// L*P must be the identity for P in the prime-order subgroup.
+	l := make([]byte, 32)
+	lBig := Ed25519().Params().N.Bytes()
+	for i := range lBig {
+		l[i] = lBig[len(lBig)-1-i]
+	}
+	if new(Point).ScalarMult(l, P).Equal(new(Point).Sub(P, P)) != 1 {
+		t.Error("HashToPoint output is not in the prime-order subgroup")
+	}
+}
+
+func BenchmarkHashToPoint(b *testing.B) {
+	msg, dst := []byte("message"), []byte("dst")
+	for i := 0; i < b.N; i++ {
+		HashToPoint(msg, dst)
+	}
+}
+
+func TestHashToPointLongDST(t *testing.T) {
+	dst := []byte(strings.Repeat("long DST ", 32))
+	short := sha512.Sum512(append([]byte("H2C-OVERSIZE-DST-"), dst...))
+	p := HashToPoint([]byte("abc"), dst)
+	q := HashToPoint([]byte("abc"), short[:])
+	if p.Equal(q) != 1 {
+		t.Error("a long DST wasn't hashed down as in RFC 9380, Section 5.3.3")
+	}
+}
+
+func TestNewRandomPoint(t *testing.T) {
+	P, err := NewRandomPoint(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Q, err := NewRandomPoint(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if P.Equal(Q) == 1 {
+		t.Error("two random points are equal")
+	}
+	if P.IsTorsionFree() != 1 || P.IsIdentity() == 1 {
+		t.Error("random point is not a generator of the prime-order subgroup")
+	}
+
+	if _, err := NewRandomPoint(bytes.NewReader(make([]byte, 63))); err == nil {
+		t.Error("NewRandomPoint succeeded with a short read")
+	}
+}
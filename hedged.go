@@ -0,0 +1,43 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	cryptorand "crypto/rand"
+	"io"
+	"strconv"
+)
+
+// HedgedRandomnessSize is the number of bytes SignHedged reads from its
+// randomness source.
+const HedgedRandomnessSize = 32
+
+// SignHedged signs the message with privateKey like Sign, but mixes
+// HedgedRandomnessSize bytes read from rand into the nonce. If rand is nil,
+// crypto/rand.Reader is used. It panics if len(privateKey) is not
+// PrivateKeySize.
+//
+// Deterministic nonces don't depend on a random number generator, but a fault
+// injected while signing the same message twice can turn the two signatures
+// into a key recovery. Hedged nonces depend on both the key and the
+// randomness, so they stay secret as long as either one is good. The
+// signatures are valid for every verifier, but not reproducible.
+//
+// The randomness Z is hashed with the nonce prefix of the key, and the result
+// takes the place of the prefix in the derivation of r. An empty Z, which
+// Options.Randomness allows, gives the RFC 8032 deterministic signature.
+func SignHedged(rand io.Reader, privateKey PrivateKey, message []byte) ([]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	z := make([]byte, HedgedRandomnessSize)
+	if _, err := io.ReadFull(rand, z); err != nil {
+		return nil, err
+	}
+	return sign(privateKey, message, nil, z), nil
+}
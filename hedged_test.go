@@ -0,0 +1,74 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSignHedged(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("hedged")
+
+	sig1, err := SignHedged(nil, priv, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := SignHedged(rand.Reader, priv, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sig1, sig2) || bytes.Equal(sig1, Sign(priv, message)) {
+		t.Error("hedged signatures are not randomized")
+	}
+	for _, sig := range [][]byte{sig1, sig2} {
+		if !Verify(pub, message, sig) || !VerifyStrict(pub, message, sig) {
+			t.Error("hedged signature rejected")
+		}
+	}
+
+	// The same randomness gives the same signature.
+	z := bytes.Repeat([]byte{0x42}, HedgedRandomnessSize)
+	sig1, _ = SignHedged(bytes.NewReader(z), priv, message)
+	sig2, _ = SignWithOptions(priv, message, &Options{Randomness: z})
+	if !bytes.Equal(sig1, sig2) {
+		t.Error("SignHedged and Options.Randomness disagree")
+	}
+
+	if _, err := SignHedged(bytes.NewReader(z[:31]), priv, message); err == nil {
+		t.Error("short read from rand didn't fail")
+	}
+}
+
+func TestSignWithRandomnessOptions(t *testing.T) {
+	seed, _ := hex.DecodeString(rfc8032Vectors[0].seed)
+	want, _ := hex.DecodeString(rfc8032Vectors[0].signature)
+	priv := NewKeyFromSeed(seed)
+	pub := PublicKey(priv[SeedSize:])
+
+	// Empty randomness is RFC 8032 signing.
+	if sig, _ := SignWithOptions(priv, nil, &Options{Randomness: []byte{}}); !bytes.Equal(sig, want) {
+		t.Errorf("got %x, want %x", sig, want)
+	}
+
+	opts := &Options{Context: "foo", Randomness: []byte("some randomness")}
+	sig, err := SignWithOptions(priv, []byte("message"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyWithOptions(pub, []byte("message"), sig, opts); err != nil {
+		t.Errorf("hedged Ed25519ctx signature rejected: %v", err)
+	}
+	e := NewExpandedPrivateKey(priv)
+	if esig, _ := e.SignWithOptions([]byte("message"), opts); !bytes.Equal(esig, sig) {
+		t.Error("ExpandedPrivateKey and SignWithOptions disagree")
+	}
+}
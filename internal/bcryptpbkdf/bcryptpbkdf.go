@@ -0,0 +1,87 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bcryptpbkdf implements bcrypt_pbkdf, the password-based key
+// derivation function of OpenBSD that OpenSSH encrypts private keys with. It
+// follows the structure of PBKDF2, with a bcrypt-based hash in place of HMAC.
+package bcryptpbkdf
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+// magic is the plaintext that bcryptHash encrypts, "OxychromaticBlowfishSwat
+// Dynamite".
+var magic = []byte("OxychromaticBlowfishSwatDynamite")
+
+// Key derives a keyLen-byte key from password and salt, with the given
+// number of rounds. Unlike PBKDF2, the output bytes of each block are spread
+// across the key, so that computing any part of it costs as much as
+// computing all of it.
+func Key(password, salt []byte, rounds, keyLen int) ([]byte, error) {
+	if rounds < 1 {
+		return nil, errors.New("bcryptpbkdf: number of rounds is too small")
+	}
+	if len(password) == 0 {
+		return nil, errors.New("bcryptpbkdf: empty password")
+	}
+	if len(salt) == 0 || len(salt) > 1<<20 {
+		return nil, errors.New("bcryptpbkdf: bad salt length")
+	}
+	if keyLen <= 0 || keyLen > 1024 {
+		return nil, errors.New("bcryptpbkdf: bad key length")
+	}
+
+	numBlocks := (keyLen + 31) / 32
+	key := make([]byte, keyLen)
+	sha2pass := sha512.Sum512(password)
+	var out, tmp [32]byte
+	for block := 1; block <= numBlocks; block++ {
+		countSalt := binary.BigEndian.AppendUint32(append([]byte{}, salt...), uint32(block))
+		sha2salt := sha512.Sum512(countSalt)
+		bcryptHash(&tmp, &sha2pass, &sha2salt)
+		out = tmp
+		for i := 1; i < rounds; i++ {
+			sha2salt = sha512.Sum512(tmp[:])
+			bcryptHash(&tmp, &sha2pass, &sha2salt)
+			for j := range out {
+				out[j] ^= tmp[j]
+			}
+		}
+		// Byte i of the block goes to position i*numBlocks + block - 1.
+		for i, b := range out {
+			if dest := i*numBlocks + block - 1; dest < keyLen {
+				key[dest] = b
+			}
+		}
+	}
+	return key, nil
+}
+
+// bcryptHash sets out to the bcrypt hash of sha2pass and sha2salt.
+func bcryptHash(out *[32]byte, sha2pass, sha2salt *[sha512.Size]byte) {
+	c, err := blowfish.NewSaltedCipher(sha2pass[:], sha2salt[:])
+	if err != nil {
+		panic("bcryptpbkdf: " + err.Error())
+	}
+	for i := 0; i < 64; i++ {
+		blowfish.ExpandKey(sha2salt[:], c)
+		blowfish.ExpandKey(sha2pass[:], c)
+	}
+
+	copy(out[:], magic)
+	for i := 0; i < 64; i++ {
+		for j := 0; j < len(out); j += 8 {
+			c.Encrypt(out[j:], out[j:])
+		}
+	}
+	// OpenBSD writes the words out in little-endian order.
+	for j := 0; j < len(out); j += 4 {
+		binary.LittleEndian.PutUint32(out[j:], binary.BigEndian.Uint32(out[j:]))
+	}
+}
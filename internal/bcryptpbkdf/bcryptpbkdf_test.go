@@ -0,0 +1,46 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bcryptpbkdf
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	key, err := Key([]byte("password"), []byte("salt"), 4, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "5bbf0cc293587f1c3635555c27796598d47e579071bf427e9d8fbe842aba34d9"
+	if got := hex.EncodeToString(key); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// A shorter key is not a prefix of a longer one, since the output bytes
+	// are spread across the key.
+	long, err := Key([]byte("password"), []byte("salt"), 4, 48)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(long[:32]) == want {
+		t.Error("bytes were not spread across the key")
+	}
+
+	for _, tc := range []struct {
+		password, salt string
+		rounds, keyLen int
+	}{
+		{"", "salt", 4, 32},
+		{"password", "", 4, 32},
+		{"password", "salt", 0, 32},
+		{"password", "salt", 4, 0},
+		{"password", "salt", 4, 1025},
+	} {
+		if _, err := Key([]byte(tc.password), []byte(tc.salt), tc.rounds, tc.keyLen); err == nil {
+			t.Errorf("%+v: accepted", tc)
+		}
+	}
+}
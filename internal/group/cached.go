@@ -0,0 +1,165 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package group
+
+import (
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+// ProjectiveCached holds the values of a point that the addition formula
+// needs from its second operand, or the "Cached" representation in ref10.
+// Precomputing them saves a multiplication and two additions every time the
+// same point is added.
+type ProjectiveCached struct {
+	YplusX, YminusX, Z, T2d radix51.FieldElement
+}
+
+// AffineCached is ProjectiveCached with Z = 1, or the "Precomp" (Niels)
+// representation in ref10. It is the cheapest form to add, and is used for
+// tables that are computed once and reused many times.
+type AffineCached struct {
+	YplusX, YminusX, T2d radix51.FieldElement
+}
+
+// FromExtended sets v to the cached form of u, and returns v.
+func (v *ProjectiveCached) FromExtended(u *ExtendedGroupElement) *ProjectiveCached {
+	v.YplusX.Add(&u.Y, &u.X)
+	v.YminusX.Sub(&u.Y, &u.X)
+	v.Z.Set(&u.Z)
+	v.T2d.Mul(&u.T, twoD)
+	return v
+}
+
+// Zero sets v to the cached form of the identity point, and returns v.
+func (v *ProjectiveCached) Zero() *ProjectiveCached {
+	v.YplusX.One()
+	v.YminusX.One()
+	v.Z.One()
+	v.T2d.Zero()
+	return v
+}
+
+// Select sets v to a if cond == 1 and to b if cond == 0, in constant time.
+func (v *ProjectiveCached) Select(a, b *ProjectiveCached, cond int) *ProjectiveCached {
+	v.YplusX.Select(&a.YplusX, &b.YplusX, cond)
+	v.YminusX.Select(&a.YminusX, &b.YminusX, cond)
+	v.Z.Select(&a.Z, &b.Z, cond)
+	v.T2d.Select(&a.T2d, &b.T2d, cond)
+	return v
+}
+
+// CondNeg sets v to -v if cond == 1 and leaves it unchanged if cond == 0, in
+// constant time. Negating a cached point swaps Y+X with Y-X and negates 2dT.
+func (v *ProjectiveCached) CondNeg(cond int) *ProjectiveCached {
+	var t radix51.FieldElement
+	t.Set(&v.YplusX)
+	v.YplusX.Select(&v.YminusX, &v.YplusX, cond)
+	v.YminusX.Select(&t, &v.YminusX, cond)
+	v.T2d.CondNeg(&v.T2d, cond)
+	return v
+}
+
+// FromExtended sets v to the affine cached form of u, and returns v. This
+// costs an inversion, so it is only worth it for points that will be added
+// many times.
+func (v *AffineCached) FromExtended(u *ExtendedGroupElement) *AffineCached {
+	var x, y, zinv radix51.FieldElement
+	zinv.Invert(&u.Z)
+	x.Mul(&u.X, &zinv)
+	y.Mul(&u.Y, &zinv)
+
+	v.YplusX.Add(&y, &x)
+	v.YminusX.Sub(&y, &x)
+	v.T2d.Mul(x.Mul(&x, &y), twoD)
+	return v
+}
+
+// Zero sets v to the affine cached form of the identity point, and returns v.
+func (v *AffineCached) Zero() *AffineCached {
+	v.YplusX.One()
+	v.YminusX.One()
+	v.T2d.Zero()
+	return v
+}
+
+// Select sets v to a if cond == 1 and to b if cond == 0, in constant time.
+func (v *AffineCached) Select(a, b *AffineCached, cond int) *AffineCached {
+	v.YplusX.Select(&a.YplusX, &b.YplusX, cond)
+	v.YminusX.Select(&a.YminusX, &b.YminusX, cond)
+	v.T2d.Select(&a.T2d, &b.T2d, cond)
+	return v
+}
+
+// CondNeg sets v to -v if cond == 1 and leaves it unchanged if cond == 0, in
+// constant time.
+func (v *AffineCached) CondNeg(cond int) *AffineCached {
+	var t radix51.FieldElement
+	t.Set(&v.YplusX)
+	v.YplusX.Select(&v.YminusX, &v.YplusX, cond)
+	v.YminusX.Select(&t, &v.YminusX, cond)
+	v.T2d.CondNeg(&v.T2d, cond)
+	return v
+}
+
+// AddCached sets v = p + q, where q is in cached form, and returns v. This is
+// add-2008-hwcd-3 with the parts that only depend on q already computed.
+func (v *ExtendedGroupElement) AddCached(p *ExtendedGroupElement, q *ProjectiveCached) *ExtendedGroupElement {
+	var tmp, A, B, C, D radix51.FieldElement
+	A.Mul(tmp.Sub(&p.Y, &p.X), &q.YminusX) // A <-- (Y1-X1)*(Y2-X2)
+	B.Mul(tmp.Add(&p.Y, &p.X), &q.YplusX)  // B <-- (Y1+X1)*(Y2+X2)
+	C.Mul(&p.T, &q.T2d)                    // C <-- T1*2d*T2
+	D.Mul(&p.Z, &q.Z)                      // D <-- 2*Z1*Z2
+	D.Add(&D, &D)
+	return v.fromABCD(&A, &B, &C, &D)
+}
+
+// SubCached sets v = p - q, where q is in cached form, and returns v.
+func (v *ExtendedGroupElement) SubCached(p *ExtendedGroupElement, q *ProjectiveCached) *ExtendedGroupElement {
+	var tmp, A, B, C, D radix51.FieldElement
+	A.Mul(tmp.Sub(&p.Y, &p.X), &q.YplusX)  // A <-- (Y1-X1)*(Y2+X2)
+	B.Mul(tmp.Add(&p.Y, &p.X), &q.YminusX) // B <-- (Y1+X1)*(Y2-X2)
+	C.Mul(&p.T, &q.T2d)                    // C <-- -T1*2d*T2
+	C.Neg(&C)
+	D.Mul(&p.Z, &q.Z) // D <-- 2*Z1*Z2
+	D.Add(&D, &D)
+	return v.fromABCD(&A, &B, &C, &D)
+}
+
+// AddAffineCached sets v = p + q, where q is in affine cached form, and
+// returns v. Since Z2 = 1 this saves one more multiplication over AddCached.
+func (v *ExtendedGroupElement) AddAffineCached(p *ExtendedGroupElement, q *AffineCached) *ExtendedGroupElement {
+	var tmp, A, B, C, D radix51.FieldElement
+	A.Mul(tmp.Sub(&p.Y, &p.X), &q.YminusX) // A <-- (Y1-X1)*(y2-x2)
+	B.Mul(tmp.Add(&p.Y, &p.X), &q.YplusX)  // B <-- (Y1+X1)*(y2+x2)
+	C.Mul(&p.T, &q.T2d)                    // C <-- T1*2d*x2*y2
+	D.Add(&p.Z, &p.Z)                      // D <-- 2*Z1
+	return v.fromABCD(&A, &B, &C, &D)
+}
+
+// SubAffineCached sets v = p - q, where q is in affine cached form, and
+// returns v.
+func (v *ExtendedGroupElement) SubAffineCached(p *ExtendedGroupElement, q *AffineCached) *ExtendedGroupElement {
+	var tmp, A, B, C, D radix51.FieldElement
+	A.Mul(tmp.Sub(&p.Y, &p.X), &q.YplusX)  // A <-- (Y1-X1)*(y2+x2)
+	B.Mul(tmp.Add(&p.Y, &p.X), &q.YminusX) // B <-- (Y1+X1)*(y2-x2)
+	C.Mul(&p.T, &q.T2d)                    // C <-- -T1*2d*x2*y2
+	C.Neg(&C)
+	D.Add(&p.Z, &p.Z) // D <-- 2*Z1
+	return v.fromABCD(&A, &B, &C, &D)
+}
+
+// fromABCD finishes add-2008-hwcd-3 once A, B, C and D are known.
+func (v *ExtendedGroupElement) fromABCD(A, B, C, D *radix51.FieldElement) *ExtendedGroupElement {
+	var E, F, G, H radix51.FieldElement
+	E.Sub(B, A)     // E <-- B-A
+	F.Sub(D, C)     // F <-- D-C
+	G.Add(D, C)     // G <-- D+C
+	H.Add(B, A)     // H <-- B+A
+	v.X.Mul(&E, &F) // X3 <-- E*F
+	v.Y.Mul(&G, &H) // Y3 <-- G*H
+	v.T.Mul(&E, &H) // T3 <-- E*H
+	v.Z.Mul(&F, &G) // Z3 <-- F*G
+	return v
+}
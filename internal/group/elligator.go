@@ -0,0 +1,157 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package group
+
+import (
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+var (
+	// montgomeryA is the A coefficient of curve25519, v^2 = u^3 + A*u^2 + u.
+	montgomeryA = &radix51.FieldElement{486662, 0, 0, 0, 0}
+
+	// elligatorC2 is 2^((p+3)/8), c2 in RFC 9380, Appendix G.2.1.
+	elligatorC2 = &radix51.FieldElement{1718705420411057, 234908883556509,
+		2233514472574048, 2117202627021982, 765476049583133}
+
+	// sqrtMinusAPlus2 is sqrt(-486664) with sgn0 = 0, c1 in RFC 9380,
+	// Appendix G.2.2. It scales the rational map from curve25519 so that it
+	// lands on edwards25519.
+	sqrtMinusAPlus2 = &radix51.FieldElement{1693982333959686, 608509411481997,
+		2235573344831311, 947681270984193, 266558006233600}
+)
+
+// MapToCurveElligator2 sets v to the image of the field element u under the
+// Elligator 2 map to edwards25519, and returns v. This is
+// map_to_curve_elligator2_edwards25519 from RFC 9380, Appendix G.2.2: the
+// Elligator 2 map to curve25519 followed by the rational map to the Edwards
+// form. It runs in constant time.
+//
+// The result is not necessarily in the prime-order subgroup.
+func (v *ExtendedGroupElement) MapToCurveElligator2(u *radix51.FieldElement) *ExtendedGroupElement {
+	var xMn, xMd, yMn, yMd radix51.FieldElement
+	mapToCurve25519(&xMn, &xMd, &yMn, &yMd, u)
+
+	var xn, xd, yn, yd, tv1 radix51.FieldElement
+	xn.Mul(&xMn, &yMd)
+	xn.Mul(&xn, sqrtMinusAPlus2)
+	xd.Mul(&xMd, &yMn) // xn / xd = c1 * xM / yM
+	yn.Sub(&xMn, &xMd)
+	yd.Add(&xMn, &xMd) // (n / d - 1) / (n / d + 1) = (n - d) / (n + d)
+	tv1.Mul(&xd, &yd)
+	e := tv1.Equal(radix51.Zero)
+	xn.Select(radix51.Zero, &xn, e)
+	xd.Select(radix51.One, &xd, e)
+	yn.Select(radix51.One, &yn, e)
+	yd.Select(radix51.One, &yd, e)
+
+	// (x, y) = (xn / xd, yn / yd) in extended coordinates.
+	v.X.Mul(&xn, &yd)
+	v.Y.Mul(&yn, &xd)
+	v.Z.Mul(&xd, &yd)
+	v.T.Mul(&xn, &yn)
+	return v
+}
+
+// MapToCurve25519U sets v to the u-coordinate of the image of the field
+// element r under the Elligator 2 map to curve25519, with Z = 2, and returns
+// v. This is the x coordinate of map_to_curve_elligator2_curve25519 from RFC
+// 9380, Appendix G.2.1, and the map of Signal's XEdDSA specification. It runs
+// in constant time.
+func MapToCurve25519U(v, r *radix51.FieldElement) *radix51.FieldElement {
+	var xn, xd, yn, yd radix51.FieldElement
+	mapToCurve25519(&xn, &xd, &yn, &yd, r)
+	return v.Mul(&xn, xd.Invert(&xd))
+}
+
+// mapToCurve25519 implements map_to_curve_elligator2_curve25519 from RFC
+// 9380, Appendix G.2.1, returning the point as fractions
+// (xn / xd, yn / yd) on curve25519.
+func mapToCurve25519(xn, xd, yn, yd, u *radix51.FieldElement) {
+	var tv1, tv2, tv3, x1n, gxd, gx1, gx2, y11, y12, y1, x2n, y21, y22, y2 radix51.FieldElement
+
+	tv1.Square(u)
+	tv1.Add(&tv1, &tv1)
+	xd.Add(&tv1, radix51.One) // Nonzero: -1 is square (mod p), tv1 is not
+	x1n.Neg(montgomeryA)      // x1 = x1n / xd = -A / (1 + 2 * u^2)
+	tv2.Square(xd)
+	gxd.Mul(&tv2, xd)          // gxd = xd^3
+	gx1.Mul(montgomeryA, &tv1) // x1n + A * xd
+	gx1.Mul(&gx1, &x1n)        // x1n^2 + A * x1n * xd
+	gx1.Add(&gx1, &tv2)        // x1n^2 + A * x1n * xd + xd^2
+	gx1.Mul(&gx1, &x1n)        // x1n^3 + A * x1n^2 * xd + x1n * xd^2
+	tv3.Square(&gxd)
+	tv2.Square(&tv3)    // gxd^4
+	tv3.Mul(&tv3, &gxd) // gxd^3
+	tv3.Mul(&tv3, &gx1) // gx1 * gxd^3
+	tv2.Mul(&tv2, &tv3) // gx1 * gxd^7
+	y11.Pow22523(&tv2)  // (gx1 * gxd^7)^((p - 5) / 8)
+	y11.Mul(&y11, &tv3) // gx1 * gxd^3 * (gx1 * gxd^7)^((p - 5) / 8)
+	y12.Mul(&y11, radix51.SqrtM1)
+	tv2.Square(&y11)
+	tv2.Mul(&tv2, &gxd)
+	e1 := tv2.Equal(&gx1)
+	y1.Select(&y11, &y12, e1) // If g(x1) is square, this is its sqrt
+	x2n.Mul(&x1n, &tv1)       // x2 = x2n / xd = 2 * u^2 * x1n / xd
+	y21.Mul(&y11, u)
+	y21.Mul(&y21, elligatorC2)
+	y22.Mul(&y21, radix51.SqrtM1)
+	gx2.Mul(&gx1, &tv1) // g(x2) = gx2 / gxd = 2 * u^2 * g(x1)
+	tv2.Square(&y21)
+	tv2.Mul(&tv2, &gxd)
+	e2 := tv2.Equal(&gx2)
+	y2.Select(&y21, &y22, e2) // If g(x2) is square, this is its sqrt
+	tv2.Square(&y1)
+	tv2.Mul(&tv2, &gxd)
+	e3 := tv2.Equal(&gx1)
+	xn.Select(&x1n, &x2n, e3) // If e3, x = x1, else x = x2
+	yn.Select(&y1, &y2, e3)   // If e3, y = y1, else y = y2
+	e4 := yn.IsNegative()     // Fix sign of y
+	yn.CondNeg(yn, e3^e4)
+	yd.One()
+}
+
+// ToRepresentative sets r to a field element such that MapToCurveElligator2(r)
+// is v, and returns 1. If v is not in the image of the map, which is the case
+// for about half of all points, it returns 0 and r is undefined. The returned
+// r is always at most (p-1)/2, so it fits in 254 bits.
+func (v *ExtendedGroupElement) ToRepresentative(r *radix51.FieldElement) int {
+	// Recover the curve25519 point (u, w) that the Edwards rational map
+	// sends to v: u = (1 + y) / (1 - y) and w = c1 * u / x.
+	var zPlusY, zMinusY, inv, u, w radix51.FieldElement
+	zPlusY.Add(&v.Z, &v.Y)
+	zMinusY.Sub(&v.Z, &v.Y)
+	inv.Mul(&zMinusY, &v.X)
+	inv.Invert(&inv)
+	u.Mul(&zPlusY, &v.X)
+	u.Mul(&u, &inv)
+	w.Mul(&zPlusY, &v.Z)
+	w.Mul(&w, &inv)
+	w.Mul(&w, sqrtMinusAPlus2)
+
+	// The forward map picks u = -A / (1 + 2r^2) with a negative w, or
+	// u = -2Ar^2 / (1 + 2r^2) with a non-negative w. Solving for r^2 gives
+	// -(u + A) / 2u and -u / 2(u + A) respectively.
+	var uPlusA, num, den radix51.FieldElement
+	uPlusA.Add(&u, montgomeryA)
+	wNegative := w.IsNegative()
+	num.Select(&uPlusA, &u, wNegative)
+	num.Neg(&num)
+	den.Select(&u, &uPlusA, wNegative)
+	den.Add(&den, &den)
+	r.SqrtRatio(&num, &den)
+
+	// Of r and -r, pick the one in [0, (p-1)/2]. 2r mod p is odd exactly
+	// when r is larger than that.
+	var twoR radix51.FieldElement
+	twoR.Add(r, r)
+	r.CondNeg(r, twoR.IsNegative())
+
+	// Mapping r back both checks that r^2 was square and rules out the
+	// exceptional points of the rational map, such as (0, -1).
+	var check ExtendedGroupElement
+	check.MapToCurveElligator2(r)
+	return check.Equal(v)
+}
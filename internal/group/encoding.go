@@ -0,0 +1,100 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package group
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+// ErrInvalidEncoding is returned by FromBytes when the input is not the
+// encoding of a point on the curve.
+var ErrInvalidEncoding = errors.New("ed25519: invalid point encoding")
+
+// ToBytes writes the 32-byte compressed Edwards-Y encoding of v to r, as
+// specified in RFC 8032, Section 5.1.2. The little-endian encoding of y is
+// followed by the sign of x in the most significant bit of the final octet.
+func (v *ExtendedGroupElement) ToBytes(r []byte) {
+	if len(r) != 32 {
+		panic("invalid output size")
+	}
+
+	var x, y, zinv radix51.FieldElement
+	zinv.Invert(&v.Z)
+	x.Mul(&v.X, &zinv)
+	y.Mul(&v.Y, &zinv)
+
+	y.ToBytes(r)
+	r[31] |= byte(x.IsNegative() << 7)
+}
+
+// FromBytes sets v to the point encoded by the 32-byte compressed Edwards-Y
+// string in x, following RFC 8032, Section 5.1.3. If x is not a valid
+// encoding, FromBytes returns ErrInvalidEncoding and leaves v unchanged.
+//
+// Only canonical encodings are accepted: y must be fully reduced, and the
+// sign bit must not be set when x is zero. This means every point has exactly
+// one encoding that FromBytes accepts, the one ToBytes produces.
+func (v *ExtendedGroupElement) FromBytes(x []byte) error {
+	return v.fromBytes(x, true)
+}
+
+// FromBytesNonCanonical is like FromBytes, but it also accepts the
+// non-canonical encodings allowed by ZIP-215: y is reduced modulo p, and a
+// set sign bit with x = 0 is ignored. Some points have more than one encoding
+// that FromBytesNonCanonical accepts.
+func (v *ExtendedGroupElement) FromBytesNonCanonical(x []byte) error {
+	return v.fromBytes(x, false)
+}
+
+func (v *ExtendedGroupElement) fromBytes(x []byte, canonical bool) error {
+	if len(x) != 32 {
+		return ErrInvalidEncoding
+	}
+
+	// FromBytes ignores the most significant bit, which holds the sign of x,
+	// and reduces y modulo p.
+	var y radix51.FieldElement
+	y.FromBytes(x)
+
+	// Reject y >= p by checking that it survives a round trip through its
+	// reduced encoding.
+	var yBytes [32]byte
+	y.ToBytes(yBytes[:])
+	yBytes[31] |= x[31] & 0x80
+	if canonical && subtle.ConstantTimeCompare(yBytes[:], x) != 1 {
+		return ErrInvalidEncoding
+	}
+
+	// -x^2 + y^2 = 1 + d*x^2*y^2
+	// x^2 = (y^2 - 1) / (d*y^2 + 1)
+	var y2, u, w radix51.FieldElement
+	y2.Square(&y)
+	u.Sub(&y2, radix51.One)
+	w.Mul(&y2, D)
+	w.Add(&w, radix51.One)
+
+	var xx radix51.FieldElement
+	if _, wasSquare := xx.SqrtRatio(&u, &w); wasSquare == 0 {
+		return ErrInvalidEncoding
+	}
+
+	// SqrtRatio returns the non-negative root, so flip it if the encoding
+	// asks for the negative one. Zero has no negative, so a set sign bit with
+	// x = 0 is a non-canonical encoding of the same point.
+	sign := int(x[31] >> 7)
+	if canonical && sign == 1 && xx.Equal(radix51.Zero) == 1 {
+		return ErrInvalidEncoding
+	}
+	xx.CondNeg(&xx, sign)
+
+	v.X.Set(&xx)
+	v.Y.Set(&y)
+	v.Z.One()
+	v.T.Mul(&xx, &y)
+	return nil
+}
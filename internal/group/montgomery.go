@@ -0,0 +1,62 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package group
+
+import (
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+// montgomeryScale is the square root of -486664 that makes the RFC 7748
+// base points correspond, (9, 1478...) on curve25519 and (1511..., 4/5) on
+// edwards25519. It's the negation of sqrtMinusAPlus2, the root RFC 9380
+// specifies for its Elligator 2 map.
+var montgomeryScale = new(radix51.FieldElement).Neg(sqrtMinusAPlus2)
+
+// EdwardsToMontgomery sets (u, w) to the curve25519 point birationally
+// equivalent to the edwards25519 point (x, y):
+//
+//	u = (1 + y) / (1 - y), w = c * u / x
+//
+// where c = sqrt(-486664) is montgomeryScale, as in RFC 7748. The
+// identity, which corresponds to the point at infinity, maps to (0, 0), the
+// same as (0, -1).
+func EdwardsToMontgomery(u, w, x, y *radix51.FieldElement) {
+	var n, d, t radix51.FieldElement
+	n.Add(radix51.One, y)
+	d.Sub(radix51.One, y)
+	t.Mul(&d, x)
+	t.Invert(&t) // 1 / ((1 - y) * x)
+
+	u.Mul(&n, x)
+	u.Mul(u, &t)
+
+	w.Mul(&n, &t)
+	w.Mul(w, montgomeryScale)
+}
+
+// MontgomeryToEdwards sets (x, y) to the edwards25519 point birationally
+// equivalent to the curve25519 point (u, w), the inverse of
+// EdwardsToMontgomery:
+//
+//	x = c * u / w, y = (u - 1) / (u + 1)
+//
+// (0, 0) maps to (0, -1).
+func MontgomeryToEdwards(x, y, u, w *radix51.FieldElement) {
+	var n, d, t radix51.FieldElement
+	n.Sub(u, radix51.One)
+	d.Add(u, radix51.One)
+	t.Mul(&d, w)
+	t.Invert(&t) // 1 / ((u + 1) * w)
+
+	x.Mul(montgomeryScale, u)
+	x.Mul(x, &d)
+	x.Mul(x, &t)
+
+	y.Mul(&n, w)
+	y.Mul(y, &t)
+
+	// w = 0 only for (0, 0), where the inversion above gave 0 for y too.
+	y.Select(radix51.MinusOne, y, w.Equal(radix51.Zero))
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package group
+
+// strausThreshold is the number of points at which VartimeMultiScalarMult
+// switches from Straus to Pippenger. Below it, the per-point tables of Straus
+// are cheap enough that sharing the doublings wins; above it, Pippenger's
+// bucket method needs fewer additions overall. This is the same crossover
+// curve25519-dalek uses.
+const strausThreshold = 190
+
+// VartimeMultiScalarMult sets v = sum(scalars[i] * points[i]), where each
+// scalar is a 256-bit little-endian integer, and returns v. It panics if the
+// two slices have different lengths.
+//
+// Execution time depends on the inputs, so this must only be used with public
+// scalars, such as in signature verification.
+func (v *ExtendedGroupElement) VartimeMultiScalarMult(scalars []*[32]byte, points []*ExtendedGroupElement) *ExtendedGroupElement {
+	if len(scalars) != len(points) {
+		panic("ed25519: called VartimeMultiScalarMult with different size inputs")
+	}
+
+	if len(points) < strausThreshold {
+		return v.straus(scalars, points)
+	}
+	return v.pippenger(scalars, points)
+}
+
+// straus computes the multiscalar multiplication by interleaving width-5
+// NAFs of all the scalars, so that every point shares the same 257
+// doublings.
+func (v *ExtendedGroupElement) straus(scalars []*[32]byte, points []*ExtendedGroupElement) *ExtendedGroupElement {
+	tables := make([][8]ProjectiveCached, len(points))
+	for i := range points {
+		buildOddMultiplesTable(&tables[i], points[i])
+	}
+
+	nafs := make([][257]int8, len(scalars))
+	for i := range scalars {
+		nafs[i] = NonAdjacentForm(scalars[i], 5)
+	}
+
+	var acc ExtendedGroupElement
+	acc.Zero()
+
+	// Skip the leading zero digits, which would only double the identity.
+	i := 256
+	for ; i >= 0; i-- {
+		nonZero := false
+		for j := range nafs {
+			if nafs[j][i] != 0 {
+				nonZero = true
+				break
+			}
+		}
+		if nonZero {
+			break
+		}
+	}
+
+	for ; i >= 0; i-- {
+		acc.Double(&acc)
+		for j := range nafs {
+			if d := nafs[j][i]; d > 0 {
+				acc.AddCached(&acc, &tables[j][d/2])
+			} else if d < 0 {
+				acc.SubCached(&acc, &tables[j][-d/2])
+			}
+		}
+	}
+
+	return v.Set(&acc)
+}
+
+// pippenger computes the multiscalar multiplication with the bucket method.
+// Each scalar is split into signed radix-2^w digits. For each digit position,
+// every point is added into the bucket for its digit, and the buckets are
+// then combined with a running sum that weights bucket b by b.
+func (v *ExtendedGroupElement) pippenger(scalars []*[32]byte, points []*ExtendedGroupElement) *ExtendedGroupElement {
+	var w uint
+	switch {
+	case len(points) < 500:
+		w = 6
+	case len(points) < 800:
+		w = 7
+	default:
+		w = 8
+	}
+
+	digits := make([][]int32, len(scalars))
+	for i := range scalars {
+		digits[i] = SignedRadix2w(scalars[i], w)
+	}
+
+	cached := make([]ProjectiveCached, len(points))
+	for i := range points {
+		cached[i].FromExtended(points[i])
+	}
+
+	buckets := make([]ExtendedGroupElement, 1<<(w-1))
+
+	var acc, sum, runningSum ExtendedGroupElement
+	var tmp ProjectiveCached
+	acc.Zero()
+	for pos := len(digits[0]) - 1; pos >= 0; pos-- {
+		for i := uint(0); i < w; i++ {
+			acc.Double(&acc)
+		}
+
+		for b := range buckets {
+			buckets[b].Zero()
+		}
+		for i := range digits {
+			if d := digits[i][pos]; d > 0 {
+				buckets[d-1].AddCached(&buckets[d-1], &cached[i])
+			} else if d < 0 {
+				buckets[-d-1].SubCached(&buckets[-d-1], &cached[i])
+			}
+		}
+
+		// sum = 1*buckets[0] + 2*buckets[1] + ... computed as the sum of the
+		// running sums from the top bucket down.
+		runningSum.Zero()
+		sum.Zero()
+		for b := len(buckets) - 1; b >= 0; b-- {
+			runningSum.AddCached(&runningSum, tmp.FromExtended(&buckets[b]))
+			sum.AddCached(&sum, tmp.FromExtended(&runningSum))
+		}
+
+		acc.AddCached(&acc, tmp.FromExtended(&sum))
+	}
+
+	return v.Set(&acc)
+}
+
+// buildOddMultiplesTable sets table[i] = (2i+1)*p for i = 0..7, the table
+// for a width-5 NAF.
+func buildOddMultiplesTable(table *[8]ProjectiveCached, p *ExtendedGroupElement) {
+	var p2, multiple ExtendedGroupElement
+	var p2Cached ProjectiveCached
+	p2Cached.FromExtended(p2.Double(p))
+
+	table[0].FromExtended(p)
+	multiple.Set(p)
+	for i := 1; i < 8; i++ {
+		multiple.AddCached(&multiple, &p2Cached)
+		table[i].FromExtended(&multiple)
+	}
+}
+
+// NonAdjacentForm computes the width-w non-adjacent form of the 256-bit
+// little-endian integer k. Every nonzero digit is odd and less than 2^(w-1)
+// in absolute value, and any w consecutive digits contain at most one nonzero
+// digit. An unreduced k can carry into a 257th digit.
+func NonAdjacentForm(k *[32]byte, w uint) [257]int8 {
+	if w < 2 || w > 8 {
+		panic("ed25519: invalid NAF width")
+	}
+
+	var naf [257]int8
+
+	// One extra limb so the window can always read the next limb.
+	var x [5]uint64
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			x[i] |= uint64(k[i*8+j]) << uint(8*j)
+		}
+	}
+
+	width := uint64(1 << w)
+	windowMask := width - 1
+
+	pos := uint(0)
+	carry := uint64(0)
+	for pos < 257 {
+		indexU64 := pos / 64
+		indexBit := pos % 64
+		var bitBuf uint64
+		if indexBit < 64-w {
+			// This window's bits are contained in a single limb.
+			bitBuf = x[indexU64] >> indexBit
+		} else {
+			// The window spans two limbs.
+			bitBuf = (x[indexU64] >> indexBit) | (x[1+indexU64] << (64 - indexBit))
+		}
+
+		// Add the carry into the current window.
+		window := carry + (bitBuf & windowMask)
+
+		if window&1 == 0 {
+			// If the window value is even, the current digit is zero, so
+			// move on to the next bit.
+			pos++
+			continue
+		}
+
+		if window < width/2 {
+			carry = 0
+			naf[pos] = int8(window)
+		} else {
+			carry = 1
+			naf[pos] = int8(window) - int8(width)
+		}
+
+		pos += w
+	}
+
+	return naf
+}
+
+// SignedRadix2w writes the 256-bit little-endian integer k as digits d[i] in
+// [-2^(w-1), 2^(w-1)) such that k = sum(d[i] * 2^(w*i)). There is one more
+// digit than it takes to hold 256 bits, to absorb the final carry.
+func SignedRadix2w(k *[32]byte, w uint) []int32 {
+	if w < 2 || w > 16 {
+		panic("ed25519: invalid radix width")
+	}
+
+	n := (256+int(w)-1)/int(w) + 1
+	digits := make([]int32, n)
+
+	var carry int32
+	for i := 0; i < n; i++ {
+		d := int32(bitsAt(k, uint(i)*w, w)) + carry
+		carry = (d + 1<<(w-1)) >> w
+		digits[i] = d - carry<<w
+	}
+
+	return digits
+}
+
+// bitsAt returns the w bits of k starting at bit position pos, treating
+// positions past the end of k as zero.
+func bitsAt(k *[32]byte, pos, w uint) uint32 {
+	var out uint32
+	for i := uint(0); i < w; i++ {
+		bit := pos + i
+		if bit >= 256 {
+			break
+		}
+		out |= uint32(k[bit/8]>>(bit%8)&1) << i
+	}
+	return out
+}
+
+// MultiScalarMult sets v = sum(scalars[i] * points[i]), where each scalar is a
+// 256-bit little-endian integer, and returns v. It runs in constant time with
+// respect to the scalars, and panics if the two slices have different
+// lengths.
+//
+// This is Straus' method with fixed 4-bit windows: all points share the same
+// 252 doublings, and for every window each point's table is scanned in full
+// to select the right multiple.
+func (v *ExtendedGroupElement) MultiScalarMult(scalars []*[32]byte, points []*ExtendedGroupElement) *ExtendedGroupElement {
+	if len(scalars) != len(points) {
+		panic("ed25519: called MultiScalarMult with different size inputs")
+	}
+
+	tables := make([][16]ProjectiveCached, len(points))
+	for i := range points {
+		buildWindowTable(&tables[i], points[i])
+	}
+
+	var acc ExtendedGroupElement
+	var selected ProjectiveCached
+	acc.Zero()
+	for i := 63; i >= 0; i-- {
+		acc.Double(&acc)
+		acc.Double(&acc)
+		acc.Double(&acc)
+		acc.Double(&acc)
+
+		for j := range scalars {
+			selectWindow(&selected, &tables[j], nibble(scalars[j], i))
+			acc.AddCached(&acc, &selected)
+		}
+	}
+
+	return v.Set(&acc)
+}
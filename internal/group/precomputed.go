@@ -0,0 +1,203 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package group
+
+import (
+	"crypto/subtle"
+	"sync"
+)
+
+// PrecomputedTable holds affine cached multiples of a fixed point P, for fast
+// constant-time scalar multiplication by P.
+type PrecomputedTable struct {
+	// rows[i][j] = (j+1)*256^i*P for i = 0..31 and j = 0..7.
+	rows [32][8]AffineCached
+	// top = 2^256*P, for the carry out of the most significant digit.
+	top AffineCached
+}
+
+// FromExtended fills in the table for the point p, and returns t.
+func (t *PrecomputedTable) FromExtended(p *ExtendedGroupElement) *PrecomputedTable {
+	var base, multiple ExtendedGroupElement
+	base.Set(p)
+	for i := 0; i < 32; i++ {
+		multiple.Set(&base)
+		for j := 0; j < 8; j++ {
+			t.rows[i][j].FromExtended(&multiple)
+			multiple.Add(&multiple, &base)
+		}
+		// base = 256*base
+		for j := 0; j < 8; j++ {
+			base.Double(&base)
+		}
+	}
+	t.top.FromExtended(&base)
+	return t
+}
+
+// ScalarMultPrecomputed sets v = k*P, where t is the table for P and k is a
+// 256-bit little-endian integer, and returns v. It runs in constant time with
+// respect to k.
+//
+// Unlike the windowed ScalarMult, it needs no doublings beyond the four at
+// the midpoint, so it's several times faster.
+func (v *ExtendedGroupElement) ScalarMultPrecomputed(k *[32]byte, t *PrecomputedTable) *ExtendedGroupElement {
+	var e [65]int8
+	signedRadix16(&e, k)
+
+	// k*P = sum(e[i]*16^i*P)
+	//     = sum(e[2i]*256^i*P) + 16*sum(e[2i+1]*256^i*P) + e[64]*2^256*P
+	var acc ExtendedGroupElement
+	var selected AffineCached
+	acc.Zero()
+	for i := 1; i < 64; i += 2 {
+		t.selectMultiple(&selected, i/2, e[i])
+		acc.AddAffineCached(&acc, &selected)
+	}
+	acc.Double(&acc)
+	acc.Double(&acc)
+	acc.Double(&acc)
+	acc.Double(&acc)
+	for i := 0; i < 64; i += 2 {
+		t.selectMultiple(&selected, i/2, e[i])
+		acc.AddAffineCached(&acc, &selected)
+	}
+
+	selected.Zero()
+	selected.Select(&t.top, &selected, int(e[64]))
+	acc.AddAffineCached(&acc, &selected)
+
+	return v.Set(&acc)
+}
+
+// selectMultiple sets v = b*256^pos*P in constant time, for b in [-8, 8].
+func (t *PrecomputedTable) selectMultiple(v *AffineCached, pos int, b int8) {
+	// Compute the absolute value of b without branching.
+	bNegative := int(uint8(b) >> 7)
+	bAbs := int32(b) - int32((-bNegative)&int(b))<<1
+
+	v.Zero()
+	for j := int32(1); j <= 8; j++ {
+		v.Select(&t.rows[pos][j-1], v, subtle.ConstantTimeEq(bAbs, j))
+	}
+	v.CondNeg(bNegative)
+}
+
+// signedRadix16 writes k as 65 signed digits such that k = sum(e[i]*16^i).
+// The first 64 digits are in [-8, 8), and e[64] is the final carry, 0 or 1.
+func signedRadix16(e *[65]int8, k *[32]byte) {
+	// Compute unsigned radix-16 digits.
+	for i := 0; i < 32; i++ {
+		e[2*i] = int8(k[i] & 15)
+		e[2*i+1] = int8((k[i] >> 4) & 15)
+	}
+	e[64] = 0
+
+	// Recenter coefficients from [0, 16) to [-8, 8).
+	for i := 0; i < 64; i++ {
+		carry := (e[i] + 8) >> 4
+		e[i] -= carry << 4
+		e[i+1] += carry
+	}
+}
+
+// NAFTable holds the odd multiples of a fixed point P and of 2^128*P, in
+// affine cached form, for variable-time multiplication by width-8 NAFs of the
+// two halves of a scalar.
+type NAFTable struct {
+	// lo[i] = (2i+1)*P and hi[i] = (2i+1)*2^128*P for i = 0..63.
+	lo, hi [64]AffineCached
+}
+
+// FromExtended fills in the table for the point p, and returns t.
+func (t *NAFTable) FromExtended(p *ExtendedGroupElement) *NAFTable {
+	var q ExtendedGroupElement
+	buildAffineOddMultiples(&t.lo, p)
+	buildAffineOddMultiples(&t.hi, q.MultByPow2(p, 128))
+	return t
+}
+
+// buildAffineOddMultiples sets table[i] = (2i+1)*p for i = 0..63.
+func buildAffineOddMultiples(table *[64]AffineCached, p *ExtendedGroupElement) {
+	var p2, multiple ExtendedGroupElement
+	var p2Cached ProjectiveCached
+	p2Cached.FromExtended(p2.Double(p))
+
+	table[0].FromExtended(p)
+	multiple.Set(p)
+	for i := 1; i < 64; i++ {
+		multiple.AddCached(&multiple, &p2Cached)
+		table[i].FromExtended(&multiple)
+	}
+}
+
+// basepointNAFTable is the NAFTable for the base point, computed on first
+// use.
+var basepointNAFTable NAFTable
+var basepointNAFTableOnce sync.Once
+
+// BasepointNAFTable returns the NAFTable for the base point B.
+func BasepointNAFTable() *NAFTable {
+	basepointNAFTableOnce.Do(func() { basepointNAFTable.FromExtended(Basepoint()) })
+	return &basepointNAFTable
+}
+
+// VartimeDoubleMultPrecomputed sets v = a*P + b*Q, where ta and tb are the
+// tables for P and Q and a and b are 256-bit little-endian integers, and
+// returns v.
+//
+// Splitting each scalar in halves makes four half-size multiplications that
+// share a single chain of 129 doublings, half that of VartimeMultiScalarMult.
+//
+// Execution time depends on the inputs, so this must only be used with public
+// scalars.
+func (v *ExtendedGroupElement) VartimeDoubleMultPrecomputed(a *[32]byte, ta *NAFTable, b *[32]byte, tb *NAFTable) *ExtendedGroupElement {
+	aLo, aHi := halfNAFs(a)
+	bLo, bHi := halfNAFs(b)
+
+	var acc ExtendedGroupElement
+	acc.Zero()
+
+	// Skip the leading zero digits, which would only double the identity.
+	i := 128
+	for ; i >= 0; i-- {
+		if aLo[i] != 0 || aHi[i] != 0 || bLo[i] != 0 || bHi[i] != 0 {
+			break
+		}
+	}
+
+	for ; i >= 0; i-- {
+		acc.Double(&acc)
+		acc.addNAFDigit(&ta.lo, aLo[i])
+		acc.addNAFDigit(&ta.hi, aHi[i])
+		acc.addNAFDigit(&tb.lo, bLo[i])
+		acc.addNAFDigit(&tb.hi, bHi[i])
+	}
+
+	return v.Set(&acc)
+}
+
+// halfNAFs returns the width-8 NAFs of the low and high 128 bits of k. The
+// NAF of a 128-bit integer can carry into a 129th digit.
+func halfNAFs(k *[32]byte) (lo, hi [129]int8) {
+	var half [32]byte
+	copy(half[:16], k[:16])
+	loNAF := NonAdjacentForm(&half, 8)
+	copy(half[:16], k[16:])
+	hiNAF := NonAdjacentForm(&half, 8)
+	copy(lo[:], loNAF[:129])
+	copy(hi[:], hiNAF[:129])
+	return lo, hi
+}
+
+// addNAFDigit sets v = v + d*P, where table holds the odd multiples of P and
+// d is an odd NAF digit or zero.
+func (v *ExtendedGroupElement) addNAFDigit(table *[64]AffineCached, d int8) {
+	if d > 0 {
+		v.AddAffineCached(v, &table[d/2])
+	} else if d < 0 {
+		v.SubAffineCached(v, &table[-d/2])
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package group
+
+import (
+	"crypto/subtle"
+	"sync"
+)
+
+// ScalarMult sets v = k*p, where k is a 256-bit little-endian integer, and
+// returns v. It runs in constant time with respect to k.
+//
+// This is a fixed 4-bit window over all 256 bits of k, so it does not require
+// k to be reduced and gives the right answer for points outside the
+// prime-order subgroup.
+func (v *ExtendedGroupElement) ScalarMult(k *[32]byte, p *ExtendedGroupElement) *ExtendedGroupElement {
+	var table [16]ProjectiveCached
+	buildWindowTable(&table, p)
+
+	var acc ExtendedGroupElement
+	var selected ProjectiveCached
+	acc.Zero()
+	for i := 63; i >= 0; i-- {
+		acc.Double(&acc)
+		acc.Double(&acc)
+		acc.Double(&acc)
+		acc.Double(&acc)
+
+		selectWindow(&selected, &table, nibble(k, i))
+		acc.AddCached(&acc, &selected)
+	}
+
+	return v.Set(&acc)
+}
+
+// CombinedMult sets v = k1*B + k2*p, where B is the base point and k1 and k2
+// are 256-bit little-endian integers, and returns v. It runs in constant time
+// with respect to k1 and k2.
+//
+// The two multiplications share a single chain of doublings by interleaving
+// their 4-bit windows, so this costs about one ScalarMult plus 64 additions
+// instead of two full ScalarMults.
+func (v *ExtendedGroupElement) CombinedMult(k1, k2 *[32]byte, p *ExtendedGroupElement) *ExtendedGroupElement {
+	basepointTableOnce.Do(initBasepointTable)
+
+	var table [16]ProjectiveCached
+	buildWindowTable(&table, p)
+
+	var acc ExtendedGroupElement
+	var selected ProjectiveCached
+	var selectedBase AffineCached
+	acc.Zero()
+	for i := 63; i >= 0; i-- {
+		acc.Double(&acc)
+		acc.Double(&acc)
+		acc.Double(&acc)
+		acc.Double(&acc)
+
+		digit := nibble(k1, i)
+		selectedBase.Zero()
+		for j := int32(1); j < 16; j++ {
+			selectedBase.Select(&basepointMultiples[j], &selectedBase, subtle.ConstantTimeEq(digit, j))
+		}
+		acc.AddAffineCached(&acc, &selectedBase)
+
+		selectWindow(&selected, &table, nibble(k2, i))
+		acc.AddCached(&acc, &selected)
+	}
+
+	return v.Set(&acc)
+}
+
+// buildWindowTable sets table[i] = i*p for i = 0..15.
+func buildWindowTable(table *[16]ProjectiveCached, p *ExtendedGroupElement) {
+	var multiple ExtendedGroupElement
+	table[0].Zero()
+	table[1].FromExtended(p)
+	multiple.Set(p)
+	for i := 2; i < 16; i++ {
+		multiple.AddCached(&multiple, &table[1])
+		table[i].FromExtended(&multiple)
+	}
+}
+
+// selectWindow sets v = table[digit] in constant time, for digit in [0, 16).
+func selectWindow(v *ProjectiveCached, table *[16]ProjectiveCached, digit int32) {
+	v.Zero()
+	for j := int32(1); j < 16; j++ {
+		v.Select(&table[j], v, subtle.ConstantTimeEq(digit, j))
+	}
+}
+
+// nibble returns the i-th 4-bit digit of the little-endian integer k.
+func nibble(k *[32]byte, i int) int32 {
+	return int32(k[i/2]>>uint(4*(i&1))) & 15
+}
+
+// basepointTable is the precomputed table for the base point. It is the same
+// layout as the table in ref10, but it's computed on first use.
+var basepointTable PrecomputedTable
+var basepointTableOnce sync.Once
+
+// basepointMultiples holds i*B for i = 0..15, for the unsigned 4-bit windows
+// of CombinedMult. It is filled in along with basepointTable.
+var basepointMultiples [16]AffineCached
+
+// Basepoint returns a new copy of the standard base point B, the point with
+// y = 4/5 and positive x.
+func Basepoint() *ExtendedGroupElement {
+	var b ExtendedGroupElement
+	if err := b.FromBytes(basepointBytes[:]); err != nil {
+		panic("ed25519: invalid base point encoding")
+	}
+	return &b
+}
+
+var basepointBytes = [32]byte{
+	0x58, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+	0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+	0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+	0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+}
+
+func initBasepointTable() {
+	B := Basepoint()
+	basepointTable.FromExtended(B)
+
+	var multiple ExtendedGroupElement
+	basepointMultiples[0].Zero()
+	multiple.Set(B)
+	for i := 1; i < 16; i++ {
+		basepointMultiples[i].FromExtended(&multiple)
+		multiple.Add(&multiple, B)
+	}
+}
+
+// ScalarBaseMult sets v = k*B, where B is the base point and k is a 256-bit
+// little-endian integer, and returns v. It runs in constant time with respect
+// to k.
+func (v *ExtendedGroupElement) ScalarBaseMult(k *[32]byte) *ExtendedGroupElement {
+	basepointTableOnce.Do(initBasepointTable)
+	return v.ScalarMultPrecomputed(k, &basepointTable)
+}
+
+// groupOrderBytes is the group order as a 256-bit little-endian integer.
+var groupOrderBytes = [32]byte{
+	0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+	0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+}
+
+// IsTorsionFree returns 1 if v is in the prime-order subgroup generated by
+// the base point, and 0 if it has a small-order component. It computes L*v,
+// which is the identity exactly for the points of order dividing L, and runs
+// in constant time.
+func (v *ExtendedGroupElement) IsTorsionFree() int {
+	var lv, identity ExtendedGroupElement
+	lv.ScalarMult(&groupOrderBytes, v)
+	identity.Zero()
+	return lv.Equal(&identity)
+}
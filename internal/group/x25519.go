@@ -0,0 +1,131 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package group
+
+import (
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+// a24 is (486662 - 2) / 4, the curve25519 constant of the ladder step.
+var a24 = new(radix51.FieldElement).SetInt(121665)
+
+// X25519 sets out to the X25519 function of RFC 7748, Section 5, applied to
+// scalar and the u-coordinate point. The scalar is clamped, and the top bit
+// of point is ignored, as RFC 7748 specifies. It runs in constant time, with
+// the Montgomery ladder.
+func X25519(out, scalar, point *[32]byte) {
+	var x, z radix51.FieldElement
+	ladder(&x, &z, scalar, point)
+	z.Invert(&z)
+	x.Mul(&x, &z)
+	x.ToBytes(out[:])
+}
+
+// X25519Batch sets out[i] to X25519(scalars[i], points[i]) for every i, like
+// X25519, but computes all the final inversions with Montgomery's trick: a
+// single inversion and three multiplications per element, instead of one
+// inversion per element. A zero Z, from a low-order point, gives a zero
+// output, as with X25519, and doesn't affect the others. It runs in constant
+// time with respect to the values, but not their number. The three slices
+// must have the same length.
+func X25519Batch(out, scalars, points [][32]byte) {
+	n := len(out)
+	if len(scalars) != n || len(points) != n {
+		panic("group: mismatched X25519Batch lengths")
+	}
+	if n == 0 {
+		return
+	}
+
+	xs := make([]radix51.FieldElement, n)
+	zs := make([]radix51.FieldElement, n)
+	for i := range out {
+		ladder(&xs[i], &zs[i], &scalars[i], &points[i])
+	}
+
+	// prefix[i] is the product of all the nonzero Z before i.
+	prefix := make([]radix51.FieldElement, n)
+	var acc, z radix51.FieldElement
+	acc.One()
+	for i := range zs {
+		prefix[i].Set(&acc)
+		z.Select(radix51.One, &zs[i], zs[i].Equal(radix51.Zero))
+		acc.Mul(&acc, &z)
+	}
+
+	// Walking backwards, inv is the inverse of the product of the nonzero Z
+	// up to and including i. A zero Z leaves inv unchanged, and zeroes X.
+	var inv, t radix51.FieldElement
+	inv.Invert(&acc)
+	for i := n - 1; i >= 0; i-- {
+		isZero := zs[i].Equal(radix51.Zero)
+		z.Select(radix51.One, &zs[i], isZero)
+		t.Mul(&inv, &prefix[i])
+		inv.Mul(&inv, &z)
+		t.Select(radix51.Zero, &t, isZero)
+		xs[i].Mul(&xs[i], &t)
+		xs[i].ToBytes(out[i][:])
+	}
+}
+
+// ladder sets x and z to the projective u-coordinate of the clamped scalar
+// times point, with the Montgomery ladder of RFC 7748, Section 5.
+func ladder(x, z *radix51.FieldElement, scalar, point *[32]byte) {
+	var k [32]byte
+	copy(k[:], scalar[:])
+	k[0] &= 248
+	k[31] &= 127
+	k[31] |= 64
+
+	var x1, x2, z2, x3, z3 radix51.FieldElement
+	x1.FromBytes(point[:])
+	x2.One()
+	z2.Zero()
+	x3.Set(&x1)
+	z3.One()
+
+	var a, aa, b, bb, e, c, d, da, cb, t radix51.FieldElement
+	swap := 0
+	for i := 254; i >= 0; i-- {
+		bit := int(k[i/8]>>uint(i&7)) & 1
+		swap ^= bit
+		cswap(&x2, &x3, swap)
+		cswap(&z2, &z3, swap)
+		swap = bit
+
+		a.Add(&x2, &z2)
+		aa.Square(&a)
+		b.Sub(&x2, &z2)
+		bb.Square(&b)
+		e.Sub(&aa, &bb)
+		c.Add(&x3, &z3)
+		d.Sub(&x3, &z3)
+		da.Mul(&d, &a)
+		cb.Mul(&c, &b)
+
+		x3.Add(&da, &cb)
+		x3.Square(&x3)
+		z3.Sub(&da, &cb)
+		z3.Square(&z3)
+		z3.Mul(&z3, &x1)
+		x2.Mul(&aa, &bb)
+		t.Mul(a24, &e)
+		t.Add(&t, &aa)
+		z2.Mul(&e, &t)
+	}
+	cswap(&x2, &x3, swap)
+	cswap(&z2, &z3, swap)
+
+	x.Set(&x2)
+	z.Set(&z2)
+}
+
+// cswap swaps a and b if cond is 1, and leaves them unchanged if it's 0.
+func cswap(a, b *radix51.FieldElement, cond int) {
+	var t radix51.FieldElement
+	t.Select(b, a, cond)
+	b.Select(a, b, cond)
+	a.Set(&t)
+}
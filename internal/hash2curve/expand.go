@@ -0,0 +1,73 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hash2curve implements the hash-independent parts of hashing to
+// elliptic curves, as specified in RFC 9380.
+package hash2curve
+
+import (
+	"errors"
+	"hash"
+)
+
+// ExpandMessageXMD implements expand_message_xmd from RFC 9380, Section
+// 5.3.1. It returns a uniformly random byte string of length n derived from
+// msg and the domain separation tag dst, using the Merkle-Damgard hash
+// function h. A dst longer than 255 bytes is first hashed down, as in
+// Section 5.3.3.
+func ExpandMessageXMD(h func() hash.Hash, msg, dst []byte, n int) ([]byte, error) {
+	H := h()
+	bInBytes := H.Size()
+	sInBytes := H.BlockSize()
+
+	ell := (n + bInBytes - 1) / bInBytes
+	if ell > 255 || n > 65535 {
+		return nil, errors.New("hash2curve: requested output is too long")
+	}
+	if len(dst) > 255 {
+		// DST = H("H2C-OVERSIZE-DST-" || a_very_long_DST)
+		H.Write([]byte("H2C-OVERSIZE-DST-"))
+		H.Write(dst)
+		dst = H.Sum(nil)
+		H.Reset()
+	}
+
+	// DST_prime = DST || I2OSP(len(DST), 1)
+	dstPrime := make([]byte, 0, len(dst)+1)
+	dstPrime = append(dstPrime, dst...)
+	dstPrime = append(dstPrime, byte(len(dst)))
+
+	// b_0 = H(Z_pad || msg || l_i_b_str || I2OSP(0, 1) || DST_prime)
+	H.Write(make([]byte, sInBytes))
+	H.Write(msg)
+	H.Write([]byte{byte(n >> 8), byte(n), 0})
+	H.Write(dstPrime)
+	b0 := H.Sum(nil)
+
+	// b_1 = H(b_0 || I2OSP(1, 1) || DST_prime)
+	H.Reset()
+	H.Write(b0)
+	H.Write([]byte{1})
+	H.Write(dstPrime)
+	bi := H.Sum(nil)
+
+	out := make([]byte, 0, ell*bInBytes)
+	out = append(out, bi...)
+
+	// b_i = H(strxor(b_0, b_(i - 1)) || I2OSP(i, 1) || DST_prime)
+	tmp := make([]byte, bInBytes)
+	for i := 2; i <= ell; i++ {
+		for j := range tmp {
+			tmp[j] = b0[j] ^ bi[j]
+		}
+		H.Reset()
+		H.Write(tmp)
+		H.Write([]byte{byte(i)})
+		H.Write(dstPrime)
+		bi = H.Sum(nil)
+		out = append(out, bi...)
+	}
+
+	return out[:n], nil
+}
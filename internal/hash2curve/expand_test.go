@@ -0,0 +1,68 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hash2curve
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Test vectors from RFC 9380, Appendix K.3.
+func TestExpandMessageXMD(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA512-256")
+	tests := []struct {
+		msg string
+		n   int
+		out string
+	}{
+		{"", 0x20, "6b9a7312411d92f921c6f68ca0b6380730a1a4d982c507211a90964c394179ba"},
+		{"abc", 0x20, "0da749f12fbe5483eb066a5f595055679b976e93abe9be6f0f6318bce7aca8dc"},
+		{"q128_" + strings.Repeat("q", 128), 0x20, "7336234ee9983902440f6bc35b348352013becd88938d2afec44311caf8356b3"},
+		{"", 0x80, "41b037d1734a5f8df225dd8c7de38f851efdb45c372887be655212d07251b921b052b62eaed99b46f72f2ef4cc96bfaf254ebbbec091e1a3b9e4fb5e5b619d2e0c5414800a1d882b62bb5cd1778f098b8eb6cb399d5d9d18f5d5842cf5d13d7eb00a7cff859b605da678b318bd0e65ebff70bec88c753b159a805d2c89c55961"},
+		{"abc", 0x80, "7f1dddd13c08b543f2e2037b14cefb255b44c83cc397c1786d975653e36a6b11bdd7732d8b38adb4a0edc26a0cef4bb45217135456e58fbca1703cd6032cb1347ee720b87972d63fbf232587043ed2901bce7f22610c0419751c065922b488431851041310ad659e4b23520e1772ab29dcdeb2002222a363f0c2b1c972b3efe1"},
+	}
+	for _, tt := range tests {
+		want, _ := hex.DecodeString(tt.out)
+		got, err := ExpandMessageXMD(sha512.New, []byte(tt.msg), dst, tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("msg %q, len %d: got %x, want %x", tt.msg, tt.n, got, want)
+		}
+	}
+}
+
+// Test vectors from RFC 9380, Appendix K.2, which use a 256-byte DST.
+func TestExpandMessageXMDLongDST(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128-long-DST-" + strings.Repeat("1", 208))
+	tests := []struct {
+		msg string
+		out string
+	}{
+		{"", "e8dc0c8b686b7ef2074086fbdd2f30e3f8bfbd3bdf177f73f04b97ce618a3ed3"},
+		{"abc", "52dbf4f36cf560fca57dedec2ad924ee9c266341d8f3d6afe5171733b16bbb12"},
+	}
+	for _, tt := range tests {
+		want, _ := hex.DecodeString(tt.out)
+		got, err := ExpandMessageXMD(sha256.New, []byte(tt.msg), dst, 0x20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("msg %q: got %x, want %x", tt.msg, got, want)
+		}
+	}
+}
+
+func TestExpandMessageXMDLimits(t *testing.T) {
+	if _, err := ExpandMessageXMD(sha512.New, nil, []byte("dst"), 255*64+1); err == nil {
+		t.Error("accepted an output longer than 255 blocks")
+	}
+}
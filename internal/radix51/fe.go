@@ -28,6 +28,10 @@ var (
 	One      = &FieldElement{1, 0, 0, 0, 0}
 	Two      = &FieldElement{2, 0, 0, 0, 0}
 	MinusOne = new(FieldElement).Neg(One)
+
+	// SqrtM1 is 2^((p-1)/4), which squared is equal to -1 by Euler's Criterion.
+	SqrtM1 = &FieldElement{1718705420411056, 234908883556509,
+		2233514472574048, 2117202627021982, 765476049583133}
 )
 
 func (v *FieldElement) Zero() *FieldElement {
@@ -210,6 +214,88 @@ func (v *FieldElement) Invert(z *FieldElement) *FieldElement {
 	return v.Mul(&t, &z11) // 2^255 - 21
 }
 
+// Pow22523 sets v = x^((p-5)/8), and returns v. (p-5)/8 is 2^252-3.
+func (v *FieldElement) Pow22523(x *FieldElement) *FieldElement {
+	var t0, t1, t2 FieldElement
+
+	t0.Square(x)             // x^2
+	t1.Square(&t0)           // x^4
+	t1.Square(&t1)           // x^8
+	t1.Mul(x, &t1)           // x^9
+	t0.Mul(&t0, &t1)         // x^11
+	t0.Square(&t0)           // x^22
+	t0.Mul(&t1, &t0)         // x^31
+	t1.Square(&t0)           // x^62
+	for i := 1; i < 5; i++ { // x^992
+		t1.Square(&t1)
+	}
+	t0.Mul(&t1, &t0)          // x^1023 -> 1023 = 2^10 - 1
+	t1.Square(&t0)            // 2^11 - 2
+	for i := 1; i < 10; i++ { // 2^20 - 2^10
+		t1.Square(&t1)
+	}
+	t1.Mul(&t1, &t0)          // 2^20 - 1
+	t2.Square(&t1)            // 2^21 - 2
+	for i := 1; i < 20; i++ { // 2^40 - 2^20
+		t2.Square(&t2)
+	}
+	t1.Mul(&t2, &t1)          // 2^40 - 1
+	t1.Square(&t1)            // 2^41 - 2
+	for i := 1; i < 10; i++ { // 2^50 - 2^10
+		t1.Square(&t1)
+	}
+	t0.Mul(&t1, &t0)          // 2^50 - 1
+	t1.Square(&t0)            // 2^51 - 2
+	for i := 1; i < 50; i++ { // 2^100 - 2^50
+		t1.Square(&t1)
+	}
+	t1.Mul(&t1, &t0)           // 2^100 - 1
+	t2.Square(&t1)             // 2^101 - 2
+	for i := 1; i < 100; i++ { // 2^200 - 2^100
+		t2.Square(&t2)
+	}
+	t1.Mul(&t2, &t1)          // 2^200 - 1
+	t1.Square(&t1)            // 2^201 - 2
+	for i := 1; i < 50; i++ { // 2^250 - 2^50
+		t1.Square(&t1)
+	}
+	t0.Mul(&t1, &t0)     // 2^250 - 1
+	t0.Square(&t0)       // 2^251 - 2
+	t0.Square(&t0)       // 2^252 - 4
+	return v.Mul(&t0, x) // 2^252 - 3 -> x^(2^252-3)
+}
+
+// SqrtRatio sets v to the non-negative square root of the ratio of u and w.
+//
+// If u/w is square, SqrtRatio returns v and 1. If u/w is not square, SqrtRatio
+// sets v according to Section 4.3 of draft-irtf-cfrg-ristretto255-decaf448-00,
+// and returns v and 0.
+func (v *FieldElement) SqrtRatio(u, w *FieldElement) (*FieldElement, int) {
+	var a, b FieldElement
+
+	// r = (u * w^3) * (u * w^7)^((p-5)/8)
+	var w2, uw3, uw7, r FieldElement
+	w2.Square(w)
+	uw3.Mul(u, a.Mul(&w2, w))
+	uw7.Mul(&uw3, a.Square(&w2))
+	r.Mul(&uw3, a.Pow22523(&uw7))
+
+	var check, uNeg FieldElement
+	check.Mul(w, a.Square(&r)) // check = w * r^2
+	uNeg.Neg(u)
+
+	correctSignSqrt := check.Equal(u)
+	flippedSignSqrt := check.Equal(&uNeg)
+	flippedSignSqrtI := check.Equal(b.Mul(&uNeg, SqrtM1))
+
+	// r = CT_SELECT(r * SQRT_M1 IF flipped_sign_sqrt | flipped_sign_sqrt_i ELSE r)
+	rPrime := b.Mul(&r, SqrtM1)
+	r.Select(rPrime, &r, flippedSignSqrt|flippedSignSqrtI)
+
+	v.Abs(&r) // Choose the nonnegative square root.
+	return v, correctSignSqrt | flippedSignSqrt
+}
+
 func (v *FieldElement) Set(a *FieldElement) *FieldElement {
 	*v = *a
 	return v
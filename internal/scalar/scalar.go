@@ -0,0 +1,336 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package scalar implements arithmetic modulo the order of the edwards25519
+// prime-order subgroup, L = 2^252 + 27742317777372353535851937790883648493,
+// on four 64-bit limbs with Montgomery multiplication. All operations run in
+// constant time and don't allocate.
+package scalar
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Scalar represents an integer modulo L. An element s represents the integer
+// (s[0] + s[1]*2^64 + s[2]*2^128 + s[3]*2^192) / R mod L, where R = 2^256,
+// that is, it is kept in Montgomery form. Limbs are always fully reduced, so
+// every integer has exactly one representation. The zero value is a valid
+// zero element.
+type Scalar [4]uint64
+
+var (
+	// order is L.
+	order = Scalar{0x5812631a5cf5d3ed, 0x14def9dea2f79cd6, 0, 0x1000000000000000}
+
+	// one is 1 in Montgomery form, R mod L.
+	one = Scalar{0xd6ec31748d98951d, 0xc6ef5bf4737dcf70, 0xfffffffffffffffe, 0x0fffffffffffffff}
+
+	// rr is R^2 mod L, which montMul maps from a plain integer x to its
+	// Montgomery form x*R mod L.
+	rr = Scalar{0xa40611e3449c0f01, 0xd00e1ba768859347, 0xceec73d217f5be65, 0x0399411b7c309a3d}
+
+	// rrr is R^3 mod L, which montMul maps from x to the Montgomery form of
+	// x*R, for the high half of a wide input.
+	rrr = Scalar{0x2a9e49687b83a2db, 0x278324e6aef7f3ec, 0x8065dc6c04ec5b65, 0x0e530b773599cec7}
+)
+
+// Fixed exponents, as 64-bit little-endian limbs.
+var (
+	// orderMinusTwo is L - 2, the exponent that inverts by Fermat's little
+	// theorem.
+	orderMinusTwo = [4]uint64{0x5812631a5cf5d3eb, 0x14def9dea2f79cd6, 0, 0x1000000000000000}
+
+	// sqrtExp is (L + 3) / 8. Since L = 5 mod 8, x^sqrtExp is a square root
+	// of either x or -x, when x is a square.
+	sqrtExp = [4]uint64{0xcb024c634b9eba7e, 0x029bdf3bd45ef39a, 0, 0x0200000000000000}
+
+	// legendreExp is (L - 1) / 2, Euler's criterion.
+	legendreExp = [4]uint64{0x2c09318d2e7ae9f6, 0x0a6f7cef517bce6b, 0, 0x0800000000000000}
+)
+
+// sqrtM1 is a square root of -1 modulo L, 2^((L-1)/4), in Montgomery form.
+var sqrtM1 = Scalar{0x7c790e32b42f0e7d, 0x4c8ce706a7ae2cc8, 0xd73823cc921779ad, 0x05599959893f562a}
+
+// lInv is -L^-1 mod 2^64.
+const lInv uint64 = 0xd2b51da312547e1b
+
+// Zero sets v = 0, and returns v.
+func (v *Scalar) Zero() *Scalar {
+	*v = Scalar{}
+	return v
+}
+
+// One sets v = 1, and returns v.
+func (v *Scalar) One() *Scalar {
+	*v = one
+	return v
+}
+
+// Set sets v = a, and returns v.
+func (v *Scalar) Set(a *Scalar) *Scalar {
+	*v = *a
+	return v
+}
+
+// Add sets v = a + b mod L, and returns v.
+func (v *Scalar) Add(a, b *Scalar) *Scalar {
+	// Both operands are below L < 2^253, so the sum can't overflow 256 bits.
+	var t Scalar
+	var c uint64
+	t[0], c = bits.Add64(a[0], b[0], 0)
+	t[1], c = bits.Add64(a[1], b[1], c)
+	t[2], c = bits.Add64(a[2], b[2], c)
+	t[3], _ = bits.Add64(a[3], b[3], c)
+	return v.subtractOrder(&t)
+}
+
+// Sub sets v = a - b mod L, and returns v.
+func (v *Scalar) Sub(a, b *Scalar) *Scalar {
+	var t Scalar
+	var borrow uint64
+	t[0], borrow = bits.Sub64(a[0], b[0], 0)
+	t[1], borrow = bits.Sub64(a[1], b[1], borrow)
+	t[2], borrow = bits.Sub64(a[2], b[2], borrow)
+	t[3], borrow = bits.Sub64(a[3], b[3], borrow)
+
+	// If the subtraction underflowed, add L back.
+	m := -borrow
+	var c uint64
+	v[0], c = bits.Add64(t[0], order[0]&m, 0)
+	v[1], c = bits.Add64(t[1], order[1]&m, c)
+	v[2], c = bits.Add64(t[2], order[2]&m, c)
+	v[3], _ = bits.Add64(t[3], order[3]&m, c)
+	return v
+}
+
+// Neg sets v = -a mod L, and returns v.
+func (v *Scalar) Neg(a *Scalar) *Scalar {
+	return v.Sub(&Scalar{}, a)
+}
+
+// Mul sets v = a * b mod L, and returns v.
+func (v *Scalar) Mul(a, b *Scalar) *Scalar {
+	// In Montgomery form, a*b/R is exactly the representation of the product.
+	return v.montMul(a, b)
+}
+
+// MulAdd sets v = a * b + c mod L, and returns v.
+func (v *Scalar) MulAdd(a, b, c *Scalar) *Scalar {
+	var t Scalar
+	t.montMul(a, b)
+	return v.Add(&t, c)
+}
+
+// Invert sets v = 1/x mod L, and returns v. If x is zero, v is set to zero.
+// It runs in constant time.
+func (v *Scalar) Invert(x *Scalar) *Scalar {
+	return v.pow(x, &orderMinusTwo)
+}
+
+// Sqrt sets v to a square root of x, and returns v and 1, if x is a square
+// modulo L. Of the two roots, v is the one with an even canonical encoding.
+// If x is not a square, v is set to zero, and Sqrt returns v and 0. It runs
+// in constant time.
+func (v *Scalar) Sqrt(x *Scalar) (*Scalar, int) {
+	var r, r2, negX, rI Scalar
+	r.pow(x, &sqrtExp)
+	r2.Mul(&r, &r)
+	negX.Neg(x)
+
+	// Either r^2 = x, or r^2 = -x and (r * sqrt(-1))^2 = x.
+	correct := r2.Equal(x)
+	flipped := r2.Equal(&negX)
+	rI.Mul(&r, &sqrtM1)
+	r.Select(&rI, &r, flipped)
+	wasSquare := correct | flipped
+
+	var b [32]byte
+	r.ToBytes(b[:])
+	negR := new(Scalar).Neg(&r)
+	r.Select(negR, &r, int(b[0]&1))
+
+	return v.Select(&r, &Scalar{}, wasSquare), wasSquare
+}
+
+// Legendre returns the Legendre symbol of x modulo L: 1 if x is a nonzero
+// square, -1 if it is not a square, and 0 if it is zero. It runs in constant
+// time.
+func Legendre(x *Scalar) int {
+	var e, minusOne Scalar
+	e.pow(x, &legendreExp)
+	minusOne.Neg(&one)
+	return e.Equal(&one) - e.Equal(&minusOne)
+}
+
+// pow sets v = x^e mod L, and returns v. It walks the exponent in 4-bit
+// windows, so for a fixed e the sequence of squarings and multiplications is
+// the same addition chain for every x, and it runs in constant time with
+// respect to x.
+func (v *Scalar) pow(x *Scalar, e *[4]uint64) *Scalar {
+	// table[i] = x^i
+	var table [16]Scalar
+	table[0].One()
+	table[1].Set(x)
+	for i := 2; i < 16; i++ {
+		table[i].Mul(&table[i-1], x)
+	}
+
+	var z Scalar
+	z.Set(&table[window(e, 63)])
+	for i := 62; i >= 0; i-- {
+		z.Mul(&z, &z)
+		z.Mul(&z, &z)
+		z.Mul(&z, &z)
+		z.Mul(&z, &z)
+		// The exponent is public, so skipping its zero digits leaks nothing.
+		if d := window(e, i); d != 0 {
+			z.Mul(&z, &table[d])
+		}
+	}
+	return v.Set(&z)
+}
+
+// window returns the i-th 4-bit digit of the little-endian limbs e.
+func window(e *[4]uint64, i int) int {
+	return int(e[i/16]>>uint(4*(i%16))) & 0xf
+}
+
+// montMul sets v = a * b / R mod L with the CIOS method, and returns v. a must
+// be below L, and b below 2^256.
+func (v *Scalar) montMul(a, b *Scalar) *Scalar {
+	// t is kept below 2L + 2^64 between rounds, and below 2^320 within a
+	// round, so five limbs are enough.
+	var t [5]uint64
+	for i := 0; i < 4; i++ {
+		// t += a * b[i]
+		var c uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[j], b[i])
+			var cc uint64
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j], c = lo, hi
+		}
+		t[4] += c
+
+		// t = (t + m * L) / 2^64, where m is chosen to clear the low limb.
+		m := t[0] * lInv
+		hi, lo := bits.Mul64(m, order[0])
+		_, cc := bits.Add64(lo, t[0], 0)
+		c = hi + cc
+		for j := 1; j < 4; j++ {
+			hi, lo := bits.Mul64(m, order[j])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j-1], c = lo, hi
+		}
+		t[3], cc = bits.Add64(t[4], c, 0)
+		t[4] = cc
+	}
+
+	// The result is below 2L < 2^254, so t[4] is zero here.
+	return v.subtractOrder(&Scalar{t[0], t[1], t[2], t[3]})
+}
+
+// subtractOrder sets v = t - L if t >= L, and v = t otherwise, and returns v.
+// t must be below 2L.
+func (v *Scalar) subtractOrder(t *Scalar) *Scalar {
+	var d Scalar
+	var borrow uint64
+	d[0], borrow = bits.Sub64(t[0], order[0], 0)
+	d[1], borrow = bits.Sub64(t[1], order[1], borrow)
+	d[2], borrow = bits.Sub64(t[2], order[2], borrow)
+	d[3], borrow = bits.Sub64(t[3], order[3], borrow)
+	return v.Select(t, &d, int(borrow))
+}
+
+// FromBytes sets v to the 32-byte little-endian integer x reduced modulo L,
+// and returns v. It panics if x is not 32 bytes long.
+func (v *Scalar) FromBytes(x []byte) *Scalar {
+	if len(x) != 32 {
+		panic("ed25519: invalid scalar length")
+	}
+	var t Scalar
+	for i := range t {
+		t[i] = binary.LittleEndian.Uint64(x[8*i:])
+	}
+	return v.montMul(&rr, &t)
+}
+
+// FromUniformBytes sets v to the 64-byte little-endian integer x reduced
+// modulo L, and returns v. It panics if x is not 64 bytes long.
+func (v *Scalar) FromUniformBytes(x []byte) *Scalar {
+	if len(x) != 64 {
+		panic("ed25519: invalid wide scalar length")
+	}
+	// x = lo + hi * R, and montMul(rrr, hi) is the Montgomery form of hi * R.
+	var lo, hi Scalar
+	for i := range lo {
+		lo[i] = binary.LittleEndian.Uint64(x[8*i:])
+		hi[i] = binary.LittleEndian.Uint64(x[32+8*i:])
+	}
+	lo.montMul(&rr, &lo)
+	hi.montMul(&rrr, &hi)
+	return v.Add(&lo, &hi)
+}
+
+// ToBytes writes the canonical 32-byte little-endian encoding of v to r. It
+// panics if r is not 32 bytes long.
+func (v *Scalar) ToBytes(r []byte) {
+	if len(r) != 32 {
+		panic("ed25519: invalid scalar length")
+	}
+	var t Scalar
+	t.montMul(v, &Scalar{1, 0, 0, 0})
+	for i := range t {
+		binary.LittleEndian.PutUint64(r[8*i:], t[i])
+	}
+}
+
+// IsCanonical returns 1 if the 32-byte little-endian integer x is below L,
+// and 0 otherwise. It runs in constant time.
+func IsCanonical(x []byte) int {
+	if len(x) != 32 {
+		panic("ed25519: invalid scalar length")
+	}
+	var borrow uint64
+	for i := range order {
+		_, borrow = bits.Sub64(binary.LittleEndian.Uint64(x[8*i:]), order[i], borrow)
+	}
+	return int(borrow)
+}
+
+// Equal returns 1 if v and u are equal, and 0 otherwise. It runs in constant
+// time.
+func (v *Scalar) Equal(u *Scalar) int {
+	var acc uint64
+	for i := range v {
+		acc |= v[i] ^ u[i]
+	}
+	return subtle.ConstantTimeEq(int32(acc>>32|acc&0xffffffff), 0)
+}
+
+// IsZero returns 1 if v is zero, and 0 otherwise. It runs in constant time.
+func (v *Scalar) IsZero() int {
+	return v.Equal(&Scalar{})
+}
+
+const mask64Bits uint64 = (1 << 64) - 1
+
+// Select sets v to a if cond == 1, and to b if cond == 0.
+// v, a and b are allowed to overlap.
+func (v *Scalar) Select(a, b *Scalar, cond int) *Scalar {
+	m := uint64(cond) * mask64Bits
+	v[0] = (m & a[0]) | (^m & b[0])
+	v[1] = (m & a[1]) | (^m & b[1])
+	v[2] = (m & a[2]) | (^m & b[2])
+	v[3] = (m & a[3]) | (^m & b[3])
+	return v
+}
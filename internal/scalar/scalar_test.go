@@ -0,0 +1,166 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scalar
+
+import (
+	"math/big"
+	mathrand "math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// quickCheckConfig will make each quickcheck test run (256 * -quickchecks)
+// times. The default value of -quickchecks is 100.
+var quickCheckConfig = &quick.Config{MaxCountScale: 1 << 8}
+
+var bigOrder, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+// Generate returns a Scalar from 32 arbitrary bytes, which are unreduced
+// about 15/16 of the time.
+func (Scalar) Generate(rand *mathrand.Rand, size int) reflect.Value {
+	var b [32]byte
+	rand.Read(b[:])
+	var s Scalar
+	s.FromBytes(b[:])
+	return reflect.ValueOf(s)
+}
+
+func (v *Scalar) toBig() *big.Int {
+	var b [32]byte
+	v.ToBytes(b[:])
+	for i, j := 0, 31; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return new(big.Int).SetBytes(b[:])
+}
+
+func TestScalarMatchesBigInt(t *testing.T) {
+	check := func(x, y, z Scalar) bool {
+		bx, by, bz := x.toBig(), y.toBig(), z.toBig()
+		mod := func(v *big.Int) *big.Int { return v.Mod(v, bigOrder) }
+
+		var r Scalar
+		if r.Add(&x, &y).toBig().Cmp(mod(new(big.Int).Add(bx, by))) != 0 {
+			return false
+		}
+		if r.Sub(&x, &y).toBig().Cmp(mod(new(big.Int).Sub(bx, by))) != 0 {
+			return false
+		}
+		if r.Neg(&x).toBig().Cmp(mod(new(big.Int).Neg(bx))) != 0 {
+			return false
+		}
+		if r.Mul(&x, &y).toBig().Cmp(mod(new(big.Int).Mul(bx, by))) != 0 {
+			return false
+		}
+		want := mod(new(big.Int).Add(new(big.Int).Mul(bx, by), bz))
+		return r.MulAdd(&x, &y, &z).toBig().Cmp(want) == 0
+	}
+	if err := quick.Check(check, quickCheckConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScalarBytesRoundTrip(t *testing.T) {
+	check := func(in [32]byte) bool {
+		var s Scalar
+		s.FromBytes(in[:])
+		var out [32]byte
+		s.ToBytes(out[:])
+
+		le := in
+		for i, j := 0, 31; i < j; i, j = i+1, j-1 {
+			le[i], le[j] = le[j], le[i]
+		}
+		want := new(big.Int).SetBytes(le[:])
+		canonical := want.Cmp(bigOrder) < 0
+		want.Mod(want, bigOrder)
+
+		return s.toBig().Cmp(want) == 0 &&
+			(IsCanonical(in[:]) == 1) == canonical &&
+			(!canonical || out == in)
+	}
+	if err := quick.Check(check, quickCheckConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScalarAliasing(t *testing.T) {
+	check := func(x, y Scalar) bool {
+		var want Scalar
+		want.Mul(&x, &y)
+		x.Mul(&x, &y)
+		return x.Equal(&want) == 1
+	}
+	if err := quick.Check(check, quickCheckConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScalarFromUniformBytes(t *testing.T) {
+	check := func(in [64]byte) bool {
+		var s Scalar
+		s.FromUniformBytes(in[:])
+
+		le := in
+		for i, j := 0, 63; i < j; i, j = i+1, j-1 {
+			le[i], le[j] = le[j], le[i]
+		}
+		want := new(big.Int).SetBytes(le[:])
+		return s.toBig().Cmp(want.Mod(want, bigOrder)) == 0
+	}
+	if err := quick.Check(check, quickCheckConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScalarInvert(t *testing.T) {
+	check := func(x Scalar) bool {
+		var inv Scalar
+		inv.Invert(&x)
+		if x.IsZero() == 1 {
+			return inv.IsZero() == 1
+		}
+		want := new(big.Int).ModInverse(x.toBig(), bigOrder)
+		return inv.toBig().Cmp(want) == 0
+	}
+	if err := quick.Check(check, quickCheckConfig); err != nil {
+		t.Error(err)
+	}
+
+	var zero Scalar
+	if !check(zero) {
+		t.Error("1/0 != 0")
+	}
+}
+
+func TestScalarSqrt(t *testing.T) {
+	check := func(x Scalar) bool {
+		var r, r2 Scalar
+		_, wasSquare := r.Sqrt(&x)
+
+		want := big.Jacobi(x.toBig(), bigOrder)
+		if Legendre(&x) != want {
+			return false
+		}
+		if want == -1 {
+			return wasSquare == 0 && r.IsZero() == 1
+		}
+		var b [32]byte
+		r.ToBytes(b[:])
+		return wasSquare == 1 && r2.Mul(&r, &r).Equal(&x) == 1 && b[0]&1 == 0
+	}
+	if err := quick.Check(check, quickCheckConfig); err != nil {
+		t.Error(err)
+	}
+
+	var zero, r Scalar
+	if _, wasSquare := r.Sqrt(&zero); wasSquare != 1 || r.IsZero() != 1 {
+		t.Error("sqrt(0) != 0")
+	}
+	if Legendre(&zero) != 0 {
+		t.Error("Legendre(0) != 0")
+	}
+}
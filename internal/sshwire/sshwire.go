@@ -0,0 +1,53 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sshwire implements the uint32 and string types of the SSH wire
+// encoding, RFC 4251, Section 5, which OpenSSH keys and signatures are built
+// from.
+package sshwire
+
+import "encoding/binary"
+
+// Reader reads values from the bytes it holds. After any read fails, the
+// Reader is nil, and every following read fails too, so that errors only
+// need checking at the end.
+type Reader []byte
+
+// ReadUint32 reads a big-endian uint32.
+func (r *Reader) ReadUint32() uint32 {
+	if len(*r) < 4 {
+		*r = nil
+		return 0
+	}
+	v := binary.BigEndian.Uint32(*r)
+	*r = (*r)[4:]
+	return v
+}
+
+// ReadString reads a uint32 length followed by that many bytes. The result
+// aliases the Reader's buffer.
+func (r *Reader) ReadString() []byte {
+	n := r.ReadUint32()
+	if *r == nil || uint64(len(*r)) < uint64(n) {
+		*r = nil
+		return nil
+	}
+	s := (*r)[:n:n]
+	*r = (*r)[n:]
+	return s
+}
+
+// Writer appends values to the bytes it holds.
+type Writer []byte
+
+// WriteUint32 appends a big-endian uint32.
+func (w *Writer) WriteUint32(v uint32) {
+	*w = binary.BigEndian.AppendUint32(*w, v)
+}
+
+// WriteString appends the length of s, as a uint32, followed by s.
+func (w *Writer) WriteString(s []byte) {
+	w.WriteUint32(uint32(len(s)))
+	*w = append(*w, s...)
+}
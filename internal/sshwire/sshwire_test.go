@@ -0,0 +1,48 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sshwire
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadWrite(t *testing.T) {
+	var w Writer
+	w.WriteUint32(0x01020304)
+	w.WriteString([]byte("ssh-ed25519"))
+	w.WriteString(nil)
+	want := []byte("\x01\x02\x03\x04\x00\x00\x00\x0bssh-ed25519\x00\x00\x00\x00")
+	if !bytes.Equal(w, want) {
+		t.Fatalf("got %q, want %q", w, want)
+	}
+
+	r := Reader(w)
+	if v := r.ReadUint32(); v != 0x01020304 {
+		t.Errorf("ReadUint32 = %#x", v)
+	}
+	if s := r.ReadString(); string(s) != "ssh-ed25519" {
+		t.Errorf("ReadString = %q", s)
+	}
+	if s := r.ReadString(); r == nil || len(s) != 0 {
+		t.Errorf("empty ReadString = %q, reader %v", s, r)
+	}
+	if r == nil || len(r) != 0 {
+		t.Errorf("%d bytes left", len(r))
+	}
+
+	// A failed read poisons the reader, even if later reads would fit.
+	r = Reader("\x00\x00\x00\x09abc\x00\x00\x00\x00")
+	if s := r.ReadString(); s != nil || r != nil {
+		t.Errorf("short string read as %q", s)
+	}
+	if r.ReadUint32(); r != nil {
+		t.Error("read after a failure succeeded")
+	}
+	r = Reader("\xff\xff\xff\xff")
+	if s := r.ReadString(); s != nil || r != nil {
+		t.Errorf("huge string read as %q", s)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jose implements the Ed25519 and X25519 parts of JOSE, as specified
+// in RFC 8037: Octet Key Pair JSON Web Keys, and JSON Web Signatures with
+// the EdDSA algorithm.
+//
+// Ed25519 keys are ed25519.PrivateKey and ed25519.PublicKey, and X25519 keys
+// are the *ecdh.PrivateKey and *ecdh.PublicKey of this module's ecdh package.
+package jose
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/ecdh"
+)
+
+// The key type and curves of RFC 8037, Section 2.
+const (
+	ktyOKP     = "OKP"
+	crvEd25519 = "Ed25519"
+	crvX25519  = "X25519"
+)
+
+// jwk holds the members of an OKP JSON Web Key. Members not listed here,
+// such as "kid" or "use", are ignored when parsing.
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	D   string `json:"d,omitempty"`
+}
+
+// MarshalJWK returns the JSON Web Key of key, an OKP key as in RFC 8037,
+// Section 2. The key can be an ed25519.PrivateKey or ed25519.PublicKey, an
+// *ecdh.PrivateKey or *ecdh.PublicKey. Private keys include the public key
+// in "x", and the private key in "d": the seed for Ed25519.
+func MarshalJWK(key any) ([]byte, error) {
+	k, err := newJWK(key)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(k)
+}
+
+func newJWK(key any) (*jwk, error) {
+	k := &jwk{Kty: ktyOKP}
+	var x, d []byte
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		if len(key) != ed25519.PublicKeySize {
+			return nil, errors.New("jose: bad Ed25519 public key length")
+		}
+		k.Crv, x = crvEd25519, key
+	case ed25519.PrivateKey:
+		if len(key) != ed25519.PrivateKeySize {
+			return nil, errors.New("jose: bad Ed25519 private key length")
+		}
+		k.Crv, x, d = crvEd25519, key[ed25519.SeedSize:], key.Seed()
+	case *ecdh.PublicKey:
+		k.Crv, x = crvX25519, key.Bytes()
+	case *ecdh.PrivateKey:
+		k.Crv, x, d = crvX25519, key.PublicKey().Bytes(), key.Bytes()
+	default:
+		return nil, errors.New("jose: unsupported key type")
+	}
+	k.X = base64.RawURLEncoding.EncodeToString(x)
+	if d != nil {
+		k.D = base64.RawURLEncoding.EncodeToString(d)
+	}
+	return k, nil
+}
+
+// ParseJWK parses an OKP JSON Web Key with the Ed25519 or X25519 curve. It
+// returns an ed25519.PrivateKey or ed25519.PublicKey, an *ecdh.PrivateKey or
+// *ecdh.PublicKey, depending on the curve and on whether "d" is present. For
+// private keys, "x" must be the public key of "d".
+func ParseJWK(data []byte) (any, error) {
+	var k jwk
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, errors.New("jose: invalid JWK: " + err.Error())
+	}
+	if k.Kty != ktyOKP {
+		return nil, errors.New("jose: unsupported JWK key type " + k.Kty)
+	}
+	x, err := base64.RawURLEncoding.Strict().DecodeString(k.X)
+	if err != nil || len(x) != 32 {
+		return nil, errors.New("jose: invalid JWK public key")
+	}
+	var d []byte
+	if k.D != "" {
+		d, err = base64.RawURLEncoding.Strict().DecodeString(k.D)
+		if err != nil || len(d) != 32 {
+			return nil, errors.New("jose: invalid JWK private key")
+		}
+	}
+
+	var key any
+	var pub []byte
+	switch k.Crv {
+	case crvEd25519:
+		if d == nil {
+			return ed25519.PublicKey(x), nil
+		}
+		priv := ed25519.NewKeyFromSeed(d)
+		key, pub = priv, priv[ed25519.SeedSize:]
+	case crvX25519:
+		if d == nil {
+			return ecdh.X25519().NewPublicKey(x)
+		}
+		priv, err := ecdh.X25519().NewPrivateKey(d)
+		if err != nil {
+			return nil, err
+		}
+		key, pub = priv, priv.PublicKey().Bytes()
+	default:
+		return nil, errors.New("jose: unsupported JWK curve " + k.Crv)
+	}
+	if subtle.ConstantTimeCompare(pub, x) != 1 {
+		return nil, errors.New("jose: JWK public key doesn't match the private key")
+	}
+	return key, nil
+}
+
+// Thumbprint returns the RFC 7638 thumbprint of the public part of key, the
+// SHA-256 hash of its required JWK members, as in RFC 8037, Appendix A.3. The
+// key types are those of MarshalJWK. The thumbprint is commonly used, base64url
+// encoded, as the "kid" of the key.
+func Thumbprint(key any) ([]byte, error) {
+	k, err := newJWK(key)
+	if err != nil {
+		return nil, err
+	}
+	// The required members, in lexicographic order and without whitespace.
+	// Their values are base64url or fixed strings, which need no escaping.
+	members := `{"crv":"` + k.Crv + `","kty":"` + k.Kty + `","x":"` + k.X + `"}`
+	h := sha256.Sum256([]byte(members))
+	return h[:], nil
+}
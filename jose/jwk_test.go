@@ -0,0 +1,120 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jose
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/ecdh"
+)
+
+// The Ed25519 key of RFC 8037, Appendix A.1 and A.2.
+const (
+	rfc8037PrivateJWK = `{"kty":"OKP","crv":"Ed25519",
+   "d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+   "x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	rfc8037PublicJWK = `{"kty":"OKP","crv":"Ed25519",
+   "x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+)
+
+func rfc8037Key(t *testing.T) ed25519.PrivateKey {
+	key, err := ParseJWK([]byte(rfc8037PrivateJWK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		t.Fatalf("got %T, want ed25519.PrivateKey", key)
+	}
+	return priv
+}
+
+func TestParseJWK(t *testing.T) {
+	priv := rfc8037Key(t)
+	key, err := ParseJWK([]byte(rfc8037PublicJWK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub, ok := key.(ed25519.PublicKey); !ok || !pub.Equal(priv.Public()) {
+		t.Errorf("got %T %x, want the public key of the private key", key, key)
+	}
+
+	out, err := MarshalJWK(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}`
+	if string(out) != want {
+		t.Errorf("got %s, want %s", out, want)
+	}
+
+	for name, s := range map[string]string{
+		"kty":      `{"kty":"EC","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+		"crv":      `{"kty":"OKP","crv":"Ed448","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+		"padding":  `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo="}`,
+		"standard": `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+		"short":    `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHUQ"}`,
+		"missing":  `{"kty":"OKP","crv":"Ed25519"}`,
+		"mismatch": `{"kty":"OKP","crv":"Ed25519","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A","x":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}`,
+		"json":     `{"kty":"OKP",`,
+	} {
+		if _, err := ParseJWK([]byte(s)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestJWKX25519(t *testing.T) {
+	// RFC 8037, Appendix A.6: the ephemeral key of ECDH-ES with X25519,
+	// which is the Alice key of RFC 7748, Section 6.1.
+	key, err := ParseJWK([]byte(`{"kty":"OKP","crv":"X25519",
+   "x":"hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, ok := key.(*ecdh.PublicKey)
+	if !ok {
+		t.Fatalf("got %T, want *ecdh.PublicKey", key)
+	}
+	d, _ := base64.RawURLEncoding.DecodeString("dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo")
+	priv, err := ecdh.X25519().NewPrivateKey(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.PublicKey().Equal(pub) {
+		t.Errorf("public key doesn't match")
+	}
+
+	out, err := MarshalJWK(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err = ParseJWK(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equal(key) {
+		t.Errorf("round trip of %s returned %v", out, key)
+	}
+}
+
+func TestThumbprint(t *testing.T) {
+	// RFC 8037, Appendix A.3.
+	priv := rfc8037Key(t)
+	for _, key := range []any{priv, ed25519.PublicKey(priv[ed25519.SeedSize:])} {
+		tp, err := Thumbprint(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := base64.RawURLEncoding.EncodeToString(tp); got != "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k" {
+			t.Errorf("%T: got %s", key, got)
+		}
+	}
+	if _, err := Thumbprint("not a key"); err == nil {
+		t.Error("string accepted")
+	}
+}
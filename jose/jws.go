@@ -0,0 +1,89 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jose
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gtank/ed25519"
+)
+
+// algEdDSA is the JWS algorithm of RFC 8037, Section 3.1.
+const algEdDSA = "EdDSA"
+
+// SignJWS returns the JWS Compact Serialization of payload, signed by priv
+// with the EdDSA algorithm, as in RFC 8037, Section 3.1. JWTs are JWSs with
+// a JSON payload of claims, and "typ" set to "JWT" in the header.
+//
+// The protected header is header with "alg" set to "EdDSA", or just that if
+// header is nil. It's an error for header to set "alg" to another value. The
+// members are serialized in lexicographic order.
+func SignJWS(header map[string]any, payload []byte, priv ed25519.PrivateKey) (string, error) {
+	if l := len(priv); l != ed25519.PrivateKeySize {
+		panic("jose: bad private key length: " + strconv.Itoa(l))
+	}
+	protected := map[string]any{"alg": algEdDSA}
+	for k, v := range header {
+		if k == "alg" && v != algEdDSA {
+			return "", errors.New("jose: header algorithm is not EdDSA")
+		}
+		protected[k] = v
+	}
+	h, err := json.Marshal(protected)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(h) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+	sig := ed25519.Sign(priv, []byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// VerifyJWS checks that token is a JWS Compact Serialization signed by pub
+// with the EdDSA algorithm, and returns its protected header and payload.
+//
+// The header must set "alg" to "EdDSA". Following RFC 7515, Section 4.1.11,
+// tokens whose header has a "crit" member are rejected, since VerifyJWS
+// doesn't implement any extension. Claims in the payload, such as the
+// expiration time of a JWT, are not checked.
+func VerifyJWS(token string, pub ed25519.PublicKey) (header map[string]any, payload []byte, err error) {
+	if l := len(pub); l != ed25519.PublicKeySize {
+		panic("jose: bad public key length: " + strconv.Itoa(l))
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("jose: JWS doesn't have three parts")
+	}
+	h, err := base64.RawURLEncoding.Strict().DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, errors.New("jose: invalid JWS header encoding")
+	}
+	payload, err = base64.RawURLEncoding.Strict().DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, errors.New("jose: invalid JWS payload encoding")
+	}
+	sig, err := base64.RawURLEncoding.Strict().DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, errors.New("jose: invalid JWS signature encoding")
+	}
+
+	if err := json.Unmarshal(h, &header); err != nil || header == nil {
+		return nil, nil, errors.New("jose: invalid JWS header")
+	}
+	if header["alg"] != algEdDSA {
+		return nil, nil, errors.New("jose: JWS algorithm is not EdDSA")
+	}
+	if _, ok := header["crit"]; ok {
+		return nil, nil, errors.New("jose: unsupported critical JWS header parameters")
+	}
+	if !ed25519.Verify(pub, []byte(parts[0]+"."+parts[1]), sig) {
+		return nil, nil, errors.New("jose: invalid JWS signature")
+	}
+	return header, payload, nil
+}
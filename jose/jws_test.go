@@ -0,0 +1,83 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+// RFC 8037, Appendix A.4 and A.5.
+const rfc8037JWS = "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc." +
+	"hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+
+func TestSignJWS(t *testing.T) {
+	priv := rfc8037Key(t)
+	token, err := SignJWS(nil, []byte("Example of Ed25519 signing"), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != rfc8037JWS {
+		t.Errorf("got %s, want %s", token, rfc8037JWS)
+	}
+	if alg, _ := SignJWS(map[string]any{"alg": algEdDSA}, []byte("Example of Ed25519 signing"), priv); alg != rfc8037JWS {
+		t.Errorf("explicit alg: got %s", alg)
+	}
+	if _, err := SignJWS(map[string]any{"alg": "none"}, nil, priv); err == nil {
+		t.Error("alg none accepted")
+	}
+}
+
+func TestVerifyJWS(t *testing.T) {
+	priv := rfc8037Key(t)
+	pub := ed25519.PublicKey(priv[ed25519.SeedSize:])
+	header, payload, err := VerifyJWS(rfc8037JWS, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != "Example of Ed25519 signing" || len(header) != 1 || header["alg"] != "EdDSA" {
+		t.Errorf("got %v, %q", header, payload)
+	}
+
+	token, err := SignJWS(map[string]any{"typ": "JWT", "kid": "key-1"}, []byte(`{"sub":"1234567890"}`), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, payload, err = VerifyJWS(token, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header["typ"] != "JWT" || header["kid"] != "key-1" || string(payload) != `{"sub":"1234567890"}` {
+		t.Errorf("got %v, %q", header, payload)
+	}
+
+	parts := strings.Split(rfc8037JWS, ".")
+	crit, _ := SignJWS(map[string]any{"crit": []string{"b64"}, "b64": false}, []byte("x"), priv)
+	noneToken, _ := SignJWS(nil, []byte("x"), priv)
+	noneParts := strings.Split(noneToken, ".")
+	other, _, _ := ed25519.GenerateKey(nil)
+	for name, err := range map[string]error{
+		"payload":   verifyErr(parts[0]+".RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmcu."+parts[2], pub),
+		"key":       verifyErr(rfc8037JWS, other),
+		"parts":     verifyErr(parts[0]+"."+parts[1], pub),
+		"extra":     verifyErr(rfc8037JWS+".", pub),
+		"padding":   verifyErr(rfc8037JWS+"=", pub),
+		"crit":      verifyErr(crit, pub),
+		"alg none":  verifyErr("eyJhbGciOiJub25lIn0."+noneParts[1]+"."+noneParts[2], pub),
+		"alg HS256": verifyErr("eyJhbGciOiJIUzI1NiJ9."+parts[1]+"."+parts[2], pub),
+		"header":    verifyErr("bnVsbA."+parts[1]+"."+parts[2], pub),
+	} {
+		if err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func verifyErr(token string, pub ed25519.PublicKey) error {
+	_, _, err := VerifyJWS(token, pub)
+	return err
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+// BlindingFactorSize is the size of a key blinding factor.
+const BlindingFactorSize = 32
+
+// torBasepointString is the string Tor hashes into its blinding factors to
+// name the base point, as B in rend-spec-v3, Appendix A.2.
+const torBasepointString = "(15112221349535400772501151409588531511454012693041857206046113283949847762202, 46316835694926478169428394003475163141307993866256225615783033603165251855960)"
+
+// TorBlindingFactor returns the blinding factor of Tor v3 onion services
+// for publicKey, the service's long-term identity key, and the time period
+// periodNumber of periodLength minutes, as specified in rend-spec-v3,
+// Appendix A.2:
+//
+//	h = SHA3-256("Derive temporary signing key" | INT_1(0) | A | s | B | N)
+//
+// clamped, where N = "key-blind" | INT_8(periodNumber) | INT_8(periodLength),
+// and s is an optional shared secret, which Tor leaves empty. It panics if
+// len(publicKey) is not PublicKeySize.
+func TorBlindingFactor(publicKey PublicKey, secret []byte, periodNumber, periodLength uint64) []byte {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	h := sha3.New256()
+	h.Write([]byte("Derive temporary signing key\x00"))
+	h.Write(publicKey)
+	h.Write(secret)
+	h.Write([]byte(torBasepointString))
+	h.Write([]byte("key-blind"))
+	var n [16]byte
+	binary.BigEndian.PutUint64(n[:8], periodNumber)
+	binary.BigEndian.PutUint64(n[8:], periodLength)
+	h.Write(n[:])
+
+	factor := h.Sum(nil)
+	factor[0] &= 248
+	factor[31] &= 63
+	factor[31] |= 64
+	return factor
+}
+
+// blindingScalar returns factor, a 32-byte little-endian integer, reduced
+// modulo L.
+func blindingScalar(factor []byte) (*Scalar, error) {
+	if len(factor) != BlindingFactorSize {
+		return nil, errors.New("ed25519: bad blinding factor length: " + strconv.Itoa(len(factor)))
+	}
+	var wide [64]byte
+	copy(wide[:], factor)
+	h := new(Scalar)
+	h.SetUniformBytes(wide[:])
+	if h.Equal(NewScalar()) == 1 {
+		return nil, errors.New("ed25519: zero blinding factor")
+	}
+	return h, nil
+}
+
+// BlindPublicKey returns the blinded public key A' = [h]A, where h is the
+// blinding factor, such as the output of TorBlindingFactor. Signatures by the
+// private key blinded with ExpandedPrivateKey.Blind and the same factor
+// verify under it, but A' can't be linked to A without knowing h.
+//
+// It returns an error if publicKey is not a point of prime order, or if
+// factor is not BlindingFactorSize bytes long. It panics if len(publicKey)
+// is not PublicKeySize.
+func BlindPublicKey(publicKey PublicKey, factor []byte) (PublicKey, error) {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	h, err := blindingScalar(factor)
+	if err != nil {
+		return nil, err
+	}
+	A, err := new(Point).SetBytesPrimeOrder(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return new(Point).ScalarMult(h.Bytes(), A).Bytes(), nil
+}
+
+// Blind returns the private key e blinded by factor, as in rend-spec-v3,
+// Appendix A.2: the secret scalar is multiplied by h, and the nonce prefix is
+// replaced by
+//
+//	SHA-512("Derive temporary signing key hash input" | prefix)[:32]
+//
+// Its public key is BlindPublicKey(e.Public(), factor), and it signs like
+// any other ExpandedPrivateKey. It returns an error if factor is not
+// BlindingFactorSize bytes long.
+func (e *ExpandedPrivateKey) Blind(factor []byte) (*ExpandedPrivateKey, error) {
+	h, err := blindingScalar(factor)
+	if err != nil {
+		return nil, err
+	}
+	blinded := new(ExpandedPrivateKey)
+	blinded.s.Mul(h, &e.s)
+
+	ph := sha512.New()
+	ph.Write([]byte("Derive temporary signing key hash input"))
+	ph.Write(e.prefix[:])
+	copy(blinded.prefix[:], ph.Sum(nil))
+
+	copy(blinded.publicKey[:], new(Point).ScalarBaseMult(blinded.s.Bytes()).Bytes())
+	return blinded, nil
+}
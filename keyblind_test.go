@@ -0,0 +1,95 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+// TestTorBlindingVector checks TorBlindingFactor and BlindPublicKey against
+// test_blinding_basics in Tor's src/test/test_hs_common.c, which blinds its
+// identity key for time period 1234 of 1440 minutes.
+func TestTorBlindingVector(t *testing.T) {
+	pub, _ := hex.DecodeString("833990b085c1a688c1d4c8b1f6b56afaf5a2eca674449e1d704f83765ccb7bc6")
+	wantFactor, _ := hex.DecodeString("309e50db31fee6775abd0af6fb7c371e060308f4f847db09fe4cfe13af602247")
+	wantBlinded, _ := hex.DecodeString("3a50bf210e8f9ee955ae0014f7a6917fb65ebf098a86305abb508d1a7291b6d5")
+
+	factor := TorBlindingFactor(pub, nil, 1234, 1440)
+	if !bytes.Equal(factor, wantFactor) {
+		t.Errorf("blinding factor: got %x, want %x", factor, wantFactor)
+	}
+	blinded, err := BlindPublicKey(pub, factor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(blinded, wantBlinded) {
+		t.Errorf("blinded key: got %x, want %x", blinded, wantBlinded)
+	}
+}
+
+func TestKeyBlinding(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewExpandedPrivateKey(priv)
+	message := []byte("onion service descriptor")
+
+	var previous PublicKey
+	for period := uint64(19000); period < 19003; period++ {
+		factor := TorBlindingFactor(pub, nil, period, 1440)
+		if len(factor) != BlindingFactorSize || factor[0]&7 != 0 || factor[31]&0xc0 != 0x40 {
+			t.Errorf("period %d: factor %x is not clamped", period, factor)
+		}
+
+		blindedPub, err := BlindPublicKey(pub, factor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blinded, err := e.Blind(factor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(blinded.Public(), blindedPub) {
+			t.Errorf("period %d: blinded key pair mismatch: %x, %x", period, blinded.Public(), blindedPub)
+		}
+		if bytes.Equal(blindedPub, pub) || bytes.Equal(blindedPub, previous) {
+			t.Errorf("period %d: blinded key %x not fresh", period, blindedPub)
+		}
+		previous = blindedPub
+
+		sig := blinded.Sign(message)
+		if !Verify(blindedPub, message, sig) {
+			t.Errorf("period %d: blinded signature rejected", period)
+		}
+		if Verify(pub, message, sig) {
+			t.Errorf("period %d: blinded signature accepted by the identity key", period)
+		}
+		if bytes.Equal(sig, e.Sign(message)) {
+			t.Errorf("period %d: blinded signature reuses the identity nonce", period)
+		}
+	}
+
+	// The shared secret and period length are bound into the factor.
+	f := TorBlindingFactor(pub, nil, 1, 1440)
+	if bytes.Equal(f, TorBlindingFactor(pub, []byte("secret"), 1, 1440)) || bytes.Equal(f, TorBlindingFactor(pub, nil, 1, 720)) {
+		t.Error("blinding factor ignores its inputs")
+	}
+
+	if _, err := BlindPublicKey(pub, f[:31]); err == nil {
+		t.Error("short blinding factor accepted")
+	}
+	if _, err := e.Blind(make([]byte, BlindingFactorSize)); err == nil {
+		t.Error("zero blinding factor accepted")
+	}
+	T, _ := new(Point).SetBytes(order8Bytes)
+	A, _ := new(Point).SetBytes(pub)
+	if _, err := BlindPublicKey(new(Point).Add(A, T).Bytes(), f); err == nil {
+		t.Error("public key with a torsion component blinded")
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keyfile
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	cryptorand "crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/internal/bcryptpbkdf"
+	"github.com/gtank/ed25519/internal/sshwire"
+)
+
+// The OpenSSH private key format is specified in PROTOCOL.key of the
+// OpenSSH sources, and the ssh-ed25519 key type in RFC 8709.
+const (
+	opensshMagic = "openssh-key-v1\x00"
+	sshEd25519   = "ssh-ed25519"
+)
+
+// The encryption of OpenSSH private keys: AES-256 in CTR mode, keyed with
+// bcrypt_pbkdf, and the number of rounds and salt size of ssh-keygen.
+const (
+	opensshCipher     = "aes256-ctr"
+	opensshKDF        = "bcrypt"
+	opensshRounds     = 16
+	opensshSaltSize   = 16
+	opensshMaxRounds  = 1 << 16
+	opensshCipherKey  = 32
+	opensshCipherIV   = aes.BlockSize
+	opensshPlainBlock = 8
+)
+
+// ErrPassphraseRequired is returned when parsing an encrypted OpenSSH
+// private key without a passphrase.
+var ErrPassphraseRequired = errors.New("keyfile: OpenSSH private key is encrypted")
+
+var (
+	errInvalidOpenSSHKey = errors.New("keyfile: invalid OpenSSH private key")
+	errWrongPassphrase   = errors.New("keyfile: wrong passphrase for OpenSSH private key")
+)
+
+// MarshalSSHPublicKey returns the SSH wire encoding of pub, RFC 8709,
+// Section 4: the string "ssh-ed25519" followed by the key, as in the
+// base64 part of an authorized_keys line, and in SSH signatures.
+func MarshalSSHPublicKey(pub ed25519.PublicKey) []byte {
+	if l := len(pub); l != ed25519.PublicKeySize {
+		panic("keyfile: bad public key length: " + strconv.Itoa(l))
+	}
+	var w sshwire.Writer
+	w.WriteString([]byte(sshEd25519))
+	w.WriteString(pub)
+	return w
+}
+
+// ParseSSHPublicKey parses the SSH wire encoding of an ssh-ed25519 public
+// key, as produced by MarshalSSHPublicKey.
+func ParseSSHPublicKey(data []byte) (ed25519.PublicKey, error) {
+	r := sshwire.Reader(data)
+	keyType, key := r.ReadString(), r.ReadString()
+	if r == nil || len(r) != 0 || string(keyType) != sshEd25519 ||
+		len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("keyfile: invalid ssh-ed25519 public key")
+	}
+	return append(ed25519.PublicKey{}, key...), nil
+}
+
+// MarshalAuthorizedKey returns pub in the format of authorized_keys files
+// and of .pub files written by ssh-keygen: "ssh-ed25519", the base64
+// encoding of MarshalSSHPublicKey, and the comment if it's not empty,
+// followed by a newline.
+func MarshalAuthorizedKey(pub ed25519.PublicKey, comment string) []byte {
+	line := []byte(sshEd25519 + " ")
+	line = base64.StdEncoding.AppendEncode(line, MarshalSSHPublicKey(pub))
+	if comment != "" {
+		line = append(append(line, ' '), comment...)
+	}
+	return append(line, '\n')
+}
+
+// ParseAuthorizedKey parses the first ssh-ed25519 key in data, in the format
+// of authorized_keys files, and returns it with its comment, its options, and
+// the rest of data after its line. Like sshd, it skips blank lines, comments
+// starting with '#', and lines that it can't parse, including those with
+// keys of other types. The options are returned as they appear, for example
+// `from="*.example.com"` or "no-pty", without being interpreted.
+func ParseAuthorizedKey(data []byte) (pub ed25519.PublicKey, comment string, options []string, rest []byte, err error) {
+	for len(data) > 0 {
+		var line []byte
+		line, data, _ = bytes.Cut(data, []byte{'\n'})
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		if pub, comment, options, ok := parseAuthorizedKeyLine(line); ok {
+			return pub, comment, options, data, nil
+		}
+	}
+	return nil, "", nil, nil, errors.New("keyfile: no ssh-ed25519 key found")
+}
+
+// parseAuthorizedKeyLine parses "[options] ssh-ed25519 base64 [comment]".
+func parseAuthorizedKeyLine(line []byte) (ed25519.PublicKey, string, []string, bool) {
+	var options []string
+	if !bytes.HasPrefix(line, []byte(sshEd25519+" ")) && !bytes.HasPrefix(line, []byte(sshEd25519+"\t")) {
+		var ok bool
+		options, line, ok = parseOptions(line)
+		if !ok {
+			return nil, "", nil, false
+		}
+	}
+	keyType, line := nextField(line)
+	encoded, line := nextField(line)
+	if string(keyType) != sshEd25519 {
+		return nil, "", nil, false
+	}
+	wire, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return nil, "", nil, false
+	}
+	pub, err := ParseSSHPublicKey(wire)
+	if err != nil {
+		return nil, "", nil, false
+	}
+	return pub, string(line), options, true
+}
+
+// parseOptions splits the comma-separated options at the start of line,
+// which end at the first whitespace outside double quotes, and returns the
+// rest of line.
+func parseOptions(line []byte) (options []string, rest []byte, ok bool) {
+	start, quoted := 0, false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && quoted && i+1 < len(line):
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			options = append(options, string(line[start:i]))
+			start = i + 1
+		case (c == ' ' || c == '\t') && !quoted:
+			options = append(options, string(line[start:i]))
+			return options, bytes.TrimLeft(line[i:], " \t"), true
+		}
+	}
+	return nil, nil, false
+}
+
+// nextField returns the first whitespace-separated field of line, and the
+// rest of line after the whitespace that follows it.
+func nextField(line []byte) (field, rest []byte) {
+	i := bytes.IndexAny(line, " \t")
+	if i < 0 {
+		return line, nil
+	}
+	return line[:i], bytes.TrimLeft(line[i:], " \t")
+}
+
+// MarshalOpenSSHPrivateKey returns priv as an unencrypted OPENSSH PRIVATE KEY
+// PEM block, the format of ssh-keygen, with the given comment.
+func MarshalOpenSSHPrivateKey(priv ed25519.PrivateKey, comment string) ([]byte, error) {
+	return marshalOpenSSHPrivateKey(cryptorand.Reader, priv, comment, nil)
+}
+
+// MarshalOpenSSHPrivateKeyWithPassphrase is like MarshalOpenSSHPrivateKey,
+// but encrypts the key with passphrase, like ssh-keygen: with aes256-ctr and
+// a key derived by bcrypt_pbkdf with 16 rounds and a random salt.
+func MarshalOpenSSHPrivateKeyWithPassphrase(priv ed25519.PrivateKey, comment string, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("keyfile: empty passphrase")
+	}
+	return marshalOpenSSHPrivateKey(cryptorand.Reader, priv, comment, passphrase)
+}
+
+func marshalOpenSSHPrivateKey(rand io.Reader, priv ed25519.PrivateKey, comment string, passphrase []byte) ([]byte, error) {
+	if l := len(priv); l != ed25519.PrivateKeySize {
+		panic("keyfile: bad private key length: " + strconv.Itoa(l))
+	}
+	pub := priv[ed25519.SeedSize:]
+
+	// The check value is random, so that the private section of two
+	// encryptions of the same key differ.
+	var check [4]byte
+	if _, err := io.ReadFull(rand, check[:]); err != nil {
+		return nil, err
+	}
+	var private sshwire.Writer
+	private = append(private, check[:]...)
+	private = append(private, check[:]...)
+	private.WriteString([]byte(sshEd25519))
+	private.WriteString(pub)
+	private.WriteString(priv)
+	private.WriteString([]byte(comment))
+
+	cipherName, kdfName := "none", "none"
+	var kdfOptions sshwire.Writer
+	blockSize := opensshPlainBlock
+	var stream cipher.Stream
+	if passphrase != nil {
+		salt := make([]byte, opensshSaltSize)
+		if _, err := io.ReadFull(rand, salt); err != nil {
+			return nil, err
+		}
+		kdfOptions.WriteString(salt)
+		kdfOptions.WriteUint32(opensshRounds)
+		var err error
+		stream, err = newOpenSSHCipher(passphrase, salt, opensshRounds)
+		if err != nil {
+			return nil, err
+		}
+		cipherName, kdfName, blockSize = opensshCipher, opensshKDF, aes.BlockSize
+	}
+	for i := 1; len(private)%blockSize != 0; i++ {
+		private = append(private, byte(i))
+	}
+	if stream != nil {
+		stream.XORKeyStream(private, private)
+	}
+
+	w := sshwire.Writer(opensshMagic)
+	w.WriteString([]byte(cipherName))
+	w.WriteString([]byte(kdfName))
+	w.WriteString(kdfOptions)
+	w.WriteUint32(1)
+	w.WriteString(MarshalSSHPublicKey(ed25519.PublicKey(pub)))
+	w.WriteString(private)
+	return encodeOpenSSHPEM(w), nil
+}
+
+// encodeOpenSSHPEM is like pem.EncodeToMemory, but wraps lines at 70
+// characters instead of 64, like ssh-keygen.
+func encodeOpenSSHPEM(data []byte) []byte {
+	const lineLen = 70
+	b64 := base64.StdEncoding.EncodeToString(data)
+	out := []byte("-----BEGIN " + pemOpenSSHPrivateKey + "-----\n")
+	for len(b64) > lineLen {
+		out = append(append(out, b64[:lineLen]...), '\n')
+		b64 = b64[lineLen:]
+	}
+	out = append(append(out, b64...), '\n')
+	return append(out, "-----END "+pemOpenSSHPrivateKey+"-----\n"...)
+}
+
+// ParseOpenSSHPrivateKey parses an unencrypted OPENSSH PRIVATE KEY PEM block
+// holding an Ed25519 key, and returns the key and its comment. If the key is
+// encrypted, it returns ErrPassphraseRequired.
+func ParseOpenSSHPrivateKey(pemBytes []byte) (ed25519.PrivateKey, string, error) {
+	return parseOpenSSHPEM(pemBytes, nil)
+}
+
+// ParseOpenSSHPrivateKeyWithPassphrase is like ParseOpenSSHPrivateKey, but
+// decrypts the key with passphrase. Unencrypted keys are parsed as well.
+func ParseOpenSSHPrivateKeyWithPassphrase(pemBytes, passphrase []byte) (ed25519.PrivateKey, string, error) {
+	return parseOpenSSHPEM(pemBytes, passphrase)
+}
+
+func parseOpenSSHPEM(pemBytes, passphrase []byte) (ed25519.PrivateKey, string, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, "", errors.New("keyfile: no PEM block found")
+	}
+	if block.Type != pemOpenSSHPrivateKey {
+		return nil, "", errors.New("keyfile: unexpected PEM block type " + block.Type)
+	}
+	return parseOpenSSHPrivateKey(block.Bytes, passphrase)
+}
+
+// parseOpenSSHPrivateKey parses the contents of an OPENSSH PRIVATE KEY block
+// holding a single Ed25519 key, decrypting it with passphrase if needed.
+func parseOpenSSHPrivateKey(data, passphrase []byte) (ed25519.PrivateKey, string, error) {
+	if !bytes.HasPrefix(data, []byte(opensshMagic)) {
+		return nil, "", errInvalidOpenSSHKey
+	}
+	r := sshwire.Reader(data[len(opensshMagic):])
+	cipherName, kdfName, kdfOptions := r.ReadString(), r.ReadString(), r.ReadString()
+	nKeys := r.ReadUint32()
+	publicKey := r.ReadString()
+	private := r.ReadString()
+	if r == nil || len(r) != 0 || nKeys != 1 {
+		return nil, "", errInvalidOpenSSHKey
+	}
+	pub, err := ParseSSHPublicKey(publicKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	blockSize := opensshPlainBlock
+	switch {
+	case string(cipherName) == "none" && string(kdfName) == "none":
+		if len(kdfOptions) != 0 {
+			return nil, "", errInvalidOpenSSHKey
+		}
+	case string(cipherName) == opensshCipher && string(kdfName) == opensshKDF:
+		if passphrase == nil {
+			return nil, "", ErrPassphraseRequired
+		}
+		o := sshwire.Reader(kdfOptions)
+		salt, rounds := o.ReadString(), o.ReadUint32()
+		if o == nil || len(o) != 0 || rounds > opensshMaxRounds {
+			return nil, "", errInvalidOpenSSHKey
+		}
+		stream, err := newOpenSSHCipher(passphrase, salt, int(rounds))
+		if err != nil {
+			return nil, "", errInvalidOpenSSHKey
+		}
+		private = append([]byte{}, private...)
+		stream.XORKeyStream(private, private)
+		blockSize = aes.BlockSize
+	default:
+		return nil, "", errors.New("keyfile: unsupported OpenSSH private key encryption " +
+			string(cipherName) + " with " + string(kdfName))
+	}
+	if len(private)%blockSize != 0 {
+		return nil, "", errInvalidOpenSSHKey
+	}
+
+	// The private section starts with two equal check values, which tell a
+	// wrong passphrase apart, and ends with padding 1, 2, 3, ...
+	p := sshwire.Reader(private)
+	check1, check2 := p.ReadUint32(), p.ReadUint32()
+	if p == nil || check1 != check2 {
+		if passphrase != nil && string(cipherName) != "none" {
+			return nil, "", errWrongPassphrase
+		}
+		return nil, "", errInvalidOpenSSHKey
+	}
+	keyType := p.ReadString()
+	privPub := p.ReadString()
+	priv := p.ReadString()
+	comment := p.ReadString()
+	if p == nil || string(keyType) != sshEd25519 {
+		return nil, "", errInvalidOpenSSHKey
+	}
+	for i, b := range p {
+		if b != byte(i+1) {
+			return nil, "", errInvalidOpenSSHKey
+		}
+	}
+	if !bytes.Equal(privPub, pub) || len(priv) != ed25519.PrivateKeySize ||
+		!bytes.Equal(priv[ed25519.SeedSize:], pub) {
+		return nil, "", errInvalidOpenSSHKey
+	}
+	key := ed25519.NewKeyFromSeed(priv[:ed25519.SeedSize])
+	if subtle.ConstantTimeCompare(key, priv) != 1 {
+		return nil, "", errors.New("keyfile: OpenSSH public key doesn't match the private key")
+	}
+	return key, string(comment), nil
+}
+
+// newOpenSSHCipher returns the aes256-ctr stream keyed by bcrypt_pbkdf.
+func newOpenSSHCipher(passphrase, salt []byte, rounds int) (cipher.Stream, error) {
+	k, err := bcryptpbkdf.Key(passphrase, salt, rounds, opensshCipherKey+opensshCipherIV)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k[:opensshCipherKey])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewCTR(block, k[opensshCipherKey:]), nil
+}
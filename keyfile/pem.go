@@ -0,0 +1,81 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keyfile
+
+import (
+	stded25519 "crypto/ed25519"
+	"encoding/pem"
+	"errors"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/ecdh"
+)
+
+// PEM block types recognized by DecodePEM.
+const (
+	pemPrivateKey        = "PRIVATE KEY"
+	pemPublicKey         = "PUBLIC KEY"
+	pemOpenSSHPrivateKey = "OPENSSH PRIVATE KEY"
+)
+
+// EncodePEM returns the PEM encoding of key, in the format of openssl genpkey
+// and openssl pkey -pubout: a PRIVATE KEY block with the PKCS #8 encoding of
+// private keys, or a PUBLIC KEY block with the PKIX encoding of public keys.
+// The key types are those of MarshalPKCS8PrivateKey and
+// MarshalPKIXPublicKey.
+func EncodePEM(key any) ([]byte, error) {
+	var block pem.Block
+	var err error
+	switch key.(type) {
+	case ed25519.PrivateKey, stded25519.PrivateKey, *ecdh.PrivateKey:
+		block.Type = pemPrivateKey
+		block.Bytes, err = MarshalPKCS8PrivateKey(key)
+	case ed25519.PublicKey, stded25519.PublicKey, *ecdh.PublicKey:
+		block.Type = pemPublicKey
+		block.Bytes, err = MarshalPKIXPublicKey(key)
+	default:
+		return nil, errors.New("keyfile: unsupported key type")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&block), nil
+}
+
+// DecodePEM decodes the first PEM block in data, and returns the key it
+// holds and the rest of data after the block. The block type selects the
+// format:
+//
+//   - PRIVATE KEY, as in ParsePKCS8PrivateKey;
+//   - PUBLIC KEY, as in ParsePKIXPublicKey;
+//   - OPENSSH PRIVATE KEY, the format of ssh-keygen, which holds an
+//     ed25519.PrivateKey.
+//
+// Blocks with headers, which mark legacy encrypted keys, are rejected.
+// Encrypted OpenSSH keys return ErrPassphraseRequired, and can be parsed with
+// ParseOpenSSHPrivateKeyWithPassphrase.
+func DecodePEM(data []byte) (key any, rest []byte, err error) {
+	block, rest := pem.Decode(data)
+	if block == nil {
+		return nil, data, errors.New("keyfile: no PEM block found")
+	}
+	if len(block.Headers) != 0 {
+		return nil, rest, errors.New("keyfile: unexpected PEM headers")
+	}
+	switch block.Type {
+	case pemPrivateKey:
+		key, err = ParsePKCS8PrivateKey(block.Bytes)
+	case pemPublicKey:
+		key, err = ParsePKIXPublicKey(block.Bytes)
+	case pemOpenSSHPrivateKey:
+		key, _, err = parseOpenSSHPrivateKey(block.Bytes, nil)
+	default:
+		err = errors.New("keyfile: unsupported PEM block type " + block.Type)
+	}
+	if err != nil {
+		return nil, rest, err
+	}
+	return key, rest, nil
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package keyfile implements the standard file and wire encodings of Ed25519
+// and X25519 keys, so that keys generated by this module can be exchanged
+// with OpenSSL, OpenSSH, crypto/x509 and other tools.
+//
+// Ed25519 keys are ed25519.PrivateKey and ed25519.PublicKey, and X25519 keys
+// are the *ecdh.PrivateKey and *ecdh.PublicKey of this module's ecdh package.
+// The PKCS #8 and PKIX marshaling functions also accept the crypto/ed25519
+// key types. The OpenSSH formats only hold Ed25519 keys.
+package keyfile
+
+import (
+	"bytes"
+	stded25519 "crypto/ed25519"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/ecdh"
+)
+
+// The algorithm identifiers of RFC 8410, Section 3. Their parameters must be
+// absent.
+var (
+	oidX25519  = asn1.ObjectIdentifier{1, 3, 101, 110}
+	oidEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+// oneAsymmetricKey is the PrivateKeyInfo of RFC 5208 and its extension, the
+// OneAsymmetricKey of RFC 5958, which can carry the public key as well.
+type oneAsymmetricKey struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+	Attributes asn1.RawValue  `asn1:"optional,tag:0"`
+	PublicKey  asn1.BitString `asn1:"optional,tag:1"`
+}
+
+// subjectPublicKeyInfo is the SubjectPublicKeyInfo of RFC 5280.
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// MarshalPKCS8PrivateKey converts a private key to the PKCS #8 form of RFC
+// 8410, Section 7, as produced by openssl genpkey. The key can be an
+// ed25519.PrivateKey, a crypto/ed25519.PrivateKey, or an *ecdh.PrivateKey.
+// The encoding is a version 1 PrivateKeyInfo, without the public key, for
+// compatibility with parsers that don't support RFC 5958.
+func MarshalPKCS8PrivateKey(key any) ([]byte, error) {
+	var oid asn1.ObjectIdentifier
+	var raw []byte
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		if len(k) != ed25519.PrivateKeySize {
+			return nil, errors.New("keyfile: bad Ed25519 private key length")
+		}
+		oid, raw = oidEd25519, k.Seed()
+	case stded25519.PrivateKey:
+		return MarshalPKCS8PrivateKey(ed25519.PrivateKey(k))
+	case *ecdh.PrivateKey:
+		oid, raw = oidX25519, k.Bytes()
+	default:
+		return nil, errors.New("keyfile: unsupported private key type")
+	}
+
+	// The privateKey field holds a CurvePrivateKey, which is itself an OCTET
+	// STRING.
+	curvePrivateKey, err := asn1.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(oneAsymmetricKey{
+		Algorithm:  pkix.AlgorithmIdentifier{Algorithm: oid},
+		PrivateKey: curvePrivateKey,
+	})
+}
+
+// ParsePKCS8PrivateKey parses a private key in the PKCS #8 form of RFC 8410,
+// Section 7. It returns an ed25519.PrivateKey or an *ecdh.PrivateKey.
+//
+// It accepts both versions of the format. If the key has a public key, as
+// version 2 keys can, it must match the private key. Attributes are ignored.
+func ParsePKCS8PrivateKey(der []byte) (any, error) {
+	var k oneAsymmetricKey
+	if rest, err := asn1.Unmarshal(der, &k); err != nil {
+		return nil, errors.New("keyfile: invalid PKCS #8 private key: " + err.Error())
+	} else if len(rest) != 0 {
+		return nil, errors.New("keyfile: trailing data after PKCS #8 private key")
+	}
+	if k.Version != 0 && k.Version != 1 {
+		return nil, errors.New("keyfile: unsupported PKCS #8 version")
+	}
+	if err := checkAlgorithm(k.Algorithm); err != nil {
+		return nil, err
+	}
+	var raw []byte
+	if rest, err := asn1.Unmarshal(k.PrivateKey, &raw); err != nil || len(rest) != 0 {
+		return nil, errors.New("keyfile: invalid CurvePrivateKey")
+	}
+	if len(raw) != 32 {
+		return nil, errors.New("keyfile: bad private key length")
+	}
+
+	var key any
+	var pub []byte
+	if k.Algorithm.Algorithm.Equal(oidEd25519) {
+		priv := ed25519.NewKeyFromSeed(raw)
+		key, pub = priv, priv[ed25519.SeedSize:]
+	} else {
+		priv, err := ecdh.X25519().NewPrivateKey(raw)
+		if err != nil {
+			return nil, err
+		}
+		key, pub = priv, priv.PublicKey().Bytes()
+	}
+
+	if k.PublicKey.BitLength != 0 {
+		if k.Version != 1 {
+			return nil, errors.New("keyfile: public key in version 1 PKCS #8 private key")
+		}
+		if k.PublicKey.BitLength != 8*len(pub) || !bytes.Equal(k.PublicKey.Bytes, pub) {
+			return nil, errors.New("keyfile: PKCS #8 public key doesn't match the private key")
+		}
+	}
+	return key, nil
+}
+
+// MarshalPKIXPublicKey converts a public key to the PKIX form of RFC 8410,
+// Section 4, as produced by openssl pkey -pubout. The key can be an
+// ed25519.PublicKey, a crypto/ed25519.PublicKey, or an *ecdh.PublicKey.
+func MarshalPKIXPublicKey(key any) ([]byte, error) {
+	var oid asn1.ObjectIdentifier
+	var raw []byte
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return nil, errors.New("keyfile: bad Ed25519 public key length")
+		}
+		oid, raw = oidEd25519, k
+	case stded25519.PublicKey:
+		return MarshalPKIXPublicKey(ed25519.PublicKey(k))
+	case *ecdh.PublicKey:
+		oid, raw = oidX25519, k.Bytes()
+	default:
+		return nil, errors.New("keyfile: unsupported public key type")
+	}
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oid},
+		PublicKey: asn1.BitString{Bytes: raw, BitLength: 8 * len(raw)},
+	})
+}
+
+// ParsePKIXPublicKey parses a public key in the PKIX form of RFC 8410,
+// Section 4. It returns an ed25519.PublicKey or an *ecdh.PublicKey.
+//
+// Like crypto/x509, it only checks the length of Ed25519 keys, not that they
+// encode a point.
+func ParsePKIXPublicKey(der []byte) (any, error) {
+	var k subjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &k); err != nil {
+		return nil, errors.New("keyfile: invalid PKIX public key: " + err.Error())
+	} else if len(rest) != 0 {
+		return nil, errors.New("keyfile: trailing data after PKIX public key")
+	}
+	if err := checkAlgorithm(k.Algorithm); err != nil {
+		return nil, err
+	}
+	if k.PublicKey.BitLength != 8*32 {
+		return nil, errors.New("keyfile: bad public key length")
+	}
+	if k.Algorithm.Algorithm.Equal(oidEd25519) {
+		return ed25519.PublicKey(append([]byte{}, k.PublicKey.Bytes...)), nil
+	}
+	return ecdh.X25519().NewPublicKey(k.PublicKey.Bytes)
+}
+
+// checkAlgorithm returns an error if a is not the identifier of Ed25519 or
+// X25519, or has parameters.
+func checkAlgorithm(a pkix.AlgorithmIdentifier) error {
+	if !a.Algorithm.Equal(oidEd25519) && !a.Algorithm.Equal(oidX25519) {
+		return errors.New("keyfile: unsupported algorithm " + a.Algorithm.String())
+	}
+	if len(a.Parameters.FullBytes) != 0 {
+		return errors.New("keyfile: unexpected algorithm parameters")
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keyfile
+
+import (
+	"bytes"
+	stded25519 "crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/ecdh"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func mustDecodeBase64(t *testing.T, s string) []byte {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// Keys generated with OpenSSL 3.0, by openssl genpkey -algorithm ed25519 or
+// x25519, and openssl pkey -pubout.
+const (
+	opensslEd25519Private = "MC4CAQAwBQYDK2VwBCIEIDu26nDpmNIUYqOwW43unIAJJPnH9Yfr2NP26m8XOJwk"
+	opensslEd25519Public  = "MCowBQYDK2VwAyEA+NCa6DISzIRF6sBqOS73yYc0mrUZ53wNVE3ouyW1QCs="
+	opensslX25519Private  = "MC4CAQAwBQYDK2VuBCIEIICXGy7IAqAmO7ThGO1DFhT5nAUAP6Qr8828nfsYaRJf"
+	opensslX25519Public   = "MCowBQYDK2VuAyEAiP0G71bToAw9FaK3w2wOemKQApVKriWruP4PpkHzLzo="
+)
+
+func TestPKCS8Ed25519(t *testing.T) {
+	der := mustDecodeBase64(t, opensslEd25519Private)
+	key, err := ParsePKCS8PrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		t.Fatalf("got %T, want ed25519.PrivateKey", key)
+	}
+	if got := hex.EncodeToString(priv.Seed()); got != "3bb6ea70e998d21462a3b05b8dee9c800924f9c7f587ebd8d3f6ea6f17389c24" {
+		t.Errorf("seed = %s", got)
+	}
+	if got := hex.EncodeToString(priv[ed25519.SeedSize:]); got != "f8d09ae83212cc8445eac06a392ef7c987349ab519e77c0d544de8bb25b5402b" {
+		t.Errorf("public key = %s", got)
+	}
+	out, err := MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, der) {
+		t.Errorf("round trip = %x, want %x", out, der)
+	}
+	if out, _ := MarshalPKCS8PrivateKey(stded25519.PrivateKey(priv)); !bytes.Equal(out, der) {
+		t.Errorf("crypto/ed25519 key = %x, want %x", out, der)
+	}
+	if std, err := x509.MarshalPKCS8PrivateKey(stded25519.PrivateKey(priv)); err != nil || !bytes.Equal(std, der) {
+		t.Errorf("crypto/x509 = %x, %v, want %x", std, err, der)
+	}
+
+	der = mustDecodeBase64(t, opensslEd25519Public)
+	key, err = ParsePKIXPublicKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub, ok := key.(ed25519.PublicKey); !ok || !bytes.Equal(pub, priv[ed25519.SeedSize:]) {
+		t.Fatalf("got %T %x, want the public key of the private key", key, key)
+	}
+	if out, err := MarshalPKIXPublicKey(priv.Public()); err != nil || !bytes.Equal(out, der) {
+		t.Errorf("round trip = %x, %v, want %x", out, err, der)
+	}
+}
+
+func TestPKCS8X25519(t *testing.T) {
+	der := mustDecodeBase64(t, opensslX25519Private)
+	key, err := ParsePKCS8PrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, ok := key.(*ecdh.PrivateKey)
+	if !ok {
+		t.Fatalf("got %T, want *ecdh.PrivateKey", key)
+	}
+	if out, err := MarshalPKCS8PrivateKey(priv); err != nil || !bytes.Equal(out, der) {
+		t.Errorf("round trip = %x, %v, want %x", out, err, der)
+	}
+
+	der = mustDecodeBase64(t, opensslX25519Public)
+	key, err = ParsePKIXPublicKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub, ok := key.(*ecdh.PublicKey); !ok || !pub.Equal(priv.PublicKey()) {
+		t.Fatalf("got %T %v, want the public key of the private key", key, key)
+	}
+	if out, err := MarshalPKIXPublicKey(priv.PublicKey()); err != nil || !bytes.Equal(out, der) {
+		t.Errorf("round trip = %x, %v, want %x", out, err, der)
+	}
+}
+
+func TestPKCS8Version2(t *testing.T) {
+	// RFC 8410, Section 10.3: a OneAsymmetricKey with an attribute and the
+	// public key.
+	der := mustDecodeBase64(t, "MHICAQEwBQYDK2VwBCIEINTuctv5E1hK1bbY8fdp+K06/nwoy/HU++CXqI9EdVhC"+
+		"oB8wHQYKKoZIhvcNAQkJFDEPDA1DdXJkbGUgQ2hhaXJzgSEAGb9ECWmEzf6FQbrB"+
+		"Z9w7lshQhqowtrbLDFw4rXAxZuE=")
+	key, err := ParsePKCS8PrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv := key.(ed25519.PrivateKey)
+	if got := hex.EncodeToString(priv[ed25519.SeedSize:]); got != "19bf44096984cdfe8541bac167dc3b96c85086aa30b6b6cb0c5c38ad703166e1" {
+		t.Errorf("public key = %s", got)
+	}
+
+	// Flip a bit of the public key, the last byte of the encoding.
+	bad := append([]byte{}, der...)
+	bad[len(bad)-1] ^= 1
+	if _, err := ParsePKCS8PrivateKey(bad); err == nil {
+		t.Error("mismatched public key accepted")
+	}
+}
+
+func TestPKCS8Invalid(t *testing.T) {
+	for name, s := range map[string]string{
+		// The Ed25519 key with an X448 OID, 1.3.101.111.
+		"X448": "MC4CAQAwBQYDK2VvBCIEIDu26nDpmNIUYqOwW43unIAJJPnH9Yfr2NP26m8XOJwk",
+		// With a NULL parameter.
+		"parameters": "MDACAQAwBwYDK2VwBQAEIgQgO7bqcOmY0hRio7Bbje6cgAkk+cf1h+vY0/bqbxc4nCQ=",
+		// Version 2.
+		"version": "MC4CAQIwBQYDK2VwBCIEIDu26nDpmNIUYqOwW43unIAJJPnH9Yfr2NP26m8XOJwk",
+		// A 31-byte key.
+		"short": "MC0CAQAwBQYDK2VwBCEEHzu26nDpmNIUYqOwW43unIAJJPnH9Yfr2NP26m8XOJw=",
+		// A bare OCTET STRING instead of a CurvePrivateKey.
+		"not wrapped": "MCwCAQAwBQYDK2VwBCA7tupw6ZjSFGKjsFuN7pyACST5x/WH69jT9upvFzicJA==",
+	} {
+		if _, err := ParsePKCS8PrivateKey(mustDecodeBase64(t, s)); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+	if _, err := ParsePKCS8PrivateKey(append(mustDecodeBase64(t, opensslEd25519Private), 0)); err == nil {
+		t.Error("trailing data accepted")
+	}
+	if _, err := ParsePKIXPublicKey(mustDecodeBase64(t, opensslEd25519Public)[:43]); err == nil {
+		t.Error("truncated public key accepted")
+	}
+	if _, err := MarshalPKCS8PrivateKey(ed25519.PublicKey(make([]byte, 32))); err == nil {
+		t.Error("public key marshaled as a private key")
+	}
+	if _, err := MarshalPKIXPublicKey(ed25519.PublicKey(make([]byte, 31))); err == nil {
+		t.Error("short public key marshaled")
+	}
+}
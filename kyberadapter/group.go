@@ -0,0 +1,47 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kyberadapter implements the go.dedis.ch/kyber/v3 Group, Point and
+// Scalar interfaces on top of the ed25519 package, so that protocols written
+// against kyber can use this implementation of edwards25519.
+//
+// Points and scalars use the same encodings as kyber's own edwards25519
+// group: 32-byte compressed Edwards-Y points and 32-byte little-endian
+// scalars reduced modulo the group order.
+package kyberadapter
+
+import (
+	"go.dedis.ch/kyber/v3"
+)
+
+// Group is the prime-order subgroup of edwards25519, as a kyber.Group. The
+// zero value is ready to use.
+type Group struct{}
+
+var _ kyber.Group = Group{}
+
+// String returns the name of the group.
+func (Group) String() string {
+	return "Ed25519"
+}
+
+// ScalarLen returns the length of an encoded scalar, 32 bytes.
+func (Group) ScalarLen() int {
+	return 32
+}
+
+// Scalar returns a new scalar set to zero.
+func (Group) Scalar() kyber.Scalar {
+	return new(scalar)
+}
+
+// PointLen returns the length of an encoded point, 32 bytes.
+func (Group) PointLen() int {
+	return 32
+}
+
+// Point returns a new point set to the identity.
+func (Group) Point() kyber.Point {
+	return new(point).Null()
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kyberadapter
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+func randomStream(t *testing.T) cipher.Stream {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cipher.NewCTR(block, make([]byte, aes.BlockSize))
+}
+
+func TestScalarArithmetic(t *testing.T) {
+	var g kyber.Group = Group{}
+	stream := randomStream(t)
+
+	a := g.Scalar().Pick(stream)
+	b := g.Scalar().Pick(stream)
+	one := g.Scalar().One()
+
+	if !g.Scalar().Sub(g.Scalar().Add(a, b), b).Equal(a) {
+		t.Error("(a + b) - b != a")
+	}
+	if !g.Scalar().Add(a, g.Scalar().Neg(a)).Equal(g.Scalar().Zero()) {
+		t.Error("a + (-a) != 0")
+	}
+	if !g.Scalar().Mul(a, g.Scalar().Inv(a)).Equal(one) {
+		t.Error("a * (1/a) != 1")
+	}
+	if !g.Scalar().Mul(g.Scalar().Div(a, b), b).Equal(a) {
+		t.Error("(a / b) * b != a")
+	}
+	if !g.Scalar().Add(one, one).Equal(g.Scalar().SetInt64(2)) {
+		t.Error("1 + 1 != 2")
+	}
+	if !g.Scalar().SetInt64(-1).Equal(g.Scalar().Neg(one)) {
+		t.Error("SetInt64(-1) != -1")
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := a.MarshalTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	c := g.Scalar()
+	if _, err := c.UnmarshalFrom(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Equal(a) {
+		t.Error("scalar did not survive MarshalTo/UnmarshalFrom")
+	}
+
+	// L itself is not a canonical encoding.
+	l := []byte{0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+		0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10}
+	if err := c.UnmarshalBinary(l); err == nil {
+		t.Error("unmarshaled a non-canonical scalar")
+	}
+	if !g.Scalar().SetBytes(l).Equal(g.Scalar().Zero()) {
+		t.Error("SetBytes(L) != 0")
+	}
+}
+
+func TestPointArithmetic(t *testing.T) {
+	var g kyber.Group = Group{}
+	stream := randomStream(t)
+
+	a := g.Scalar().Pick(stream)
+	b := g.Scalar().Pick(stream)
+	B := g.Point().Base()
+
+	aB := g.Point().Mul(a, nil)
+	if !aB.Equal(g.Point().Mul(a, B)) {
+		t.Error("Mul with a nil point disagrees with Mul with the base point")
+	}
+	// a*(b*B) = (a*b)*B
+	if !g.Point().Mul(a, g.Point().Mul(b, nil)).Equal(g.Point().Mul(g.Scalar().Mul(a, b), nil)) {
+		t.Error("a*(b*B) != (a*b)*B")
+	}
+	// a*B + b*B = (a+b)*B
+	if !g.Point().Add(aB, g.Point().Mul(b, nil)).Equal(g.Point().Mul(g.Scalar().Add(a, b), nil)) {
+		t.Error("a*B + b*B != (a+b)*B")
+	}
+	if !g.Point().Sub(aB, aB).Equal(g.Point().Null()) {
+		t.Error("a*B - a*B != 0")
+	}
+	if !g.Point().Add(aB, g.Point().Neg(aB)).Equal(g.Point()) {
+		t.Error("a*B + (-a*B) != 0")
+	}
+
+	data, err := aB.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	P := g.Point()
+	if err := P.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !P.Equal(aB) || P.String() != aB.String() {
+		t.Error("point did not survive MarshalBinary/UnmarshalBinary")
+	}
+
+	Q := aB.Clone()
+	Q.Add(Q, B)
+	if Q.Equal(aB) {
+		t.Error("modifying a clone changed the original")
+	}
+}
+
+func TestPointEmbed(t *testing.T) {
+	var g kyber.Group = Group{}
+	stream := randomStream(t)
+
+	P := g.Point().Pick(stream)
+	if P.Equal(g.Point().Null()) {
+		t.Error("picked the identity")
+	}
+	// (L-1)*P + P = L*P is the identity only in the prime-order subgroup.
+	minusOne := g.Scalar().SetInt64(-1)
+	if !g.Point().Add(g.Point().Mul(minusOne, P), P).Equal(g.Point().Null()) {
+		t.Error("picked point is not in the prime-order subgroup")
+	}
+
+	msg := []byte("embedded message")
+	E := g.Point().Embed(msg, stream)
+	got, err := E.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Errorf("got embedded data %q, want %q", got, msg)
+	}
+	if !g.Point().Add(g.Point().Mul(minusOne, E), E).Equal(g.Point().Null()) {
+		t.Error("embedding point is not in the prime-order subgroup")
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kyberadapter
+
+import (
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/gtank/ed25519"
+)
+
+type point struct {
+	p ed25519.Point
+}
+
+var _ kyber.Point = (*point)(nil)
+
+// toPoint returns a as a *point. Like kyber's own groups, it panics on points
+// from a different group.
+func toPoint(a kyber.Point) *point {
+	p, ok := a.(*point)
+	if !ok {
+		panic("kyberadapter: incompatible point type")
+	}
+	return p
+}
+
+func (P *point) Equal(P2 kyber.Point) bool {
+	return P.p.Equal(&toPoint(P2).p) == 1
+}
+
+func (P *point) Null() kyber.Point {
+	P.p.Set(ed25519.NewIdentityPoint())
+	return P
+}
+
+func (P *point) Base() kyber.Point {
+	P.p.Set(ed25519.NewGeneratorPoint())
+	return P
+}
+
+func (P *point) Set(P2 kyber.Point) kyber.Point {
+	P.p.Set(&toPoint(P2).p)
+	return P
+}
+
+func (P *point) Clone() kyber.Point {
+	return new(point).Set(P)
+}
+
+func (P *point) Add(P1, P2 kyber.Point) kyber.Point {
+	P.p.Add(&toPoint(P1).p, &toPoint(P2).p)
+	return P
+}
+
+func (P *point) Sub(P1, P2 kyber.Point) kyber.Point {
+	P.p.Sub(&toPoint(P1).p, &toPoint(P2).p)
+	return P
+}
+
+func (P *point) Neg(A kyber.Point) kyber.Point {
+	P.p.Neg(&toPoint(A).p)
+	return P
+}
+
+// Mul sets P = s*A. If A is nil, it uses the base point, with the faster
+// fixed-base multiplication.
+func (P *point) Mul(s kyber.Scalar, A kyber.Point) kyber.Point {
+	k := toScalar(s).bytes()
+	if A == nil {
+		P.p.ScalarBaseMult(k)
+		return P
+	}
+	P.p.ScalarMult(k, &toPoint(A).p)
+	return P
+}
+
+// EmbedLen returns the number of bytes of data Embed can store in a point.
+// The first byte of the encoding holds the data length and the last one is
+// left random, as in kyber's edwards25519.
+func (P *point) EmbedLen() int {
+	return (255 - 8 - 8) / 8
+}
+
+// Embed sets P to a random point in the prime-order subgroup whose encoding
+// holds up to EmbedLen bytes of data. With nil data, it picks a uniformly
+// random point.
+func (P *point) Embed(data []byte, rand cipher.Stream) kyber.Point {
+	dl := P.EmbedLen()
+	if dl > len(data) {
+		dl = len(data)
+	}
+
+	for {
+		var b [32]byte
+		rand.XORKeyStream(b[:], b[:])
+		if data != nil {
+			b[0] = byte(dl)
+			copy(b[1:1+dl], data)
+		}
+		if _, err := P.p.SetBytes(b[:]); err != nil {
+			continue
+		}
+
+		// Without data, clearing the cofactor maps any point into the
+		// subgroup. With data, the encoding can't change, so keep trying
+		// until the point is in the subgroup.
+		if data == nil {
+			P.p.MultByCofactor(&P.p)
+			if P.p.IsIdentity() == 1 {
+				continue
+			}
+			return P
+		}
+		if P.p.IsTorsionFree() == 1 {
+			return P
+		}
+	}
+}
+
+func (P *point) Pick(rand cipher.Stream) kyber.Point {
+	return P.Embed(nil, rand)
+}
+
+// Data returns the data embedded in P by Embed.
+func (P *point) Data() ([]byte, error) {
+	b := P.p.Bytes()
+	dl := int(b[0])
+	if dl > P.EmbedLen() {
+		return nil, errors.New("kyberadapter: invalid embedded data length")
+	}
+	return b[1 : 1+dl], nil
+}
+
+func (P *point) String() string {
+	return hex.EncodeToString(P.p.Bytes())
+}
+
+func (P *point) MarshalSize() int {
+	return 32
+}
+
+func (P *point) MarshalBinary() ([]byte, error) {
+	return P.p.Bytes(), nil
+}
+
+func (P *point) UnmarshalBinary(data []byte) error {
+	_, err := P.p.SetBytes(data)
+	return err
+}
+
+func (P *point) MarshalTo(w io.Writer) (int, error) {
+	return w.Write(P.p.Bytes())
+}
+
+func (P *point) UnmarshalFrom(r io.Reader) (int, error) {
+	b := make([]byte, P.MarshalSize())
+	n, err := io.ReadFull(r, b)
+	if err != nil {
+		return n, err
+	}
+	return n, P.UnmarshalBinary(b)
+}
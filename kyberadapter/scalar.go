@@ -0,0 +1,178 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kyberadapter
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/gtank/ed25519"
+)
+
+// scalar is an integer modulo the group order L, always kept reduced.
+type scalar struct {
+	v big.Int
+}
+
+var _ kyber.Scalar = (*scalar)(nil)
+
+func groupOrder() *big.Int {
+	return ed25519.Ed25519().Params().N
+}
+
+// toScalar returns a as a *scalar. Like kyber's own groups, it panics on
+// scalars from a different group.
+func toScalar(a kyber.Scalar) *scalar {
+	s, ok := a.(*scalar)
+	if !ok {
+		panic("kyberadapter: incompatible scalar type")
+	}
+	return s
+}
+
+func (s *scalar) Equal(s2 kyber.Scalar) bool {
+	a, _ := s.MarshalBinary()
+	b, _ := toScalar(s2).MarshalBinary()
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+func (s *scalar) Set(a kyber.Scalar) kyber.Scalar {
+	s.v.Set(&toScalar(a).v)
+	return s
+}
+
+func (s *scalar) Clone() kyber.Scalar {
+	return new(scalar).Set(s)
+}
+
+func (s *scalar) SetInt64(v int64) kyber.Scalar {
+	s.v.SetInt64(v)
+	s.v.Mod(&s.v, groupOrder())
+	return s
+}
+
+func (s *scalar) Zero() kyber.Scalar {
+	s.v.SetInt64(0)
+	return s
+}
+
+func (s *scalar) One() kyber.Scalar {
+	s.v.SetInt64(1)
+	return s
+}
+
+func (s *scalar) Add(a, b kyber.Scalar) kyber.Scalar {
+	s.v.Add(&toScalar(a).v, &toScalar(b).v)
+	s.v.Mod(&s.v, groupOrder())
+	return s
+}
+
+func (s *scalar) Sub(a, b kyber.Scalar) kyber.Scalar {
+	s.v.Sub(&toScalar(a).v, &toScalar(b).v)
+	s.v.Mod(&s.v, groupOrder())
+	return s
+}
+
+func (s *scalar) Neg(a kyber.Scalar) kyber.Scalar {
+	s.v.Neg(&toScalar(a).v)
+	s.v.Mod(&s.v, groupOrder())
+	return s
+}
+
+func (s *scalar) Mul(a, b kyber.Scalar) kyber.Scalar {
+	s.v.Mul(&toScalar(a).v, &toScalar(b).v)
+	s.v.Mod(&s.v, groupOrder())
+	return s
+}
+
+// Div sets s = a / b. Dividing by zero gives zero.
+func (s *scalar) Div(a, b kyber.Scalar) kyber.Scalar {
+	var inv scalar
+	inv.Inv(b)
+	return s.Mul(a, &inv)
+}
+
+// Inv sets s = 1 / a. The inverse of zero is zero.
+func (s *scalar) Inv(a kyber.Scalar) kyber.Scalar {
+	if toScalar(a).v.Sign() == 0 {
+		return s.Zero()
+	}
+	s.v.ModInverse(&toScalar(a).v, groupOrder())
+	return s
+}
+
+// Pick sets s to a uniformly random scalar, reducing 64 bytes of key stream
+// so that the bias is negligible.
+func (s *scalar) Pick(rand cipher.Stream) kyber.Scalar {
+	var b [64]byte
+	rand.XORKeyStream(b[:], b[:])
+	return s.setBytesWide(b[:])
+}
+
+// SetBytes sets s to the little-endian integer b reduced modulo L.
+func (s *scalar) SetBytes(b []byte) kyber.Scalar {
+	return s.setBytesWide(b)
+}
+
+func (s *scalar) setBytesWide(b []byte) *scalar {
+	s.v.Set(ed25519.ScalarFromLittleEndian(b))
+	s.v.Mod(&s.v, groupOrder())
+	return s
+}
+
+func (s *scalar) String() string {
+	b, _ := s.MarshalBinary()
+	return hex.EncodeToString(b)
+}
+
+func (s *scalar) MarshalSize() int {
+	return 32
+}
+
+// MarshalBinary returns the 32-byte little-endian encoding of s.
+func (s *scalar) MarshalBinary() ([]byte, error) {
+	return ed25519.ScalarToLittleEndian(&s.v), nil
+}
+
+// UnmarshalBinary sets s to the 32-byte little-endian encoding data. It
+// rejects values that are not reduced modulo L.
+func (s *scalar) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return errors.New("kyberadapter: invalid scalar length")
+	}
+	var t scalar
+	t.setBytesWide(data)
+	if b, _ := t.MarshalBinary(); subtle.ConstantTimeCompare(b, data) != 1 {
+		return errors.New("kyberadapter: non-canonical scalar encoding")
+	}
+	s.v.Set(&t.v)
+	return nil
+}
+
+func (s *scalar) MarshalTo(w io.Writer) (int, error) {
+	b, _ := s.MarshalBinary()
+	return w.Write(b)
+}
+
+func (s *scalar) UnmarshalFrom(r io.Reader) (int, error) {
+	b := make([]byte, s.MarshalSize())
+	n, err := io.ReadFull(r, b)
+	if err != nil {
+		return n, err
+	}
+	return n, s.UnmarshalBinary(b)
+}
+
+// bytes returns the little-endian encoding of s, for ed25519.Point.
+func (s *scalar) bytes() []byte {
+	b, _ := s.MarshalBinary()
+	return b
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"encoding/hex"
+	"encoding/json"
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the same
+// 32-byte encoding as Bytes.
+func (v *Point) MarshalBinary() ([]byte, error) {
+	return v.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It accepts the same
+// encodings as SetBytes.
+func (v *Point) UnmarshalBinary(data []byte) error {
+	_, err := v.SetBytes(data)
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler. The text form of a point is
+// the lowercase hex encoding of Bytes.
+func (v *Point) MarshalText() ([]byte, error) {
+	b := v.Bytes()
+	out := make([]byte, hex.EncodedLen(len(b)))
+	hex.Encode(out, b)
+	return out, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts upper or
+// lowercase hex.
+func (v *Point) UnmarshalText(text []byte) error {
+	b := make([]byte, hex.DecodedLen(len(text)))
+	if _, err := hex.Decode(b, text); err != nil {
+		return err
+	}
+	return v.UnmarshalBinary(b)
+}
+
+// MarshalJSON implements json.Marshaler. A point is a JSON string holding its
+// text form.
+func (v *Point) MarshalJSON() ([]byte, error) {
+	text, _ := v.MarshalText()
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *Point) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
+// String returns the lowercase hex encoding of Bytes, so that scalars print
+// the same way in logs and transcripts as in their text form.
+func (s *Scalar) String() string {
+	return hex.EncodeToString(s.Bytes())
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns the same
+// 32-byte encoding as Bytes.
+func (s *Scalar) MarshalBinary() ([]byte, error) {
+	return s.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It accepts the same
+// canonical encodings as SetCanonicalBytes.
+func (s *Scalar) UnmarshalBinary(data []byte) error {
+	_, err := s.SetCanonicalBytes(data)
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler. The text form of a scalar is
+// String.
+func (s *Scalar) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts upper or
+// lowercase hex.
+func (s *Scalar) UnmarshalText(text []byte) error {
+	b := make([]byte, hex.DecodedLen(len(text)))
+	if _, err := hex.Decode(b, text); err != nil {
+		return err
+	}
+	return s.UnmarshalBinary(b)
+}
+
+// MarshalJSON implements json.Marshaler. A scalar is a JSON string holding
+// its text form.
+func (s *Scalar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Scalar) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return s.UnmarshalText([]byte(text))
+}
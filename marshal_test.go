@@ -0,0 +1,154 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Point)(nil)
+	_ encoding.BinaryUnmarshaler = (*Point)(nil)
+	_ encoding.TextMarshaler     = (*Point)(nil)
+	_ encoding.TextUnmarshaler   = (*Point)(nil)
+	_ json.Marshaler             = (*Point)(nil)
+	_ json.Unmarshaler           = (*Point)(nil)
+
+	_ fmt.Stringer               = (*Scalar)(nil)
+	_ encoding.BinaryMarshaler   = (*Scalar)(nil)
+	_ encoding.BinaryUnmarshaler = (*Scalar)(nil)
+	_ encoding.TextMarshaler     = (*Scalar)(nil)
+	_ encoding.TextUnmarshaler   = (*Scalar)(nil)
+	_ json.Marshaler             = (*Scalar)(nil)
+	_ json.Unmarshaler           = (*Scalar)(nil)
+)
+
+func TestPointMarshalBinary(t *testing.T) {
+	P := new(Point).ScalarBaseMult(randomScalar(t))
+	data, err := P.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var Q Point
+	if err := Q.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if P.Equal(&Q) != 1 {
+		t.Error("point did not survive binary round trip")
+	}
+	if err := Q.UnmarshalBinary(data[:31]); err == nil {
+		t.Error("unmarshaled a short encoding")
+	}
+}
+
+func TestPointMarshalText(t *testing.T) {
+	text, err := NewGeneratorPoint().MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "5866666666666666666666666666666666666666666666666666666666666666"
+	if string(text) != want {
+		t.Errorf("got %s, want %s", text, want)
+	}
+
+	var Q Point
+	if err := Q.UnmarshalText(bytes.ToUpper(text)); err != nil {
+		t.Fatal(err)
+	}
+	if Q.Equal(NewGeneratorPoint()) != 1 {
+		t.Error("point did not survive text round trip")
+	}
+	if err := Q.UnmarshalText([]byte("zz" + want[2:])); err == nil {
+		t.Error("unmarshaled invalid hex")
+	}
+}
+
+func TestPointMarshalJSON(t *testing.T) {
+	type config struct {
+		Key *Point `json:"key"`
+	}
+	in := config{Key: new(Point).ScalarBaseMult(randomScalar(t))}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, _ := in.Key.MarshalText()
+	if !strings.Contains(string(data), `"key":"`+string(text)+`"`) {
+		t.Errorf("unexpected JSON %s", data)
+	}
+
+	var out config
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Key.Equal(in.Key) != 1 {
+		t.Error("point did not survive JSON round trip")
+	}
+
+	if err := json.Unmarshal([]byte(`{"key":42}`), &out); err == nil {
+		t.Error("unmarshaled a number as a point")
+	}
+}
+
+func TestScalarMarshal(t *testing.T) {
+	s := randomScalarElement(t)
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s2 Scalar
+	if err := s2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if s.Equal(&s2) != 1 {
+		t.Error("scalar did not survive binary round trip")
+	}
+	if err := s2.UnmarshalBinary(scalarOrderBytes); err == nil {
+		t.Error("unmarshaled a non-canonical scalar")
+	}
+
+	one, _ := NewScalar().SetCanonicalBytes(append([]byte{1}, make([]byte, 31)...))
+	want := "0100000000000000000000000000000000000000000000000000000000000000"
+	if got := fmt.Sprint(one); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if err := s2.UnmarshalText([]byte(strings.ToUpper(want))); err != nil {
+		t.Fatal(err)
+	}
+	if s2.Equal(one) != 1 {
+		t.Error("scalar did not survive text round trip")
+	}
+}
+
+func TestScalarMarshalJSON(t *testing.T) {
+	type transcript struct {
+		Challenge *Scalar `json:"c"`
+	}
+	in := transcript{Challenge: randomScalarElement(t)}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"c":"` + in.Challenge.String() + `"}`; string(data) != want {
+		t.Errorf("got JSON %s, want %s", data, want)
+	}
+
+	var out transcript
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Challenge.Equal(in.Challenge) != 1 {
+		t.Error("scalar did not survive JSON round trip")
+	}
+
+	if err := json.Unmarshal([]byte(`{"c":"zz"}`), &out); err == nil {
+		t.Error("unmarshaled invalid hex as a scalar")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+// montgomeryA is the A coefficient of curve25519, v^2 = u^3 + A*u^2 + u.
+var montgomeryA = big.NewInt(486662)
+
+// EdwardsToMontgomery returns the point (u, v) on curve25519 that is
+// birationally equivalent to the affine edwards25519 point (x, y), as in RFC
+// 7748, Section 4.1. The identity has no image, since it corresponds to the
+// point at infinity; it maps to (0, 0), the same as the point (0, -1).
+// EdwardsToMontgomery panics if (x, y) is not on the curve.
+func EdwardsToMontgomery(x, y *big.Int) (u, v *big.Int) {
+	if !Ed25519().IsOnCurve(x, y) {
+		panic("ed25519: EdwardsToMontgomery called with off-curve point")
+	}
+
+	var xe, ye, ue, ve radix51.FieldElement
+	xe.FromBig(x)
+	ye.FromBig(y)
+	group.EdwardsToMontgomery(&ue, &ve, &xe, &ye)
+	return ue.ToBig(), ve.ToBig()
+}
+
+// MontgomeryToEdwards returns the affine edwards25519 point (x, y) that is
+// birationally equivalent to the point (u, v) on curve25519, the inverse of
+// EdwardsToMontgomery. MontgomeryToEdwards panics if (u, v) is not on
+// curve25519.
+func MontgomeryToEdwards(u, v *big.Int) (x, y *big.Int) {
+	if !isOnMontgomery(u, v) {
+		panic("ed25519: MontgomeryToEdwards called with off-curve point")
+	}
+
+	var ue, ve, xe, ye radix51.FieldElement
+	ue.FromBig(u)
+	ve.FromBig(v)
+	group.MontgomeryToEdwards(&xe, &ye, &ue, &ve)
+	return xe.ToBig(), ye.ToBig()
+}
+
+// isOnMontgomery reports whether (u, v) is a point on curve25519 with
+// coordinates in [0, p).
+func isOnMontgomery(u, v *big.Int) bool {
+	p := Ed25519().Params().P
+	if u.Sign() < 0 || u.Cmp(p) >= 0 || v.Sign() < 0 || v.Cmp(p) >= 0 {
+		return false
+	}
+
+	// v^2 = u^3 + A*u^2 + u = u*(u*(u + A) + 1)
+	rhs := new(big.Int).Add(u, montgomeryA)
+	rhs.Mul(rhs, u)
+	rhs.Add(rhs, bigOne)
+	rhs.Mul(rhs, u)
+	rhs.Mod(rhs, p)
+
+	lhs := new(big.Int).Mul(v, v)
+	lhs.Mod(lhs, p)
+
+	return lhs.Cmp(rhs) == 0
+}
+
+// EdwardsToMontgomeryBytes converts a 32-byte compressed edwards25519 point,
+// such as an Ed25519 public key, to the 32-byte u-coordinate of the
+// equivalent curve25519 point, such as an X25519 public key. It returns an
+// error if edwards is not a valid encoding.
+func EdwardsToMontgomeryBytes(edwards []byte) ([]byte, error) {
+	p, err := new(Point).SetBytes(edwards)
+	if err != nil {
+		return nil, err
+	}
+	return p.BytesMontgomery(), nil
+}
+
+// MontgomeryToEdwardsBytes converts the 32-byte u-coordinate of a curve25519
+// point to the compressed encoding of the equivalent edwards25519 point. The
+// u-coordinate determines y but not the sign of x, which is given by signBit,
+// 0 or 1, as in XEdDSA.
+//
+// As in X25519, the top bit of u is ignored and non-canonical values are
+// accepted. MontgomeryToEdwardsBytes returns an error if u is not the
+// u-coordinate of a point on curve25519 (rather than its twist), or if u = -1
+// which has no Edwards equivalent.
+func MontgomeryToEdwardsBytes(u []byte, signBit int) ([]byte, error) {
+	if len(u) != 32 {
+		return nil, errors.New("ed25519: invalid u-coordinate length")
+	}
+	if signBit&^1 != 0 {
+		panic("ed25519: invalid sign bit")
+	}
+
+	var ue, n, d, y radix51.FieldElement
+	ue.FromBytes(u)
+
+	// y = (u - 1) / (u + 1)
+	n.Sub(&ue, radix51.One)
+	d.Add(&ue, radix51.One)
+	if d.Equal(radix51.Zero) == 1 {
+		return nil, errors.New("ed25519: u-coordinate has no Edwards equivalent")
+	}
+	y.Mul(&n, d.Invert(&d))
+
+	out := make([]byte, 32)
+	y.ToBytes(out)
+	out[31] |= byte(signBit) << 7
+
+	// Decoding checks that y belongs to a point on the curve.
+	if _, err := new(Point).SetBytes(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"math/big"
+	"testing"
+)
+
+func TestEdwardsToMontgomeryBasepoint(t *testing.T) {
+	c := Ed25519()
+	u, v := EdwardsToMontgomery(c.Params().Gx, c.Params().Gy)
+
+	// The curve25519 base point, from RFC 7748, Section 4.1.
+	wantV, _ := new(big.Int).SetString("14781619447589544791020593568409986887264606134616475288964881837755586237401", 10)
+	if u.Cmp(big.NewInt(9)) != 0 || v.Cmp(wantV) != 0 {
+		t.Errorf("B maps to (%v, %v)", u, v)
+	}
+
+	x, y := MontgomeryToEdwards(u, v)
+	if x.Cmp(c.Params().Gx) != 0 || y.Cmp(c.Params().Gy) != 0 {
+		t.Error("curve25519 base point does not map back to B")
+	}
+}
+
+func TestEdwardsToMontgomeryRoundTrip(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		x, y := new(Point).ScalarBaseMult(randomScalar(t)).p.ToAffine()
+		u, v := EdwardsToMontgomery(x, y)
+		if !isOnMontgomery(u, v) {
+			t.Fatal("image is not on curve25519")
+		}
+		x2, y2 := MontgomeryToEdwards(u, v)
+		if x.Cmp(x2) != 0 || y.Cmp(y2) != 0 {
+			t.Error("point did not survive the round trip")
+		}
+	}
+
+	// (0, -1) and (0, 0) are exceptional points of the maps.
+	minusOne := new(big.Int).Sub(Ed25519().Params().P, bigOne)
+	u, v := EdwardsToMontgomery(big.NewInt(0), minusOne)
+	if u.Sign() != 0 || v.Sign() != 0 {
+		t.Errorf("(0, -1) maps to (%v, %v)", u, v)
+	}
+	x, y := MontgomeryToEdwards(big.NewInt(0), big.NewInt(0))
+	if x.Sign() != 0 || y.Cmp(minusOne) != 0 {
+		t.Errorf("(0, 0) maps to (%v, %v)", x, y)
+	}
+}
+
+func TestMontgomeryToEdwardsOffCurve(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MontgomeryToEdwards accepted an off-curve point")
+		}
+	}()
+	MontgomeryToEdwards(big.NewInt(9), big.NewInt(9))
+}
+
+func TestMontgomeryBytes(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		clamped := ClampScalarBytes([32]byte(randomScalar(t)))
+		k := clamped[:]
+		priv, err := ecdh.X25519().NewPrivateKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		A := new(Point).ScalarBaseMult(k)
+
+		u, err := EdwardsToMontgomeryBytes(A.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(u, priv.PublicKey().Bytes()) {
+			t.Errorf("got u = %x, want %x", u, priv.PublicKey().Bytes())
+		}
+
+		signBit := int(A.Bytes()[31] >> 7)
+		edwards, err := MontgomeryToEdwardsBytes(u, signBit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(edwards, A.Bytes()) {
+			t.Errorf("got %x, want %x", edwards, A.Bytes())
+		}
+	}
+}
+
+func TestMontgomeryToEdwardsBytesInvalid(t *testing.T) {
+	// u = 2 is on the twist: u^3 + A*u^2 + u is not square.
+	u := make([]byte, 32)
+	u[0] = 2
+	if _, err := MontgomeryToEdwardsBytes(u, 0); err == nil {
+		t.Error("converted a point on the twist")
+	}
+
+	// u = -1 would give y = 1/0.
+	minusOne := bytes.Repeat([]byte{0xff}, 32)
+	minusOne[0] = 0xec
+	minusOne[31] = 0x7f
+	if _, err := MontgomeryToEdwardsBytes(minusOne, 0); err == nil {
+		t.Error("converted u = -1")
+	}
+
+	if _, err := MontgomeryToEdwardsBytes(u[:31], 0); err == nil {
+		t.Error("converted a short u-coordinate")
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"github.com/gtank/ed25519/internal/group"
+)
+
+// VartimeMultiScalarMult sets v = sum(scalars[i] * points[i]), and returns v.
+// Each scalar is a 32-byte little-endian integer. It panics if the number of
+// scalars and points differ.
+//
+// Straus' method is used for small inputs and Pippenger's bucket method for
+// large ones, which is what makes batch verification and proof verification
+// cheaper than one ScalarMult per term.
+//
+// Execution time depends on the inputs, so this must only be used with public
+// scalars.
+func (v *Point) VartimeMultiScalarMult(scalars [][]byte, points []*Point) *Point {
+	s, p := multiScalarArgs(scalars, points)
+	v.p.VartimeMultiScalarMult(s, p)
+	return v
+}
+
+// MultiScalarMult sets v = sum(scalars[i] * points[i]), and returns v. Each
+// scalar is a 32-byte little-endian integer. It panics if the number of
+// scalars and points differ.
+//
+// Unlike VartimeMultiScalarMult, this runs in constant time with respect to
+// the scalars, so it can be used with secret values, for example to compute
+// Pedersen vector commitments.
+func (v *Point) MultiScalarMult(scalars [][]byte, points []*Point) *Point {
+	s, p := multiScalarArgs(scalars, points)
+	v.p.MultiScalarMult(s, p)
+	return v
+}
+
+// multiScalarArgs converts the arguments of the multiscalar multiplication
+// methods to the form the group package takes.
+func multiScalarArgs(scalars [][]byte, points []*Point) ([]*[32]byte, []*group.ExtendedGroupElement) {
+	if len(scalars) != len(points) {
+		panic("ed25519: called multiscalar multiplication with different size inputs")
+	}
+
+	s := make([]*[32]byte, len(scalars))
+	p := make([]*group.ExtendedGroupElement, len(points))
+	for i := range scalars {
+		if len(scalars[i]) != 32 {
+			panic("ed25519: invalid scalar length")
+		}
+		s[i] = new([32]byte)
+		copy(s[i][:], scalars[i])
+		p[i] = &points[i].p
+	}
+	return s, p
+}
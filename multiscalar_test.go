@@ -0,0 +1,99 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+// naiveMultiScalarMult computes sum(scalars[i] * points[i]) one term at a
+// time, as a reference.
+func naiveMultiScalarMult(t testing.TB, scalars [][]byte, points []*Point) *Point {
+	B := basepoint(t)
+	acc := new(Point).Sub(B, B)
+	for i := range scalars {
+		acc.Add(acc, new(Point).ScalarMult(scalars[i], points[i]))
+	}
+	return acc
+}
+
+func randomMultiScalarInput(t testing.TB, n int) ([][]byte, []*Point) {
+	scalars := make([][]byte, n)
+	points := make([]*Point, n)
+	for i := 0; i < n; i++ {
+		scalars[i] = randomScalar(t)
+		points[i] = new(Point).ScalarBaseMult(randomScalar(t))
+	}
+	return scalars, points
+}
+
+func TestVartimeMultiScalarMult(t *testing.T) {
+	sizes := []int{0, 1, 2, 16}
+	if !testing.Short() {
+		// Large enough to take the Pippenger path.
+		sizes = append(sizes, 200)
+	}
+
+	for _, n := range sizes {
+		scalars, points := randomMultiScalarInput(t, n)
+		got := new(Point).VartimeMultiScalarMult(scalars, points)
+		want := naiveMultiScalarMult(t, scalars, points)
+		if got.Equal(want) != 1 {
+			t.Errorf("n = %d: VartimeMultiScalarMult disagrees with ScalarMult", n)
+		}
+	}
+}
+
+func TestVartimeMultiScalarMultUnreduced(t *testing.T) {
+	// All-ones scalars exercise the carry into the extra NAF and radix digit.
+	scalars, points := randomMultiScalarInput(t, 3)
+	for i := range scalars {
+		scalars[i] = bytes.Repeat([]byte{0xff}, 32)
+	}
+
+	got := new(Point).VartimeMultiScalarMult(scalars, points)
+	want := naiveMultiScalarMult(t, scalars, points)
+	if got.Equal(want) != 1 {
+		t.Error("VartimeMultiScalarMult disagrees with ScalarMult for 2^256-1")
+	}
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 16} {
+		scalars, points := randomMultiScalarInput(t, n)
+		got := new(Point).MultiScalarMult(scalars, points)
+		want := naiveMultiScalarMult(t, scalars, points)
+		if got.Equal(want) != 1 {
+			t.Errorf("n = %d: MultiScalarMult disagrees with ScalarMult", n)
+		}
+
+		vartime := new(Point).VartimeMultiScalarMult(scalars, points)
+		if got.Equal(vartime) != 1 {
+			t.Errorf("n = %d: MultiScalarMult disagrees with VartimeMultiScalarMult", n)
+		}
+	}
+}
+
+func BenchmarkMultiScalarMult(b *testing.B) {
+	scalars, points := randomMultiScalarInput(b, 16)
+	var v Point
+	for i := 0; i < b.N; i++ {
+		v.MultiScalarMult(scalars, points)
+	}
+}
+
+func BenchmarkVartimeMultiScalarMult(b *testing.B) {
+	for _, n := range []int{16, 256} {
+		scalars, points := randomMultiScalarInput(b, n)
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			var v Point
+			for i := 0; i < b.N; i++ {
+				v.VartimeMultiScalarMult(scalars, points)
+			}
+		})
+	}
+}
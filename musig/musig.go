@@ -0,0 +1,300 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package musig implements MuSig2 multi-signatures that verify as ordinary
+// Ed25519 signatures.
+//
+// n signers aggregate their Ed25519 public keys into a single key, and then
+// jointly produce signatures for it in two rounds: each signer publishes a
+// pair of nonce commitments, and once all of them are known, a partial
+// signature. The partial signatures add up to a 64-byte signature that
+// Ed25519 verifiers, including crypto/ed25519, accept for the aggregate key
+// without knowing it's shared. All n signers are needed for every signature.
+//
+// Key aggregation follows MuSig2: with L the hash of all the keys, signer i
+// has coefficient a_i = H(L, X_i), and the aggregate key is X = sum(a_i*X_i).
+// The coefficients defeat rogue-key attacks, so keys don't need proofs of
+// possession. The nonce of the signature is R = R_1 + b*R_2, where R_1 and
+// R_2 are the sums of the signers' first and second nonce commitments and
+// b = H(X, R_1, R_2, M), and its challenge is the Ed25519 one,
+// c = SHA-512(R || X || M).
+//
+// Secret nonces must never be reused: signing two different messages with the
+// same SecretNonce reveals the private key. SecretNonce.Sign erases it.
+package musig
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+)
+
+// Sizes of the protocol messages, in bytes.
+const (
+	// PublicNonceSize is the size of a signer's public nonce, or of an
+	// aggregate nonce: two compressed points.
+	PublicNonceSize = 64
+	// PartialSignatureSize is the size of a partial signature, a scalar.
+	PartialSignatureSize = 32
+)
+
+// Domain separation prefixes of the hash functions.
+const (
+	keyListDomain = "gtank/ed25519/musig2-v1 key list"
+	keyCoefDomain = "gtank/ed25519/musig2-v1 key coefficient"
+	nonceDomain   = "gtank/ed25519/musig2-v1 nonce coefficient"
+)
+
+// hashToScalar returns SHA-512(domain || parts...) reduced modulo L.
+func hashToScalar(domain string, parts ...[]byte) *ed25519.Scalar {
+	h := sha512.New()
+	h.Write([]byte(domain))
+	for _, p := range parts {
+		h.Write(p)
+	}
+	s, _ := ed25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	return s
+}
+
+// KeyAggContext is the result of aggregating a list of public keys.
+type KeyAggContext struct {
+	publicKeys [][]byte
+	coeffs     []ed25519.Scalar
+	key        ed25519.Point
+	keyBytes   []byte
+}
+
+// AggregateKeys aggregates publicKeys into a single key. The order of the keys
+// matters, so all signers must use the same list. It returns an error if the
+// list is empty, or if a key is not a valid encoding of a point in the
+// prime-order subgroup.
+func AggregateKeys(publicKeys []ed25519.PublicKey) (*KeyAggContext, error) {
+	if len(publicKeys) == 0 {
+		return nil, errors.New("musig: no public keys")
+	}
+	ctx := &KeyAggContext{
+		publicKeys: make([][]byte, len(publicKeys)),
+		coeffs:     make([]ed25519.Scalar, len(publicKeys)),
+	}
+	points := make([]*ed25519.Point, len(publicKeys))
+	list := sha512.New()
+	list.Write([]byte(keyListDomain))
+	for i, pub := range publicKeys {
+		p, err := new(ed25519.Point).SetBytesPrimeOrder(pub)
+		if err != nil {
+			return nil, errors.New("musig: invalid public key " + strconv.Itoa(i) + ": " + err.Error())
+		}
+		points[i] = p
+		ctx.publicKeys[i] = append([]byte{}, pub...)
+		list.Write(pub)
+	}
+	L := list.Sum(nil)
+
+	scalars := make([][]byte, len(publicKeys))
+	for i := range publicKeys {
+		ctx.coeffs[i].Set(hashToScalar(keyCoefDomain, L, ctx.publicKeys[i]))
+		scalars[i] = ctx.coeffs[i].Bytes()
+	}
+	ctx.key.VartimeMultiScalarMult(scalars, points)
+	ctx.keyBytes = ctx.key.Bytes()
+	return ctx, nil
+}
+
+// PublicKey returns the aggregate public key, which verifies the signatures of
+// the group.
+func (ctx *KeyAggContext) PublicKey() ed25519.PublicKey {
+	return append(ed25519.PublicKey{}, ctx.keyBytes...)
+}
+
+// coefficient returns the key aggregation coefficient of publicKey, or nil if
+// publicKey is not one of the aggregated keys.
+func (ctx *KeyAggContext) coefficient(publicKey []byte) *ed25519.Scalar {
+	for i, pub := range ctx.publicKeys {
+		if bytes.Equal(pub, publicKey) {
+			return &ctx.coeffs[i]
+		}
+	}
+	return nil
+}
+
+// SecretNonce is a signer's pair of secret nonces for one signing session.
+type SecretNonce struct {
+	k1, k2 ed25519.Scalar
+	public []byte
+	used   bool
+}
+
+// NewNonce generates a secret nonce pair from rand, for a single signature.
+func NewNonce(rand io.Reader) (*SecretNonce, error) {
+	k1, err := ed25519.NewRandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	k2, err := ed25519.NewRandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	n := &SecretNonce{k1: *k1, k2: *k2}
+	n.public = append(new(ed25519.Point).ScalarBaseMult(k1.Bytes()).Bytes(),
+		new(ed25519.Point).ScalarBaseMult(k2.Bytes()).Bytes()...)
+	return n, nil
+}
+
+// Public returns the public nonce to send to the other signers, the
+// commitments k1*B || k2*B.
+func (n *SecretNonce) Public() []byte {
+	return append([]byte{}, n.public...)
+}
+
+// decodeNonce decodes a public or aggregate nonce into its two points.
+func decodeNonce(nonce []byte) (R1, R2 *ed25519.Point, err error) {
+	if len(nonce) != PublicNonceSize {
+		return nil, nil, errors.New("musig: bad nonce length: " + strconv.Itoa(len(nonce)))
+	}
+	if R1, err = new(ed25519.Point).SetBytesPrimeOrder(nonce[:32]); err != nil {
+		return nil, nil, err
+	}
+	if R2, err = new(ed25519.Point).SetBytesPrimeOrder(nonce[32:]); err != nil {
+		return nil, nil, err
+	}
+	return R1, R2, nil
+}
+
+// AggregateNonces sums the public nonces of all signers into the aggregate
+// nonce. Any party can do it, and hand the result to the signers. It returns
+// an error if a nonce is malformed.
+func AggregateNonces(publicNonces [][]byte) ([]byte, error) {
+	if len(publicNonces) == 0 {
+		return nil, errors.New("musig: no nonces")
+	}
+	R1, R2 := ed25519.NewIdentityPoint(), ed25519.NewIdentityPoint()
+	for i, nonce := range publicNonces {
+		P1, P2, err := decodeNonce(nonce)
+		if err != nil {
+			return nil, errors.New("musig: invalid nonce " + strconv.Itoa(i) + ": " + err.Error())
+		}
+		R1.Add(R1, P1)
+		R2.Add(R2, P2)
+	}
+	return append(R1.Bytes(), R2.Bytes()...), nil
+}
+
+// Session is the public state of a signing session, shared by all signers and
+// the aggregator.
+type Session struct {
+	ctx     *KeyAggContext
+	b, c    ed25519.Scalar
+	nonce   ed25519.Point
+	rBytes  []byte
+	message []byte
+}
+
+// NewSession starts signing message under the aggregate key of ctx, with the
+// aggregate nonce of the signers. It returns an error if aggNonce is
+// malformed.
+func NewSession(ctx *KeyAggContext, aggNonce, message []byte) (*Session, error) {
+	R1, R2, err := decodeNonce(aggNonce)
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{ctx: ctx, message: append([]byte{}, message...)}
+
+	// b = H(X, R_1, R_2, M), R = R_1 + b*R_2
+	s.b.Set(hashToScalar(nonceDomain, ctx.keyBytes, aggNonce, message))
+	s.nonce.ScalarMult(s.b.Bytes(), R2)
+	s.nonce.Add(&s.nonce, R1)
+	s.rBytes = s.nonce.Bytes()
+
+	// c = SHA-512(R || X || M), the Ed25519 challenge.
+	h := sha512.New()
+	h.Write(s.rBytes)
+	h.Write(ctx.keyBytes)
+	h.Write(message)
+	s.c.SetUniformBytes(h.Sum(nil))
+	return s, nil
+}
+
+// Sign returns the partial signature of privateKey, using nonce, which it
+// erases. It returns an error if nonce was already used, or if the key of
+// privateKey is not one of the aggregated keys. It panics if len(privateKey)
+// is not ed25519.PrivateKeySize.
+func (s *Session) Sign(nonce *SecretNonce, privateKey ed25519.PrivateKey) ([]byte, error) {
+	if l := len(privateKey); l != ed25519.PrivateKeySize {
+		panic("musig: bad private key length: " + strconv.Itoa(l))
+	}
+	if nonce.used {
+		return nil, errors.New("musig: nonce already used")
+	}
+	a := s.ctx.coefficient(privateKey[ed25519.SeedSize:])
+	if a == nil {
+		return nil, errors.New("musig: private key is not part of the aggregate key")
+	}
+
+	h := sha512.Sum512(privateKey.Seed())
+	x, _ := ed25519.NewScalar().SetBytesWithClamping(h[:32])
+
+	// s_i = k1 + b*k2 + c*a_i*x_i
+	partial := ed25519.NewScalar().MultiplyAdd(&s.b, &nonce.k2, &nonce.k1)
+	ca := ed25519.NewScalar().Mul(&s.c, a)
+	partial.MultiplyAdd(ca, x, partial)
+
+	zero := ed25519.NewScalar()
+	nonce.k1.Set(zero)
+	nonce.k2.Set(zero)
+	nonce.used = true
+	return partial.Bytes(), nil
+}
+
+// VerifyPartial reports whether partial is a valid partial signature by
+// publicKey, which sent publicNonce. Aggregators can use it to identify a
+// signer whose contribution makes the signature invalid.
+func (s *Session) VerifyPartial(partial, publicNonce []byte, publicKey ed25519.PublicKey) bool {
+	si, err := ed25519.NewScalar().SetCanonicalBytes(partial)
+	if err != nil {
+		return false
+	}
+	R1, R2, err := decodeNonce(publicNonce)
+	if err != nil {
+		return false
+	}
+	a := s.ctx.coefficient(publicKey)
+	if a == nil {
+		return false
+	}
+	X, err := new(ed25519.Point).SetBytes(publicKey)
+	if err != nil {
+		return false
+	}
+
+	// s_i*B = R_1 + b*R_2 + c*a_i*X_i
+	ca := ed25519.NewScalar().Mul(&s.c, a)
+	rhs := new(ed25519.Point).VartimeMultiScalarMult(
+		[][]byte{s.b.Bytes(), ca.Bytes()}, []*ed25519.Point{R2, X})
+	rhs.Add(rhs, R1)
+	lhs := new(ed25519.Point).ScalarBaseMult(si.Bytes())
+	return lhs.Equal(rhs) == 1
+}
+
+// Aggregate sums the partial signatures of all signers into an Ed25519
+// signature. It returns an error if a partial signature is malformed, or if
+// the result doesn't verify, in which case VerifyPartial can find the culprit.
+func (s *Session) Aggregate(partials [][]byte) ([]byte, error) {
+	sum := ed25519.NewScalar()
+	for i, partial := range partials {
+		si, err := ed25519.NewScalar().SetCanonicalBytes(partial)
+		if err != nil {
+			return nil, errors.New("musig: invalid partial signature " + strconv.Itoa(i))
+		}
+		sum.Add(sum, si)
+	}
+	sig := append(append(make([]byte, 0, ed25519.SignatureSize), s.rBytes...), sum.Bytes()...)
+	if !ed25519.Verify(s.ctx.keyBytes, s.message, sig) {
+		return nil, errors.New("musig: invalid signature")
+	}
+	return sig, nil
+}
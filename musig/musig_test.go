@@ -0,0 +1,143 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package musig
+
+import (
+	stded25519 "crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+type signer struct {
+	pub   ed25519.PublicKey
+	priv  ed25519.PrivateKey
+	nonce *SecretNonce
+}
+
+func newSigners(t *testing.T, n int) ([]signer, *KeyAggContext) {
+	signers := make([]signer, n)
+	pubs := make([]ed25519.PublicKey, n)
+	for i := range signers {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers[i] = signer{pub: pub, priv: priv}
+		pubs[i] = pub
+	}
+	ctx, err := AggregateKeys(pubs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signers, ctx
+}
+
+// newSession runs the first round.
+func newSession(t *testing.T, signers []signer, ctx *KeyAggContext, message []byte) (*Session, [][]byte) {
+	pubNonces := make([][]byte, len(signers))
+	for i := range signers {
+		nonce, err := NewNonce(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers[i].nonce = nonce
+		pubNonces[i] = nonce.Public()
+	}
+	aggNonce, err := AggregateNonces(pubNonces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSession(ctx, aggNonce, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, pubNonces
+}
+
+func TestMuSig2(t *testing.T) {
+	for _, n := range []int{1, 2, 5} {
+		signers, ctx := newSigners(t, n)
+		message := []byte("cosigned")
+		s, pubNonces := newSession(t, signers, ctx, message)
+
+		partials := make([][]byte, n)
+		for i, sg := range signers {
+			partial, err := s.Sign(sg.nonce, sg.priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !s.VerifyPartial(partial, pubNonces[i], sg.pub) {
+				t.Errorf("n = %d: valid partial signature %d rejected", n, i)
+			}
+			partials[i] = partial
+		}
+		sig, err := s.Aggregate(partials)
+		if err != nil {
+			t.Fatalf("n = %d: %v", n, err)
+		}
+		if !stded25519.Verify(stded25519.PublicKey(ctx.PublicKey()), message, sig) {
+			t.Errorf("n = %d: signature rejected by crypto/ed25519", n)
+		}
+		if _, err := s.Sign(signers[0].nonce, signers[0].priv); err == nil {
+			t.Errorf("n = %d: nonce was used twice", n)
+		}
+	}
+}
+
+func TestMuSig2Misbehavior(t *testing.T) {
+	signers, ctx := newSigners(t, 3)
+	message := []byte("cosigned")
+	s, pubNonces := newSession(t, signers, ctx, message)
+
+	partials := make([][]byte, len(signers))
+	for i, sg := range signers {
+		partials[i], _ = s.Sign(sg.nonce, sg.priv)
+	}
+	partials[1][0] ^= 1
+	if s.VerifyPartial(partials[1], pubNonces[1], signers[1].pub) {
+		t.Error("tampered partial signature accepted")
+	}
+	if s.VerifyPartial(partials[0], pubNonces[0], signers[1].pub) {
+		t.Error("partial signature accepted for the wrong signer")
+	}
+	if _, err := s.Aggregate(partials); err == nil {
+		t.Error("invalid signature aggregated")
+	}
+	if _, err := s.Aggregate(partials[:2]); err == nil {
+		t.Error("signature aggregated without all signers")
+	}
+
+	_, outsider, _ := ed25519.GenerateKey(rand.Reader)
+	nonce, _ := NewNonce(rand.Reader)
+	if _, err := s.Sign(nonce, outsider); err == nil {
+		t.Error("outsider produced a partial signature")
+	}
+}
+
+func TestAggregateKeys(t *testing.T) {
+	signers, ctx := newSigners(t, 3)
+	pubs := []ed25519.PublicKey{signers[1].pub, signers[0].pub, signers[2].pub}
+	reordered, err := AggregateKeys(pubs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reordered.PublicKey().Equal(ctx.PublicKey()) {
+		t.Error("key order doesn't affect the aggregate key")
+	}
+
+	order8, _ := new(ed25519.Point).SetBytes([]byte{
+		0xc7, 0x17, 0x6a, 0x70, 0x3d, 0x4d, 0xd8, 0x4f, 0xba, 0x3c, 0x0b, 0x76, 0x0d, 0x10, 0x67, 0x0f,
+		0x2a, 0x20, 0x53, 0xfa, 0x2c, 0x39, 0xcc, 0xc6, 0x4e, 0xc7, 0xfd, 0x77, 0x92, 0xac, 0x03, 0x7a})
+	A, _ := new(ed25519.Point).SetBytes(signers[0].pub)
+	mixed := new(ed25519.Point).Add(A, order8).Bytes()
+	if _, err := AggregateKeys([]ed25519.PublicKey{signers[1].pub, mixed}); err == nil {
+		t.Error("key with a small-order component accepted")
+	}
+	if _, err := AggregateKeys(nil); err == nil {
+		t.Error("empty key list accepted")
+	}
+}
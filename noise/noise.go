@@ -0,0 +1,95 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package noise provides the 25519 DH functions of the Noise Protocol
+// Framework, Section 12.1, in the shape of the DHFunc interface of
+// github.com/flynn/noise and similar libraries, so this module can serve as
+// their curve backend.
+//
+// Those libraries define their own key pair type, so DH25519 doesn't satisfy
+// their interfaces directly; a wrapper that converts DHKey is enough:
+//
+//	type dh25519 struct{}
+//
+//	func (dh25519) GenerateKeypair(r io.Reader) (noise.DHKey, error) {
+//		k, err := ednoise.DH25519.GenerateKeypair(r)
+//		return noise.DHKey{Private: k.Private, Public: k.Public}, err
+//	}
+//
+// with DH, DHLen and DHName forwarded as they are.
+package noise
+
+import (
+	cryptorand "crypto/rand"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+)
+
+// DHKey is a Diffie-Hellman key pair, with the same fields as the DHKey of
+// github.com/flynn/noise.
+type DHKey struct {
+	Private []byte
+	Public  []byte
+}
+
+// DHFunc is the interface of the DH functions of a Noise cipher suite.
+type DHFunc interface {
+	// GenerateKeypair generates a new key pair using entropy from random.
+	GenerateKeypair(random io.Reader) (DHKey, error)
+
+	// DH performs a Diffie-Hellman calculation between the private key in
+	// privkey and the public key in pubkey, and returns the shared secret.
+	DH(privkey, pubkey []byte) ([]byte, error)
+
+	// DHLen is the number of bytes returned by DH.
+	DHLen() int
+
+	// DHName is the name of the DH function, as used in protocol names.
+	DHName() string
+}
+
+// DH25519 is the Curve25519 DH function, X25519 as in RFC 7748.
+var DH25519 DHFunc = dh25519{}
+
+type dh25519 struct{}
+
+// GenerateKeypair implements DHFunc. If random is nil, crypto/rand.Reader is
+// used.
+func (dh25519) GenerateKeypair(random io.Reader) (DHKey, error) {
+	if random == nil {
+		random = cryptorand.Reader
+	}
+	priv := make([]byte, ed25519.X25519Size)
+	if _, err := io.ReadFull(random, priv); err != nil {
+		return DHKey{}, err
+	}
+	return DHKey{Private: priv, Public: ed25519.X25519Base(priv)}, nil
+}
+
+// DH implements DHFunc. The Noise specification lets implementations either
+// return the all-zero output for an invalid public key or signal an error.
+// Like github.com/flynn/noise, DH does the latter, so a handshake with a
+// peer that sent a low-order key fails instead of running on a known secret.
+func (dh25519) DH(privkey, pubkey []byte) ([]byte, error) {
+	if l := len(privkey); l != ed25519.X25519Size {
+		return nil, errors.New("noise: bad private key length: " + strconv.Itoa(l))
+	}
+	if l := len(pubkey); l != ed25519.X25519Size {
+		return nil, errors.New("noise: bad public key length: " + strconv.Itoa(l))
+	}
+	out, err := (&ed25519.X25519Options{Contributory: true}).X25519(privkey, pubkey)
+	if err != nil {
+		return nil, errors.New("noise: low order public key")
+	}
+	return out, nil
+}
+
+// DHLen implements DHFunc, and returns 32.
+func (dh25519) DHLen() int { return ed25519.X25519Size }
+
+// DHName implements DHFunc, and returns "25519".
+func (dh25519) DHName() string { return "25519" }
@@ -0,0 +1,73 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noise
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"testing"
+)
+
+func TestDH25519(t *testing.T) {
+	if DH25519.DHName() != "25519" || DH25519.DHLen() != 32 {
+		t.Fatalf("DHName = %q, DHLen = %d", DH25519.DHName(), DH25519.DHLen())
+	}
+
+	a, err := DH25519.GenerateKeypair(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := DH25519.GenerateKeypair(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab, err := DH25519.DH(a.Private, b.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ba, err := DH25519.DH(b.Private, a.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ab, ba) || len(ab) != DH25519.DHLen() {
+		t.Fatalf("shared secrets differ: %x, %x", ab, ba)
+	}
+
+	// Compare with crypto/ecdh, which flynn/noise ultimately relies on.
+	priv, err := ecdh.X25519().NewPrivateKey(a.Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv.PublicKey().Bytes(), a.Public) {
+		t.Errorf("public key = %x, want %x", a.Public, priv.PublicKey().Bytes())
+	}
+	pub, err := ecdh.X25519().NewPublicKey(b.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := priv.ECDH(pub); !bytes.Equal(ab, want) {
+		t.Errorf("DH = %x, want %x", ab, want)
+	}
+
+	// Deterministic key generation from the supplied randomness.
+	seed := bytes.Repeat([]byte{7}, 32)
+	k, err := DH25519.GenerateKeypair(bytes.NewReader(seed))
+	if err != nil || !bytes.Equal(k.Private, seed) {
+		t.Errorf("GenerateKeypair didn't use random: %x, %v", k.Private, err)
+	}
+	if _, err := DH25519.GenerateKeypair(bytes.NewReader(seed[:31])); err == nil {
+		t.Error("short randomness accepted")
+	}
+
+	if _, err := DH25519.DH(a.Private, make([]byte, 32)); err == nil {
+		t.Error("low order public key accepted")
+	}
+	if _, err := DH25519.DH(a.Private[:31], b.Public); err == nil {
+		t.Error("short private key accepted")
+	}
+	if _, err := DH25519.DH(a.Private, b.Public[:31]); err == nil {
+		t.Error("short public key accepted")
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package opaque implements the key exchange building blocks of OPAQUE
+// (RFC 9807) for the ristretto255-SHA512 configuration: password
+// stretching, the 3DH combination of static and ephemeral Diffie-Hellman
+// values, and the HKDF key schedule that turns them into session and MAC
+// keys.
+//
+// It is not a complete OPAQUE implementation. Registration, envelopes and
+// message framing are left to the caller, who combines these pieces with the
+// OPRF of package oprf, so that the parts that are easy to get subtly wrong,
+// the order of the DH values and the labels and transcript of the key
+// schedule, are implemented once.
+package opaque
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	"github.com/gtank/ed25519/oprf"
+	"github.com/gtank/ed25519/ristretto255"
+)
+
+// Sizes of the configuration, in bytes, as named in RFC 9807.
+const (
+	// Nh is the output size of the hash function, SHA-512.
+	Nh = sha512.Size
+	// Nx is the output size of the KDF, HKDF-SHA-512.
+	Nx = sha512.Size
+	// Nm is the output size of the MAC, HMAC-SHA-512.
+	Nm = sha512.Size
+	// Npk is the size of a public key, a ristretto255 element.
+	Npk = 32
+	// Nsk is the size of a private key, a scalar.
+	Nsk = 32
+	// Nseed is the size of a key derivation seed.
+	Nseed = 32
+)
+
+// KSF is a key stretching function, such as Argon2id or scrypt with the
+// parameters of the deployment. It must be deterministic, and return an
+// output of the same length as its input.
+type KSF func(msg []byte) ([]byte, error)
+
+// IdentityKSF is the identity key stretching function. It's only appropriate
+// where the OPRF key alone is trusted to protect passwords, such as in tests.
+func IdentityKSF(msg []byte) ([]byte, error) {
+	return msg, nil
+}
+
+// RandomizedPassword stretches the OPRF output of a password with ksf, and
+// returns randomized_password = Extract("", oprfOutput || ksf(oprfOutput)),
+// the secret OPAQUE derives envelope keys from.
+func RandomizedPassword(oprfOutput []byte, ksf KSF) ([]byte, error) {
+	stretched, err := ksf(oprfOutput)
+	if err != nil {
+		return nil, err
+	}
+	ikm := append(append([]byte{}, oprfOutput...), stretched...)
+	return hkdf.Extract(sha512.New, ikm, nil)
+}
+
+// DeriveDiffieHellmanKeyPair derives a 3DH key pair deterministically from
+// seed, which must be Nseed uniformly random bytes, with the DeriveKeyPair
+// function of the OPRF and the info "OPAQUE-DeriveDiffieHellmanKeyPair".
+// Ephemeral key pairs are derived from fresh seeds the same way.
+func DeriveDiffieHellmanKeyPair(seed []byte) (privateKey, publicKey []byte, err error) {
+	key, err := oprf.DeriveKey(oprf.ModeOPRF, seed, []byte("OPAQUE-DeriveDiffieHellmanKeyPair"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return key.Bytes(), key.Public(), nil
+}
+
+// dh returns privateKey*publicKey, encoded.
+func dh(privateKey, publicKey []byte) ([]byte, error) {
+	if len(privateKey) != Nsk {
+		return nil, errors.New("opaque: invalid private key")
+	}
+	P := ristretto255.NewElement()
+	if err := P.Decode(publicKey); err != nil || P.Equal(ristretto255.NewElement()) == 1 {
+		return nil, errors.New("opaque: invalid public key")
+	}
+	return P.ScalarMult(privateKey, P).Encode(nil), nil
+}
+
+// tripleDH concatenates three DH values.
+func tripleDH(sk1, pk1, sk2, pk2, sk3, pk3 []byte) ([]byte, error) {
+	ikm := make([]byte, 0, 3*Npk)
+	for _, pair := range [][2][]byte{{sk1, pk1}, {sk2, pk2}, {sk3, pk3}} {
+		v, err := dh(pair[0], pair[1])
+		if err != nil {
+			return nil, err
+		}
+		ikm = append(ikm, v...)
+	}
+	return ikm, nil
+}
+
+// ClientTripleDH returns the 3DH input keying material on the client side,
+// DH(eskU, epkS) || DH(eskU, pkS) || DH(skU, epkS), from the client's static
+// and ephemeral private keys and the server's static and ephemeral public
+// keys.
+func ClientTripleDH(clientPrivateKey, clientEphemeralPrivateKey, serverPublicKey, serverEphemeralPublicKey []byte) ([]byte, error) {
+	return tripleDH(clientEphemeralPrivateKey, serverEphemeralPublicKey,
+		clientEphemeralPrivateKey, serverPublicKey,
+		clientPrivateKey, serverEphemeralPublicKey)
+}
+
+// ServerTripleDH returns the 3DH input keying material on the server side,
+// DH(eskS, epkU) || DH(skS, epkU) || DH(eskS, pkU), which is equal to the
+// client's.
+func ServerTripleDH(serverPrivateKey, serverEphemeralPrivateKey, clientPublicKey, clientEphemeralPublicKey []byte) ([]byte, error) {
+	return tripleDH(serverEphemeralPrivateKey, clientEphemeralPublicKey,
+		serverPrivateKey, clientEphemeralPublicKey,
+		serverEphemeralPrivateKey, clientPublicKey)
+}
+
+// Preamble returns the transcript the key schedule and MACs are computed
+// over, RFC 9807, Section 6.4.2. The messages are passed serialized, as sent.
+func Preamble(context, clientIdentity, ke1, serverIdentity, credentialResponse, serverNonce, serverPublicKeyshare []byte) []byte {
+	p := []byte("OPAQUEv1-")
+	p = appendLengthPrefixed(p, context)
+	p = appendLengthPrefixed(p, clientIdentity)
+	p = append(p, ke1...)
+	p = appendLengthPrefixed(p, serverIdentity)
+	p = append(p, credentialResponse...)
+	p = append(p, serverNonce...)
+	return append(p, serverPublicKeyshare...)
+}
+
+func appendLengthPrefixed(b, x []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(x)))
+	return append(b, x...)
+}
+
+// ExpandLabel implements Expand-Label, HKDF-Expand with the label
+// I2OSP(length, 2) || I2OSP(len("OPAQUE-" || label), 1) || "OPAQUE-" ||
+// label || I2OSP(len(context), 1) || context.
+func ExpandLabel(secret []byte, label string, context []byte, length int) ([]byte, error) {
+	full := "OPAQUE-" + label
+	if len(full) > 255 || len(context) > 255 {
+		return nil, errors.New("opaque: label or context too long")
+	}
+	info := binary.BigEndian.AppendUint16(nil, uint16(length))
+	info = append(info, byte(len(full)))
+	info = append(info, full...)
+	info = append(info, byte(len(context)))
+	info = append(info, context...)
+	return hkdf.Expand(sha512.New, secret, string(info), length)
+}
+
+// DeriveSecret implements Derive-Secret, Expand-Label to Nx bytes.
+func DeriveSecret(secret []byte, label string, transcriptHash []byte) ([]byte, error) {
+	return ExpandLabel(secret, label, transcriptHash, Nx)
+}
+
+// Keys are the outputs of the key schedule for one session.
+type Keys struct {
+	// SessionKey is the shared secret of the session.
+	SessionKey []byte
+	// ServerMACKey is Km2, which authenticates the server's KE2 message.
+	ServerMACKey []byte
+	// ClientMACKey is Km3, which authenticates the client's KE3 message.
+	ClientMACKey []byte
+}
+
+// DeriveKeys runs the key schedule of RFC 9807, Section 6.4.2, on the 3DH
+// input keying material and the preamble.
+func DeriveKeys(ikm, preamble []byte) (*Keys, error) {
+	prk, err := hkdf.Extract(sha512.New, ikm, nil)
+	if err != nil {
+		return nil, err
+	}
+	transcript := sha512.Sum512(preamble)
+	handshake, err := DeriveSecret(prk, "HandshakeSecret", transcript[:])
+	if err != nil {
+		return nil, err
+	}
+	k := &Keys{}
+	if k.SessionKey, err = DeriveSecret(prk, "SessionKey", transcript[:]); err != nil {
+		return nil, err
+	}
+	if k.ServerMACKey, err = DeriveSecret(handshake, "ServerMAC", nil); err != nil {
+		return nil, err
+	}
+	if k.ClientMACKey, err = DeriveSecret(handshake, "ClientMAC", nil); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// ServerMAC returns the server's MAC, HMAC(Km2, Hash(preamble)).
+func (k *Keys) ServerMAC(preamble []byte) []byte {
+	transcript := sha512.Sum512(preamble)
+	return mac(k.ServerMACKey, transcript[:])
+}
+
+// ClientMAC returns the client's MAC, HMAC(Km3, Hash(preamble ||
+// serverMAC)).
+func (k *Keys) ClientMAC(preamble, serverMAC []byte) []byte {
+	h := sha512.New()
+	h.Write(preamble)
+	h.Write(serverMAC)
+	return mac(k.ClientMACKey, h.Sum(nil))
+}
+
+func mac(key, msg []byte) []byte {
+	m := hmac.New(sha512.New, key)
+	m.Write(msg)
+	return m.Sum(nil)
+}
+
+// VerifyMAC reports whether got is equal to want, in constant time.
+func VerifyMAC(got, want []byte) bool {
+	return hmac.Equal(got, want)
+}
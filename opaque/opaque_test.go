@@ -0,0 +1,117 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opaque
+
+import (
+	"bytes"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"testing"
+)
+
+func newKeyPair(t *testing.T) (sk, pk []byte) {
+	seed := make([]byte, Nseed)
+	if _, err := rand.Read(seed); err != nil {
+		t.Fatal(err)
+	}
+	sk, pk, err := DeriveDiffieHellmanKeyPair(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sk, pk
+}
+
+func TestKeyExchange(t *testing.T) {
+	skU, pkU := newKeyPair(t)
+	eskU, epkU := newKeyPair(t)
+	skS, pkS := newKeyPair(t)
+	eskS, epkS := newKeyPair(t)
+
+	clientIKM, err := ClientTripleDH(skU, eskU, pkS, epkS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverIKM, err := ServerTripleDH(skS, eskS, pkU, epkU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(clientIKM, serverIKM) {
+		t.Fatal("client and server 3DH differ")
+	}
+
+	preamble := Preamble([]byte("ctx"), []byte("alice"), []byte("ke1"), []byte("server"),
+		[]byte("credential response"), []byte("nonce"), epkS)
+	clientKeys, err := DeriveKeys(clientIKM, preamble)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverKeys, _ := DeriveKeys(serverIKM, preamble)
+	serverMAC := serverKeys.ServerMAC(preamble)
+	if !VerifyMAC(clientKeys.ServerMAC(preamble), serverMAC) {
+		t.Error("server MAC rejected")
+	}
+	clientMAC := clientKeys.ClientMAC(preamble, serverMAC)
+	if !VerifyMAC(serverKeys.ClientMAC(preamble, serverMAC), clientMAC) {
+		t.Error("client MAC rejected")
+	}
+	if !bytes.Equal(clientKeys.SessionKey, serverKeys.SessionKey) {
+		t.Error("session keys differ")
+	}
+
+	// A different static key on either side changes everything.
+	skX, _ := newKeyPair(t)
+	wrongIKM, _ := ClientTripleDH(skX, eskU, pkS, epkS)
+	wrongKeys, _ := DeriveKeys(wrongIKM, preamble)
+	if VerifyMAC(wrongKeys.ServerMAC(preamble), serverMAC) || bytes.Equal(wrongKeys.SessionKey, serverKeys.SessionKey) {
+		t.Error("impostor client derived the server's keys")
+	}
+
+	otherPreamble := Preamble([]byte("ctx"), []byte("mallory"), []byte("ke1"), []byte("server"),
+		[]byte("credential response"), []byte("nonce"), epkS)
+	otherKeys, _ := DeriveKeys(clientIKM, otherPreamble)
+	if bytes.Equal(otherKeys.SessionKey, clientKeys.SessionKey) {
+		t.Error("preamble doesn't affect the keys")
+	}
+}
+
+func TestExpandLabel(t *testing.T) {
+	secret := bytes.Repeat([]byte{1}, 64)
+	got, err := ExpandLabel(secret, "ServerMAC", []byte{0xaa, 0xbb}, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := append([]byte{0x00, 0x40, byte(len("OPAQUE-ServerMAC"))}, "OPAQUE-ServerMAC"...)
+	info = append(info, 2, 0xaa, 0xbb)
+	want, _ := hkdf.Expand(sha512.New, secret, string(info), 64)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
+
+func TestRandomizedPassword(t *testing.T) {
+	output := bytes.Repeat([]byte{7}, 64)
+	rwd, err := RandomizedPassword(output, IdentityKSF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := hkdf.Extract(sha512.New, append(append([]byte{}, output...), output...), nil)
+	if !bytes.Equal(rwd, want) {
+		t.Errorf("got %x, want %x", rwd, want)
+	}
+
+	failing := func([]byte) ([]byte, error) { return nil, errors.New("out of memory") }
+	if _, err := RandomizedPassword(output, failing); err == nil {
+		t.Error("KSF error ignored")
+	}
+}
+
+func TestDHRejectsIdentity(t *testing.T) {
+	sk, pk := newKeyPair(t)
+	if _, err := ClientTripleDH(sk, sk, pk, make([]byte, Npk)); err == nil {
+		t.Error("identity public key accepted")
+	}
+}
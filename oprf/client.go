@@ -0,0 +1,124 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oprf
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/ristretto255"
+)
+
+// Client is the client side of the protocol, for one mode and, in the
+// verifiable modes, one server public key.
+type Client struct {
+	mode Mode
+	pub  *ristretto255.Element
+}
+
+// NewClient returns a client for mode. publicKey is the server's public key,
+// which is required in ModeVOPRF and ModePOPRF, and ignored in ModeOPRF.
+func NewClient(mode Mode, publicKey []byte) (*Client, error) {
+	if err := mode.check(); err != nil {
+		return nil, err
+	}
+	c := &Client{mode: mode}
+	if mode != ModeOPRF {
+		pub, err := decodeNonIdentity(publicKey)
+		if err != nil {
+			return nil, errors.New("oprf: invalid public key")
+		}
+		c.pub = pub
+	}
+	return c, nil
+}
+
+// Blinded is the client's state for one input, between Blind and Finalize.
+type Blinded struct {
+	input, info []byte
+	blind       ed25519.Scalar
+	element     *ristretto255.Element
+	tweakedKey  *ristretto255.Element
+}
+
+// Element returns the serialized blinded element to send to the server.
+func (b *Blinded) Element() []byte {
+	return encode(b.element)
+}
+
+// Blind blinds input with a random scalar read from rand. info is the public
+// input of ModePOPRF, and is ignored by the other modes. It returns
+// ErrInvalidInput if input hashes to the identity.
+func (c *Client) Blind(rand io.Reader, input, info []byte) (*Blinded, error) {
+	blind, err := ed25519.NewRandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return c.blindWith(input, info, blind)
+}
+
+func (c *Client) blindWith(input, info []byte, blind *ed25519.Scalar) (*Blinded, error) {
+	b := &Blinded{input: append([]byte{}, input...), info: append([]byte{}, info...)}
+	if c.mode == ModePOPRF {
+		// tweakedKey = m*G + pkS
+		b.tweakedKey = ristretto255.NewElement().ScalarBaseMult(c.mode.tweak(info).Bytes())
+		b.tweakedKey.Add(b.tweakedKey, c.pub)
+		if isIdentity(b.tweakedKey) {
+			return nil, ErrInvalidInput
+		}
+	}
+	P := c.mode.hashToGroup(input)
+	if isIdentity(P) {
+		return nil, ErrInvalidInput
+	}
+	b.blind.Set(blind)
+	b.element = ristretto255.NewElement().ScalarMult(blind.Bytes(), P)
+	return b, nil
+}
+
+// Finalize returns the PRF outputs for the inputs of blinded, given the
+// evaluated elements returned by the server for them, in the same order. In
+// ModeVOPRF and ModePOPRF, it first checks proof, and returns ErrVerify if it
+// doesn't match the server's public key. In ModePOPRF, all inputs must have
+// been blinded with the same info.
+func (c *Client) Finalize(blinded []*Blinded, evaluatedElements [][]byte, proof []byte) ([][]byte, error) {
+	if len(blinded) == 0 || len(blinded) != len(evaluatedElements) {
+		return nil, errors.New("oprf: wrong number of evaluated elements")
+	}
+	evaluated := make([]*ristretto255.Element, len(evaluatedElements))
+	blindedElements := make([]*ristretto255.Element, len(blinded))
+	for i, e := range evaluatedElements {
+		var err error
+		if evaluated[i], err = decodeNonIdentity(e); err != nil {
+			return nil, err
+		}
+		blindedElements[i] = blinded[i].element
+	}
+
+	switch c.mode {
+	case ModeVOPRF:
+		if !c.mode.verifyProof(c.pub, blindedElements, evaluated, proof) {
+			return nil, ErrVerify
+		}
+	case ModePOPRF:
+		for _, b := range blinded[1:] {
+			if string(b.info) != string(blinded[0].info) {
+				return nil, errors.New("oprf: inputs blinded with different info")
+			}
+		}
+		if !c.mode.verifyProof(blinded[0].tweakedKey, evaluated, blindedElements, proof) {
+			return nil, ErrVerify
+		}
+	}
+
+	outputs := make([][]byte, len(blinded))
+	for i, b := range blinded {
+		inv := ed25519.NewScalar().Invert(&b.blind)
+		N := ristretto255.NewElement().ScalarMult(inv.Bytes(), evaluated[i])
+		outputs[i] = c.mode.finalizeHash(b.input, b.info, N)
+	}
+	return outputs, nil
+}
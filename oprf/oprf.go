@@ -0,0 +1,165 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package oprf implements the OPRF, VOPRF and POPRF protocols of RFC 9497,
+// with the ristretto255-SHA512 ciphersuite.
+//
+// An oblivious pseudorandom function lets a client learn F(k, input) from a
+// server holding the key k, without the server learning input or the output,
+// and without the client learning k. It's the core of Privacy Pass tokens and
+// of OPAQUE.
+//
+// In the verifiable mode (VOPRF), the server also proves that it used the key
+// matching its public key, so that it can't tag clients by evaluating with a
+// different key for each. The partially-oblivious mode (POPRF) adds a public
+// input, info, that both sides see and that is bound into the output.
+//
+// The protocol runs as follows. The client calls Client.Blind on each input
+// and sends the blinded elements to the server. The server calls
+// PrivateKey.BlindEvaluate and returns the evaluated elements and, in the
+// verifiable modes, a proof. The client then calls Client.Finalize to obtain
+// the outputs. A server that knows the input can compute the same output
+// directly with PrivateKey.Evaluate.
+package oprf
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/internal/hash2curve"
+	"github.com/gtank/ed25519/ristretto255"
+)
+
+// Mode is an RFC 9497 protocol variant.
+type Mode byte
+
+const (
+	// ModeOPRF is the base oblivious PRF.
+	ModeOPRF Mode = 0x00
+	// ModeVOPRF is the verifiable OPRF.
+	ModeVOPRF Mode = 0x01
+	// ModePOPRF is the partially-oblivious, verifiable OPRF.
+	ModePOPRF Mode = 0x02
+)
+
+// Identifier is the RFC 9497 name of the ciphersuite.
+const Identifier = "ristretto255-SHA512"
+
+// Sizes of the ciphersuite, in bytes.
+const (
+	// ElementSize is Ne, the size of a serialized group element.
+	ElementSize = 32
+	// ScalarSize is Ns, the size of a serialized scalar.
+	ScalarSize = 32
+	// OutputSize is Nh, the size of an output.
+	OutputSize = sha512.Size
+	// ProofSize is the size of a proof, the scalars c and s.
+	ProofSize = 2 * ScalarSize
+)
+
+// Errors of RFC 9497, Section 4.
+var (
+	ErrInvalidInput   = errors.New("oprf: input hashes to the identity element")
+	ErrVerify         = errors.New("oprf: proof verification failed")
+	ErrDeriveKeyPair  = errors.New("oprf: key pair derivation failed")
+	ErrInverseScalar  = errors.New("oprf: tweaked key is zero")
+	errInvalidElement = errors.New("oprf: invalid element encoding")
+	errInvalidMode    = errors.New("oprf: invalid mode")
+)
+
+// contextString returns "OPRFV1-" || I2OSP(mode, 1) || "-" || Identifier.
+func (m Mode) contextString() string {
+	return "OPRFV1-" + string([]byte{byte(m)}) + "-" + Identifier
+}
+
+func (m Mode) check() error {
+	if m > ModePOPRF {
+		return errInvalidMode
+	}
+	return nil
+}
+
+// hashToGroup implements HashToGroup, hash_to_ristretto255 of RFC 9380 with
+// DST "HashToGroup-" || contextString.
+func (m Mode) hashToGroup(input []byte) *ristretto255.Element {
+	u, err := hash2curve.ExpandMessageXMD(sha512.New, input, []byte("HashToGroup-"+m.contextString()), 64)
+	if err != nil {
+		panic("oprf: " + err.Error())
+	}
+	return ristretto255.NewElement().FromUniformBytes(u)
+}
+
+// hashToScalar implements HashToScalar, with DST "HashToScalar-" ||
+// contextString unless dst is given.
+func (m Mode) hashToScalar(input []byte, dst string) *ed25519.Scalar {
+	if dst == "" {
+		dst = "HashToScalar-" + m.contextString()
+	}
+	u, err := hash2curve.ExpandMessageXMD(sha512.New, input, []byte(dst), 64)
+	if err != nil {
+		panic("oprf: " + err.Error())
+	}
+	s, _ := ed25519.NewScalar().SetUniformBytes(u)
+	return s
+}
+
+// lengthPrefixed returns I2OSP(len(x), 2) || x for each x, concatenated.
+func lengthPrefixed(xs ...[]byte) []byte {
+	var out []byte
+	for _, x := range xs {
+		out = binary.BigEndian.AppendUint16(out, uint16(len(x)))
+		out = append(out, x...)
+	}
+	return out
+}
+
+func encode(e *ristretto255.Element) []byte {
+	return e.Encode(make([]byte, 0, ElementSize))
+}
+
+func decode(b []byte) (*ristretto255.Element, error) {
+	e := ristretto255.NewElement()
+	if err := e.Decode(b); err != nil {
+		return nil, errInvalidElement
+	}
+	return e, nil
+}
+
+// decodeNonIdentity implements DeserializeElement, which also rejects the
+// identity.
+func decodeNonIdentity(b []byte) (*ristretto255.Element, error) {
+	e, err := decode(b)
+	if err != nil {
+		return nil, err
+	}
+	if isIdentity(e) {
+		return nil, errInvalidElement
+	}
+	return e, nil
+}
+
+func isIdentity(e *ristretto255.Element) bool {
+	return e.Equal(ristretto255.NewElement()) == 1
+}
+
+// tweak returns m = HashToScalar("Info" || I2OSP(len(info), 2) || info), the
+// POPRF key tweak.
+func (m Mode) tweak(info []byte) *ed25519.Scalar {
+	return m.hashToScalar(append([]byte("Info"), lengthPrefixed(info)...), "")
+}
+
+// finalizeHash returns the output hash of Finalize and Evaluate, with info
+// only hashed in ModePOPRF.
+func (m Mode) finalizeHash(input, info []byte, element *ristretto255.Element) []byte {
+	var transcript []byte
+	if m == ModePOPRF {
+		transcript = lengthPrefixed(input, info, encode(element))
+	} else {
+		transcript = lengthPrefixed(input, encode(element))
+	}
+	h := sha512.Sum512(append(transcript, "Finalize"...))
+	return h[:]
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oprf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+// rfc9497Vectors are from RFC 9497, Appendix A.1, ristretto255-SHA512, Test
+// Vector 1 of each mode: Seed = 0xa3 repeated, KeyInfo = "test key",
+// Input = 0x00 and, for POPRF, Info = "test info".
+var rfc9497Vectors = []struct {
+	mode   Mode
+	skSm   string
+	output string
+}{
+	{
+		mode:   ModeOPRF,
+		skSm:   "5ebcea5ee37023ccb9fc2d2019f9d7737be85591ae8652ffa9ef0f4d37063b0e",
+		output: "527759c3d9366f277d8c6020418d96bb393ba2afb20ff90df23fb7708264e2f3ab9135e3bd69955851de4b1f9fe8a0973396719b7912ba9ee8aa7d0b5e24bcf6",
+	},
+	{
+		mode:   ModeVOPRF,
+		skSm:   "e6f73f344b79b379f1a0dd37e07ff62e38d9f71345ce62ae3a9bc60b04ccd909",
+		output: "b58cfbe118e0cb94d79b5fd6a6dafb98764dff49c14e1770b566e42402da1a7da4d8527693914139caee5bd03903af43a491351d23b430948dd50cde10d32b3c",
+	},
+	{
+		mode:   ModePOPRF,
+		skSm:   "145c79c108538421ac164ecbe131942136d5570b16d8bf41a24d4337da981e07",
+		output: "ca688351e88afb1d841fde4401c79efebb2eb75e7998fa9737bd5a82a152406d38bd29f680504e54fd4587eddcf2f37a2617ac2fbd2993f7bdf45442ace7d221",
+	},
+}
+
+func TestRFC9497Vectors(t *testing.T) {
+	seed := bytes.Repeat([]byte{0xa3}, 32)
+	input := []byte{0x00}
+	info := []byte("test info")
+	for _, tt := range rfc9497Vectors {
+		key, err := DeriveKey(tt.mode, seed, []byte("test key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key.Bytes()); got != tt.skSm {
+			t.Errorf("mode %d: got key %s, want %s", tt.mode, got, tt.skSm)
+		}
+		out, err := key.Evaluate(input, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(out); got != tt.output {
+			t.Errorf("mode %d: Evaluate got %s, want %s", tt.mode, got, tt.output)
+		}
+
+		client, err := NewClient(tt.mode, key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs := run(t, client, key, [][]byte{input}, info)
+		if got := hex.EncodeToString(outputs[0]); got != tt.output {
+			t.Errorf("mode %d: Finalize got %s, want %s", tt.mode, got, tt.output)
+		}
+	}
+}
+
+// run evaluates inputs obliviously.
+func run(t *testing.T, client *Client, key *PrivateKey, inputs [][]byte, info []byte) [][]byte {
+	t.Helper()
+	blinded := make([]*Blinded, len(inputs))
+	elements := make([][]byte, len(inputs))
+	for i, input := range inputs {
+		var err error
+		if blinded[i], err = client.Blind(rand.Reader, input, info); err != nil {
+			t.Fatal(err)
+		}
+		elements[i] = blinded[i].Element()
+	}
+	evaluated, proof, err := key.BlindEvaluate(rand.Reader, elements, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs, err := client.Finalize(blinded, evaluated, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return outputs
+}
+
+func TestBatch(t *testing.T) {
+	inputs := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	info := []byte("epoch 12")
+	for _, mode := range []Mode{ModeOPRF, ModeVOPRF, ModePOPRF} {
+		key, err := GenerateKey(rand.Reader, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, _ := NewClient(mode, key.Public())
+		outputs := run(t, client, key, inputs, info)
+		for i, input := range inputs {
+			want, _ := key.Evaluate(input, info)
+			if !bytes.Equal(outputs[i], want) {
+				t.Errorf("mode %d, input %d: oblivious and direct evaluation differ", mode, i)
+			}
+		}
+		if mode == ModePOPRF {
+			other, _ := key.Evaluate(inputs[0], []byte("epoch 13"))
+			if bytes.Equal(outputs[0], other) {
+				t.Error("info doesn't affect the output")
+			}
+		}
+	}
+}
+
+func TestVerifiableModesRejectWrongKey(t *testing.T) {
+	for _, mode := range []Mode{ModeVOPRF, ModePOPRF} {
+		key, _ := GenerateKey(rand.Reader, mode)
+		other, _ := GenerateKey(rand.Reader, mode)
+		client, _ := NewClient(mode, key.Public())
+
+		b, err := client.Blind(rand.Reader, []byte("input"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evaluated, proof, err := other.BlindEvaluate(rand.Reader, [][]byte{b.Element()}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Finalize([]*Blinded{b}, evaluated, proof); err != ErrVerify {
+			t.Errorf("mode %d: evaluation with another key: got %v, want %v", mode, err, ErrVerify)
+		}
+
+		evaluated, proof, _ = key.BlindEvaluate(rand.Reader, [][]byte{b.Element()}, nil)
+		proof[0] ^= 1
+		if _, err := client.Finalize([]*Blinded{b}, evaluated, proof); err != ErrVerify {
+			t.Errorf("mode %d: tampered proof: got %v, want %v", mode, err, ErrVerify)
+		}
+	}
+}
+
+func TestBlindEvaluateRejectsIdentity(t *testing.T) {
+	key, _ := GenerateKey(rand.Reader, ModeOPRF)
+	if _, _, err := key.BlindEvaluate(rand.Reader, [][]byte{make([]byte, ElementSize)}, nil); err == nil {
+		t.Error("identity element accepted")
+	}
+}
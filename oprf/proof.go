@@ -0,0 +1,86 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oprf
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/ristretto255"
+)
+
+// The batched DLEQ proofs of RFC 9497, Section 2.2, which show that every D[i]
+// is k*C[i] for the k with B = k*A.
+
+// composites implements ComputeComposites and, if k is not nil,
+// ComputeCompositesFast. It returns M = sum(d_i*C[i]) and Z = sum(d_i*D[i]),
+// or Z = k*M.
+func (m Mode) composites(k *ed25519.Scalar, B *ristretto255.Element, C, D []*ristretto255.Element) (M, Z *ristretto255.Element) {
+	seedDST := "Seed-" + m.contextString()
+	seed := sha512.Sum512(lengthPrefixed(encode(B), []byte(seedDST)))
+
+	M, Z = ristretto255.NewElement(), ristretto255.NewElement()
+	for i := range C {
+		transcript := lengthPrefixed(seed[:])
+		transcript = binary.BigEndian.AppendUint16(transcript, uint16(i))
+		transcript = append(transcript, lengthPrefixed(encode(C[i]), encode(D[i]))...)
+		transcript = append(transcript, "Composite"...)
+		d := m.hashToScalar(transcript, "")
+
+		M.Add(M, ristretto255.NewElement().ScalarMult(d.Bytes(), C[i]))
+		if k == nil {
+			Z.Add(Z, ristretto255.NewElement().ScalarMult(d.Bytes(), D[i]))
+		}
+	}
+	if k != nil {
+		Z.ScalarMult(k.Bytes(), M)
+	}
+	return M, Z
+}
+
+// challenge returns the challenge of a proof over B, M, Z and the
+// commitments t2 and t3.
+func (m Mode) challenge(B, M, Z, t2, t3 *ristretto255.Element) *ed25519.Scalar {
+	transcript := lengthPrefixed(encode(B), encode(M), encode(Z), encode(t2), encode(t3))
+	return m.hashToScalar(append(transcript, "Challenge"...), "")
+}
+
+// generateProof implements GenerateProof with A the generator, and the
+// proof randomness r.
+func (m Mode) generateProof(k *ed25519.Scalar, B *ristretto255.Element, C, D []*ristretto255.Element, r *ed25519.Scalar) []byte {
+	M, Z := m.composites(k, B, C, D)
+	t2 := ristretto255.NewElement().ScalarBaseMult(r.Bytes())
+	t3 := ristretto255.NewElement().ScalarMult(r.Bytes(), M)
+	c := m.challenge(B, M, Z, t2, t3)
+
+	// s = r - c*k
+	s := ed25519.NewScalar().Mul(c, k)
+	s.Sub(r, s)
+	return append(c.Bytes(), s.Bytes()...)
+}
+
+// verifyProof implements VerifyProof with A the generator.
+func (m Mode) verifyProof(B *ristretto255.Element, C, D []*ristretto255.Element, proof []byte) bool {
+	if len(proof) != ProofSize {
+		return false
+	}
+	c, err := ed25519.NewScalar().SetCanonicalBytes(proof[:ScalarSize])
+	if err != nil {
+		return false
+	}
+	s, err := ed25519.NewScalar().SetCanonicalBytes(proof[ScalarSize:])
+	if err != nil {
+		return false
+	}
+	M, Z := m.composites(nil, B, C, D)
+
+	// t2 = s*A + c*B, t3 = s*M + c*Z
+	t2 := ristretto255.NewElement().ScalarBaseMult(s.Bytes())
+	t2.Add(t2, ristretto255.NewElement().ScalarMult(c.Bytes(), B))
+	t3 := ristretto255.NewElement().ScalarMult(s.Bytes(), M)
+	t3.Add(t3, ristretto255.NewElement().ScalarMult(c.Bytes(), Z))
+	return m.challenge(B, M, Z, t2, t3).Equal(c) == 1
+}
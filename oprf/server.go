@@ -0,0 +1,168 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package oprf
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/ristretto255"
+)
+
+// PrivateKey is a server's OPRF key, for a given mode.
+type PrivateKey struct {
+	mode Mode
+	k    ed25519.Scalar
+	pub  *ristretto255.Element
+}
+
+// GenerateKey returns a random key for mode, with randomness read from rand.
+func GenerateKey(rand io.Reader, mode Mode) (*PrivateKey, error) {
+	if err := mode.check(); err != nil {
+		return nil, err
+	}
+	for {
+		k, err := ed25519.NewRandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		if k.IsZero() == 0 {
+			return newPrivateKey(mode, k), nil
+		}
+	}
+}
+
+// DeriveKey implements DeriveKeyPair of RFC 9497, Section 3.2.1: it derives
+// a key for mode deterministically from seed, which must be 32 uniformly
+// random bytes, and from the public info.
+func DeriveKey(mode Mode, seed, info []byte) (*PrivateKey, error) {
+	if err := mode.check(); err != nil {
+		return nil, err
+	}
+	if len(seed) != ScalarSize || len(info) > 0xffff {
+		return nil, ErrDeriveKeyPair
+	}
+	deriveInput := append(append([]byte{}, seed...), lengthPrefixed(info)...)
+	dst := "DeriveKeyPair" + mode.contextString()
+	for counter := 0; counter < 256; counter++ {
+		k := mode.hashToScalar(append(deriveInput, byte(counter)), dst)
+		if k.IsZero() == 0 {
+			return newPrivateKey(mode, k), nil
+		}
+	}
+	return nil, ErrDeriveKeyPair
+}
+
+// NewPrivateKey returns the key for mode with the serialized scalar b, as
+// returned by PrivateKey.Bytes.
+func NewPrivateKey(mode Mode, b []byte) (*PrivateKey, error) {
+	if err := mode.check(); err != nil {
+		return nil, err
+	}
+	k, err := ed25519.NewScalar().SetCanonicalBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if k.IsZero() == 1 {
+		return nil, errors.New("oprf: zero private key")
+	}
+	return newPrivateKey(mode, k), nil
+}
+
+func newPrivateKey(mode Mode, k *ed25519.Scalar) *PrivateKey {
+	key := &PrivateKey{mode: mode}
+	key.k.Set(k)
+	key.pub = ristretto255.NewElement().ScalarBaseMult(k.Bytes())
+	return key
+}
+
+// Bytes returns the serialized private scalar.
+func (key *PrivateKey) Bytes() []byte {
+	return key.k.Bytes()
+}
+
+// Public returns the serialized public key, which clients of the verifiable
+// modes need.
+func (key *PrivateKey) Public() []byte {
+	return encode(key.pub)
+}
+
+// BlindEvaluate evaluates the PRF on the blinded elements of one or more
+// clients. In ModeVOPRF and ModePOPRF, it also returns a single proof for the
+// whole batch, with randomness read from rand, and in ModePOPRF info is the
+// public input, which must be the same for the whole batch. In ModeOPRF, rand
+// and info are ignored and the proof is nil.
+func (key *PrivateKey) BlindEvaluate(rand io.Reader, blindedElements [][]byte, info []byte) (evaluatedElements [][]byte, proof []byte, err error) {
+	var r *ed25519.Scalar
+	if key.mode != ModeOPRF {
+		if r, err = ed25519.NewRandomScalar(rand); err != nil {
+			return nil, nil, err
+		}
+	}
+	return key.blindEvaluate(blindedElements, info, r)
+}
+
+func (key *PrivateKey) blindEvaluate(blindedElements [][]byte, info []byte, r *ed25519.Scalar) ([][]byte, []byte, error) {
+	if len(blindedElements) == 0 {
+		return nil, nil, errors.New("oprf: no blinded elements")
+	}
+	blinded := make([]*ristretto255.Element, len(blindedElements))
+	for i, b := range blindedElements {
+		var err error
+		if blinded[i], err = decodeNonIdentity(b); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// In ModePOPRF, evaluate with 1/t, where t = k + m is the tweaked key.
+	k := &key.k
+	B := key.pub
+	if key.mode == ModePOPRF {
+		t := ed25519.NewScalar().Add(k, key.mode.tweak(info))
+		if t.IsZero() == 1 {
+			return nil, nil, ErrInverseScalar
+		}
+		B = ristretto255.NewElement().ScalarBaseMult(t.Bytes())
+		k = t.Invert(t)
+	}
+
+	evaluated := make([]*ristretto255.Element, len(blinded))
+	out := make([][]byte, len(blinded))
+	for i := range blinded {
+		evaluated[i] = ristretto255.NewElement().ScalarMult(k.Bytes(), blinded[i])
+		out[i] = encode(evaluated[i])
+	}
+
+	switch key.mode {
+	case ModeVOPRF:
+		return out, key.mode.generateProof(k, B, blinded, evaluated, r), nil
+	case ModePOPRF:
+		// The proof shows blinded = t*evaluated, for B = t*G.
+		t := ed25519.NewScalar().Invert(k)
+		return out, key.mode.generateProof(t, B, evaluated, blinded, r), nil
+	default:
+		return out, nil, nil
+	}
+}
+
+// Evaluate returns the PRF output for input, as a client would obtain it,
+// for servers that know the input. info is the public input of ModePOPRF, and
+// is ignored by the other modes.
+func (key *PrivateKey) Evaluate(input, info []byte) ([]byte, error) {
+	P := key.mode.hashToGroup(input)
+	if isIdentity(P) {
+		return nil, ErrInvalidInput
+	}
+	k := &key.k
+	if key.mode == ModePOPRF {
+		t := ed25519.NewScalar().Add(k, key.mode.tweak(info))
+		if t.IsZero() == 1 {
+			return nil, ErrInverseScalar
+		}
+		k = t.Invert(t)
+	}
+	return key.mode.finalizeHash(input, info, ristretto255.NewElement().ScalarMult(k.Bytes(), P)), nil
+}
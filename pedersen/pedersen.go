@@ -0,0 +1,99 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pedersen implements Pedersen commitments over edwards25519.
+//
+// A commitment to a value v with blinding factor r is C = v*B + r*H, where B
+// is the standard generator and H is a second generator whose discrete log
+// with respect to B is unknown. Commitments are perfectly hiding, binding
+// under the discrete log assumption, and additively homomorphic: the sum of
+// two commitments is a commitment to the sum of the values under the sum of
+// the blinding factors.
+//
+// Values and blinding factors are ed25519.Scalars. Blinding factors must be
+// uniformly random and secret; commitments to different values must never
+// reuse one.
+package pedersen
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/gtank/ed25519"
+)
+
+// The generators are derived with hash-to-curve from fixed strings, so that
+// nobody knows their discrete logs with respect to B or to each other.
+const (
+	generatorDST = "gtank/ed25519/pedersen-v1_XMD:SHA-512_ELL2_RO_"
+	hMessage     = "H"
+	vectorPrefix = "G"
+)
+
+var (
+	hOnce  sync.Once
+	h      *ed25519.Point
+	hTable *ed25519.PrecomputedTable
+)
+
+func initH() {
+	h = ed25519.HashToPoint([]byte(hMessage), []byte(generatorDST))
+	hTable = ed25519.NewPrecomputedTable(h)
+}
+
+// H returns a copy of the blinding generator H, HashToPoint("H") under this
+// package's domain separation tag.
+func H() *ed25519.Point {
+	hOnce.Do(initH)
+	return new(ed25519.Point).Set(h)
+}
+
+// Commit returns the commitment value*B + blinding*H. It runs in constant time
+// with respect to value and blinding.
+func Commit(value, blinding *ed25519.Scalar) *ed25519.Point {
+	hOnce.Do(initH)
+	c := new(ed25519.Point).ScalarBaseMult(value.Bytes())
+	r := new(ed25519.Point).ScalarMultPrecomputed(blinding.Bytes(), hTable)
+	return c.Add(c, r)
+}
+
+// Verify reports whether c is the commitment to value with the given
+// blinding factor. It runs in constant time with respect to its inputs.
+func Verify(c *ed25519.Point, value, blinding *ed25519.Scalar) bool {
+	return Commit(value, blinding).Equal(c) == 1
+}
+
+// Generators returns the first n vector generators G_0, ..., G_(n-1), where
+// G_i is HashToPoint("G" || uint32(i)) under this package's domain separation
+// tag. They are independent of B, H and each other.
+func Generators(n int) []*ed25519.Point {
+	gens := make([]*ed25519.Point, n)
+	msg := make([]byte, len(vectorPrefix)+4)
+	copy(msg, vectorPrefix)
+	for i := range gens {
+		binary.BigEndian.PutUint32(msg[len(vectorPrefix):], uint32(i))
+		gens[i] = ed25519.HashToPoint(msg, []byte(generatorDST))
+	}
+	return gens
+}
+
+// CommitVector returns the commitment sum(values[i]*G_i) + blinding*H to a
+// vector of values, where G_i are the points returned by Generators. It runs
+// in constant time with respect to values and blinding.
+func CommitVector(values []*ed25519.Scalar, blinding *ed25519.Scalar) *ed25519.Point {
+	hOnce.Do(initH)
+	scalars := make([][]byte, len(values))
+	for i := range values {
+		scalars[i] = values[i].Bytes()
+	}
+	c := new(ed25519.Point).MultiScalarMult(scalars, Generators(len(values)))
+	r := new(ed25519.Point).ScalarMultPrecomputed(blinding.Bytes(), hTable)
+	return c.Add(c, r)
+}
+
+// VerifyVector reports whether c is the commitment to values with the given
+// blinding factor.
+func VerifyVector(c *ed25519.Point, values []*ed25519.Scalar, blinding *ed25519.Scalar) bool {
+	return CommitVector(values, blinding).Equal(c) == 1
+}
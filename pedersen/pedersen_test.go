@@ -0,0 +1,111 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pedersen
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func randomScalar(t testing.TB) *ed25519.Scalar {
+	s, err := ed25519.NewRandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGenerators(t *testing.T) {
+	H1, H2 := H(), H()
+	if H1.Equal(H2) != 1 {
+		t.Error("H is not deterministic")
+	}
+	if H1.IsTorsionFree() != 1 {
+		t.Error("H is not in the prime-order subgroup")
+	}
+	if H1.Equal(ed25519.NewGeneratorPoint()) == 1 {
+		t.Error("H is B")
+	}
+	// H returns a copy.
+	H1.Double(H1)
+	if H1.Equal(H()) == 1 {
+		t.Error("modifying H changed the package generator")
+	}
+
+	gens := Generators(3)
+	for i, G := range gens {
+		if G.Equal(H()) == 1 {
+			t.Errorf("G_%d is H", i)
+		}
+		for j := range gens[:i] {
+			if G.Equal(gens[j]) == 1 {
+				t.Errorf("G_%d is G_%d", i, j)
+			}
+		}
+	}
+	if Generators(5)[2].Equal(gens[2]) != 1 {
+		t.Error("generators depend on n")
+	}
+}
+
+func TestCommitVerify(t *testing.T) {
+	v, r := randomScalar(t), randomScalar(t)
+	c := Commit(v, r)
+	if !Verify(c, v, r) {
+		t.Error("commitment did not verify")
+	}
+	if Verify(c, randomScalar(t), r) {
+		t.Error("commitment verified with the wrong value")
+	}
+	if Verify(c, v, randomScalar(t)) {
+		t.Error("commitment verified with the wrong blinding factor")
+	}
+}
+
+func TestCommitHomomorphic(t *testing.T) {
+	v1, r1 := randomScalar(t), randomScalar(t)
+	v2, r2 := randomScalar(t), randomScalar(t)
+
+	sum := new(ed25519.Point).Add(Commit(v1, r1), Commit(v2, r2))
+	v := ed25519.NewScalar().Add(v1, v2)
+	r := ed25519.NewScalar().Add(r1, r2)
+	if !Verify(sum, v, r) {
+		t.Error("sum of commitments is not a commitment to the sum")
+	}
+}
+
+func TestCommitVector(t *testing.T) {
+	values := []*ed25519.Scalar{randomScalar(t), randomScalar(t), randomScalar(t)}
+	r := randomScalar(t)
+	c := CommitVector(values, r)
+	if !VerifyVector(c, values, r) {
+		t.Error("vector commitment did not verify")
+	}
+
+	gens := Generators(len(values))
+	want := new(ed25519.Point).ScalarMult(r.Bytes(), H())
+	for i := range values {
+		want.Add(want, new(ed25519.Point).ScalarMult(values[i].Bytes(), gens[i]))
+	}
+	if c.Equal(want) != 1 {
+		t.Error("CommitVector disagrees with the naive sum")
+	}
+
+	values[1] = randomScalar(t)
+	if VerifyVector(c, values, r) {
+		t.Error("vector commitment verified with a wrong value")
+	}
+}
+
+func BenchmarkCommit(b *testing.B) {
+	v, r := randomScalar(b), randomScalar(b)
+	Commit(v, r)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Commit(v, r)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"errors"
+
+	"github.com/gtank/ed25519/internal/group"
+)
+
+// Point represents a point on the edwards25519 curve. Unlike the
+// elliptic.Curve methods, Point operations work directly on extended
+// coordinates and never convert through big.Int.
+//
+// The zero value is NOT a valid point. A Point must be obtained from
+// NewIdentityPoint or NewGeneratorPoint, or set by SetBytes, ScalarBaseMult or
+// another operation, before it's used as an operand.
+//
+// Methods follow the receiver convention of math/big: the receiver is set to
+// the result and returned, and it may alias any of the operands.
+type Point struct {
+	p group.ExtendedGroupElement
+}
+
+// NewIdentityPoint returns a new Point set to the identity element.
+func NewIdentityPoint() *Point {
+	v := new(Point)
+	v.p.Zero()
+	return v
+}
+
+// NewGeneratorPoint returns a new Point set to the canonical generator B, the
+// point with y = 4/5 and positive x.
+func NewGeneratorPoint() *Point {
+	v := new(Point)
+	v.p.Set(group.Basepoint())
+	return v
+}
+
+// Set sets v = u, and returns v.
+func (v *Point) Set(u *Point) *Point {
+	v.p.Set(&u.p)
+	return v
+}
+
+// Add sets v = p + q, and returns v.
+func (v *Point) Add(p, q *Point) *Point {
+	v.p.Add(&p.p, &q.p)
+	return v
+}
+
+// Sub sets v = p - q, and returns v.
+func (v *Point) Sub(p, q *Point) *Point {
+	v.p.Sub(&p.p, &q.p)
+	return v
+}
+
+// Neg sets v = -p, and returns v.
+func (v *Point) Neg(p *Point) *Point {
+	v.p.Neg(&p.p)
+	return v
+}
+
+// Double sets v = 2*p, and returns v.
+func (v *Point) Double(p *Point) *Point {
+	v.p.Double(&p.p)
+	return v
+}
+
+// Triple sets v = 3*p, and returns v. It's faster than a doubling followed by
+// an addition.
+func (v *Point) Triple(p *Point) *Point {
+	v.p.Triple(&p.p)
+	return v
+}
+
+// MultByPow2 sets v = 2^k*p, and returns v. It's faster than k calls to
+// Double, since the intermediate results skip the extended T coordinate.
+func (v *Point) MultByPow2(p *Point, k uint) *Point {
+	v.p.MultByPow2(&p.p, k)
+	return v
+}
+
+// MultByCofactor sets v = 8*p, and returns v. The result is always in the
+// prime-order subgroup, and it is the identity exactly when p has small
+// order.
+func (v *Point) MultByCofactor(p *Point) *Point {
+	v.p.MultByCofactor(&p.p)
+	return v
+}
+
+// ScalarMult sets v = k*p, and returns v. k is a 32-byte little-endian
+// integer, the same byte order as scalars in RFC 8032. k is used as is and not
+// reduced modulo the group order, so the result is correct for points with a
+// small-order component too. It runs in constant time with respect to k.
+func (v *Point) ScalarMult(k []byte, p *Point) *Point {
+	var s [32]byte
+	if len(k) != 32 {
+		panic("ed25519: invalid scalar length")
+	}
+	copy(s[:], k)
+	v.p.ScalarMult(&s, &p.p)
+	return v
+}
+
+// ScalarBaseMult sets v = k*B, where B is the canonical generator, and returns
+// v. k is a 32-byte little-endian integer. It uses a precomputed table of
+// multiples of B and runs in constant time with respect to k.
+func (v *Point) ScalarBaseMult(k []byte) *Point {
+	var s [32]byte
+	if len(k) != 32 {
+		panic("ed25519: invalid scalar length")
+	}
+	copy(s[:], k)
+	v.p.ScalarBaseMult(&s)
+	return v
+}
+
+// CombinedMult sets v = k1*B + k2*p, where B is the canonical generator, and
+// returns v. k1 and k2 are 32-byte little-endian integers. It runs in constant
+// time with respect to both scalars. Both products share one chain of
+// doublings, so this is much cheaper than two ScalarMult calls.
+func (v *Point) CombinedMult(k1, k2 []byte, p *Point) *Point {
+	var s1, s2 [32]byte
+	if len(k1) != 32 || len(k2) != 32 {
+		panic("ed25519: invalid scalar length")
+	}
+	copy(s1[:], k1)
+	copy(s2[:], k2)
+	v.p.CombinedMult(&s1, &s2, &p.p)
+	return v
+}
+
+// Equal returns 1 if v and u are equal, and 0 otherwise. It runs in constant
+// time.
+func (v *Point) Equal(u *Point) int {
+	return v.p.Equal(&u.p)
+}
+
+// Select sets v to a if cond == 1, and to b if cond == 0, and returns v. It
+// runs in constant time, and panics if cond is not 0 or 1.
+func (v *Point) Select(a, b *Point, cond int) *Point {
+	checkCond(cond)
+	v.p.Select(&a.p, &b.p, cond)
+	return v
+}
+
+// CondNeg sets v to -p if cond == 1, and to p if cond == 0, and returns v. It
+// runs in constant time, and panics if cond is not 0 or 1.
+func (v *Point) CondNeg(p *Point, cond int) *Point {
+	checkCond(cond)
+	v.p.CondNeg(&p.p, cond)
+	return v
+}
+
+// checkCond panics if cond is not 0 or 1. Any other value would silently mix
+// the limbs of both operands.
+func checkCond(cond int) {
+	if cond&^1 != 0 {
+		panic("ed25519: invalid condition value")
+	}
+}
+
+// IsIdentity returns 1 if v is the identity element, and 0 otherwise. It runs
+// in constant time.
+func (v *Point) IsIdentity() int {
+	var identity group.ExtendedGroupElement
+	identity.Zero()
+	return v.p.Equal(&identity)
+}
+
+// Bytes returns the 32-byte compressed Edwards-Y encoding of v, as specified
+// in RFC 8032, Section 5.1.2.
+func (v *Point) Bytes() []byte {
+	out := make([]byte, 32)
+	v.p.ToBytes(out)
+	return out
+}
+
+// SetBytes sets v to the point encoded by the 32-byte compressed Edwards-Y
+// string x, and returns v. If x is not a valid encoding of a point on the
+// curve, SetBytes returns nil and an error, and v is unchanged.
+func (v *Point) SetBytes(x []byte) (*Point, error) {
+	if err := v.p.FromBytes(x); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+var errNotPrimeOrder = errors.New("ed25519: point is not in the prime-order subgroup")
+
+// SetBytesPrimeOrder is like SetBytes, but it also rejects points that are
+// not in the prime-order subgroup. Protocols that assume a prime-order group
+// should use it to parse untrusted points, instead of SetBytes followed by a
+// separate check that's easy to forget.
+func (v *Point) SetBytesPrimeOrder(x []byte) (*Point, error) {
+	var p group.ExtendedGroupElement
+	if err := p.FromBytes(x); err != nil {
+		return nil, err
+	}
+	if p.IsTorsionFree() != 1 {
+		return nil, errNotPrimeOrder
+	}
+	v.p.Set(&p)
+	return v, nil
+}
+
+// IsTorsionFree returns 1 if v is in the prime-order subgroup, and 0 if it has
+// a small-order component. Any point generated by ScalarBaseMult is torsion
+// free, but points parsed with SetBytes need not be. It runs in constant
+// time.
+func (v *Point) IsTorsionFree() int {
+	return v.p.IsTorsionFree()
+}
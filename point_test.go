@@ -0,0 +1,369 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+var basepointBytes, _ = hex.DecodeString("5866666666666666666666666666666666666666666666666666666666666666")
+
+func basepoint(t testing.TB) *Point {
+	B, err := new(Point).SetBytes(basepointBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return B
+}
+
+func randomScalar(t testing.TB) []byte {
+	k := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, k); err != nil {
+		t.Fatal(err)
+	}
+	k[31] &= 127
+	return k
+}
+
+func TestPointBasepointMatchesParams(t *testing.T) {
+	c := Ed25519()
+	x, y := basepoint(t).p.ToAffine()
+	if x.Cmp(c.Params().Gx) != 0 || y.Cmp(c.Params().Gy) != 0 {
+		t.Error("decoded base point does not match curve parameters")
+	}
+}
+
+func TestPointAddMatchesCurve(t *testing.T) {
+	c := Ed25519()
+	B := basepoint(t)
+
+	P := new(Point).ScalarBaseMult(randomScalar(t))
+	Q := new(Point).ScalarBaseMult(randomScalar(t))
+	R := new(Point).Add(P, Q)
+
+	px, py := P.p.ToAffine()
+	qx, qy := Q.p.ToAffine()
+	ex, ey := c.Add(px, py, qx, qy)
+	rx, ry := R.p.ToAffine()
+	if ex.Cmp(rx) != 0 || ey.Cmp(ry) != 0 {
+		t.Error("Point.Add disagrees with curve Add")
+	}
+
+	if new(Point).Sub(R, Q).Equal(P) != 1 {
+		t.Error("(P+Q)-Q != P")
+	}
+	if new(Point).Add(P, new(Point).Neg(P)).Equal(new(Point).Sub(B, B)) != 1 {
+		t.Error("P+(-P) != B-B")
+	}
+	if new(Point).Double(P).Equal(new(Point).Add(P, P)) != 1 {
+		t.Error("2*P != P+P")
+	}
+	if P.Equal(Q) != 0 {
+		t.Error("distinct points compared equal")
+	}
+}
+
+func TestPointScalarMultMatchesBase(t *testing.T) {
+	B := basepoint(t)
+	for i := 0; i < 10; i++ {
+		k := randomScalar(t)
+		P := new(Point).ScalarBaseMult(k)
+		Q := new(Point).ScalarMult(k, B)
+		if P.Equal(Q) != 1 {
+			t.Errorf("ScalarBaseMult and ScalarMult disagree for k = %x", k)
+		}
+	}
+}
+
+func TestPointScalarMultMatchesCurve(t *testing.T) {
+	c := Ed25519()
+	B := basepoint(t)
+
+	k := randomScalar(t)
+	P := new(Point).ScalarMult(k, B)
+
+	ex, ey := c.ScalarMult(c.Params().Gx, c.Params().Gy, ScalarFromLittleEndian(k).Bytes())
+	px, py := P.p.ToAffine()
+	if ex.Cmp(px) != 0 || ey.Cmp(py) != 0 {
+		t.Error("Point.ScalarMult disagrees with curve ScalarMult")
+	}
+}
+
+func TestPointScalarMultUnreduced(t *testing.T) {
+	B := basepoint(t)
+
+	// L and 2^256-1 are not reduced, and 2^256-1 carries out of the top
+	// signed digit of ScalarBaseMult.
+	l := make([]byte, 32)
+	lBig := Ed25519().Params().N.Bytes()
+	for i := range lBig {
+		l[i] = lBig[len(lBig)-1-i]
+	}
+	if new(Point).ScalarMult(l, B).Equal(new(Point).Sub(B, B)) != 1 {
+		t.Error("L*B is not the identity")
+	}
+
+	max := bytes.Repeat([]byte{0xff}, 32)
+	if new(Point).ScalarBaseMult(max).Equal(new(Point).ScalarMult(max, B)) != 1 {
+		t.Error("ScalarBaseMult and ScalarMult disagree for 2^256-1")
+	}
+}
+
+func TestPointCombinedMult(t *testing.T) {
+	P := new(Point).ScalarBaseMult(randomScalar(t))
+
+	// Use full 256-bit scalars to exercise the unreduced path.
+	k1 := randomScalar(t)
+	k2 := randomScalar(t)
+	k1[31] |= 0x80
+	k2[31] |= 0x80
+
+	got := new(Point).CombinedMult(k1, k2, P)
+	want := new(Point).Add(new(Point).ScalarBaseMult(k1), new(Point).ScalarMult(k2, P))
+	if got.Equal(want) != 1 {
+		t.Error("CombinedMult disagrees with ScalarBaseMult + ScalarMult")
+	}
+}
+
+func BenchmarkPointCombinedMult(b *testing.B) {
+	P := new(Point).ScalarBaseMult(randomScalar(b))
+	k1, k2 := randomScalar(b), randomScalar(b)
+	var R Point
+	for i := 0; i < b.N; i++ {
+		R.CombinedMult(k1, k2, P)
+	}
+}
+
+// Test vector generated by instrumenting x/crypto/ed25519 GenerateKey.
+func TestPointScalarBaseMultVector(t *testing.T) {
+	expanded, _ := hex.DecodeString("f04154b9d80963bb4c76214ece8a1049bdd16fbfc5003aff9835a59643ace276")
+	public, _ := hex.DecodeString("65a8343a83ec15e55050f12fc22f2c81a4fe7327c8da1524441f9ce5e5bc27dd")
+
+	A := new(Point).ScalarBaseMult(expanded)
+	if !bytes.Equal(A.Bytes(), public) {
+		t.Errorf("got %x, want %x", A.Bytes(), public)
+	}
+}
+
+func TestPointBytesRoundTrip(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		P := new(Point).ScalarBaseMult(randomScalar(t))
+		Q, err := new(Point).SetBytes(P.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if P.Equal(Q) != 1 {
+			t.Error("point did not survive Bytes/SetBytes round trip")
+		}
+	}
+}
+
+func TestPointSetBytesInvalid(t *testing.T) {
+	// y = 2 is not the y-coordinate of any point on the curve.
+	invalid := make([]byte, 32)
+	invalid[0] = 2
+	if _, err := new(Point).SetBytes(invalid); err == nil {
+		t.Error("decoded an off-curve point")
+	}
+	if _, err := new(Point).SetBytes(basepointBytes[:31]); err == nil {
+		t.Error("decoded a short encoding")
+	}
+}
+
+func BenchmarkPointScalarBaseMult(b *testing.B) {
+	k := randomScalar(b)
+	var P Point
+	for i := 0; i < b.N; i++ {
+		P.ScalarBaseMult(k)
+	}
+}
+
+func BenchmarkPointScalarMult(b *testing.B) {
+	B := basepoint(b)
+	k := randomScalar(b)
+	var P Point
+	for i := 0; i < b.N; i++ {
+		P.ScalarMult(k, B)
+	}
+}
+
+// order8Bytes encodes a point of order 8.
+var order8Bytes, _ = hex.DecodeString("26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05")
+
+func TestPointIsTorsionFree(t *testing.T) {
+	B := basepoint(t)
+	T, err := new(Point).SetBytes(order8Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if B.IsTorsionFree() != 1 {
+		t.Error("B is not torsion free")
+	}
+	if new(Point).Sub(B, B).IsTorsionFree() != 1 {
+		t.Error("the identity is not torsion free")
+	}
+	if new(Point).ScalarBaseMult(randomScalar(t)).IsTorsionFree() != 1 {
+		t.Error("a multiple of B is not torsion free")
+	}
+	if T.IsTorsionFree() != 0 {
+		t.Error("a point of order 8 is torsion free")
+	}
+	if new(Point).Add(B, T).IsTorsionFree() != 0 {
+		t.Error("B + T is torsion free")
+	}
+}
+
+func TestPointSetBytesPrimeOrder(t *testing.T) {
+	if _, err := new(Point).SetBytesPrimeOrder(basepointBytes); err != nil {
+		t.Errorf("rejected B: %v", err)
+	}
+	if _, err := new(Point).SetBytesPrimeOrder(order8Bytes); err == nil {
+		t.Error("accepted a point of order 8")
+	}
+	B := basepoint(t)
+	T, _ := new(Point).SetBytes(order8Bytes)
+	if _, err := new(Point).SetBytesPrimeOrder(new(Point).Add(B, T).Bytes()); err == nil {
+		t.Error("accepted B + T")
+	}
+}
+
+func TestPointMultByCofactor(t *testing.T) {
+	B := basepoint(t)
+	T, _ := new(Point).SetBytes(order8Bytes)
+	identity := new(Point).Sub(B, B)
+
+	if new(Point).MultByCofactor(T).Equal(identity) != 1 {
+		t.Error("8*T is not the identity")
+	}
+
+	P := new(Point).Add(new(Point).ScalarBaseMult(randomScalar(t)), T)
+	eight := make([]byte, 32)
+	eight[0] = 8
+	got := new(Point).MultByCofactor(P)
+	if got.Equal(new(Point).ScalarMult(eight, P)) != 1 {
+		t.Error("MultByCofactor disagrees with ScalarMult by 8")
+	}
+	if got.IsTorsionFree() != 1 {
+		t.Error("MultByCofactor result is not torsion free")
+	}
+}
+
+func TestPointConstructors(t *testing.T) {
+	B := NewGeneratorPoint()
+	if B.Equal(basepoint(t)) != 1 {
+		t.Error("NewGeneratorPoint is not the base point")
+	}
+	if !bytes.Equal(B.Bytes(), basepointBytes) {
+		t.Errorf("NewGeneratorPoint encodes to %x", B.Bytes())
+	}
+
+	I := NewIdentityPoint()
+	if I.IsIdentity() != 1 {
+		t.Error("NewIdentityPoint is not the identity")
+	}
+	if I.Equal(new(Point).Sub(B, B)) != 1 {
+		t.Error("NewIdentityPoint is not B-B")
+	}
+	if B.IsIdentity() != 0 {
+		t.Error("B is the identity")
+	}
+	T, _ := new(Point).SetBytes(order8Bytes)
+	if T.IsIdentity() != 0 {
+		t.Error("a point of order 8 is the identity")
+	}
+
+	P := new(Point).Set(B)
+	P.Double(P)
+	if B.Equal(NewGeneratorPoint()) != 1 {
+		t.Error("modifying a copy changed the original")
+	}
+	if P.Equal(new(Point).Add(B, B)) != 1 {
+		t.Error("Set did not copy the point")
+	}
+}
+
+func TestPointSelectCondNeg(t *testing.T) {
+	P := new(Point).ScalarBaseMult(randomScalar(t))
+	Q := new(Point).ScalarBaseMult(randomScalar(t))
+
+	if new(Point).Select(P, Q, 1).Equal(P) != 1 {
+		t.Error("Select(P, Q, 1) != P")
+	}
+	if new(Point).Select(P, Q, 0).Equal(Q) != 1 {
+		t.Error("Select(P, Q, 0) != Q")
+	}
+	if new(Point).CondNeg(P, 1).Equal(new(Point).Neg(P)) != 1 {
+		t.Error("CondNeg(P, 1) != -P")
+	}
+	if new(Point).CondNeg(P, 0).Equal(P) != 1 {
+		t.Error("CondNeg(P, 0) != P")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Select did not panic on cond = 2")
+		}
+	}()
+	new(Point).Select(P, Q, 2)
+}
+
+func TestPointTriple(t *testing.T) {
+	T, _ := new(Point).SetBytes(order8Bytes)
+	for _, P := range []*Point{
+		new(Point).ScalarBaseMult(randomScalar(t)),
+		NewIdentityPoint(),
+		T,
+		new(Point).Add(new(Point).Double(NewGeneratorPoint()), T),
+	} {
+		want := new(Point).Add(new(Point).Double(P), P)
+		if new(Point).Triple(P).Equal(want) != 1 {
+			t.Errorf("Triple(%x) != 2P + P", P.Bytes())
+		}
+		// Aliasing the receiver.
+		Q := new(Point).Set(P)
+		if Q.Triple(Q).Equal(want) != 1 {
+			t.Error("Triple with aliased receiver != 2P + P")
+		}
+	}
+}
+
+func TestPointMultByPow2(t *testing.T) {
+	P := new(Point).Add(new(Point).ScalarBaseMult(randomScalar(t)), NewGeneratorPoint())
+	want := new(Point).Set(P)
+	for k := uint(0); k < 10; k++ {
+		if new(Point).MultByPow2(P, k).Equal(want) != 1 {
+			t.Errorf("MultByPow2(P, %d) != 2^%d*P", k, k)
+		}
+		want.Double(want)
+	}
+
+	// The result must be a fully valid extended point, including T, so
+	// that it can be used in additions.
+	Q := new(Point).MultByPow2(P, 5)
+	if new(Point).Add(Q, P).Equal(new(Point).Add(want.Set(Q), P)) != 1 || Q.p.IsValid() != 1 {
+		t.Error("MultByPow2 result has an inconsistent T")
+	}
+}
+
+func BenchmarkPointTriple(b *testing.B) {
+	P := new(Point).ScalarBaseMult(randomScalar(b))
+	for i := 0; i < b.N; i++ {
+		P.Triple(P)
+	}
+}
+
+func BenchmarkPointDoubleAdd(b *testing.B) {
+	P := new(Point).ScalarBaseMult(randomScalar(b))
+	Q := new(Point)
+	for i := 0; i < b.N; i++ {
+		Q.Double(P)
+		P.Add(Q, P)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"io"
+	"strconv"
+)
+
+// DiscreteLogProofSize is the size of a proof produced by ProveDiscreteLog,
+// the challenge c followed by the response s.
+const DiscreteLogProofSize = 64
+
+// dlogDomain is the transcript label of proofs of knowledge. Transcripts are
+// separated from every other hash in the package, including signatures.
+const dlogDomain = "gtank/ed25519/dlog-pok-v1"
+
+// ProveDiscreteLog returns a Schnorr proof of knowledge of x, the discrete log
+// of A = x*B, where B is the canonical generator, without revealing x.
+//
+// As with ProveDLEQ, context binds the proof to a protocol and session, and
+// the nonce is derived from x, context and randomness read from rand. The
+// proof is not an Ed25519 signature, and can't be confused with one.
+func ProveDiscreteLog(rand io.Reader, x *Scalar, context []byte) ([]byte, error) {
+	A := new(Point).ScalarBaseMult(x.Bytes())
+
+	entropy := make([]byte, 32)
+	if _, err := io.ReadFull(rand, entropy); err != nil {
+		return nil, err
+	}
+	t := discreteLogTranscript(context, A)
+	k := nonceScalar(t, x, entropy)
+
+	R := new(Point).ScalarBaseMult(k.Bytes())
+	c := discreteLogChallenge(t, R)
+
+	// s = k - c*x
+	s := NewScalar().Mul(c, x)
+	s.Sub(k, s)
+	return append(c.Bytes(), s.Bytes()...), nil
+}
+
+// VerifyDiscreteLog reports whether proof shows knowledge of the discrete log
+// of A, for context, as produced by ProveDiscreteLog. A must be in the
+// prime-order subgroup.
+func VerifyDiscreteLog(A *Point, proof, context []byte) bool {
+	if len(proof) != DiscreteLogProofSize || A.IsTorsionFree() != 1 {
+		return false
+	}
+	c, err := NewScalar().SetCanonicalBytes(proof[:32])
+	if err != nil {
+		return false
+	}
+	s, err := NewScalar().SetCanonicalBytes(proof[32:])
+	if err != nil {
+		return false
+	}
+
+	// R = s*B + c*A
+	R := new(Point).VartimeMultiScalarMult([][]byte{s.Bytes(), c.Bytes()}, []*Point{NewGeneratorPoint(), A})
+	return discreteLogChallenge(discreteLogTranscript(context, A), R).Equal(c) == 1
+}
+
+// ProveKeyKnowledge returns a proof of knowledge of the secret scalar of
+// privateKey, as by ProveDiscreteLog, for registration flows and complaints
+// that must show a key is controlled by its presenter. It panics if
+// len(privateKey) is not PrivateKeySize.
+func ProveKeyKnowledge(rand io.Reader, privateKey PrivateKey, context []byte) ([]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	var e ExpandedPrivateKey
+	e.expand(privateKey)
+	return ProveDiscreteLog(rand, &e.s, context)
+}
+
+// VerifyKeyKnowledge reports whether proof shows knowledge of the secret
+// scalar of publicKey, for context. It returns false if publicKey is not a
+// canonical encoding of a point in the prime-order subgroup.
+func VerifyKeyKnowledge(publicKey PublicKey, proof, context []byte) bool {
+	A, err := new(Point).SetBytesPrimeOrder(publicKey)
+	if err != nil {
+		return false
+	}
+	return VerifyDiscreteLog(A, proof, context)
+}
+
+// discreteLogTranscript returns the transcript of a proof of knowledge
+// statement.
+func discreteLogTranscript(context []byte, A *Point) *Transcript {
+	t := NewTranscript(dlogDomain)
+	t.AppendMessage("context", context)
+	t.AppendPoint("A", A)
+	return t
+}
+
+// discreteLogChallenge appends the commitment to t and returns the
+// Fiat-Shamir challenge of a proof of knowledge.
+func discreteLogChallenge(t *Transcript, R *Point) *Scalar {
+	t.AppendPoint("R", R)
+	return t.ChallengeScalar("challenge")
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestDiscreteLogProof(t *testing.T) {
+	x := randomScalarElement(t)
+	A := new(Point).ScalarBaseMult(x.Bytes())
+	context := []byte("registration")
+
+	proof, err := ProveDiscreteLog(rand.Reader, x, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyDiscreteLog(A, proof, context) {
+		t.Fatal("valid proof rejected")
+	}
+	if VerifyDiscreteLog(A, proof, []byte("other")) {
+		t.Error("proof accepted for another context")
+	}
+	if VerifyDiscreteLog(new(Point).ScalarBaseMult(randomScalar(t)), proof, context) {
+		t.Error("proof accepted for another point")
+	}
+	T, _ := new(Point).SetBytes(order8Bytes)
+	if VerifyDiscreteLog(new(Point).Add(A, T), proof, context) {
+		t.Error("point with a small-order component accepted")
+	}
+	bad := append([]byte{}, proof...)
+	bad[40] ^= 1
+	if VerifyDiscreteLog(A, bad, context) {
+		t.Error("tampered proof accepted")
+	}
+}
+
+func TestKeyKnowledgeProof(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	context := []byte("complaint against participant 3")
+	proof, err := ProveKeyKnowledge(rand.Reader, priv, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyKeyKnowledge(pub, proof, context) {
+		t.Fatal("valid proof rejected")
+	}
+	other, _, _ := GenerateKey(rand.Reader)
+	if VerifyKeyKnowledge(other, proof, context) {
+		t.Error("proof accepted for another key")
+	}
+
+	// The proof is not a signature of the context.
+	if Verify(pub, context, proof) {
+		t.Error("proof verifies as a signature")
+	}
+}
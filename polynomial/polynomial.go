@@ -0,0 +1,181 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package polynomial implements polynomials over the edwards25519 scalar
+// field, the integers modulo L.
+//
+// These are the polynomials of Shamir secret sharing, verifiable secret
+// sharing and distributed key generation: a dealer picks a random polynomial
+// whose constant term is the secret, hands out its values, and publishes
+// commitments to its coefficients, such as a_i*B for Feldman's scheme.
+package polynomial
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gtank/ed25519"
+)
+
+// Polynomial is a polynomial a_0 + a_1*x + ... + a_d*x^d with coefficients
+// modulo L. A Polynomial is immutable once created.
+type Polynomial struct {
+	// coeffs holds a_0, ..., a_d, lowest degree first.
+	coeffs []ed25519.Scalar
+}
+
+// New returns the polynomial with the given coefficients, lowest degree
+// first. The coefficients are copied. It panics if there are none.
+func New(coeffs ...*ed25519.Scalar) *Polynomial {
+	if len(coeffs) == 0 {
+		panic("polynomial: no coefficients")
+	}
+	p := &Polynomial{coeffs: make([]ed25519.Scalar, len(coeffs))}
+	for i, c := range coeffs {
+		p.coeffs[i].Set(c)
+	}
+	return p
+}
+
+// NewRandom returns a polynomial of the given degree with constant term
+// secret and the other coefficients uniformly random, read from rand.
+func NewRandom(rand io.Reader, secret *ed25519.Scalar, degree int) (*Polynomial, error) {
+	if degree < 0 {
+		return nil, errors.New("polynomial: negative degree")
+	}
+	p := &Polynomial{coeffs: make([]ed25519.Scalar, degree+1)}
+	p.coeffs[0].Set(secret)
+	for i := 1; i <= degree; i++ {
+		c, err := ed25519.NewRandomScalar(rand)
+		if err != nil {
+			return nil, err
+		}
+		p.coeffs[i].Set(c)
+	}
+	return p, nil
+}
+
+// Degree returns d, the index of the highest coefficient. It counts leading
+// zero coefficients, so it's the degree p was created with, which is an upper
+// bound on its actual degree.
+func (p *Polynomial) Degree() int {
+	return len(p.coeffs) - 1
+}
+
+// Coefficient returns a copy of a_i, or zero if i is greater than the degree.
+// It panics if i is negative.
+func (p *Polynomial) Coefficient(i int) *ed25519.Scalar {
+	if i < 0 {
+		panic("polynomial: negative coefficient index")
+	}
+	if i >= len(p.coeffs) {
+		return ed25519.NewScalar()
+	}
+	return ed25519.NewScalar().Set(&p.coeffs[i])
+}
+
+// Commitments returns a_i*B for every coefficient, where B is the canonical
+// generator. These are the Feldman VSS commitments: a share (x, y) is
+// consistent with them if y*B = sum(x^i * (a_i*B)).
+func (p *Polynomial) Commitments() []*ed25519.Point {
+	out := make([]*ed25519.Point, len(p.coeffs))
+	for i := range p.coeffs {
+		out[i] = new(ed25519.Point).ScalarBaseMult(p.coeffs[i].Bytes())
+	}
+	return out
+}
+
+// Evaluate returns p(x), computed with Horner's rule. It runs in constant
+// time with respect to x and the coefficients.
+func (p *Polynomial) Evaluate(x *ed25519.Scalar) *ed25519.Scalar {
+	v := ed25519.NewScalar().Set(&p.coeffs[len(p.coeffs)-1])
+	for i := len(p.coeffs) - 2; i >= 0; i-- {
+		v.MultiplyAdd(v, x, &p.coeffs[i])
+	}
+	return v
+}
+
+// Add returns p + q.
+func (p *Polynomial) Add(q *Polynomial) *Polynomial {
+	if len(p.coeffs) < len(q.coeffs) {
+		p, q = q, p
+	}
+	r := New(scalarPointers(p.coeffs)...)
+	for i := range q.coeffs {
+		r.coeffs[i].Add(&r.coeffs[i], &q.coeffs[i])
+	}
+	return r
+}
+
+// Interpolate returns the unique polynomial of degree less than len(xs) with
+// p(xs[i]) = ys[i] for every i, by Lagrange interpolation. It returns an
+// error if xs and ys have different or zero lengths, or if xs has duplicates.
+func Interpolate(xs, ys []*ed25519.Scalar) (*Polynomial, error) {
+	if len(xs) != len(ys) || len(xs) == 0 {
+		return nil, errors.New("polynomial: invalid number of points")
+	}
+	n := len(xs)
+
+	// m(x) = prod(x - xs[j]), of degree n.
+	m := make([]ed25519.Scalar, n+1)
+	m[0].Set(one())
+	var t ed25519.Scalar
+	for j := 0; j < n; j++ {
+		// Multiply by (x - xs[j]), from the top coefficient down.
+		for k := j + 1; k > 0; k-- {
+			t.Mul(&m[k], xs[j])
+			m[k].Sub(&m[k-1], &t)
+		}
+		m[0].Mul(&m[0], t.Negate(xs[j]))
+	}
+
+	// The basis polynomial for point i is m(x) / (x - xs[i]) divided by its
+	// value at xs[i], which is zero exactly when xs[i] is repeated.
+	basis := make([][]ed25519.Scalar, n)
+	denoms := make([]ed25519.Scalar, n)
+	for i := 0; i < n; i++ {
+		basis[i] = divideLinear(m, xs[i])
+		denoms[i].Set(New(scalarPointers(basis[i])...).Evaluate(xs[i]))
+		if denoms[i].IsZero() == 1 {
+			return nil, errors.New("polynomial: duplicate x coordinates")
+		}
+	}
+	ed25519.BatchInvert(denoms)
+
+	p := &Polynomial{coeffs: make([]ed25519.Scalar, n)}
+	var w ed25519.Scalar
+	for i := 0; i < n; i++ {
+		w.Mul(ys[i], &denoms[i])
+		for k := 0; k < n; k++ {
+			p.coeffs[k].MultiplyAdd(&w, &basis[i][k], &p.coeffs[k])
+		}
+	}
+	return p, nil
+}
+
+// divideLinear returns m(x) / (x - r) by synthetic division, assuming r is a
+// root of m.
+func divideLinear(m []ed25519.Scalar, r *ed25519.Scalar) []ed25519.Scalar {
+	q := make([]ed25519.Scalar, len(m)-1)
+	q[len(q)-1].Set(&m[len(m)-1])
+	for k := len(q) - 2; k >= 0; k-- {
+		q[k].MultiplyAdd(&q[k+1], r, &m[k+1])
+	}
+	return q
+}
+
+func scalarPointers(s []ed25519.Scalar) []*ed25519.Scalar {
+	out := make([]*ed25519.Scalar, len(s))
+	for i := range s {
+		out[i] = &s[i]
+	}
+	return out
+}
+
+func one() *ed25519.Scalar {
+	var b [32]byte
+	b[0] = 1
+	s, _ := ed25519.NewScalar().SetCanonicalBytes(b[:])
+	return s
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package polynomial
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func randomScalar(t testing.TB) *ed25519.Scalar {
+	s, err := ed25519.NewRandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func smallScalar(x byte) *ed25519.Scalar {
+	var b [32]byte
+	b[0] = x
+	s, _ := ed25519.NewScalar().SetCanonicalBytes(b[:])
+	return s
+}
+
+func TestEvaluate(t *testing.T) {
+	// 3 + 2x + x^2 at x = 5 is 38.
+	p := New(smallScalar(3), smallScalar(2), smallScalar(1))
+	if p.Evaluate(smallScalar(5)).Equal(smallScalar(38)) != 1 {
+		t.Error("3 + 2*5 + 5^2 != 38")
+	}
+	if p.Degree() != 2 {
+		t.Errorf("got degree %d, want 2", p.Degree())
+	}
+	if p.Coefficient(1).Equal(smallScalar(2)) != 1 || p.Coefficient(7).Equal(ed25519.NewScalar()) != 1 {
+		t.Error("wrong coefficients")
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	secret := randomScalar(t)
+	p, err := NewRandom(rand.Reader, secret, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Coefficient(0).Equal(secret) != 1 {
+		t.Error("the constant term is not the secret")
+	}
+
+	var xs, ys []*ed25519.Scalar
+	for i := 0; i < 5; i++ {
+		x := randomScalar(t)
+		xs = append(xs, x)
+		ys = append(ys, p.Evaluate(x))
+	}
+	q, err := Interpolate(xs, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= 4; i++ {
+		if q.Coefficient(i).Equal(p.Coefficient(i)) != 1 {
+			t.Errorf("coefficient %d differs after interpolation", i)
+		}
+	}
+
+	if _, err := Interpolate(xs[:2], ys[:3]); err == nil {
+		t.Error("accepted mismatched lengths")
+	}
+	if _, err := Interpolate([]*ed25519.Scalar{xs[0], xs[0]}, ys[:2]); err == nil {
+		t.Error("accepted duplicate x coordinates")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	p := New(randomScalar(t), randomScalar(t), randomScalar(t))
+	q := New(randomScalar(t))
+	x := randomScalar(t)
+	want := ed25519.NewScalar().Add(p.Evaluate(x), q.Evaluate(x))
+	if p.Add(q).Evaluate(x).Equal(want) != 1 || q.Add(p).Evaluate(x).Equal(want) != 1 {
+		t.Error("(p + q)(x) != p(x) + q(x)")
+	}
+}
+
+func TestCommitments(t *testing.T) {
+	p, _ := NewRandom(rand.Reader, randomScalar(t), 2)
+	x := smallScalar(3)
+	y := p.Evaluate(x)
+
+	// y*B = sum(x^i * C_i)
+	acc := ed25519.NewIdentityPoint()
+	xi := smallScalar(1)
+	for _, c := range p.Commitments() {
+		acc.Add(acc, new(ed25519.Point).ScalarMult(xi.Bytes(), c))
+		xi.Mul(xi, x)
+	}
+	if acc.Equal(new(ed25519.Point).ScalarBaseMult(y.Bytes())) != 1 {
+		t.Error("share is inconsistent with the commitments")
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"strconv"
+)
+
+// PossessionProofSize is the size of a proof produced by ProvePossession.
+const PossessionProofSize = SignatureSize
+
+// possessionContext is the Ed25519ctx context of proofs of possession, which
+// separates them from plain Ed25519 signatures and from other contexts.
+const possessionContext = "gtank/ed25519 proof of possession v1"
+
+// ProvePossession returns a proof that the holder of priv knows its private
+// key: an Ed25519ctx signature of the public key, with a context reserved for
+// this purpose.
+//
+// Schemes that add public keys together, like naive multisignatures, are
+// open to rogue-key attacks, where a party picks its key as a function of
+// the others' to control the sum. Requiring a proof of possession from every
+// party before aggregating their keys prevents them. The proof can't be
+// produced from a signature by the key on anything else. It panics if
+// len(priv) is not PrivateKeySize.
+func ProvePossession(priv PrivateKey) []byte {
+	if l := len(priv); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	return sign(priv, priv[SeedSize:], dom2(0, possessionContext), nil)
+}
+
+// VerifyPossession reports whether proof is a valid proof of possession of
+// the private key of pub, as produced by ProvePossession. It also checks that
+// pub is a point of prime order, and rejects the identity, since keys with a
+// small-order component can be used to bias aggregate keys. It panics if
+// len(pub) is not PublicKeySize.
+func VerifyPossession(pub PublicKey, proof []byte) bool {
+	if l := len(pub); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	A, err := new(Point).SetBytesPrimeOrder(pub)
+	if err != nil || A.IsIdentity() == 1 {
+		return false
+	}
+	dom := dom2(0, possessionContext)
+	return verifyWithRules(pub, pub, proof, dom, RulesStrict, false) == nil
+}
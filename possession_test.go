@@ -0,0 +1,53 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestProvePossession(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof := ProvePossession(priv)
+	if len(proof) != PossessionProofSize {
+		t.Errorf("proof is %d bytes, want %d", len(proof), PossessionProofSize)
+	}
+	if !VerifyPossession(pub, proof) {
+		t.Error("valid proof rejected")
+	}
+
+	other, _, _ := GenerateKey(rand.Reader)
+	if VerifyPossession(other, proof) {
+		t.Error("proof accepted for another key")
+	}
+
+	// Plain signatures of the public key, and signatures with other
+	// contexts, are not proofs of possession.
+	if VerifyPossession(pub, Sign(priv, pub)) {
+		t.Error("plain signature accepted as a proof")
+	}
+	ctxSig, _ := SignWithOptions(priv, pub, &Options{Context: "other"})
+	if VerifyPossession(pub, ctxSig) {
+		t.Error("signature with another context accepted as a proof")
+	}
+	if err := VerifyWithOptions(pub, pub, proof, &Options{Context: possessionContext}); err != nil {
+		t.Error("proof is not an Ed25519ctx signature")
+	}
+
+	// Keys with a small-order component are rejected, even with a proof.
+	T, _ := new(Point).SetBytes(order8Bytes)
+	if VerifyPossession(T.Bytes(), proof) {
+		t.Error("small-order key accepted")
+	}
+	identity := NewIdentityPoint().Bytes()
+	forgery := append(append([]byte{}, identity...), make([]byte, 32)...)
+	if VerifyPossession(identity, forgery) {
+		t.Error("identity key accepted")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"github.com/gtank/ed25519/internal/group"
+)
+
+// PrecomputedTable holds multiples of a fixed point, so that scalar
+// multiplications by that point run as fast as ScalarBaseMult. It's worth
+// building for any point that's multiplied by many different scalars, such as
+// a second generator or a long-term public key.
+//
+// A table takes about 30KiB of memory and costs about as much to build as
+// ten ScalarMult calls.
+type PrecomputedTable struct {
+	t group.PrecomputedTable
+}
+
+// NewPrecomputedTable returns a new table of multiples of p.
+func NewPrecomputedTable(p *Point) *PrecomputedTable {
+	t := new(PrecomputedTable)
+	t.t.FromExtended(&p.p)
+	return t
+}
+
+// ScalarMultPrecomputed sets v = k*P, where t is the table for P, and returns
+// v. k is a 32-byte little-endian integer. Like ScalarMult, it doesn't reduce
+// k, so the result is correct for any P. It runs in constant time with
+// respect to k.
+func (v *Point) ScalarMultPrecomputed(k []byte, t *PrecomputedTable) *Point {
+	var s [32]byte
+	if len(k) != 32 {
+		panic("ed25519: invalid scalar length")
+	}
+	copy(s[:], k)
+	v.p.ScalarMultPrecomputed(&s, &t.t)
+	return v
+}
+
+// PrecomputedPoint is a point in the cached form (Y+X, Y-X, Z, 2dT) that
+// point addition consumes. Adding a PrecomputedPoint skips rederiving those
+// values, which saves a multiplication per addition when the same point is
+// added many times.
+type PrecomputedPoint struct {
+	c group.ProjectiveCached
+}
+
+// NewPrecomputedPoint returns p in cached form.
+func NewPrecomputedPoint(p *Point) *PrecomputedPoint {
+	q := new(PrecomputedPoint)
+	q.c.FromExtended(&p.p)
+	return q
+}
+
+// AddPrecomputed sets v = p + q, and returns v.
+func (v *Point) AddPrecomputed(p *Point, q *PrecomputedPoint) *Point {
+	v.p.AddCached(&p.p, &q.c)
+	return v
+}
+
+// SubPrecomputed sets v = p - q, and returns v.
+func (v *Point) SubPrecomputed(p *Point, q *PrecomputedPoint) *Point {
+	v.p.SubCached(&p.p, &q.c)
+	return v
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/gtank/ed25519/internal/group"
+)
+
+// PrecomputedPublicKey is a public key decoded once, with tables of multiples
+// of -A and of the base point, for fast repeated verification of signatures
+// by the same key, such as a stream of messages from one peer. Verification
+// takes about half as many point doublings as Verify, and skips decoding A.
+//
+// Building one costs about as much as ten verifications, and it takes about
+// 15KiB of memory.
+type PrecomputedPublicKey struct {
+	publicKey [PublicKeySize]byte
+	A         Point
+	canonical bool
+	minusA    group.NAFTable
+}
+
+// NewPrecomputedPublicKey returns a PrecomputedPublicKey for publicKey, or an
+// error if it's not the encoding of a point. Non-canonical encodings are
+// accepted here, but signatures are only valid for them under RulesZIP215. It
+// panics if len(publicKey) is not PublicKeySize.
+func NewPrecomputedPublicKey(publicKey PublicKey) (*PrecomputedPublicKey, error) {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	k := new(PrecomputedPublicKey)
+	if _, err := k.A.setBytesNonCanonical(publicKey); err != nil {
+		return nil, err
+	}
+	copy(k.publicKey[:], publicKey)
+	k.canonical = bytes.Equal(k.A.Bytes(), publicKey)
+
+	var minusA Point
+	minusA.Neg(&k.A)
+	k.minusA.FromExtended(&minusA.p)
+	return k, nil
+}
+
+// Verify reports whether sig is a valid signature of message by k, like
+// Verify.
+func (k *PrecomputedPublicKey) Verify(message, sig []byte) bool {
+	return k.VerifyWithOptions(message, sig, nil) == nil
+}
+
+// VerifyWithOptions checks whether sig is a valid signature of message by k,
+// like VerifierOptions.Verify. A valid signature is indicated by returning a
+// nil error. A nil opts selects the defaults.
+func (k *PrecomputedPublicKey) VerifyWithOptions(message, sig []byte, opts *VerifierOptions) error {
+	if opts == nil {
+		opts = &VerifierOptions{}
+	}
+	dom, err := opts.domFor(message)
+	if err != nil {
+		return err
+	}
+	if !k.canonical && opts.Rules != RulesZIP215 {
+		return errInvalidPublicKey
+	}
+	if len(sig) != SignatureSize {
+		return errInvalidSignatureLength
+	}
+	var c Scalar
+	c.setChallenge(dom, sig[:32], k.publicKey[:], message)
+	return verifyWithKey(&k.A, &k.minusA, sig, &c, opts.Rules, opts.cofactored())
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func TestPrecomputedPublicKey(t *testing.T) {
+	for i, tt := range rfc8032Vectors {
+		public, _ := hex.DecodeString(tt.public)
+		message, _ := hex.DecodeString(tt.message)
+		sig, _ := hex.DecodeString(tt.signature)
+
+		k, err := NewPrecomputedPublicKey(public)
+		if err != nil {
+			t.Fatalf("test %d: %v", i+1, err)
+		}
+		if !k.Verify(message, sig) {
+			t.Errorf("test %d: valid signature rejected", i+1)
+		}
+		if k.Verify(append(message, 0), sig) {
+			t.Errorf("test %d: signature accepted for the wrong message", i+1)
+		}
+	}
+
+	if _, err := NewPrecomputedPublicKey(make([]byte, 32)); err != nil {
+		t.Errorf("small-order key rejected: %v", err)
+	}
+	invalid, _ := hex.DecodeString("0200000000000000000000000000000000000000000000000000000000000000")
+	if _, err := NewPrecomputedPublicKey(invalid); err == nil {
+		t.Error("invalid encoding accepted")
+	}
+}
+
+func TestPrecomputedPublicKeyMatchesVerifierOptions(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("precomputed")
+	ctxSig, _ := SignWithOptions(priv, message, &Options{Context: "foo"})
+	identity := NewIdentityPoint().Bytes()
+	forgery := append(append([]byte{}, identity...), make([]byte, 32)...)
+
+	tests := []struct {
+		publicKey PublicKey
+		sig       []byte
+	}{
+		{pub, Sign(priv, message)},
+		{pub, signWithTorsion(t, priv, message)},
+		{pub, ctxSig},
+		{pub, forgery},
+		{identity, forgery},
+		{identityPlusPBytes, forgery},
+		{identityNegZeroBytes, forgery},
+	}
+	var optss []*VerifierOptions
+	for _, rules := range []VerificationRules{RulesRFC8032, RulesZIP215, RulesStrict} {
+		for _, eq := range []VerificationEquation{EquationDefault, EquationCofactorless, EquationCofactored} {
+			optss = append(optss,
+				&VerifierOptions{Rules: rules, Equation: eq},
+				&VerifierOptions{Options: Options{Context: "foo"}, Rules: rules, Equation: eq})
+		}
+	}
+
+	for i, tt := range tests {
+		k, err := NewPrecomputedPublicKey(tt.publicKey)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		for _, opts := range optss {
+			want := opts.Verify(tt.publicKey, message, tt.sig)
+			if got := k.VerifyWithOptions(message, tt.sig, opts); got != want {
+				t.Errorf("test %d, %+v: got %v, want %v", i, opts, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	pub, priv, _ := GenerateKey(rand.Reader)
+	message := []byte("Hello, world!")
+	sig := Sign(priv, message)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(pub, message, sig)
+	}
+}
+
+func BenchmarkPrecomputedPublicKeyVerify(b *testing.B) {
+	pub, priv, _ := GenerateKey(rand.Reader)
+	message := []byte("Hello, world!")
+	sig := Sign(priv, message)
+	k, _ := NewPrecomputedPublicKey(pub)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k.Verify(message, sig)
+	}
+}
+
+func BenchmarkNewPrecomputedPublicKey(b *testing.B) {
+	pub, _, _ := GenerateKey(rand.Reader)
+	for i := 0; i < b.N; i++ {
+		NewPrecomputedPublicKey(pub)
+	}
+}
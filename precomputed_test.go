@@ -0,0 +1,86 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestScalarMultPrecomputed(t *testing.T) {
+	P := new(Point).ScalarBaseMult(randomScalar(t))
+	table := NewPrecomputedTable(P)
+	for i := 0; i < 10; i++ {
+		k := randomScalar(t)
+		got := new(Point).ScalarMultPrecomputed(k, table)
+		if got.Equal(new(Point).ScalarMult(k, P)) != 1 {
+			t.Errorf("ScalarMultPrecomputed and ScalarMult disagree for k = %x", k)
+		}
+	}
+}
+
+func TestScalarMultPrecomputedTorsion(t *testing.T) {
+	// With a small-order component and a full 256-bit scalar, any reduction
+	// of k would give the wrong answer.
+	T, _ := new(Point).SetBytes(order8Bytes)
+	P := new(Point).Add(new(Point).ScalarBaseMult(randomScalar(t)), T)
+	table := NewPrecomputedTable(P)
+
+	for _, k := range [][]byte{bytes.Repeat([]byte{0xff}, 32), randomScalar(t)} {
+		k[31] |= 0x80
+		got := new(Point).ScalarMultPrecomputed(k, table)
+		if got.Equal(new(Point).ScalarMult(k, P)) != 1 {
+			t.Errorf("ScalarMultPrecomputed and ScalarMult disagree for k = %x", k)
+		}
+	}
+}
+
+func BenchmarkScalarMultPrecomputed(b *testing.B) {
+	table := NewPrecomputedTable(new(Point).ScalarBaseMult(randomScalar(b)))
+	k := randomScalar(b)
+	var P Point
+	for i := 0; i < b.N; i++ {
+		P.ScalarMultPrecomputed(k, table)
+	}
+}
+
+func BenchmarkNewPrecomputedTable(b *testing.B) {
+	P := new(Point).ScalarBaseMult(randomScalar(b))
+	for i := 0; i < b.N; i++ {
+		NewPrecomputedTable(P)
+	}
+}
+
+func TestAddPrecomputed(t *testing.T) {
+	P := new(Point).ScalarBaseMult(randomScalar(t))
+	Q := new(Point).ScalarBaseMult(randomScalar(t))
+	q := NewPrecomputedPoint(Q)
+
+	if new(Point).AddPrecomputed(P, q).Equal(new(Point).Add(P, Q)) != 1 {
+		t.Error("AddPrecomputed disagrees with Add")
+	}
+	if new(Point).SubPrecomputed(P, q).Equal(new(Point).Sub(P, Q)) != 1 {
+		t.Error("SubPrecomputed disagrees with Sub")
+	}
+
+	// Adding the same point repeatedly, and aliasing the receiver.
+	acc := NewIdentityPoint()
+	for i := 0; i < 8; i++ {
+		acc.AddPrecomputed(acc, q)
+	}
+	eight := make([]byte, 32)
+	eight[0] = 8
+	if acc.Equal(new(Point).ScalarMult(eight, Q)) != 1 {
+		t.Error("eight additions of Q != 8*Q")
+	}
+}
+
+func BenchmarkAddPrecomputed(b *testing.B) {
+	P := new(Point).ScalarBaseMult(randomScalar(b))
+	q := NewPrecomputedPoint(new(Point).ScalarBaseMult(randomScalar(b)))
+	for i := 0; i < b.N; i++ {
+		P.AddPrecomputed(P, q)
+	}
+}
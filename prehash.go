@@ -0,0 +1,99 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"strconv"
+)
+
+// Ed25519ph, RFC 8032, Section 5.1, signs SHA-512(M) instead of M. The
+// message only needs to be read once, so it can be streamed, at the cost of
+// relying on the collision resistance of SHA-512. Its signatures are not
+// valid Ed25519 signatures, and vice versa. SignWithOptions also supports
+// Ed25519ph with a context string.
+
+// SignPH signs the SHA-512 digest of a message with privateKey, using
+// Ed25519ph. It returns an error if digest is not 64 bytes long, and panics
+// if len(privateKey) is not PrivateKeySize.
+func SignPH(privateKey PrivateKey, digest []byte) ([]byte, error) {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	if len(digest) != sha512.Size {
+		return nil, errors.New("ed25519: bad Ed25519ph message hash length")
+	}
+	return sign(privateKey, digest, dom2(1, ""), nil), nil
+}
+
+// VerifyPH reports whether sig is a valid Ed25519ph signature by publicKey of
+// the message with the given SHA-512 digest. It panics if len(publicKey) is
+// not PublicKeySize.
+func VerifyPH(publicKey PublicKey, digest, sig []byte) bool {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	if len(digest) != sha512.Size {
+		return false
+	}
+	return verify(publicKey, digest, sig, dom2(1, "")) == nil
+}
+
+// StreamSigner computes an Ed25519ph signature of everything written to it,
+// without buffering the message.
+type StreamSigner struct {
+	h          hash.Hash
+	privateKey PrivateKey
+}
+
+// NewStreamSigner returns a StreamSigner for privateKey. It panics if
+// len(privateKey) is not PrivateKeySize.
+func NewStreamSigner(privateKey PrivateKey) *StreamSigner {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	return &StreamSigner{h: sha512.New(), privateKey: privateKey}
+}
+
+// Write adds more data to the message. It never returns an error.
+func (s *StreamSigner) Write(p []byte) (int, error) {
+	return s.h.Write(p)
+}
+
+// Sign returns the Ed25519ph signature of the message written so far. It
+// doesn't change the state, so more data can be written and signed again.
+func (s *StreamSigner) Sign() []byte {
+	sig, _ := SignPH(s.privateKey, s.h.Sum(nil))
+	return sig
+}
+
+// StreamVerifier checks an Ed25519ph signature of everything written to it,
+// without buffering the message.
+type StreamVerifier struct {
+	h         hash.Hash
+	publicKey PublicKey
+}
+
+// NewStreamVerifier returns a StreamVerifier for publicKey. It panics if
+// len(publicKey) is not PublicKeySize.
+func NewStreamVerifier(publicKey PublicKey) *StreamVerifier {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	return &StreamVerifier{h: sha512.New(), publicKey: publicKey}
+}
+
+// Write adds more data to the message. It never returns an error.
+func (v *StreamVerifier) Write(p []byte) (int, error) {
+	return v.h.Write(p)
+}
+
+// Verify reports whether sig is a valid Ed25519ph signature of the message
+// written so far.
+func (v *StreamVerifier) Verify(sig []byte) bool {
+	return VerifyPH(v.publicKey, v.h.Sum(nil), sig)
+}
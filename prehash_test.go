@@ -0,0 +1,81 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto"
+	stded25519 "crypto/ed25519"
+	"crypto/sha512"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+func TestSignPH(t *testing.T) {
+	// RFC 8032, Section 7.3, TEST abc.
+	seed, _ := hex.DecodeString("833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42")
+	public, _ := hex.DecodeString("ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf")
+	want, _ := hex.DecodeString("98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae4131f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406")
+	digest := sha512.Sum512([]byte("abc"))
+
+	priv := NewKeyFromSeed(seed)
+	sig, err := SignPH(priv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, want) {
+		t.Errorf("got %x, want %x", sig, want)
+	}
+	if !VerifyPH(public, digest[:], sig) {
+		t.Error("valid signature rejected")
+	}
+
+	// Ed25519ph and Ed25519 signatures are not interchangeable.
+	if Verify(public, digest[:], sig) {
+		t.Error("Ed25519ph signature accepted as an Ed25519 signature")
+	}
+	if VerifyPH(public, digest[:], Sign(priv, digest[:])) {
+		t.Error("Ed25519 signature accepted as an Ed25519ph signature")
+	}
+
+	if _, err := SignPH(priv, []byte("abc")); err == nil {
+		t.Error("signed a message that isn't a SHA-512 digest")
+	}
+}
+
+func TestStreamSigner(t *testing.T) {
+	pub, priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := bytes.Repeat([]byte("a large file "), 10000)
+
+	s := NewStreamSigner(priv)
+	if _, err := io.Copy(s, bytes.NewReader(message)); err != nil {
+		t.Fatal(err)
+	}
+	sig := s.Sign()
+
+	digest := sha512.Sum512(message)
+	std, err := stded25519.NewKeyFromSeed(priv.Seed()).Sign(nil, digest[:], &stded25519.Options{Hash: crypto.SHA512})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, std) {
+		t.Error("signature differs from crypto/ed25519 Ed25519ph")
+	}
+
+	v := NewStreamVerifier(pub)
+	v.Write(message[:100])
+	v.Write(message[100:])
+	if !v.Verify(sig) {
+		t.Error("valid signature rejected")
+	}
+	v.Write([]byte{0})
+	if v.Verify(sig) {
+		t.Error("signature accepted for a longer message")
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ring implements ring signatures over edwards25519: spontaneous
+// anonymous group (SAG) signatures, and their linkable variant (LSAG).
+//
+// A ring signature is made with one private key over a list of public keys,
+// the ring, and proves that the holder of one of them signed, without
+// revealing which. Signers don't need the cooperation, or even knowledge, of
+// the other members of the ring. Signatures grow linearly with the ring.
+//
+// Linkable signatures also carry a key image I = x*Hp(X), which is the same
+// for every signature by the same key X, whatever the ring and message, and
+// unrelated to X otherwise. Verifiers can compare key images to detect that
+// two signatures come from the same signer, for example to prevent double
+// voting or double spending, still without learning who it is.
+//
+// Keys are Ed25519 keys. Ring members and key images must be in the
+// prime-order subgroup: a small-order component would let a signer produce
+// several key images for the same key.
+package ring
+
+import (
+	"crypto/sha512"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+)
+
+// Labels of the challenge transcripts, and domain separation of the hash to
+// the key image base point.
+const (
+	sagDomain      = "gtank/ed25519/ring-v1 SAG"
+	lsagDomain     = "gtank/ed25519/ring-v1 LSAG"
+	keyImageDST    = "gtank/ed25519/ring-v1_XMD:SHA-512_ELL2_RO_"
+	keyImageLength = 32
+)
+
+// Sign returns a SAG signature of message by privateKey, whose public key
+// must be in ring. The signature is 32*(len(ring)+1) bytes long. It returns an
+// error if ring doesn't contain the public key or contains an invalid key.
+// It panics if len(privateKey) is not ed25519.PrivateKeySize.
+func Sign(rand io.Reader, privateKey ed25519.PrivateKey, ring []ed25519.PublicKey, message []byte) ([]byte, error) {
+	return sign(rand, privateKey, ring, message, false)
+}
+
+// Verify reports whether sig is a valid SAG signature of message by a member
+// of ring.
+func Verify(ring []ed25519.PublicKey, message, sig []byte) bool {
+	return verify(ring, message, sig, false)
+}
+
+// SignLinkable returns an LSAG signature of message by privateKey, whose
+// public key must be in ring. The signature starts with the key image of
+// privateKey, and is 32*(len(ring)+2) bytes long. It returns an error if ring
+// doesn't contain the public key or contains an invalid key. It panics if
+// len(privateKey) is not ed25519.PrivateKeySize.
+func SignLinkable(rand io.Reader, privateKey ed25519.PrivateKey, ring []ed25519.PublicKey, message []byte) ([]byte, error) {
+	return sign(rand, privateKey, ring, message, true)
+}
+
+// VerifyLinkable reports whether sig is a valid LSAG signature of message by
+// a member of ring.
+func VerifyLinkable(ring []ed25519.PublicKey, message, sig []byte) bool {
+	return verify(ring, message, sig, true)
+}
+
+// KeyImage returns the key image of privateKey, which every LSAG signature by
+// it carries. It panics if len(privateKey) is not ed25519.PrivateKeySize.
+func KeyImage(privateKey ed25519.PrivateKey) []byte {
+	if l := len(privateKey); l != ed25519.PrivateKeySize {
+		panic("ring: bad private key length: " + strconv.Itoa(l))
+	}
+	x := secretScalar(privateKey)
+	return new(ed25519.Point).ScalarMult(x.Bytes(), keyImageBase(privateKey[ed25519.SeedSize:])).Bytes()
+}
+
+// Linked reports whether the LSAG signatures sig1 and sig2 carry the same key
+// image, that is, whether they were made with the same private key. It
+// doesn't verify them.
+func Linked(sig1, sig2 []byte) bool {
+	if len(sig1) < keyImageLength || len(sig2) < keyImageLength {
+		return false
+	}
+	return string(sig1[:keyImageLength]) == string(sig2[:keyImageLength])
+}
+
+// secretScalar returns the Ed25519 secret scalar of privateKey.
+func secretScalar(privateKey ed25519.PrivateKey) *ed25519.Scalar {
+	h := sha512.Sum512(privateKey.Seed())
+	x, _ := ed25519.NewScalar().SetBytesWithClamping(h[:32])
+	return x
+}
+
+// keyImageBase returns Hp(X), the base point of key images for X.
+func keyImageBase(X []byte) *ed25519.Point {
+	return ed25519.HashToPoint(X, []byte(keyImageDST))
+}
+
+// decodeRing decodes the ring members, which must all be in the prime-order
+// subgroup.
+func decodeRing(ring []ed25519.PublicKey) ([]*ed25519.Point, error) {
+	if len(ring) == 0 {
+		return nil, errors.New("ring: empty ring")
+	}
+	points := make([]*ed25519.Point, len(ring))
+	for i, pub := range ring {
+		p, err := new(ed25519.Point).SetBytesPrimeOrder(pub)
+		if err != nil {
+			return nil, errors.New("ring: invalid public key " + strconv.Itoa(i))
+		}
+		points[i] = p
+	}
+	return points, nil
+}
+
+// prefix is the part of the challenge transcript shared by all ring
+// members: the domain, the ring, the key image if linkable, and the message.
+type prefix struct {
+	t *ed25519.Transcript
+}
+
+func newPrefix(ring []ed25519.PublicKey, keyImage, message []byte) prefix {
+	domain := sagDomain
+	if keyImage != nil {
+		domain = lsagDomain
+	}
+	t := ed25519.NewTranscript(domain)
+	for _, pub := range ring {
+		t.AppendMessage("member", pub)
+	}
+	if keyImage != nil {
+		t.AppendMessage("key image", keyImage)
+	}
+	t.AppendMessage("message", message)
+	return prefix{t}
+}
+
+// challenge returns the challenge for the commitments L and R, with R
+// omitted for SAG, on a fork of the shared transcript.
+func (p prefix) challenge(L, R *ed25519.Point) *ed25519.Scalar {
+	t := p.t.Clone()
+	t.AppendPoint("L", L)
+	if R != nil {
+		t.AppendPoint("R", R)
+	}
+	return t.ChallengeScalar("challenge")
+}
+
+func sign(rand io.Reader, privateKey ed25519.PrivateKey, ring []ed25519.PublicKey, message []byte, linkable bool) ([]byte, error) {
+	if l := len(privateKey); l != ed25519.PrivateKeySize {
+		panic("ring: bad private key length: " + strconv.Itoa(l))
+	}
+	points, err := decodeRing(ring)
+	if err != nil {
+		return nil, err
+	}
+	pi := -1
+	for i, pub := range ring {
+		if string(pub) == string(privateKey[ed25519.SeedSize:]) {
+			pi = i
+			break
+		}
+	}
+	if pi < 0 {
+		return nil, errors.New("ring: signer's public key is not in the ring")
+	}
+	n := len(ring)
+	x := secretScalar(privateKey)
+
+	var keyImage, imagePoint *ed25519.Point
+	var keyImageBytes []byte
+	if linkable {
+		keyImage = new(ed25519.Point).ScalarMult(x.Bytes(), keyImageBase(ring[pi]))
+		keyImageBytes = keyImage.Bytes()
+	}
+	p := newPrefix(ring, keyImageBytes, message)
+
+	alpha, err := ed25519.NewRandomScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	c := make([]*ed25519.Scalar, n)
+	r := make([]*ed25519.Scalar, n)
+	L := new(ed25519.Point).ScalarBaseMult(alpha.Bytes())
+	if linkable {
+		imagePoint = new(ed25519.Point).ScalarMult(alpha.Bytes(), keyImageBase(ring[pi]))
+	}
+	c[(pi+1)%n] = p.challenge(L, imagePoint)
+
+	for j := 1; j < n; j++ {
+		i := (pi + j) % n
+		if r[i], err = ed25519.NewRandomScalar(rand); err != nil {
+			return nil, err
+		}
+		L, imagePoint = round(points[i], ring[i], keyImage, r[i], c[i])
+		c[(i+1)%n] = p.challenge(L, imagePoint)
+	}
+	r[pi] = ed25519.NewScalar().Mul(c[pi], x)
+	r[pi].Sub(alpha, r[pi])
+
+	sig := make([]byte, 0, len(keyImageBytes)+32*(n+1))
+	sig = append(sig, keyImageBytes...)
+	sig = append(sig, c[0].Bytes()...)
+	for i := range r {
+		sig = append(sig, r[i].Bytes()...)
+	}
+	return sig, nil
+}
+
+// round computes the commitments of member i, L = r*B + c*X and, if keyImage
+// is not nil, R = r*Hp(X) + c*I.
+func round(X *ed25519.Point, pub []byte, keyImage *ed25519.Point, r, c *ed25519.Scalar) (L, R *ed25519.Point) {
+	L = new(ed25519.Point).VartimeMultiScalarMult(
+		[][]byte{r.Bytes(), c.Bytes()}, []*ed25519.Point{ed25519.NewGeneratorPoint(), X})
+	if keyImage != nil {
+		R = new(ed25519.Point).VartimeMultiScalarMult(
+			[][]byte{r.Bytes(), c.Bytes()}, []*ed25519.Point{keyImageBase(pub), keyImage})
+	}
+	return L, R
+}
+
+func verify(ring []ed25519.PublicKey, message, sig []byte, linkable bool) bool {
+	points, err := decodeRing(ring)
+	if err != nil {
+		return false
+	}
+	n := len(ring)
+	var keyImage *ed25519.Point
+	var keyImageBytes []byte
+	if linkable {
+		if len(sig) < keyImageLength {
+			return false
+		}
+		keyImageBytes, sig = sig[:keyImageLength], sig[keyImageLength:]
+		keyImage, err = new(ed25519.Point).SetBytesPrimeOrder(keyImageBytes)
+		if err != nil || keyImage.IsIdentity() == 1 {
+			return false
+		}
+	}
+	if len(sig) != 32*(n+1) {
+		return false
+	}
+	c0, err := ed25519.NewScalar().SetCanonicalBytes(sig[:32])
+	if err != nil {
+		return false
+	}
+	p := newPrefix(ring, keyImageBytes, message)
+
+	c := ed25519.NewScalar().Set(c0)
+	for i := 0; i < n; i++ {
+		r, err := ed25519.NewScalar().SetCanonicalBytes(sig[32*(i+1) : 32*(i+2)])
+		if err != nil {
+			return false
+		}
+		c = p.challenge(round(points[i], ring[i], keyImage, r, c))
+	}
+	return c.Equal(c0) == 1
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ring
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func newRing(t *testing.T, n int) ([]ed25519.PublicKey, []ed25519.PrivateKey) {
+	pubs := make([]ed25519.PublicKey, n)
+	privs := make([]ed25519.PrivateKey, n)
+	for i := range pubs {
+		var err error
+		if pubs[i], privs[i], err = ed25519.GenerateKey(rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return pubs, privs
+}
+
+func TestSAG(t *testing.T) {
+	ring, privs := newRing(t, 5)
+	message := []byte("one of us")
+	for _, pi := range []int{0, 2, 4} {
+		sig, err := Sign(rand.Reader, privs[pi], ring, message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != 32*6 {
+			t.Errorf("got %d bytes, want %d", len(sig), 32*6)
+		}
+		if !Verify(ring, message, sig) {
+			t.Errorf("signer %d: valid signature rejected", pi)
+		}
+		if Verify(ring, []byte("other"), sig) {
+			t.Errorf("signer %d: signature accepted for the wrong message", pi)
+		}
+		reordered := append([]ed25519.PublicKey{ring[1], ring[0]}, ring[2:]...)
+		if Verify(reordered, message, sig) {
+			t.Errorf("signer %d: signature accepted for another ring", pi)
+		}
+		if VerifyLinkable(ring, message, sig) {
+			t.Errorf("signer %d: SAG signature accepted as LSAG", pi)
+		}
+	}
+
+	single, err := Sign(rand.Reader, privs[0], ring[:1], message)
+	if err != nil || !Verify(ring[:1], message, single) {
+		t.Errorf("ring of one: %v", err)
+	}
+
+	_, outsider, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := Sign(rand.Reader, outsider, ring, message); err == nil {
+		t.Error("signed by a key outside the ring")
+	}
+}
+
+func TestLSAG(t *testing.T) {
+	ring, privs := newRing(t, 4)
+	message := []byte("vote: yes")
+
+	sig1, err := SignLinkable(rand.Reader, privs[1], ring, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyLinkable(ring, message, sig1) {
+		t.Fatal("valid signature rejected")
+	}
+	if !bytes.Equal(sig1[:32], KeyImage(privs[1])) {
+		t.Error("signature doesn't carry the key image")
+	}
+
+	// The same signer in another ring, for another message, is linked.
+	otherRing, _ := newRing(t, 2)
+	otherRing = append(otherRing, ring[1])
+	sig2, err := SignLinkable(rand.Reader, privs[1], otherRing, []byte("vote: no"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyLinkable(otherRing, []byte("vote: no"), sig2) || !Linked(sig1, sig2) {
+		t.Error("signatures by the same key are not linked")
+	}
+
+	sig3, _ := SignLinkable(rand.Reader, privs[2], ring, message)
+	if Linked(sig1, sig3) {
+		t.Error("signatures by different keys are linked")
+	}
+
+	// The key image can't be swapped for another one.
+	forged := append(append([]byte{}, KeyImage(privs[2])...), sig1[32:]...)
+	if VerifyLinkable(ring, message, forged) {
+		t.Error("signature with a substituted key image accepted")
+	}
+}
+
+func TestTorsion(t *testing.T) {
+	ring, privs := newRing(t, 3)
+	message := []byte("torsion")
+	sig, _ := SignLinkable(rand.Reader, privs[0], ring, message)
+
+	order8, _ := new(ed25519.Point).SetBytes([]byte{
+		0xc7, 0x17, 0x6a, 0x70, 0x3d, 0x4d, 0xd8, 0x4f, 0xba, 0x3c, 0x0b, 0x76, 0x0d, 0x10, 0x67, 0x0f,
+		0x2a, 0x20, 0x53, 0xfa, 0x2c, 0x39, 0xcc, 0xc6, 0x4e, 0xc7, 0xfd, 0x77, 0x92, 0xac, 0x03, 0x7a})
+
+	// A key image with a small-order component would be a second image for
+	// the same key.
+	I, _ := new(ed25519.Point).SetBytes(sig[:32])
+	tweaked := append(new(ed25519.Point).Add(I, order8).Bytes(), sig[32:]...)
+	if VerifyLinkable(ring, message, tweaked) {
+		t.Error("key image with a small-order component accepted")
+	}
+	identity := append(ed25519.NewIdentityPoint().Bytes(), sig[32:]...)
+	if VerifyLinkable(ring, message, identity) {
+		t.Error("identity key image accepted")
+	}
+
+	X, _ := new(ed25519.Point).SetBytes(ring[1])
+	bad := []ed25519.PublicKey{ring[0], new(ed25519.Point).Add(X, order8).Bytes(), ring[2]}
+	if _, err := SignLinkable(rand.Reader, privs[0], bad, message); err == nil {
+		t.Error("ring member with a small-order component accepted")
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ristretto255 implements the ristretto255 prime-order group as
+// specified in draft-irtf-cfrg-ristretto255-decaf448-00.
+//
+// Ristretto is a technique for building a prime-order group out of a
+// non-prime-order elliptic curve. Here the curve is edwards25519, so every
+// Element is represented internally by a point of the ed25519 package, but
+// the encoding, decoding and equality functions are defined so that points
+// that differ by a small-order component are the same group element. Callers
+// never need to think about the cofactor.
+//
+// There is no Decaf mode. Decaf quotients out a cofactor of 4, so applied to
+// edwards25519, which has cofactor 8, it yields a group of order 2*L rather
+// than a prime-order group; Ristretto is the extension of Decaf that handles
+// cofactor 8. The decaf448 group of the same draft is built on Ed448, which
+// this module does not implement.
+package ristretto255
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+var (
+	sqrtM1 = radix51.SqrtM1
+
+	// sqrtADMinusOne is sqrt(a*d - 1) = sqrt(-d - 1).
+	sqrtADMinusOne = &radix51.FieldElement{2241493124984347, 425987919032274,
+		2207028919301688, 1220490630685848, 974799131293748}
+
+	// invSqrtAMinusD is 1/sqrt(a - d) = 1/sqrt(-1 - d).
+	invSqrtAMinusD = &radix51.FieldElement{278908739862762, 821645201101625,
+		8113234426968, 1777959178193151, 2118520810568447}
+
+	// oneMinusDSQ is 1 - d^2.
+	oneMinusDSQ = &radix51.FieldElement{1136626929484150, 1998550399581263,
+		496427632559748, 118527312129759, 45110755273534}
+
+	// dMinusOneSQ is (d - 1)^2.
+	dMinusOneSQ = &radix51.FieldElement{1507062230895904, 1572317787530805,
+		683053064812840, 317374165784489, 1572899562415810}
+)
+
+var errInvalidEncoding = errors.New("ristretto255: invalid element encoding")
+
+// Element is an element of the ristretto255 prime-order group.
+//
+// The zero value is NOT valid, use NewElement to get the identity.
+type Element struct {
+	r group.ExtendedGroupElement
+}
+
+// NewElement returns a new Element set to the identity value.
+func NewElement() *Element {
+	e := &Element{}
+	e.r.Zero()
+	return e
+}
+
+// Zero sets e to the identity element of the group, and returns e.
+func (e *Element) Zero() *Element {
+	e.r.Zero()
+	return e
+}
+
+// Base sets e to the canonical generator specified in
+// draft-irtf-cfrg-ristretto255-decaf448-00, Section 4, and returns e.
+func (e *Element) Base() *Element {
+	e.r.Set(group.Basepoint())
+	return e
+}
+
+// Equal returns 1 if e is equivalent to ee, and 0 otherwise. Two elements are
+// equivalent if x1*y2 == y1*x2 or y1*y2 == x1*x2, which holds exactly when the
+// underlying points differ by a 4-torsion component.
+//
+// Note that Elements must not be compared in any other way.
+func (e *Element) Equal(ee *Element) int {
+	var f0, f1 radix51.FieldElement
+
+	f0.Mul(&e.r.X, &ee.r.Y) // x1 * y2
+	f1.Mul(&e.r.Y, &ee.r.X) // y1 * x2
+	out := f0.Equal(&f1)
+
+	f0.Mul(&e.r.Y, &ee.r.Y) // y1 * y2
+	f1.Mul(&e.r.X, &ee.r.X) // x1 * x2
+	out = out | f0.Equal(&f1)
+
+	return out
+}
+
+// FromUniformBytes maps the 64-byte slice b to an Element e uniformly and
+// deterministically, and returns e. This can be used for hash-to-group
+// operations or to obtain a random element.
+func (e *Element) FromUniformBytes(b []byte) *Element {
+	if len(b) != 64 {
+		panic("ristretto255: FromUniformBytes: input is not 64 bytes long")
+	}
+
+	var f radix51.FieldElement
+	var p1, p2 group.ExtendedGroupElement
+
+	f.FromBytes(b[:32])
+	mapToPoint(&p1, &f)
+
+	f.FromBytes(b[32:])
+	mapToPoint(&p2, &f)
+
+	e.r.Add(&p1, &p2)
+	return e
+}
+
+// mapToPoint implements MAP from Section 3.2.4 of
+// draft-irtf-cfrg-ristretto255-decaf448-00.
+func mapToPoint(out *group.ExtendedGroupElement, t *radix51.FieldElement) {
+	var tmp radix51.FieldElement
+
+	// r = SQRT_M1 * t^2
+	var r radix51.FieldElement
+	r.Mul(sqrtM1, tmp.Square(t))
+
+	// u = (r + 1) * ONE_MINUS_D_SQ
+	var u radix51.FieldElement
+	u.Mul(u.Add(&r, radix51.One), oneMinusDSQ)
+
+	// c = -1
+	var c radix51.FieldElement
+	c.Set(radix51.MinusOne)
+
+	// v = (c - r*D) * (r + D)
+	var rPlusD, v radix51.FieldElement
+	rPlusD.Add(&r, group.D)
+	v.Mul(v.Sub(&c, v.Mul(&r, group.D)), &rPlusD)
+
+	// (was_square, s) = SQRT_RATIO_M1(u, v)
+	var s radix51.FieldElement
+	_, wasSquare := s.SqrtRatio(&u, &v)
+
+	// s_prime = -CT_ABS(s*t)
+	var sPrime radix51.FieldElement
+	sPrime.Neg(sPrime.Abs(sPrime.Mul(&s, t)))
+
+	// s = CT_SELECT(s IF was_square ELSE s_prime)
+	s.Select(&s, &sPrime, wasSquare)
+	// c = CT_SELECT(c IF was_square ELSE r)
+	c.Select(&c, &r, wasSquare)
+
+	// N = c * (r - 1) * D_MINUS_ONE_SQ - v
+	var N radix51.FieldElement
+	N.Mul(&c, tmp.Sub(&r, radix51.One))
+	N.Sub(N.Mul(&N, dMinusOneSQ), &v)
+
+	s2 := tmp.Square(&s)
+
+	// w0 = 2 * s * v
+	var w0 radix51.FieldElement
+	w0.Add(&s, &s)
+	w0.Mul(&w0, &v)
+	// w1 = N * SQRT_AD_MINUS_ONE
+	var w1 radix51.FieldElement
+	w1.Mul(&N, sqrtADMinusOne)
+	// w2 = 1 - s^2
+	var w2 radix51.FieldElement
+	w2.Sub(radix51.One, s2)
+	// w3 = 1 + s^2
+	var w3 radix51.FieldElement
+	w3.Add(radix51.One, s2)
+
+	// return (w0*w3, w2*w1, w1*w3, w0*w2)
+	out.X.Mul(&w0, &w3)
+	out.Y.Mul(&w2, &w1)
+	out.Z.Mul(&w1, &w3)
+	out.T.Mul(&w0, &w2)
+}
+
+// Encode appends the 32 bytes canonical encoding of e to b and returns the
+// result.
+func (e *Element) Encode(b []byte) []byte {
+	tmp := &radix51.FieldElement{}
+
+	// u1 = (z0 + y0) * (z0 - y0)
+	u1 := &radix51.FieldElement{}
+	u1.Add(&e.r.Z, &e.r.Y).Mul(u1, tmp.Sub(&e.r.Z, &e.r.Y))
+
+	// u2 = x0 * y0
+	u2 := &radix51.FieldElement{}
+	u2.Mul(&e.r.X, &e.r.Y)
+
+	// Ignore was_square since this is always square
+	// (_, invsqrt) = SQRT_RATIO_M1(1, u1 * u2^2)
+	invSqrt := &radix51.FieldElement{}
+	invSqrt.SqrtRatio(radix51.One, tmp.Square(u2).Mul(tmp, u1))
+
+	// den1 = invsqrt * u1
+	// den2 = invsqrt * u2
+	den1, den2 := &radix51.FieldElement{}, &radix51.FieldElement{}
+	den1.Mul(invSqrt, u1)
+	den2.Mul(invSqrt, u2)
+	// z_inv = den1 * den2 * t0
+	zInv := &radix51.FieldElement{}
+	zInv.Mul(den1, den2).Mul(zInv, &e.r.T)
+
+	// ix0 = x0 * SQRT_M1
+	// iy0 = y0 * SQRT_M1
+	ix0, iy0 := &radix51.FieldElement{}, &radix51.FieldElement{}
+	ix0.Mul(&e.r.X, sqrtM1)
+	iy0.Mul(&e.r.Y, sqrtM1)
+	// enchanted_denominator = den1 * INVSQRT_A_MINUS_D
+	enchantedDenominator := &radix51.FieldElement{}
+	enchantedDenominator.Mul(den1, invSqrtAMinusD)
+
+	// rotate = IS_NEGATIVE(t0 * z_inv)
+	rotate := tmp.Mul(&e.r.T, zInv).IsNegative()
+
+	// x = CT_SELECT(iy0 IF rotate ELSE x0)
+	// y = CT_SELECT(ix0 IF rotate ELSE y0)
+	x, y := &radix51.FieldElement{}, &radix51.FieldElement{}
+	x.Select(iy0, &e.r.X, rotate)
+	y.Select(ix0, &e.r.Y, rotate)
+	// z = z0
+	z := &e.r.Z
+	// den_inv = CT_SELECT(enchanted_denominator IF rotate ELSE den2)
+	denInv := &radix51.FieldElement{}
+	denInv.Select(enchantedDenominator, den2, rotate)
+
+	// y = CT_NEG(y, IS_NEGATIVE(x * z_inv))
+	y.CondNeg(y, tmp.Mul(x, zInv).IsNegative())
+
+	// s = CT_ABS(den_inv * (z - y))
+	s := tmp.Sub(z, y).Mul(tmp, denInv).Abs(tmp)
+
+	// Return the canonical little-endian encoding of s.
+	var out [32]byte
+	s.ToBytes(out[:])
+	return append(b, out[:]...)
+}
+
+// Decode sets e to the decoded value of in. If in is not a 32 byte canonical
+// encoding, Decode returns an error, and the receiver is unchanged.
+func (e *Element) Decode(in []byte) error {
+	if len(in) != 32 {
+		return errInvalidEncoding
+	}
+
+	// First, interpret the string as an integer s in little-endian representation.
+	s := &radix51.FieldElement{}
+	s.FromBytes(in)
+
+	// If the resulting value is >= p, decoding fails.
+	var buf [32]byte
+	s.ToBytes(buf[:])
+	if subtle.ConstantTimeCompare(buf[:], in) != 1 {
+		return errInvalidEncoding
+	}
+
+	// If IS_NEGATIVE(s) returns TRUE, decoding fails.
+	if s.IsNegative() == 1 {
+		return errInvalidEncoding
+	}
+
+	// ss = s^2
+	sSqr := &radix51.FieldElement{}
+	sSqr.Square(s)
+
+	// u1 = 1 - ss
+	u1 := &radix51.FieldElement{}
+	u1.Sub(radix51.One, sSqr)
+
+	// u2 = 1 + ss
+	u2 := &radix51.FieldElement{}
+	u2.Add(radix51.One, sSqr)
+
+	// u2_sqr = u2^2
+	u2Sqr := &radix51.FieldElement{}
+	u2Sqr.Square(u2)
+
+	// v = -(D * u1^2) - u2_sqr
+	v := &radix51.FieldElement{}
+	v.Square(u1).Mul(v, group.D).Neg(v).Sub(v, u2Sqr)
+
+	// (was_square, invsqrt) = SQRT_RATIO_M1(1, v * u2_sqr)
+	invSqrt, tmp := &radix51.FieldElement{}, &radix51.FieldElement{}
+	_, wasSquare := invSqrt.SqrtRatio(radix51.One, tmp.Mul(v, u2Sqr))
+
+	// den_x = invsqrt * u2
+	// den_y = invsqrt * den_x * v
+	denX, denY := &radix51.FieldElement{}, &radix51.FieldElement{}
+	denX.Mul(invSqrt, u2)
+	denY.Mul(invSqrt, denX).Mul(denY, v)
+
+	// x = CT_ABS(2 * s * den_x)
+	// y = u1 * den_y
+	// t = x * y
+	var out group.ExtendedGroupElement
+	out.X.Add(s, s).Mul(&out.X, denX).Abs(&out.X)
+	out.Y.Mul(u1, denY)
+	out.Z.One()
+	out.T.Mul(&out.X, &out.Y)
+
+	// If was_square is FALSE, or IS_NEGATIVE(t) returns TRUE, or y = 0, decoding fails.
+	if wasSquare == 0 || out.T.IsNegative() == 1 || out.Y.Equal(radix51.Zero) == 1 {
+		return errInvalidEncoding
+	}
+
+	// Otherwise, return the internal representation in extended coordinates (x, y, 1, t).
+	e.r.Set(&out)
+	return nil
+}
+
+// Add sets e = p + q, and returns e.
+func (e *Element) Add(p, q *Element) *Element {
+	e.r.Add(&p.r, &q.r)
+	return e
+}
+
+// Sub sets e = p - q, and returns e.
+func (e *Element) Sub(p, q *Element) *Element {
+	e.r.Sub(&p.r, &q.r)
+	return e
+}
+
+// Neg sets e = -p, and returns e.
+func (e *Element) Neg(p *Element) *Element {
+	e.r.Neg(&p.r)
+	return e
+}
+
+// ScalarMult sets e = k*p, where k is a 32-byte little-endian scalar, and
+// returns e.
+func (e *Element) ScalarMult(k []byte, p *Element) *Element {
+	var s [32]byte
+	if len(k) != 32 {
+		panic("ristretto255: invalid scalar length")
+	}
+	copy(s[:], k)
+	e.r.ScalarMult(&s, &p.r)
+	return e
+}
+
+// ScalarBaseMult sets e = k*B, where B is the canonical generator and k is a
+// 32-byte little-endian scalar, and returns e.
+func (e *Element) ScalarBaseMult(k []byte) *Element {
+	var s [32]byte
+	if len(k) != 32 {
+		panic("ristretto255: invalid scalar length")
+	}
+	copy(s[:], k)
+	e.r.ScalarBaseMult(&s)
+	return e
+}
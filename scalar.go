@@ -0,0 +1,311 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/scalar"
+)
+
+// Scalar is an integer modulo the group order
+// L = 2^252 + 27742317777372353535851937790883648493.
+//
+// Arithmetic runs in constant time and doesn't allocate.
+//
+// The zero value is a valid zero element. Methods follow the same receiver
+// convention as Point: the receiver is set to the result and returned, and it
+// may alias any of the operands.
+type Scalar struct {
+	s scalar.Scalar
+}
+
+// NewScalar returns a new zero Scalar.
+func NewScalar() *Scalar {
+	return new(Scalar)
+}
+
+// NewRandomScalar returns a uniformly distributed scalar, reducing 64 bytes
+// read from rand modulo L. Reducing only 32 bytes would bias the result, and
+// rejection sampling would need a variable amount of input.
+func NewRandomScalar(rand io.Reader) (*Scalar, error) {
+	var b [64]byte
+	if _, err := io.ReadFull(rand, b[:]); err != nil {
+		return nil, err
+	}
+	s := new(Scalar)
+	s.s.FromUniformBytes(b[:])
+	return s, nil
+}
+
+// ScalarFromBig returns k reduced modulo L as a Scalar. k may be negative or
+// larger than L, so any big.Int scalar used with the elliptic.Curve methods
+// converts correctly, as long as it's only used with points in the
+// prime-order subgroup: ScalarMult reduces modulo 8*L instead.
+func ScalarFromBig(k *big.Int) *Scalar {
+	e := new(big.Int).Mod(k, Ed25519().Params().N)
+	s := new(Scalar)
+	s.s.FromBytes(ScalarToLittleEndian(e))
+	return s
+}
+
+// BigInt returns s as a big.Int in [0, L).
+func (s *Scalar) BigInt() *big.Int {
+	return ScalarFromLittleEndian(s.Bytes())
+}
+
+// Set sets s = x, and returns s.
+func (s *Scalar) Set(x *Scalar) *Scalar {
+	s.s.Set(&x.s)
+	return s
+}
+
+// Add sets s = x + y mod L, and returns s.
+func (s *Scalar) Add(x, y *Scalar) *Scalar {
+	s.s.Add(&x.s, &y.s)
+	return s
+}
+
+// Sub sets s = x - y mod L, and returns s.
+func (s *Scalar) Sub(x, y *Scalar) *Scalar {
+	s.s.Sub(&x.s, &y.s)
+	return s
+}
+
+// Negate sets s = -x mod L, and returns s.
+func (s *Scalar) Negate(x *Scalar) *Scalar {
+	s.s.Neg(&x.s)
+	return s
+}
+
+// Mul sets s = x * y mod L, and returns s.
+func (s *Scalar) Mul(x, y *Scalar) *Scalar {
+	s.s.Mul(&x.s, &y.s)
+	return s
+}
+
+// MultiplyAdd sets s = x * y + z mod L, and returns s. This is the operation
+// at the core of signing, S = r + k * a, and the equivalent of sc_muladd in
+// the ref10 implementation. It runs in constant time.
+func (s *Scalar) MultiplyAdd(x, y, z *Scalar) *Scalar {
+	s.s.MulAdd(&x.s, &y.s, &z.s)
+	return s
+}
+
+// Invert sets s to the inverse of a nonzero x modulo L, and returns s. If x
+// is zero, s is set to zero. It runs in constant time.
+func (s *Scalar) Invert(x *Scalar) *Scalar {
+	s.s.Invert(&x.s)
+	return s
+}
+
+// Sqrt sets s to a square root of x modulo L, and returns s and 1, if x is a
+// square. Of the two roots, s is the one with an even canonical encoding. If x
+// is not a square, s is set to zero, and Sqrt returns s and 0. It runs in
+// constant time.
+func (s *Scalar) Sqrt(x *Scalar) (*Scalar, int) {
+	_, wasSquare := s.s.Sqrt(&x.s)
+	return s, wasSquare
+}
+
+// Legendre returns the Legendre symbol of s modulo L: 1 if s is a nonzero
+// square, -1 if it is not a square, and 0 if it is zero. Exactly half of the
+// nonzero scalars are squares. It runs in constant time.
+func (s *Scalar) Legendre() int {
+	return scalar.Legendre(&s.s)
+}
+
+// Equal returns 1 if s and t are equal, and 0 otherwise. It runs in constant
+// time.
+func (s *Scalar) Equal(t *Scalar) int {
+	return s.s.Equal(&t.s)
+}
+
+// IsZero returns 1 if s is zero, and 0 otherwise. It runs in constant time.
+func (s *Scalar) IsZero() int {
+	return s.s.IsZero()
+}
+
+// Select sets s to a if cond == 1, and to b if cond == 0, and returns s. It
+// runs in constant time, and panics if cond is not 0 or 1.
+func (s *Scalar) Select(a, b *Scalar, cond int) *Scalar {
+	checkCond(cond)
+	s.s.Select(&a.s, &b.s, cond)
+	return s
+}
+
+// SetCanonicalBytes sets s to the 32-byte little-endian integer x, and
+// returns s. If x is not the canonical encoding of a scalar, that is, if it
+// isn't less than L, SetCanonicalBytes returns nil and an error, and s is
+// unchanged.
+func (s *Scalar) SetCanonicalBytes(x []byte) (*Scalar, error) {
+	if len(x) != 32 {
+		return nil, errors.New("ed25519: invalid scalar length")
+	}
+	if scalar.IsCanonical(x) != 1 {
+		return nil, errors.New("ed25519: invalid scalar encoding")
+	}
+	s.s.FromBytes(x)
+	return s, nil
+}
+
+// IsCanonicalScalar reports whether b is the canonical encoding of a scalar,
+// a 32-byte little-endian integer less than L. RFC 8032 requires verifiers
+// to reject signatures whose S fails this check, or signatures would be
+// malleable. It runs in constant time with respect to the value of b.
+func IsCanonicalScalar(b []byte) bool {
+	return len(b) == 32 && scalar.IsCanonical(b) == 1
+}
+
+// SetUniformBytes sets s to the 64-byte little-endian integer x reduced
+// modulo L, and returns s. If x is uniformly random, the result is
+// indistinguishable from a uniform scalar, which a 32-byte input wouldn't
+// give. This is how RFC 8032 derives scalars from SHA-512 digests.
+// SetUniformBytes returns nil and an error if x is not 64 bytes long.
+func (s *Scalar) SetUniformBytes(x []byte) (*Scalar, error) {
+	if len(x) != 64 {
+		return nil, errors.New("ed25519: invalid SetUniformBytes input length")
+	}
+	s.s.FromUniformBytes(x)
+	return s, nil
+}
+
+// Bytes returns the canonical 32-byte little-endian encoding of s, the form
+// taken by the scalar arguments of Point.
+func (s *Scalar) Bytes() []byte {
+	out := make([]byte, 32)
+	s.s.ToBytes(out)
+	return out
+}
+
+// BatchInvert sets every element of scalars to its inverse modulo L, with
+// Montgomery's trick: the cost is a single inversion and three
+// multiplications per element, instead of one inversion per element. Zero
+// elements are left as zero, like with Invert, and don't affect the others.
+// It runs in constant time with respect to the values, but not their number.
+func BatchInvert(scalars []Scalar) {
+	if len(scalars) == 0 {
+		return
+	}
+
+	// prefix[i] is the product of all the nonzero elements before i.
+	prefix := make([]scalar.Scalar, len(scalars))
+	var acc, x, one scalar.Scalar
+	one.One()
+	acc.One()
+	for i := range scalars {
+		prefix[i] = acc
+		x.Select(&one, &scalars[i].s, scalars[i].s.IsZero())
+		acc.Mul(&acc, &x)
+	}
+
+	var inv Scalar
+	inv.s.Invert(&acc)
+
+	// Walking backwards, inv is the inverse of the product of the nonzero
+	// elements up to and including i.
+	var t scalar.Scalar
+	for i := len(scalars) - 1; i >= 0; i-- {
+		isZero := scalars[i].s.IsZero()
+		x.Select(&one, &scalars[i].s, isZero)
+		t.Mul(&inv.s, &prefix[i])
+		inv.s.Mul(&inv.s, &x)
+		scalars[i].s.Select(&scalars[i].s, &t, isZero)
+	}
+}
+
+// ClampScalarBytes returns k with the bits set and cleared as RFC 7748 and
+// RFC 8032 require for private scalars: the three low bits are cleared, so
+// the scalar is a multiple of the cofactor, bit 255 is cleared and bit 254 is
+// set, so it has a fixed bit length.
+//
+// The result is meant for Point.ScalarMult and Point.ScalarBaseMult, which
+// don't reduce their argument. It's generally not below L.
+func ClampScalarBytes(k [32]byte) [32]byte {
+	k[0] &= 248
+	k[31] &= 127
+	k[31] |= 64
+	return k
+}
+
+// SetBytesWithClamping applies ClampScalarBytes to the 32-byte x, sets s to
+// the result reduced modulo L, and returns s. This is how RFC 8032 derives
+// the secret scalar from the first half of a hashed private key. Reducing
+// modulo L loses the multiple-of-8 property of the clamped value, which only
+// matters for points outside the prime-order subgroup.
+//
+// SetBytesWithClamping returns nil and an error if x is not 32 bytes long.
+func (s *Scalar) SetBytesWithClamping(x []byte) (*Scalar, error) {
+	if len(x) != 32 {
+		return nil, errors.New("ed25519: invalid SetBytesWithClamping input length")
+	}
+	var k [32]byte
+	copy(k[:], x)
+	k = ClampScalarBytes(k)
+	s.s.FromBytes(k[:])
+	return s, nil
+}
+
+// NonAdjacentForm returns the width-w non-adjacent form of s: digits d[i]
+// with s = sum(d[i] * 2^i), where every nonzero digit is odd and less than
+// 2^(w-1) in absolute value, and any w consecutive digits contain at most one
+// nonzero digit. It panics if w is not between 2 and 8.
+//
+// This is the recoding variable-time multiplication algorithms use with a
+// table of odd multiples of a point. It runs in variable time.
+func (s *Scalar) NonAdjacentForm(w uint) [256]int8 {
+	var k [32]byte
+	s.s.ToBytes(k[:])
+	naf := group.NonAdjacentForm(&k, w)
+
+	// s is below 2^253, so the carry digit is always zero.
+	var out [256]int8
+	copy(out[:], naf[:256])
+	return out
+}
+
+// SignedRadix2w returns the signed fixed-window decomposition of s: digits
+// d[i] in [-2^(w-1), 2^(w-1)) with s = sum(d[i] * 2^(w*i)). Unlike the
+// non-adjacent form, every window has a digit, which is what constant-time
+// algorithms that scan a table for each window need. It panics if w is not
+// between 2 and 16.
+func (s *Scalar) SignedRadix2w(w uint) []int32 {
+	var k [32]byte
+	s.s.ToBytes(k[:])
+	return group.SignedRadix2w(&k, w)
+}
+
+// SplitHalves returns the low and high 128 bits of s as 16-byte
+// little-endian integers, so that s = lo + 2^128 * hi. Since s is below L,
+// hi is below 2^125.
+//
+// With Q = 2^128 * P precomputed, s * P = lo * P + hi * Q takes half the
+// doublings, which is how verification of s*B - h*A can share a single
+// 128-step double-and-add chain across four half-size multiplications.
+func (s *Scalar) SplitHalves() (lo, hi [16]byte) {
+	b := s.Bytes()
+	copy(lo[:], b[:16])
+	copy(hi[:], b[16:])
+	return lo, hi
+}
+
+// HalfWidthNAFs returns the width-w non-adjacent forms of the two halves of
+// s from SplitHalves, so that s = sum(lo[i] * 2^i) + 2^128 * sum(hi[i] * 2^i).
+// The NAF of a 128-bit integer can carry into a 129th digit. It panics if w
+// is not between 2 and 8, and it runs in variable time.
+func (s *Scalar) HalfWidthNAFs(w uint) (lo, hi [129]int8) {
+	l, h := s.SplitHalves()
+	var k [32]byte
+	copy(k[:], l[:])
+	loNAF := group.NonAdjacentForm(&k, w)
+	copy(k[:16], h[:])
+	hiNAF := group.NonAdjacentForm(&k, w)
+	copy(lo[:], loNAF[:129])
+	copy(hi[:], hiNAF[:129])
+	return lo, hi
+}
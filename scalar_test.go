@@ -0,0 +1,378 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func randomScalarElement(t testing.TB) *Scalar {
+	s, err := NewRandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// scalarOrderBytes is L as a 32-byte little-endian integer.
+var scalarOrderBytes, _ = hex.DecodeString("edd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010")
+
+func TestScalarSetUniformBytes(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{
+			hex.EncodeToString(bytes.Repeat([]byte{0xff}, 64)),
+			"000f9c44e31106a447938568a71b0ed065bef517d273ecce3d9a307c1b419903",
+		},
+		{
+			"000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f" +
+				"202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f",
+			"7a3c6282f02d37a05023b60d5428e6cc5961d4c31221937adae0b574e4d07205",
+		},
+	}
+	for _, tt := range tests {
+		in, _ := hex.DecodeString(tt.in)
+		s, err := NewScalar().SetUniformBytes(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(s.Bytes()); got != tt.out {
+			t.Errorf("got %s, want %s", got, tt.out)
+		}
+	}
+
+	if _, err := NewScalar().SetUniformBytes(make([]byte, 32)); err == nil {
+		t.Error("accepted a 32-byte input")
+	}
+}
+
+func TestScalarSetCanonicalBytes(t *testing.T) {
+	s := randomScalarElement(t)
+	s2, err := NewScalar().SetCanonicalBytes(s.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Equal(s2) != 1 {
+		t.Error("scalar did not survive Bytes/SetCanonicalBytes")
+	}
+
+	lMinusOne := append([]byte{}, scalarOrderBytes...)
+	lMinusOne[0]--
+	if _, err := NewScalar().SetCanonicalBytes(lMinusOne); err != nil {
+		t.Errorf("rejected L-1: %v", err)
+	}
+
+	if !IsCanonicalScalar(lMinusOne) {
+		t.Error("IsCanonicalScalar rejected L-1")
+	}
+
+	lPlusOne := append([]byte{}, scalarOrderBytes...)
+	lPlusOne[0]++
+	for _, bad := range [][]byte{scalarOrderBytes, lPlusOne, bytes.Repeat([]byte{0xff}, 32), lMinusOne[:31]} {
+		if _, err := s.SetCanonicalBytes(bad); err == nil {
+			t.Errorf("accepted %x", bad)
+		}
+		if IsCanonicalScalar(bad) {
+			t.Errorf("IsCanonicalScalar accepted %x", bad)
+		}
+	}
+	if s.Equal(s2) != 1 {
+		t.Error("failed SetCanonicalBytes modified the receiver")
+	}
+}
+
+func TestScalarArithmetic(t *testing.T) {
+	x, y := randomScalarElement(t), randomScalarElement(t)
+	zero, one := NewScalar(), NewScalar()
+	one.SetCanonicalBytes(append([]byte{1}, make([]byte, 31)...))
+
+	if NewScalar().Sub(NewScalar().Add(x, y), y).Equal(x) != 1 {
+		t.Error("(x + y) - y != x")
+	}
+	if NewScalar().Add(x, NewScalar().Negate(x)).Equal(zero) != 1 {
+		t.Error("x + (-x) != 0")
+	}
+	if NewScalar().Mul(x, NewScalar().Invert(x)).Equal(one) != 1 {
+		t.Error("x * 1/x != 1")
+	}
+	if NewScalar().Invert(zero).Equal(zero) != 1 {
+		t.Error("1/0 != 0")
+	}
+	if NewScalar().Negate(zero).Equal(zero) != 1 {
+		t.Error("-0 != 0")
+	}
+	if zero.IsZero() != 1 || one.IsZero() != 0 || NewScalar().Sub(x, x).IsZero() != 1 {
+		t.Error("IsZero is wrong")
+	}
+	if x.Equal(y) != 0 || x.Equal(NewScalar().Set(x)) != 1 {
+		t.Error("Equal is wrong")
+	}
+	if NewScalar().Select(x, y, 1).Equal(x) != 1 || NewScalar().Select(x, y, 0).Equal(y) != 1 {
+		t.Error("Select is wrong")
+	}
+
+	// (x + y) * B = x*B + y*B ties scalar arithmetic to the group.
+	sum := new(Point).ScalarBaseMult(NewScalar().Add(x, y).Bytes())
+	want := new(Point).Add(new(Point).ScalarBaseMult(x.Bytes()), new(Point).ScalarBaseMult(y.Bytes()))
+	if sum.Equal(want) != 1 {
+		t.Error("(x + y)*B != x*B + y*B")
+	}
+	prod := new(Point).ScalarBaseMult(NewScalar().Mul(x, y).Bytes())
+	want = new(Point).ScalarMult(y.Bytes(), new(Point).ScalarBaseMult(x.Bytes()))
+	if prod.Equal(want) != 1 {
+		t.Error("(x * y)*B != y*(x*B)")
+	}
+
+	// Aliasing.
+	z := NewScalar().Set(x)
+	z.Mul(z, z)
+	if z.Equal(NewScalar().Mul(x, x)) != 1 {
+		t.Error("aliased Mul disagrees")
+	}
+}
+
+func TestScalarMultiplyAdd(t *testing.T) {
+	x, y, z := randomScalarElement(t), randomScalarElement(t), randomScalarElement(t)
+	want := NewScalar().Add(NewScalar().Mul(x, y), z)
+	if NewScalar().MultiplyAdd(x, y, z).Equal(want) != 1 {
+		t.Error("x * y + z disagrees with Mul and Add")
+	}
+	if z.MultiplyAdd(x, y, z).Equal(want) != 1 {
+		t.Error("aliased MultiplyAdd disagrees")
+	}
+}
+
+func TestBatchInvert(t *testing.T) {
+	BatchInvert(nil)
+
+	scalars := make([]Scalar, 5)
+	for i := range scalars {
+		scalars[i].Set(randomScalarElement(t))
+	}
+	scalars[2] = Scalar{}
+
+	want := make([]Scalar, len(scalars))
+	for i := range scalars {
+		want[i].Invert(&scalars[i])
+	}
+
+	BatchInvert(scalars)
+	for i := range scalars {
+		if scalars[i].Equal(&want[i]) != 1 {
+			t.Errorf("element %d: batch inverse disagrees with Invert", i)
+		}
+	}
+}
+
+func TestScalarSetBytesWithClamping(t *testing.T) {
+	// The RFC 8032 test 1 private key, hashed, gives the secret scalar s with
+	// A = s*B.
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	pub, _ := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	h := sha512.Sum512(seed)
+
+	s, err := NewScalar().SetBytesWithClamping(h[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := new(Point).ScalarBaseMult(s.Bytes()).Bytes(); !bytes.Equal(got, pub) {
+		t.Errorf("got public key %x, want %x", got, pub)
+	}
+
+	var k [32]byte
+	copy(k[:], h[:32])
+	k = ClampScalarBytes(k)
+	if k[0]&7 != 0 || k[31]&0xc0 != 0x40 {
+		t.Errorf("ClampScalarBytes returned %x", k)
+	}
+	if got := new(Point).ScalarBaseMult(k[:]).Bytes(); !bytes.Equal(got, pub) {
+		t.Errorf("clamped bytes give public key %x, want %x", got, pub)
+	}
+
+	if _, err := NewScalar().SetBytesWithClamping(h[:]); err == nil {
+		t.Error("accepted a 64-byte input")
+	}
+}
+
+func TestNewRandomScalar(t *testing.T) {
+	s1, s2 := randomScalarElement(t), randomScalarElement(t)
+	if s1.Equal(s2) == 1 {
+		t.Error("two random scalars are equal")
+	}
+
+	// 64 bytes of 0xff reduce to the same value as through SetUniformBytes.
+	s, err := NewRandomScalar(bytes.NewReader(bytes.Repeat([]byte{0xff}, 64)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "000f9c44e31106a447938568a71b0ed065bef517d273ecce3d9a307c1b419903"
+	if got := hex.EncodeToString(s.Bytes()); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	if _, err := NewRandomScalar(bytes.NewReader(make([]byte, 63))); err == nil {
+		t.Error("accepted a short read")
+	}
+}
+
+func TestScalarNonAdjacentForm(t *testing.T) {
+	s := randomScalarElement(t)
+	want := ScalarFromLittleEndian(s.Bytes())
+	for w := uint(2); w <= 8; w++ {
+		naf := s.NonAdjacentForm(w)
+
+		got := new(big.Int)
+		last := -int(w)
+		for i := len(naf) - 1; i >= 0; i-- {
+			got.Lsh(got, 1)
+			got.Add(got, big.NewInt(int64(naf[i])))
+		}
+		for i, d := range naf {
+			if d == 0 {
+				continue
+			}
+			if d%2 == 0 || int(d) >= 1<<(w-1) || int(d) <= -1<<(w-1) {
+				t.Errorf("w = %d: invalid digit %d", w, d)
+			}
+			if i-last < int(w) {
+				t.Errorf("w = %d: nonzero digits at %d and %d", w, last, i)
+			}
+			last = i
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("w = %d: digits don't add up to the scalar", w)
+		}
+	}
+}
+
+func TestScalarSignedRadix2w(t *testing.T) {
+	s := randomScalarElement(t)
+	want := ScalarFromLittleEndian(s.Bytes())
+	for w := uint(2); w <= 16; w++ {
+		digits := s.SignedRadix2w(w)
+
+		got := new(big.Int)
+		for i := len(digits) - 1; i >= 0; i-- {
+			d := digits[i]
+			if int(d) >= 1<<(w-1) || int(d) < -1<<(w-1) {
+				t.Errorf("w = %d: invalid digit %d", w, d)
+			}
+			got.Lsh(got, w)
+			got.Add(got, big.NewInt(int64(d)))
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("w = %d: digits don't add up to the scalar", w)
+		}
+	}
+}
+
+func TestScalarMultiplyAddSignature(t *testing.T) {
+	// RFC 8032, Section 7.1, TEST 1: S = r + k * a for an empty message.
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	sig, _ := hex.DecodeString("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+	h := sha512.Sum512(seed)
+
+	a, _ := NewScalar().SetBytesWithClamping(h[:32])
+	A := new(Point).ScalarBaseMult(a.Bytes())
+
+	rDigest := sha512.Sum512(h[32:])
+	r, _ := NewScalar().SetUniformBytes(rDigest[:])
+	R := new(Point).ScalarBaseMult(r.Bytes())
+
+	kh := sha512.New()
+	kh.Write(R.Bytes())
+	kh.Write(A.Bytes())
+	k, _ := NewScalar().SetUniformBytes(kh.Sum(nil))
+
+	S := NewScalar().MultiplyAdd(k, a, r)
+	if got := append(R.Bytes(), S.Bytes()...); !bytes.Equal(got, sig) {
+		t.Errorf("got signature %x, want %x", got, sig)
+	}
+}
+
+func TestScalarSqrt(t *testing.T) {
+	x := randomScalarElement(t)
+	sq := NewScalar().Mul(x, x)
+	r, wasSquare := NewScalar().Sqrt(sq)
+	if wasSquare != 1 {
+		t.Fatal("x^2 is not a square")
+	}
+	if r.Equal(x) != 1 && r.Equal(NewScalar().Negate(x)) != 1 {
+		t.Error("sqrt(x^2) is neither x nor -x")
+	}
+	if sq.Legendre() != 1 {
+		t.Error("Legendre(x^2) != 1")
+	}
+
+	// 2 is not a square modulo L, since L = 5 mod 8.
+	two, _ := NewScalar().SetCanonicalBytes(append([]byte{2}, make([]byte, 31)...))
+	if _, wasSquare := NewScalar().Sqrt(two); wasSquare != 0 {
+		t.Error("2 is a square")
+	}
+	if two.Legendre() != -1 {
+		t.Error("Legendre(2) != -1")
+	}
+}
+
+func TestScalarHalfWidthNAFs(t *testing.T) {
+	s := randomScalarElement(t)
+
+	lo, hi := s.SplitHalves()
+	got := new(big.Int).Lsh(ScalarFromLittleEndian(hi[:]), 128)
+	got.Add(got, ScalarFromLittleEndian(lo[:]))
+	if got.Cmp(ScalarFromLittleEndian(s.Bytes())) != 0 {
+		t.Error("lo + 2^128 * hi != s")
+	}
+
+	loNAF, hiNAF := s.HalfWidthNAFs(5)
+	nafValue := func(naf [129]int8) *big.Int {
+		v := new(big.Int)
+		for i := len(naf) - 1; i >= 0; i-- {
+			v.Lsh(v, 1)
+			v.Add(v, big.NewInt(int64(naf[i])))
+		}
+		return v
+	}
+	if nafValue(loNAF).Cmp(ScalarFromLittleEndian(lo[:])) != 0 ||
+		nafValue(hiNAF).Cmp(ScalarFromLittleEndian(hi[:])) != 0 {
+		t.Error("NAF digits don't add up to the halves")
+	}
+
+	// s*P = lo*P + hi*(2^128*P)
+	P := new(Point).ScalarBaseMult(randomScalar(t))
+	Q := new(Point).MultByPow2(P, 128)
+	var loK, hiK [32]byte
+	copy(loK[:], lo[:])
+	copy(hiK[:], hi[:])
+	sum := new(Point).Add(new(Point).ScalarMult(loK[:], P), new(Point).ScalarMult(hiK[:], Q))
+	if sum.Equal(new(Point).ScalarMult(s.Bytes(), P)) != 1 {
+		t.Error("lo*P + hi*(2^128*P) != s*P")
+	}
+}
+
+func TestScalarFromBig(t *testing.T) {
+	x := randomScalarElement(t)
+	if ScalarFromBig(x.BigInt()).Equal(x) != 1 {
+		t.Error("scalar did not survive BigInt round trip")
+	}
+
+	N := Ed25519().Params().N
+	k := new(big.Int).Add(x.BigInt(), new(big.Int).Mul(N, big.NewInt(1000)))
+	if ScalarFromBig(k).Equal(x) != 1 {
+		t.Error("x + 1000*L did not reduce to x")
+	}
+	if ScalarFromBig(new(big.Int).Neg(x.BigInt())).Equal(NewScalar().Negate(x)) != 1 {
+		t.Error("-x did not reduce to L - x")
+	}
+	if ScalarFromBig(N).IsZero() != 1 {
+		t.Error("L did not reduce to zero")
+	}
+}
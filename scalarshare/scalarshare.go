@@ -0,0 +1,128 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package scalarshare implements Shamir secret sharing over the edwards25519
+// scalar field.
+//
+// Split hides a secret scalar as the constant term of a random polynomial of
+// degree t-1 modulo L, and hands out its values at x = 1, ..., n. Any t of
+// the n shares recover the secret by Lagrange interpolation, and any fewer
+// reveal nothing about it. Because the field is the scalar field of the
+// group, shares of a secret key a are also usable in the exponent: the same
+// Lagrange coefficients combine partial results a_i*P into a*P, which is what
+// threshold signing builds on.
+package scalarshare
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/polynomial"
+)
+
+// Share is the value of the sharing polynomial at x = Index. Indexes start at
+// 1, since the value at 0 is the secret.
+type Share struct {
+	Index uint32
+	Value ed25519.Scalar
+}
+
+// Split splits secret into n shares, any t of which recover it with Combine.
+// The polynomial coefficients are read from crypto/rand. Split returns an
+// error if t is less than 1 or greater than n.
+func Split(secret *ed25519.Scalar, t, n int) ([]Share, error) {
+	if t < 1 || t > n {
+		return nil, errors.New("scalarshare: invalid threshold")
+	}
+	if uint64(n) >= 1<<32 {
+		return nil, errors.New("scalarshare: too many shares")
+	}
+
+	f, err := polynomial.NewRandom(rand.Reader, secret, t-1)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i].Index = uint32(i + 1)
+		shares[i].Value.Set(f.Evaluate(indexScalar(shares[i].Index)))
+	}
+	return shares, nil
+}
+
+// Combine recovers the secret from at least t shares produced by Split, by
+// interpolating their polynomial at x = 0. With fewer than t shares the
+// result is an unrelated scalar: Combine can't tell, since any t-1 shares are
+// consistent with every secret.
+//
+// Combine returns an error if shares is empty, or if an index is zero or
+// appears twice.
+func Combine(shares []Share) (*ed25519.Scalar, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("scalarshare: no shares")
+	}
+	seen := make(map[uint32]bool, len(shares))
+	for _, s := range shares {
+		if s.Index == 0 || seen[s.Index] {
+			return nil, errors.New("scalarshare: invalid or duplicate share index")
+		}
+		seen[s.Index] = true
+	}
+
+	coeffs := LagrangeCoefficients(indexes(shares))
+	secret := ed25519.NewScalar()
+	for i := range shares {
+		secret.MultiplyAdd(&coeffs[i], &shares[i].Value, secret)
+	}
+	return secret, nil
+}
+
+// LagrangeCoefficients returns the coefficients l_i such that
+// f(0) = sum(l_i * f(x_i)) for every polynomial f of degree less than
+// len(xs), where x_i = xs[i]. The indexes must be nonzero and distinct.
+//
+// Applied to points instead of scalars, sum(l_i * (f(x_i) * P)) = f(0) * P,
+// which combines partial results computed with each share.
+func LagrangeCoefficients(xs []uint32) []ed25519.Scalar {
+	// l_i = prod(x_j) / (x_i * prod(x_j - x_i)) over j != i.
+	num := indexScalar(1)
+	for _, x := range xs {
+		num.Mul(num, indexScalar(x))
+	}
+
+	coeffs := make([]ed25519.Scalar, len(xs))
+	for i, xi := range xs {
+		d := indexScalar(xi)
+		for j, xj := range xs {
+			if j != i {
+				d.Mul(d, new(ed25519.Scalar).Sub(indexScalar(xj), indexScalar(xi)))
+			}
+		}
+		coeffs[i].Set(d)
+	}
+	ed25519.BatchInvert(coeffs)
+	for i := range coeffs {
+		coeffs[i].Mul(&coeffs[i], num)
+	}
+	return coeffs
+}
+
+func indexes(shares []Share) []uint32 {
+	xs := make([]uint32, len(shares))
+	for i := range shares {
+		xs[i] = shares[i].Index
+	}
+	return xs
+}
+
+// indexScalar returns x as a scalar.
+func indexScalar(x uint32) *ed25519.Scalar {
+	var b [32]byte
+	binary.LittleEndian.PutUint32(b[:], x)
+	s, _ := ed25519.NewScalar().SetCanonicalBytes(b[:])
+	return s
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package scalarshare
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func randomScalar(t testing.TB) *ed25519.Scalar {
+	s, err := ed25519.NewRandomScalar(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSplitCombine(t *testing.T) {
+	secret := randomScalar(t)
+	shares, err := Split(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("got %d shares, want 5", len(shares))
+	}
+
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var picked []Share
+		for _, i := range subset {
+			picked = append(picked, shares[i])
+		}
+		got, err := Combine(picked)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Equal(secret) != 1 {
+			t.Errorf("shares %v did not recover the secret", subset)
+		}
+	}
+
+	got, err := Combine(shares[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Equal(secret) == 1 {
+		t.Error("two shares recovered a 3-of-5 secret")
+	}
+}
+
+func TestSplitOneOfOne(t *testing.T) {
+	secret := randomScalar(t)
+	shares, err := Split(secret, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shares[0].Value.Equal(secret) != 1 {
+		t.Error("a 1-of-1 share is not the secret")
+	}
+}
+
+func TestSplitCombineErrors(t *testing.T) {
+	secret := randomScalar(t)
+	for _, tn := range [][2]int{{0, 3}, {4, 3}, {-1, 2}} {
+		if _, err := Split(secret, tn[0], tn[1]); err == nil {
+			t.Errorf("Split accepted t = %d, n = %d", tn[0], tn[1])
+		}
+	}
+
+	shares, _ := Split(secret, 2, 3)
+	if _, err := Combine(nil); err == nil {
+		t.Error("Combine accepted no shares")
+	}
+	if _, err := Combine([]Share{shares[0], shares[0]}); err == nil {
+		t.Error("Combine accepted a duplicate share")
+	}
+	if _, err := Combine([]Share{{Index: 0}, shares[1]}); err == nil {
+		t.Error("Combine accepted a zero index")
+	}
+}
+
+func TestLagrangeCoefficientsInTheExponent(t *testing.T) {
+	secret := randomScalar(t)
+	shares, _ := Split(secret, 2, 3)
+	picked := []Share{shares[2], shares[0]}
+
+	// sum(l_i * (s_i * B)) = secret * B
+	coeffs := LagrangeCoefficients([]uint32{picked[0].Index, picked[1].Index})
+	acc := ed25519.NewIdentityPoint()
+	for i := range picked {
+		partial := new(ed25519.Point).ScalarBaseMult(picked[i].Value.Bytes())
+		acc.Add(acc, partial.ScalarMult(coeffs[i].Bytes(), partial))
+	}
+	if acc.Equal(new(ed25519.Point).ScalarBaseMult(secret.Bytes())) != 1 {
+		t.Error("combining partial results in the exponent failed")
+	}
+}
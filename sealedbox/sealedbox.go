@@ -0,0 +1,151 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sealedbox implements anonymous public-key encryption, compatible
+// with the crypto_box_seal function of libsodium.
+//
+// A sealed box is encrypted to the recipient's X25519 public key with a fresh
+// ephemeral key pair, whose private key is then discarded: the recipient can
+// decrypt and authenticate the contents, but learns nothing about the
+// sender, who can't decrypt the box either. The ciphertext is
+//
+//	ephemeral public key || XSalsa20-Poly1305(message)
+//
+// under the key HSalsa20(X25519(ephemeral, recipient), 0), with the nonce
+// BLAKE2b-192(ephemeral public key || recipient public key).
+//
+// SealEd25519 and OpenEd25519 encrypt to Ed25519 keys, by converting them to
+// X25519 keys as crypto_sign_ed25519_pk_to_curve25519 and
+// crypto_sign_ed25519_sk_to_curve25519 do.
+package sealedbox
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/internal/group"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+const (
+	// KeySize is the size of X25519 public and private keys.
+	KeySize = 32
+	// Overhead is the number of bytes a sealed box is longer than its
+	// message: the ephemeral public key, and the Poly1305 tag.
+	Overhead = KeySize + secretbox.Overhead
+)
+
+var basepoint = [32]byte{9}
+
+var errOpen = errors.New("sealedbox: message authentication failed")
+
+// Seal encrypts message to the X25519 publicKey, using entropy from rand for
+// the ephemeral key. It returns an error if publicKey is of low order, like
+// crypto_box_seal. It panics if len(publicKey) is not KeySize.
+func Seal(rand io.Reader, message, publicKey []byte) ([]byte, error) {
+	if l := len(publicKey); l != KeySize {
+		panic("sealedbox: bad public key length: " + strconv.Itoa(l))
+	}
+	var ephemeralPriv, ephemeralPub, pub [32]byte
+	if _, err := io.ReadFull(rand, ephemeralPriv[:]); err != nil {
+		return nil, err
+	}
+	group.X25519(&ephemeralPub, &ephemeralPriv, &basepoint)
+	copy(pub[:], publicKey)
+
+	key, err := sharedKey(&ephemeralPriv, &pub)
+	if err != nil {
+		return nil, err
+	}
+	nonce := boxNonce(&ephemeralPub, &pub)
+
+	box := make([]byte, KeySize, Overhead+len(message))
+	copy(box, ephemeralPub[:])
+	return secretbox.Seal(box, message, nonce, key), nil
+}
+
+// Open decrypts a box sealed to the X25519 key pair publicKey and privateKey,
+// and returns the message. It returns an error if the box was not sealed to
+// publicKey, or was modified. It panics if len(publicKey) or len(privateKey)
+// is not KeySize.
+func Open(box, publicKey, privateKey []byte) ([]byte, error) {
+	if l := len(publicKey); l != KeySize {
+		panic("sealedbox: bad public key length: " + strconv.Itoa(l))
+	}
+	if l := len(privateKey); l != KeySize {
+		panic("sealedbox: bad private key length: " + strconv.Itoa(l))
+	}
+	if len(box) < Overhead {
+		return nil, errOpen
+	}
+	var ephemeralPub, pub, priv [32]byte
+	copy(ephemeralPub[:], box)
+	copy(pub[:], publicKey)
+	copy(priv[:], privateKey)
+
+	key, err := sharedKey(&priv, &ephemeralPub)
+	if err != nil {
+		return nil, errOpen
+	}
+	message, ok := secretbox.Open(nil, box[KeySize:], boxNonce(&ephemeralPub, &pub), key)
+	if !ok {
+		return nil, errOpen
+	}
+	return message, nil
+}
+
+// SealEd25519 is like Seal, but encrypts message to the X25519 equivalent of
+// an Ed25519 public key, as converted by ed25519.PublicKeyToX25519. It
+// returns an error if publicKey is not a valid point of prime order. It
+// panics if len(publicKey) is not ed25519.PublicKeySize.
+func SealEd25519(rand io.Reader, message []byte, publicKey ed25519.PublicKey) ([]byte, error) {
+	pub, err := ed25519.PublicKeyToX25519(publicKey)
+	if err != nil {
+		return nil, errors.New("sealedbox: invalid Ed25519 public key")
+	}
+	return Seal(rand, message, pub)
+}
+
+// OpenEd25519 is like Open, for boxes sealed with SealEd25519 to the public
+// key of privateKey. It panics if len(privateKey) is not
+// ed25519.PrivateKeySize.
+func OpenEd25519(box []byte, privateKey ed25519.PrivateKey) ([]byte, error) {
+	if l := len(privateKey); l != ed25519.PrivateKeySize {
+		panic("sealedbox: bad private key length: " + strconv.Itoa(l))
+	}
+	pub, err := ed25519.PublicKeyToX25519(ed25519.PublicKey(privateKey[ed25519.SeedSize:]))
+	if err != nil {
+		return nil, errors.New("sealedbox: invalid Ed25519 private key")
+	}
+	return Open(box, pub, ed25519.PrivateKeyToX25519(privateKey))
+}
+
+// sharedKey returns the crypto_box key of priv and pub, HSalsa20 of their
+// X25519 shared secret. It returns an error if the secret is zero, which
+// means pub is of low order.
+func sharedKey(priv, pub *[32]byte) (*[32]byte, error) {
+	var shared, zero [32]byte
+	group.X25519(&shared, priv, pub)
+	if subtle.ConstantTimeCompare(shared[:], zero[:]) == 1 {
+		return nil, errors.New("sealedbox: low order public key")
+	}
+	key := new([32]byte)
+	salsa.HSalsa20(key, new([16]byte), &shared, &salsa.Sigma)
+	return key, nil
+}
+
+// boxNonce returns BLAKE2b-192(ephemeralPub || pub).
+func boxNonce(ephemeralPub, pub *[32]byte) *[24]byte {
+	h, _ := blake2b.New(24, nil)
+	h.Write(ephemeralPub[:])
+	h.Write(pub[:])
+	nonce := new([24]byte)
+	copy(nonce[:], h.Sum(nil))
+	return nonce
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sealedbox
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func sequence(start, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(start + i)
+	}
+	return b
+}
+
+// Boxes sealed by libsodium's crypto_box_seal.
+func TestOpenLibsodium(t *testing.T) {
+	priv := sequence(1, 32)
+	pub, _ := hex.DecodeString("07a37cbc142093c8b755dc1b10e86cb426374ad16aa853ed0bdfc0b2b86d1c7c")
+	box, _ := hex.DecodeString("7e91cc834868cce816b35909ed2a7df0128df5d394cf2c988630d01b0164bd6154f063bbe2bf1c64a90103603eb6f99480184a9b0a88c8389068962e0a90c203219353f2f33ff9")
+	got, err := Open(box, pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "sealed to an X25519 key"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	edPriv := ed25519.NewKeyFromSeed(sequence(100, 32))
+	box, _ = hex.DecodeString("ba0328a40f129bcc79ba5ae806cec8c9ed3da5b8d726fe01ab56480b8ab65a68e9467eb569e323d30b4cae60eb3d58263ce622ca50b1b7e690b50298fbf06bc39812483448bc6425")
+	got, err = OpenEd25519(box, edPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "sealed to an Ed25519 key"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, message := range [][]byte{nil, []byte("hello"), sequence(0, 1000)} {
+		box, err := SealEd25519(rand.Reader, message, pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(box) != len(message)+Overhead {
+			t.Errorf("box is %d bytes, want %d", len(box), len(message)+Overhead)
+		}
+		got, err := OpenEd25519(box, priv)
+		if err != nil || !bytes.Equal(got, message) {
+			t.Errorf("got %x, %v, want %x", got, err, message)
+		}
+
+		for _, i := range []int{0, KeySize, Overhead - 1, len(box) - 1} {
+			bad := append([]byte{}, box...)
+			bad[i] ^= 1
+			if _, err := OpenEd25519(bad, priv); err == nil {
+				t.Errorf("box modified at %d opened", i)
+			}
+		}
+		if _, err := OpenEd25519(box[:Overhead-1], priv); err == nil {
+			t.Error("short box opened")
+		}
+	}
+
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+	box, _ := SealEd25519(rand.Reader, []byte("hello"), pub)
+	if _, err := OpenEd25519(box, other); err == nil {
+		t.Error("box opened with the wrong key")
+	}
+}
+
+func TestLowOrder(t *testing.T) {
+	// The identity and points of order 8 can't be recipients.
+	if _, err := Seal(rand.Reader, nil, make([]byte, KeySize)); err == nil {
+		t.Error("sealed to u = 0")
+	}
+	if _, err := SealEd25519(rand.Reader, nil, ed25519.NewIdentityPoint().Bytes()); err == nil {
+		t.Error("sealed to the Ed25519 identity")
+	}
+	box := make([]byte, Overhead)
+	if _, err := Open(box, sequence(0, 32), sequence(1, 32)); err == nil {
+		t.Error("opened box with u = 0 ephemeral key")
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package spake2 implements the SPAKE2 password-authenticated key exchange
+// over edwards25519, following the protocol of RFC 9382.
+//
+// Two parties who share a low-entropy password, such as a pairing code shown
+// on one device and typed on the other, agree on a strong session key. An
+// eavesdropper learns nothing, and an active attacker gets a single password
+// guess per run of the protocol. Each side sends one message, then one key
+// confirmation MAC, which the other side must check before using the key.
+//
+// The masking points M and N are derived with hash-to-curve from fixed
+// strings, so that nobody knows their discrete logs. They differ from the
+// constants of RFC 9382, so this implementation doesn't interoperate with
+// others.
+package spake2
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/gtank/ed25519"
+)
+
+// MessageSize is the size of the key share each side sends.
+const MessageSize = 32
+
+// Domain separation of the masking points, and of the password scalar.
+const (
+	pointDST       = "gtank/ed25519/spake2-v1_XMD:SHA-512_ELL2_RO_"
+	passwordDomain = "gtank/ed25519/spake2-v1 password"
+)
+
+var (
+	pointsOnce sync.Once
+	pointM     *ed25519.Point
+	pointN     *ed25519.Point
+)
+
+func initPoints() {
+	pointM = ed25519.HashToPoint([]byte("M"), []byte(pointDST))
+	pointN = ed25519.HashToPoint([]byte("N"), []byte(pointDST))
+}
+
+// Role is the side of the exchange a party plays. The two parties must play
+// different roles.
+type Role int
+
+const (
+	// RoleA is the initiator, whose share is masked with M.
+	RoleA Role = iota
+	// RoleB is the responder, whose share is masked with N.
+	RoleB
+)
+
+// State is one party's state in an exchange.
+type State struct {
+	role     Role
+	w        ed25519.Scalar
+	x        ed25519.Scalar
+	msg      []byte
+	idA, idB []byte
+	aad      []byte
+	keys     *keys
+}
+
+type keys struct {
+	ke, kcA, kcB []byte
+	tt           []byte
+}
+
+// New starts an exchange in role, with the shared password and the
+// identities of both parties, which must be the same on both sides, like
+// aad, which is bound into the key confirmation. The password should be the
+// output of a memory-hard function of the actual password. New returns the
+// state and the message to send to the peer.
+func New(rand io.Reader, role Role, password, idA, idB, aad []byte) (*State, []byte, error) {
+	if role != RoleA && role != RoleB {
+		return nil, nil, errors.New("spake2: invalid role")
+	}
+	pointsOnce.Do(initPoints)
+
+	s := &State{
+		role: role,
+		idA:  append([]byte{}, idA...),
+		idB:  append([]byte{}, idB...),
+		aad:  append([]byte{}, aad...),
+	}
+	h := sha512.New()
+	h.Write([]byte(passwordDomain))
+	h.Write(password)
+	s.w.SetUniformBytes(h.Sum(nil))
+
+	x, err := ed25519.NewRandomScalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.x.Set(x)
+
+	// pA = x*G + w*M, pB = y*G + w*N
+	mask := pointM
+	if role == RoleB {
+		mask = pointN
+	}
+	share := new(ed25519.Point).CombinedMult(x.Bytes(), s.w.Bytes(), mask)
+	s.msg = share.Bytes()
+	return s, append([]byte{}, s.msg...), nil
+}
+
+// Finish processes the peer's message, and returns the key confirmation MAC
+// to send to it. It returns an error if the message is invalid.
+func (s *State) Finish(peerMessage []byte) ([]byte, error) {
+	if s.keys != nil {
+		return nil, errors.New("spake2: Finish called twice")
+	}
+	peer, err := new(ed25519.Point).SetBytes(peerMessage)
+	if err != nil || new(ed25519.Point).MultByCofactor(peer).IsIdentity() == 1 {
+		return nil, errors.New("spake2: invalid peer message")
+	}
+
+	// K = h*x*(pB - w*N) for A, and h*y*(pA - w*M) for B.
+	mask, pA, pB := pointN, s.msg, peerMessage
+	if s.role == RoleB {
+		mask, pA, pB = pointM, peerMessage, s.msg
+	}
+	K := new(ed25519.Point).ScalarMult(s.w.Bytes(), mask)
+	K.Sub(peer, K)
+	K.ScalarMult(s.x.Bytes(), K)
+	K.MultByCofactor(K)
+	if K.IsIdentity() == 1 {
+		return nil, errors.New("spake2: invalid peer message")
+	}
+
+	// TT = len(A) || A || len(B) || B || len(pA) || pA || len(pB) || pB ||
+	// len(K) || K || len(w) || w
+	var tt []byte
+	for _, v := range [][]byte{s.idA, s.idB, pA, pB, K.Bytes(), s.w.Bytes()} {
+		tt = binary.LittleEndian.AppendUint64(tt, uint64(len(v)))
+		tt = append(tt, v...)
+	}
+	digest := sha512.Sum512(tt)
+	ke, ka := digest[:32], digest[32:]
+	kc, err := hkdf.Key(sha512.New, ka, nil, "ConfirmationKeys"+string(s.aad), 64)
+	if err != nil {
+		return nil, err
+	}
+	s.keys = &keys{ke: ke, kcA: kc[:32], kcB: kc[32:], tt: tt}
+
+	own := s.keys.kcA
+	if s.role == RoleB {
+		own = s.keys.kcB
+	}
+	return mac(own, tt), nil
+}
+
+// Confirm checks the peer's key confirmation MAC, and returns the session
+// key if it's valid. An invalid MAC means the peer used a different password,
+// or that the messages were tampered with.
+func (s *State) Confirm(peerMAC []byte) ([]byte, error) {
+	if s.keys == nil {
+		return nil, errors.New("spake2: Confirm called before Finish")
+	}
+	peerKey := s.keys.kcB
+	if s.role == RoleB {
+		peerKey = s.keys.kcA
+	}
+	if !hmac.Equal(peerMAC, mac(peerKey, s.keys.tt)) {
+		return nil, errors.New("spake2: key confirmation failed")
+	}
+	return append([]byte{}, s.keys.ke...), nil
+}
+
+func mac(key, msg []byte) []byte {
+	m := hmac.New(sha512.New, key)
+	m.Write(msg)
+	return m.Sum(nil)
+}
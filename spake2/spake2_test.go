@@ -0,0 +1,91 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spake2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func exchange(t *testing.T, pwA, pwB []byte) (keyA, keyB []byte, errA, errB error) {
+	idA, idB, aad := []byte("phone"), []byte("laptop"), []byte("pairing")
+	a, msgA, err := New(rand.Reader, RoleA, pwA, idA, idB, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, msgB, err := New(rand.Reader, RoleB, pwB, idA, idB, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgA) != MessageSize || len(msgB) != MessageSize {
+		t.Fatalf("message sizes %d and %d, want %d", len(msgA), len(msgB), MessageSize)
+	}
+	macA, err := a.Finish(msgB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	macB, err := b.Finish(msgA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyA, errA = a.Confirm(macB)
+	keyB, errB = b.Confirm(macA)
+	return
+}
+
+func TestExchange(t *testing.T) {
+	keyA, keyB, errA, errB := exchange(t, []byte("123456"), []byte("123456"))
+	if errA != nil || errB != nil {
+		t.Fatalf("confirmation failed: %v, %v", errA, errB)
+	}
+	if !bytes.Equal(keyA, keyB) || len(keyA) != 32 {
+		t.Errorf("keys differ: %x, %x", keyA, keyB)
+	}
+
+	if _, _, errA, errB := exchange(t, []byte("123456"), []byte("123457")); errA == nil || errB == nil {
+		t.Errorf("different passwords confirmed: %v, %v", errA, errB)
+	}
+}
+
+func TestInvalidMessages(t *testing.T) {
+	a, _, err := New(rand.Reader, RoleA, []byte("pw"), nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Confirm(make([]byte, 64)); err == nil {
+		t.Error("Confirm before Finish succeeded")
+	}
+
+	// Messages of small order carry no key share.
+	smallOrder := ed25519.NewIdentityPoint().Bytes()
+	for _, msg := range [][]byte{smallOrder, make([]byte, 31), bytes.Repeat([]byte{0xff}, 32)} {
+		if _, err := a.Finish(msg); err == nil {
+			t.Errorf("message %x accepted", msg)
+		}
+	}
+
+	// Replaying one's own message doesn't confirm.
+	b, msgB, _ := New(rand.Reader, RoleB, []byte("pw"), nil, nil, nil)
+	if _, err := a.Finish(msgB); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Finish(msgB); err == nil {
+		t.Error("second Finish succeeded")
+	}
+	macB, err := b.Finish(msgB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Confirm(macB); err == nil {
+		t.Error("MAC over the wrong transcript accepted")
+	}
+
+	if _, _, err := New(rand.Reader, Role(2), nil, nil, nil, nil); err == nil {
+		t.Error("invalid role accepted")
+	}
+}
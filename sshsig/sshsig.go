@@ -0,0 +1,213 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sshsig implements the SSH signature format of ssh-keygen -Y sign
+// and -Y verify, as specified in PROTOCOL.sshsig of the OpenSSH sources,
+// with ssh-ed25519 keys. It's used by git to sign commits and tags with SSH
+// keys, and to sign release artifacts.
+//
+// The message is hashed, and the hash is signed together with a namespace,
+// such as "git" or "file", which stops a signature made for one purpose from
+// being accepted for another.
+package sshsig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"hash"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+	"github.com/gtank/ed25519/internal/sshwire"
+	"github.com/gtank/ed25519/keyfile"
+)
+
+const (
+	magic     = "SSHSIG"
+	version   = 1
+	pemType   = "SSH SIGNATURE"
+	keyType   = "ssh-ed25519"
+	lineWidth = 70
+)
+
+// Hash algorithms of SSH signatures. ssh-keygen uses HashSHA512 by default.
+const (
+	HashSHA256 = "sha256"
+	HashSHA512 = "sha512"
+)
+
+// Signature is a parsed SSH signature.
+type Signature struct {
+	// PublicKey is the key that made the signature. It must be checked
+	// against the keys trusted for Namespace, as ssh-keygen does with an
+	// allowed_signers file.
+	PublicKey ed25519.PublicKey
+
+	// Namespace is the purpose the signature was made for.
+	Namespace string
+
+	// HashAlgorithm is the hash of the message that was signed, HashSHA256 or
+	// HashSHA512.
+	HashAlgorithm string
+
+	// signature is the raw Ed25519 signature.
+	signature []byte
+}
+
+// Sign returns the armored SSH signature of message by priv, for the given
+// namespace, with SHA-512 as the message hash, like ssh-keygen -Y sign. The
+// output is identical to that of ssh-keygen, since Ed25519 signatures are
+// deterministic.
+func Sign(priv ed25519.PrivateKey, message []byte, namespace string) ([]byte, error) {
+	if l := len(priv); l != ed25519.PrivateKeySize {
+		panic("sshsig: bad private key length: " + strconv.Itoa(l))
+	}
+	if namespace == "" {
+		return nil, errors.New("sshsig: empty namespace")
+	}
+	sig := &Signature{
+		PublicKey:     ed25519.PublicKey(priv[ed25519.SeedSize:]),
+		Namespace:     namespace,
+		HashAlgorithm: HashSHA512,
+	}
+	signedData, err := sig.signedData(message)
+	if err != nil {
+		return nil, err
+	}
+	sig.signature = ed25519.Sign(priv, signedData)
+	return sig.Armor(), nil
+}
+
+// Verify checks that armored is a valid SSH signature of message by pub, for
+// the given namespace, like ssh-keygen -Y verify. A valid signature is
+// indicated by returning a nil error.
+func Verify(pub ed25519.PublicKey, message []byte, namespace string, armored []byte) error {
+	sig, err := ParseSignature(armored)
+	if err != nil {
+		return err
+	}
+	if !sig.PublicKey.Equal(pub) {
+		return errors.New("sshsig: signature made by a different key")
+	}
+	if sig.Namespace != namespace {
+		return errors.New("sshsig: signature made for namespace " + strconv.Quote(sig.Namespace))
+	}
+	return sig.Verify(message)
+}
+
+// Verify checks that sig is a valid signature of message by sig.PublicKey,
+// for sig.Namespace. Callers must check that the key and namespace are the
+// expected ones; Verify, the function, does that.
+func (sig *Signature) Verify(message []byte) error {
+	signedData, err := sig.signedData(message)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(sig.PublicKey, signedData, sig.signature) {
+		return errors.New("sshsig: invalid signature")
+	}
+	return nil
+}
+
+// signedData returns the blob that is signed: the magic, the namespace, an
+// empty reserved field, the hash algorithm and the hash of message.
+func (sig *Signature) signedData(message []byte) ([]byte, error) {
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case HashSHA256:
+		h = sha256.New()
+	case HashSHA512:
+		h = sha512.New()
+	default:
+		return nil, errors.New("sshsig: unsupported hash algorithm " + strconv.Quote(sig.HashAlgorithm))
+	}
+	h.Write(message)
+
+	w := sshwire.Writer(magic)
+	w.WriteString([]byte(sig.Namespace))
+	w.WriteString(nil)
+	w.WriteString([]byte(sig.HashAlgorithm))
+	w.WriteString(h.Sum(nil))
+	return w, nil
+}
+
+// Marshal returns the binary encoding of sig.
+func (sig *Signature) Marshal() []byte {
+	var s sshwire.Writer
+	s.WriteString([]byte(keyType))
+	s.WriteString(sig.signature)
+
+	w := sshwire.Writer(magic)
+	w.WriteUint32(version)
+	w.WriteString(keyfile.MarshalSSHPublicKey(sig.PublicKey))
+	w.WriteString([]byte(sig.Namespace))
+	w.WriteString(nil)
+	w.WriteString([]byte(sig.HashAlgorithm))
+	w.WriteString(s)
+	return w
+}
+
+// Armor returns the armored encoding of sig, an SSH SIGNATURE PEM block with
+// lines of 70 characters, as written by ssh-keygen.
+func (sig *Signature) Armor() []byte {
+	b64 := base64.StdEncoding.EncodeToString(sig.Marshal())
+	out := []byte("-----BEGIN " + pemType + "-----\n")
+	for len(b64) > lineWidth {
+		out = append(append(out, b64[:lineWidth]...), '\n')
+		b64 = b64[lineWidth:]
+	}
+	out = append(append(out, b64...), '\n')
+	return append(out, "-----END "+pemType+"-----\n"...)
+}
+
+// ParseSignature parses an armored SSH signature. It doesn't verify it.
+func ParseSignature(armored []byte) (*Signature, error) {
+	block, rest := pem.Decode(armored)
+	if block == nil || block.Type != pemType || len(block.Headers) != 0 ||
+		len(bytes.TrimSpace(rest)) != 0 {
+		return nil, errors.New("sshsig: invalid armored signature")
+	}
+	return UnmarshalSignature(block.Bytes)
+}
+
+// UnmarshalSignature parses the binary encoding of an SSH signature, as
+// produced by Signature.Marshal. It doesn't verify it.
+func UnmarshalSignature(data []byte) (*Signature, error) {
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		return nil, errors.New("sshsig: invalid signature magic")
+	}
+	r := sshwire.Reader(data[len(magic):])
+	v := r.ReadUint32()
+	publicKey := r.ReadString()
+	namespace := r.ReadString()
+	r.ReadString() // reserved
+	hashAlgorithm := r.ReadString()
+	signature := r.ReadString()
+	if r == nil || len(r) != 0 {
+		return nil, errors.New("sshsig: invalid signature encoding")
+	}
+	if v != version {
+		return nil, errors.New("sshsig: unsupported signature version " + strconv.Itoa(int(v)))
+	}
+	pub, err := keyfile.ParseSSHPublicKey(publicKey)
+	if err != nil {
+		return nil, errors.New("sshsig: unsupported public key")
+	}
+
+	s := sshwire.Reader(signature)
+	sigType, sig := s.ReadString(), s.ReadString()
+	if s == nil || len(s) != 0 || string(sigType) != keyType || len(sig) != ed25519.SignatureSize {
+		return nil, errors.New("sshsig: invalid ssh-ed25519 signature")
+	}
+	return &Signature{
+		PublicKey:     pub,
+		Namespace:     string(namespace),
+		HashAlgorithm: string(hashAlgorithm),
+		signature:     append([]byte{}, sig...),
+	}, nil
+}
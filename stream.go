@@ -0,0 +1,86 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto"
+	"crypto/sha512"
+	"hash"
+	"strconv"
+)
+
+// Verifier checks a signature of a message that is written to it in pieces,
+// so that large messages can be verified without holding them in memory.
+//
+// Unlike signing, which has to read the message twice, Ed25519 verification
+// only hashes the message once, after R and A, which are known in advance.
+// So Verifier streams every variant, including plain Ed25519 and Ed25519ctx,
+// without buffering. For Ed25519ph, the message written is the one whose
+// SHA-512 digest was signed.
+type Verifier struct {
+	publicKey [PublicKeySize]byte
+	sig       []byte
+	opts      VerifierOptions
+	dom       []byte
+
+	// h hashes dom || R || A || M, or only M for Ed25519ph.
+	h hash.Hash
+}
+
+// NewVerifier returns a Verifier for the signature sig by publicKey, for the
+// variant and under the rules selected by opts. A nil opts selects the
+// defaults of VerifierOptions. NewVerifier returns an error if opts is
+// invalid, and panics if len(publicKey) is not PublicKeySize.
+func NewVerifier(publicKey PublicKey, sig []byte, opts *VerifierOptions) (*Verifier, error) {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	v := &Verifier{h: sha512.New(), sig: append([]byte{}, sig...)}
+	copy(v.publicKey[:], publicKey)
+	if opts != nil {
+		v.opts = *opts
+	}
+	if err := v.opts.checkRules(); err != nil {
+		return nil, err
+	}
+	dom, err := v.opts.dom()
+	if err != nil {
+		return nil, err
+	}
+	v.dom = dom
+
+	if v.opts.Hash != crypto.SHA512 && len(sig) == SignatureSize {
+		v.h.Write(dom)
+		v.h.Write(sig[:32])
+		v.h.Write(publicKey)
+	}
+	return v, nil
+}
+
+// Write adds more data to the message. It never returns an error.
+func (v *Verifier) Write(p []byte) (int, error) {
+	return v.h.Write(p)
+}
+
+// Verify checks whether the signature is valid for the message written so
+// far. A valid signature is indicated by returning a nil error. It doesn't
+// change the state, so more data can be written and verified again.
+func (v *Verifier) Verify() error {
+	if v.opts.Hash == crypto.SHA512 {
+		return verifyWithRules(v.publicKey[:], v.h.Sum(nil), v.sig, v.dom, v.opts.Rules, v.opts.cofactored())
+	}
+
+	if len(v.sig) != SignatureSize {
+		return errInvalidSignatureLength
+	}
+	var A Point
+	if err := decodePoint(&A, v.publicKey[:], v.opts.Rules); err != nil {
+		return errInvalidPublicKey
+	}
+	var k Scalar
+	var digest [sha512.Size]byte
+	k.s.FromUniformBytes(v.h.Sum(digest[:0]))
+	return verifyWithKey(&A, nil, v.sig, &k, v.opts.Rules, v.opts.cofactored())
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha512"
+	"io"
+	"testing"
+)
+
+func TestVerifier(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := make([]byte, 100000)
+	rand.Read(message)
+	digest := sha512.Sum512(message)
+
+	tests := []struct {
+		opts    *VerifierOptions
+		signed  []byte
+		signOpt *Options
+	}{
+		{nil, message, &Options{}},
+		{&VerifierOptions{Rules: RulesStrict}, message, &Options{}},
+		{&VerifierOptions{Options: Options{Context: "foo"}}, message, &Options{Context: "foo"}},
+		{&VerifierOptions{Options: Options{Hash: crypto.SHA512}}, digest[:], &Options{Hash: crypto.SHA512}},
+		{&VerifierOptions{Options: Options{Hash: crypto.SHA512, Context: "foo"}, Rules: RulesZIP215}, digest[:], &Options{Hash: crypto.SHA512, Context: "foo"}},
+	}
+	for i, tt := range tests {
+		sig, err := SignWithOptions(priv, tt.signed, tt.signOpt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := NewVerifier(pub, sig, tt.opts)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		// Write the message in uneven pieces.
+		if _, err := io.CopyBuffer(v, bytes.NewReader(message), make([]byte, 4093)); err != nil {
+			t.Fatal(err)
+		}
+		if err := v.Verify(); err != nil {
+			t.Errorf("test %d: valid signature rejected: %v", i, err)
+		}
+
+		v.Write([]byte{0})
+		if err := v.Verify(); err == nil {
+			t.Errorf("test %d: signature accepted for the wrong message", i)
+		}
+	}
+
+	if _, err := NewVerifier(pub, nil, &VerifierOptions{Rules: 42}); err == nil {
+		t.Error("invalid options accepted")
+	}
+
+	v, err := NewVerifier(pub, Sign(priv, message)[:63], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Write(message)
+	if err := v.Verify(); err != errInvalidSignatureLength {
+		t.Errorf("short signature: got %v, want %v", err, errInvalidSignatureLength)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/sha512"
+	"encoding"
+	"encoding/binary"
+	"hash"
+	"strconv"
+)
+
+// transcriptDomain separates transcripts from every other hash in the
+// package, including signatures.
+const transcriptDomain = "gtank/ed25519/transcript-v1"
+
+// Operations framed into a transcript, so that a message can't be mistaken
+// for a challenge or for the protocol label.
+const (
+	opProtocol byte = iota + 1
+	opMessage
+	opChallenge
+)
+
+// Transcript is a Fiat-Shamir transcript, in the style of Merlin: the
+// prover and the verifier append the same labeled messages to it, and derive
+// the same challenges from everything appended so far.
+//
+// Every operation is framed with its type, the length-prefixed label, and
+// the length-prefixed data into a running SHA-512 hash, so that no two
+// different sequences of operations produce the same challenges. Challenges
+// are appended to the transcript too, so later ones depend on earlier ones.
+//
+// The zero value is not usable; use NewTranscript.
+type Transcript struct {
+	h hash.Hash
+}
+
+// NewTranscript returns a transcript for the protocol identified by label,
+// which should be unique to the protocol and its version.
+func NewTranscript(label string) *Transcript {
+	t := &Transcript{h: sha512.New()}
+	t.h.Write([]byte(transcriptDomain))
+	t.frame(opProtocol, label, nil)
+	return t
+}
+
+// frame writes an operation to the running hash.
+func (t *Transcript) frame(op byte, label string, data []byte) {
+	var lengths [8]byte
+	t.h.Write([]byte{op})
+	binary.LittleEndian.PutUint64(lengths[:], uint64(len(label)))
+	t.h.Write(lengths[:])
+	t.h.Write([]byte(label))
+	binary.LittleEndian.PutUint64(lengths[:], uint64(len(data)))
+	t.h.Write(lengths[:])
+	t.h.Write(data)
+}
+
+// AppendMessage appends message to the transcript, under label.
+func (t *Transcript) AppendMessage(label string, message []byte) {
+	t.frame(opMessage, label, message)
+}
+
+// AppendPoint appends the encoding of p to the transcript, under label.
+func (t *Transcript) AppendPoint(label string, p *Point) {
+	t.frame(opMessage, label, p.Bytes())
+}
+
+// AppendScalar appends the encoding of s to the transcript, under label.
+func (t *Transcript) AppendScalar(label string, s *Scalar) {
+	t.frame(opMessage, label, s.Bytes())
+}
+
+// ChallengeBytes returns n bytes derived from the transcript so far, and
+// appends them to it, under label. It panics if n is negative.
+func (t *Transcript) ChallengeBytes(label string, n int) []byte {
+	if n < 0 {
+		panic("ed25519: bad challenge length: " + strconv.Itoa(n))
+	}
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(n))
+	t.frame(opChallenge, label, length[:])
+
+	// The output is SHA-512(state || counter) for counter = 0, 1, ..., where
+	// state is the hash of the transcript so far.
+	state := t.h.Sum(nil)
+	out := make([]byte, 0, n+sha512.Size)
+	for counter := uint64(0); len(out) < n; counter++ {
+		h := sha512.New()
+		h.Write(state)
+		binary.LittleEndian.PutUint64(length[:], counter)
+		h.Write(length[:])
+		out = h.Sum(out)
+	}
+	out = out[:n]
+
+	t.h.Write(out)
+	return out
+}
+
+// ChallengeScalar returns a uniformly random scalar derived from the
+// transcript so far, and appends it to the transcript, under label.
+func (t *Transcript) ChallengeScalar(label string) *Scalar {
+	s, _ := NewScalar().SetUniformBytes(t.ChallengeBytes(label, 64))
+	return s
+}
+
+// Clone returns an independent copy of t, so that a protocol can fork a
+// transcript, for example to derive a nonce from it without affecting the
+// challenges.
+func (t *Transcript) Clone() *Transcript {
+	state, err := t.h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		panic("ed25519: internal error: " + err.Error())
+	}
+	h := sha512.New()
+	if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		panic("ed25519: internal error: " + err.Error())
+	}
+	return &Transcript{h: h}
+}
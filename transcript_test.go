@@ -0,0 +1,68 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTranscript(t *testing.T) {
+	newTranscript := func() *Transcript {
+		tr := NewTranscript("test protocol")
+		tr.AppendMessage("msg", []byte("hello"))
+		tr.AppendPoint("B", NewGeneratorPoint())
+		return tr
+	}
+
+	c1 := newTranscript().ChallengeScalar("c")
+	c2 := newTranscript().ChallengeScalar("c")
+	if c1.Equal(c2) != 1 {
+		t.Error("same transcript, different challenges")
+	}
+
+	// Any difference in protocol, labels, framing or data changes the
+	// challenge.
+	variants := []*Transcript{NewTranscript("other protocol"), NewTranscript("test protocol")}
+	variants[0].AppendMessage("msg", []byte("hello"))
+	variants[0].AppendPoint("B", NewGeneratorPoint())
+	variants[1].AppendMessage("ms", []byte("ghello"))
+	variants[1].AppendPoint("B", NewGeneratorPoint())
+	for i := 0; i < 3; i++ {
+		variants = append(variants, newTranscript())
+	}
+	variants[2].AppendMessage("", nil)
+	variants[3].ChallengeBytes("c", 0)
+	for i, v := range variants[:4] {
+		if v.ChallengeScalar("c").Equal(c1) == 1 {
+			t.Errorf("variant %d: same challenge", i)
+		}
+	}
+	if variants[4].ChallengeScalar("d").Equal(c1) == 1 {
+		t.Error("challenge label ignored")
+	}
+
+	// Later challenges depend on earlier ones.
+	tr := newTranscript()
+	first := tr.ChallengeBytes("c", 32)
+	if second := tr.ChallengeBytes("c", 32); bytes.Equal(first, second) {
+		t.Error("repeated challenge")
+	}
+
+	// Challenges can be longer than a SHA-512 output.
+	long := newTranscript().ChallengeBytes("c", 100)
+	if len(long) != 100 {
+		t.Errorf("got %d bytes, want 100", len(long))
+	}
+
+	// Clones evolve independently.
+	tr = newTranscript()
+	clone := tr.Clone()
+	clone.AppendMessage("nonce", []byte("secret"))
+	clone.ChallengeScalar("k")
+	if tr.ChallengeScalar("c").Equal(c1) != 1 {
+		t.Error("clone affected the original transcript")
+	}
+}
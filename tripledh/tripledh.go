@@ -0,0 +1,94 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tripledh implements the triple Diffie-Hellman key agreement of
+// X3DH over X25519, where each party has a static identity key and an
+// ephemeral (or signed prekey) key pair.
+//
+// With A the initiator and B the responder, the shared key is
+//
+//	HKDF-SHA-512(salt = 0^64, F || DH(IKA, EKB) || DH(EKA, IKB) || DH(EKA, EKB), info)
+//
+// where IK are the static keys, EK the ephemeral ones, and F is 32 0xff
+// bytes, as specified for X25519 in Section 2.2 of the X3DH specification.
+// Both sides must agree on the order of the three secrets, which is the part
+// implementations most often get wrong, so DH3, Initiator and Responder compute
+// it from their own point of view.
+//
+// The shared key authenticates both parties implicitly: only the holders of
+// IKA and IKB can compute it. Like X3DH, it doesn't bind the identity keys
+// themselves, which protocols should include in their associated data.
+package tripledh
+
+import (
+	"crypto/hkdf"
+	"crypto/sha512"
+	"errors"
+
+	"github.com/gtank/ed25519"
+)
+
+// SharedKeySize is the size of the keys returned by Initiator and Responder.
+const SharedKeySize = 32
+
+var contributory = &ed25519.X25519Options{Contributory: true}
+
+// DH3 returns DH1 || DH2 || DH3, the three ordered X25519 outputs of the
+// triple Diffie-Hellman, for protocols that hash them with their own KDF. The
+// private keys are those of the caller, the public keys those of the peer, and
+// initiator selects the caller's role, so that both sides return the same
+// bytes. It returns an error if any DH output is all zeroes.
+func DH3(staticPriv, ephemeralPriv, peerStatic, peerEphemeral []byte, initiator bool) ([]byte, error) {
+	dhs := []dh{
+		{ephemeralPriv, peerStatic},    // DH(EKB, IKA)
+		{staticPriv, peerEphemeral},    // DH(IKB, EKA)
+		{ephemeralPriv, peerEphemeral}, // DH(EKB, EKA)
+	}
+	if initiator {
+		dhs[0], dhs[1] = dhs[1], dhs[0] // DH(IKA, EKB), DH(EKA, IKB)
+	}
+	out := make([]byte, 0, 3*ed25519.X25519Size)
+	for _, d := range dhs {
+		secret, err := contributory.X25519(d.priv, d.pub)
+		if err != nil {
+			return nil, errors.New("tripledh: low order public key")
+		}
+		out = append(out, secret...)
+	}
+	return out, nil
+}
+
+// Initiator returns the shared key of the initiator, with the static and
+// ephemeral X25519 private keys staticPriv and ephemeralPriv, and the
+// responder's public keys peerStatic and peerEphemeral. It returns an error
+// if any DH output is all zeroes, which means a peer key is of low order.
+// It panics if any key is not ed25519.X25519Size bytes long.
+func Initiator(staticPriv, ephemeralPriv, peerStatic, peerEphemeral, info []byte) ([]byte, error) {
+	return deriveKey(staticPriv, ephemeralPriv, peerStatic, peerEphemeral, info, true)
+}
+
+// Responder returns the shared key of the responder, like Initiator, with
+// the responder's private keys and the initiator's public keys. It's equal
+// to the key the initiator computes.
+func Responder(staticPriv, ephemeralPriv, peerStatic, peerEphemeral, info []byte) ([]byte, error) {
+	return deriveKey(staticPriv, ephemeralPriv, peerStatic, peerEphemeral, info, false)
+}
+
+type dh struct {
+	priv, pub []byte
+}
+
+func deriveKey(staticPriv, ephemeralPriv, peerStatic, peerEphemeral, info []byte, initiator bool) ([]byte, error) {
+	secrets, err := DH3(staticPriv, ephemeralPriv, peerStatic, peerEphemeral, initiator)
+	if err != nil {
+		return nil, err
+	}
+	ikm := make([]byte, 32, 32+len(secrets))
+	for i := range ikm {
+		ikm[i] = 0xff
+	}
+	ikm = append(ikm, secrets...)
+	salt := make([]byte, sha512.Size)
+	return hkdf.Key(sha512.New, ikm, salt, string(info), SharedKeySize)
+}
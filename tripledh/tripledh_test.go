@@ -0,0 +1,90 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tripledh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+type keyPair struct {
+	priv, pub []byte
+}
+
+func newKeyPair(t *testing.T) keyPair {
+	priv := make([]byte, ed25519.X25519Size)
+	if _, err := rand.Read(priv); err != nil {
+		t.Fatal(err)
+	}
+	return keyPair{priv, ed25519.X25519Base(priv)}
+}
+
+func TestTripleDH(t *testing.T) {
+	ika, eka := newKeyPair(t), newKeyPair(t)
+	ikb, ekb := newKeyPair(t), newKeyPair(t)
+	info := []byte("tripledh test")
+
+	a, err := Initiator(ika.priv, eka.priv, ikb.pub, ekb.pub, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Responder(ikb.priv, ekb.priv, ika.pub, eka.pub, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) || len(a) != SharedKeySize {
+		t.Fatalf("keys differ: %x, %x", a, b)
+	}
+
+	da, err := DH3(ika.priv, eka.priv, ikb.pub, ekb.pub, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := DH3(ikb.priv, ekb.priv, ika.pub, eka.pub, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(da, db) || len(da) != 3*ed25519.X25519Size {
+		t.Fatalf("DH3 outputs differ: %x, %x", da, db)
+	}
+	// DH1 = DH(IKA, EKB), DH2 = DH(EKA, IKB), DH3 = DH(EKA, EKB).
+	for i, want := range [][]byte{
+		ed25519.X25519(ika.priv, ekb.pub),
+		ed25519.X25519(eka.priv, ikb.pub),
+		ed25519.X25519(eka.priv, ekb.pub),
+	} {
+		if got := da[32*i : 32*(i+1)]; !bytes.Equal(got, want) {
+			t.Errorf("DH%d = %x, want %x", i+1, got, want)
+		}
+	}
+
+	// Both parties playing the same role, or swapping their keys, disagree.
+	if c, _ := Initiator(ikb.priv, ekb.priv, ika.pub, eka.pub, info); bytes.Equal(a, c) {
+		t.Error("initiator and responder roles are interchangeable")
+	}
+	if c, _ := Initiator(eka.priv, ika.priv, ikb.pub, ekb.pub, info); bytes.Equal(a, c) {
+		t.Error("static and ephemeral keys are interchangeable")
+	}
+	if c, _ := Initiator(ika.priv, eka.priv, ikb.pub, ekb.pub, []byte("other")); bytes.Equal(a, c) {
+		t.Error("info is ignored")
+	}
+
+	// An impostor without IKB can't compute the key.
+	mallory := newKeyPair(t)
+	if c, _ := Responder(mallory.priv, ekb.priv, ika.pub, eka.pub, info); bytes.Equal(a, c) {
+		t.Error("key computed without the responder's static key")
+	}
+
+	zero := make([]byte, ed25519.X25519Size)
+	if _, err := Initiator(ika.priv, eka.priv, zero, ekb.pub, info); err == nil {
+		t.Error("low order static key accepted")
+	}
+	if _, err := Responder(ikb.priv, ekb.priv, ika.pub, zero, info); err == nil {
+		t.Error("low order ephemeral key accepted")
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/scalar"
+)
+
+// VerificationRules selects how a verifier handles the edge cases RFC 8032
+// leaves open: non-canonical point encodings, points of small order, and the
+// choice of verification equation. Implementations disagree on them, so the
+// same signature can be valid for one verifier and invalid for another.
+// Signatures produced by Sign are valid under all rules.
+type VerificationRules int
+
+const (
+	// RulesRFC8032 are the rules of Verify: A and R must be canonical
+	// encodings, S must be less than L, and the cofactorless equation
+	// [S]B = R + [k]A is used. These match crypto/ed25519, except that it
+	// accepts some non-canonical encodings of A.
+	RulesRFC8032 VerificationRules = iota
+
+	// RulesZIP215 are the rules of Zcash ZIP-215, which pin down every edge
+	// case so that all implementations of them agree on exactly which
+	// signatures are valid, as consensus systems need:
+	//
+	//   - A and R may be non-canonical encodings, which are hashed as received;
+	//   - S must be less than L;
+	//   - the cofactored equation [8][S]B = [8]R + [8][k]A is used, so
+	//     small-order components of A and R are ignored.
+	RulesZIP215
+
+	// RulesStrict are the rules of RulesRFC8032, plus the rejection of A and
+	// R of small order, like verify_strict of ed25519-dalek. A small-order A
+	// is a key that many messages have valid signatures for, and a small-order
+	// R can make a signature valid for more than one key. Rejecting them makes
+	// signatures strongly binding, at the cost of disagreeing with other
+	// verifiers on signatures that honest signers never produce.
+	RulesStrict
+)
+
+// VerificationEquation selects the equation a verifier checks. Signatures
+// produced by Sign satisfy both, but a signature whose R or A has a
+// small-order component can satisfy only the cofactored one, so verifiers that
+// must agree with each other need to check the same equation.
+type VerificationEquation int
+
+const (
+	// EquationDefault selects the equation of the VerificationRules in use.
+	EquationDefault VerificationEquation = iota
+
+	// EquationCofactorless selects [S]B = R + [k]A, the equation of
+	// crypto/ed25519 and of RFC 8032, Section 5.1.7 as most implementations
+	// read it.
+	EquationCofactorless
+
+	// EquationCofactored selects [8][S]B = [8]R + [8][k]A, which RFC 8032
+	// says is sufficient, and which batch verification has to use to agree
+	// with single verification.
+	EquationCofactored
+)
+
+// VerifierOptions can be used with VerifierOptions.Verify to select an
+// Ed25519 variant and the rules to verify signatures with.
+type VerifierOptions struct {
+	// Options selects the Ed25519 variant, as in VerifyWithOptions. The zero
+	// value selects plain Ed25519.
+	Options
+
+	// Rules selects how edge cases are handled. The zero value is
+	// RulesRFC8032, the behavior of Verify.
+	Rules VerificationRules
+
+	// Equation overrides the verification equation of Rules, if not
+	// EquationDefault.
+	Equation VerificationEquation
+}
+
+// cofactored reports whether o selects the cofactored equation.
+func (o *VerifierOptions) cofactored() bool {
+	switch o.Equation {
+	case EquationCofactorless:
+		return false
+	case EquationCofactored:
+		return true
+	default:
+		return o.Rules == RulesZIP215
+	}
+}
+
+// Verify checks whether sig is a valid signature of message by publicKey,
+// for the variant and under the rules selected by o. A valid signature is
+// indicated by returning a nil error. A nil o selects the defaults, which are
+// equivalent to Verify. It panics if len(publicKey) is not PublicKeySize.
+func (o *VerifierOptions) Verify(publicKey PublicKey, message, sig []byte) error {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	if o == nil {
+		o = &VerifierOptions{}
+	}
+	dom, err := o.domFor(message)
+	if err != nil {
+		return err
+	}
+	return verifyWithRules(publicKey, message, sig, dom, o.Rules, o.cofactored())
+}
+
+// domFor checks o, and returns the dom2 prefix for the variant it selects.
+func (o *VerifierOptions) domFor(message []byte) ([]byte, error) {
+	if err := o.checkRules(); err != nil {
+		return nil, err
+	}
+	return o.Options.domFor(message)
+}
+
+// checkRules returns an error if o.Rules or o.Equation is not a known value.
+func (o *VerifierOptions) checkRules() error {
+	switch o.Rules {
+	case RulesRFC8032, RulesZIP215, RulesStrict:
+	default:
+		return errors.New("ed25519: unknown verification rules: " + strconv.Itoa(int(o.Rules)))
+	}
+	switch o.Equation {
+	case EquationDefault, EquationCofactorless, EquationCofactored:
+	default:
+		return errors.New("ed25519: unknown verification equation: " + strconv.Itoa(int(o.Equation)))
+	}
+	return nil
+}
+
+// VerifyZIP215 reports whether sig is a valid signature of message by
+// publicKey under RulesZIP215. It panics if len(publicKey) is not
+// PublicKeySize.
+func VerifyZIP215(publicKey PublicKey, message, sig []byte) bool {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	return verifyWithRules(publicKey, message, sig, nil, RulesZIP215, true) == nil
+}
+
+// VerifyStrict reports whether sig is a valid signature of message by
+// publicKey under RulesStrict. It panics if len(publicKey) is not
+// PublicKeySize.
+func VerifyStrict(publicKey PublicKey, message, sig []byte) bool {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	return verifyWithRules(publicKey, message, sig, nil, RulesStrict, false) == nil
+}
+
+// RecomputeR returns R' = [S]B - [k]A, the commitment that verification
+// recomputes from sig = R || S and the challenge k = SHA-512(R || A || M),
+// for protocols that need it rather than a yes or no answer. sig is a valid
+// signature under the rules of Verify if and only if R' encodes to R, and
+// under the cofactored equation if [8]R' = [8]R.
+//
+// It returns an error if publicKey is not a valid point encoding, or if sig
+// is not SignatureSize bytes long or has a non-canonical S. R itself is only
+// hashed, so it doesn't need to be a valid point. It panics if
+// len(publicKey) is not PublicKeySize.
+func RecomputeR(publicKey PublicKey, message, sig []byte) (*Point, error) {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	if len(sig) != SignatureSize {
+		return nil, errInvalidSignatureLength
+	}
+	var A Point
+	if err := A.p.FromBytes(publicKey); err != nil {
+		return nil, errInvalidPublicKey
+	}
+	var S Scalar
+	if _, err := S.SetCanonicalBytes(sig[32:]); err != nil {
+		return nil, errNonCanonicalS
+	}
+	k := challenge(nil, sig[:32], publicKey, message)
+	return new(Point).VartimeMultiScalarMult(
+		[][]byte{S.Bytes(), k.Bytes()},
+		[]*Point{NewGeneratorPoint(), new(Point).Neg(&A)}), nil
+}
+
+// Errors returned internally by verifyWithRules under RulesStrict.
+var (
+	errSmallOrderPublicKey = errors.New("ed25519: public key of small order")
+	errSmallOrderR         = errors.New("ed25519: signature R of small order")
+)
+
+// verifyWithRules implements verification for all variants, rules and
+// equations, with dom as in sign.
+func verifyWithRules(publicKey PublicKey, message, sig, dom []byte, rules VerificationRules, cofactored bool) error {
+	if len(sig) != SignatureSize {
+		return errInvalidSignatureLength
+	}
+	var A Point
+	if err := decodePoint(&A, publicKey, rules); err != nil {
+		return errInvalidPublicKey
+	}
+
+	// k is computed over the encodings as received, which only differ from
+	// the canonical ones under RulesZIP215.
+	var k Scalar
+	k.setChallenge(dom, sig[:32], publicKey, message)
+	return verifyWithKey(&A, nil, sig, &k, rules, cofactored)
+}
+
+// decodePoint sets v to the point encoded by x, accepting the encodings of A
+// and R that rules allow.
+func decodePoint(v *Point, x []byte, rules VerificationRules) error {
+	if rules == RulesZIP215 {
+		return v.p.FromBytesNonCanonical(x)
+	}
+	return v.p.FromBytes(x)
+}
+
+// verifyWithKey implements verifyWithRules once the public key is decoded to
+// A and the challenge k is computed. sig must be SignatureSize bytes long. If
+// minusA is not nil, it's the table for -A, which replaces the generic
+// multiscalar multiplication, and verifyWithKey doesn't allocate.
+func verifyWithKey(A *Point, minusA *group.NAFTable, sig []byte, k *Scalar, rules VerificationRules, cofactored bool) error {
+	var R Point
+	if err := decodePoint(&R, sig[:32], rules); err != nil {
+		return errSignatureMismatch
+	}
+	var S Scalar
+	if _, err := S.SetCanonicalBytes(sig[32:]); err != nil {
+		return errNonCanonicalS
+	}
+
+	if rules == RulesStrict {
+		var check Point
+		if check.MultByCofactor(A).IsIdentity() == 1 {
+			return errSmallOrderPublicKey
+		}
+		if check.MultByCofactor(&R).IsIdentity() == 1 {
+			return errSmallOrderR
+		}
+	}
+
+	// [S]B - [k]A - R must be the identity, or only have a small-order
+	// component with the cofactored equation.
+	var check Point
+	if minusA != nil {
+		var s, kk [32]byte
+		S.s.ToBytes(s[:])
+		k.s.ToBytes(kk[:])
+		check.p.VartimeDoubleMultPrecomputed(&s, group.BasepointNAFTable(), &kk, minusA)
+	} else {
+		check.VartimeMultiScalarMult(
+			[][]byte{S.Bytes(), k.Bytes()},
+			[]*Point{NewGeneratorPoint(), new(Point).Neg(A)})
+	}
+	check.Sub(&check, &R)
+	if cofactored {
+		check.MultByCofactor(&check)
+	}
+	if check.IsIdentity() != 1 {
+		return errSignatureMismatch
+	}
+	return nil
+}
+
+// setBytesNonCanonical is like SetBytes, but it also accepts the
+// non-canonical encodings allowed by ZIP-215.
+func (v *Point) setBytesNonCanonical(x []byte) (*Point, error) {
+	if err := v.p.FromBytesNonCanonical(x); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// IsCanonicalSignature reports whether sig is SignatureSize bytes long, and
+// both of its components are canonical encodings: R of a point, and S of a
+// scalar less than L. Any valid signature can be turned into others by adding
+// L to S or, for some R, re-encoding it, which matters to systems that
+// identify transactions by their signatures. Signatures produced by Sign are
+// always canonical, and Verify rejects all others.
+//
+// IsCanonicalSignature doesn't check that sig is valid for any message.
+func IsCanonicalSignature(sig []byte) bool {
+	if len(sig) != SignatureSize {
+		return false
+	}
+	var R group.ExtendedGroupElement
+	return R.FromBytes(sig[:32]) == nil && IsCanonicalScalar(sig[32:])
+}
+
+// CanonicalizeSignature returns a copy of sig with S reduced modulo L. If sig
+// was valid for verifiers that skip the check on S, the result is valid for
+// the same message and key, and is also accepted by Verify. It returns an
+// error if sig is not SignatureSize bytes long, or if R is not a canonical
+// point encoding: R is hashed into the challenge as received, so re-encoding
+// it would invalidate the signature.
+func CanonicalizeSignature(sig []byte) ([]byte, error) {
+	if len(sig) != SignatureSize {
+		return nil, errInvalidSignatureLength
+	}
+	var R group.ExtendedGroupElement
+	if err := R.FromBytes(sig[:32]); err != nil {
+		return nil, errNonCanonicalR
+	}
+	var S scalar.Scalar
+	S.FromBytes(sig[32:])
+
+	out := make([]byte, SignatureSize)
+	copy(out, sig[:32])
+	S.ToBytes(out[32:])
+	return out, nil
+}
+
+var errNonCanonicalR = errors.New("ed25519: non-canonical signature R")
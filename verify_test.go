@@ -0,0 +1,309 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// Non-canonical encodings of the identity: y = p + 1, and y = 1 with the sign
+// bit set.
+var (
+	identityPlusPBytes, _   = hex.DecodeString("eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	identityNegZeroBytes, _ = hex.DecodeString("0100000000000000000000000000000000000000000000000000000000000080")
+)
+
+// signWithTorsion returns a signature of message by priv whose R has a
+// component of order 8, which only the cofactored equation accepts.
+func signWithTorsion(t *testing.T, priv PrivateKey, message []byte) []byte {
+	h := sha512.Sum512(priv.Seed())
+	s, _ := NewScalar().SetBytesWithClamping(h[:32])
+
+	r := randomScalarElement(t)
+	T, _ := new(Point).SetBytes(order8Bytes)
+	R := new(Point).ScalarBaseMult(r.Bytes())
+	R.Add(R, T)
+
+	k := challenge(nil, R.Bytes(), priv[SeedSize:], message)
+	S := NewScalar().MultiplyAdd(k, s, r)
+	return append(R.Bytes(), S.Bytes()...)
+}
+
+func TestVerifyZIP215(t *testing.T) {
+	for i, tt := range rfc8032Vectors {
+		public, _ := hex.DecodeString(tt.public)
+		message, _ := hex.DecodeString(tt.message)
+		sig, _ := hex.DecodeString(tt.signature)
+		if !VerifyZIP215(public, message, sig) {
+			t.Errorf("test %d: valid signature rejected", i+1)
+		}
+		if VerifyZIP215(public, append(message, 0), sig) {
+			t.Errorf("test %d: signature accepted for the wrong message", i+1)
+		}
+	}
+
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("ZIP-215")
+
+	sig := signWithTorsion(t, priv, message)
+	if err := verifyWithRules(pub, message, sig, nil, RulesZIP215, true); err != nil {
+		t.Errorf("signature with small-order R component rejected: %v", err)
+	}
+	if err := verify(pub, message, sig, nil); err != errSignatureMismatch {
+		t.Errorf("cofactorless verification: got %v, want %v", err, errSignatureMismatch)
+	}
+
+	// A small-order A with S = 0 and a small-order R satisfies the cofactored
+	// equation for any message, whatever their encodings.
+	for _, A := range [][]byte{identityPlusPBytes, identityNegZeroBytes} {
+		for _, R := range [][]byte{identityPlusPBytes, identityNegZeroBytes, order8Bytes} {
+			sig := append(append([]byte{}, R...), make([]byte, 32)...)
+			if err := verifyWithRules(A, message, sig, nil, RulesZIP215, true); err != nil {
+				t.Errorf("A = %x, R = %x: got %v, want success", A, R, err)
+			}
+			if err := verify(A, message, sig, nil); err != errInvalidPublicKey {
+				t.Errorf("A = %x, R = %x: Verify got %v, want %v", A, R, err, errInvalidPublicKey)
+			}
+		}
+	}
+
+	// S must still be canonical.
+	malleable := append([]byte{}, Sign(priv, message)...)
+	S, _ := NewScalar().SetCanonicalBytes(malleable[32:])
+	copy(malleable[32:], ScalarToLittleEndian(new(big.Int).Add(S.BigInt(), Ed25519().Params().N)))
+	if err := verifyWithRules(pub, message, malleable, nil, RulesZIP215, true); err != errNonCanonicalS {
+		t.Errorf("S + L: got %v, want %v", err, errNonCanonicalS)
+	}
+
+	if err := verifyWithRules(pub, message, sig[:63], nil, RulesZIP215, true); err != errInvalidSignatureLength {
+		t.Errorf("short signature: got %v, want %v", err, errInvalidSignatureLength)
+	}
+	if VerifyZIP215(pub, []byte("other"), sig) {
+		t.Error("signature accepted for the wrong message")
+	}
+}
+
+func TestVerifyStrict(t *testing.T) {
+	for i, tt := range rfc8032Vectors {
+		public, _ := hex.DecodeString(tt.public)
+		message, _ := hex.DecodeString(tt.message)
+		sig, _ := hex.DecodeString(tt.signature)
+		if !VerifyStrict(public, message, sig) {
+			t.Errorf("test %d: valid signature rejected", i+1)
+		}
+		if VerifyStrict(public, append(message, 0), sig) {
+			t.Errorf("test %d: signature accepted for the wrong message", i+1)
+		}
+	}
+
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("verify_strict")
+
+	// With A the identity, S = 0 and R the identity are a valid signature of
+	// every message.
+	identity := NewIdentityPoint().Bytes()
+	forgery := append(append([]byte{}, identity...), make([]byte, 32)...)
+	if err := verify(identity, message, forgery, nil); err != nil {
+		t.Errorf("RulesRFC8032: got %v, want success", err)
+	}
+	if err := verifyWithRules(identity, message, forgery, nil, RulesStrict, false); err != errSmallOrderPublicKey {
+		t.Errorf("small-order A: got %v, want %v", err, errSmallOrderPublicKey)
+	}
+
+	// With r = 0, R is the identity and S = k * s.
+	h := sha512.Sum512(priv.Seed())
+	s, _ := NewScalar().SetBytesWithClamping(h[:32])
+	k := challenge(nil, identity, pub, message)
+	sig := append(append([]byte{}, identity...), NewScalar().Mul(k, s).Bytes()...)
+	if err := verify(pub, message, sig, nil); err != nil {
+		t.Errorf("RulesRFC8032: got %v, want success", err)
+	}
+	if err := verifyWithRules(pub, message, sig, nil, RulesStrict, false); err != errSmallOrderR {
+		t.Errorf("small-order R: got %v, want %v", err, errSmallOrderR)
+	}
+
+	sig = signWithTorsion(t, priv, message)
+	if err := verifyWithRules(pub, message, sig, nil, RulesStrict, false); err != errSignatureMismatch {
+		t.Errorf("mixed-order R: got %v, want %v", err, errSignatureMismatch)
+	}
+}
+
+func TestVerifierOptions(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("message")
+	sig := Sign(priv, message)
+	ctxSig, err := SignWithOptions(priv, message, &Options{Context: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rules := range []VerificationRules{RulesRFC8032, RulesZIP215, RulesStrict} {
+		if err := (&VerifierOptions{Rules: rules}).Verify(pub, message, sig); err != nil {
+			t.Errorf("rules %d: valid signature rejected: %v", rules, err)
+		}
+		opts := &VerifierOptions{Options: Options{Context: "foo"}, Rules: rules}
+		if err := opts.Verify(pub, message, ctxSig); err != nil {
+			t.Errorf("rules %d: valid Ed25519ctx signature rejected: %v", rules, err)
+		}
+		if err := opts.Verify(pub, message, sig); err == nil {
+			t.Errorf("rules %d: Ed25519 signature accepted as Ed25519ctx", rules)
+		}
+	}
+
+	var nilOpts *VerifierOptions
+	if err := nilOpts.Verify(pub, message, sig); err != nil {
+		t.Errorf("nil options: valid signature rejected: %v", err)
+	}
+	if err := (&VerifierOptions{Rules: 42}).Verify(pub, message, sig); err == nil {
+		t.Error("unknown rules accepted")
+	}
+}
+
+func TestVerifierOptionsEquation(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("cofactored")
+	sig := signWithTorsion(t, priv, message)
+
+	tests := []struct {
+		rules    VerificationRules
+		equation VerificationEquation
+		valid    bool
+	}{
+		{RulesRFC8032, EquationDefault, false},
+		{RulesRFC8032, EquationCofactorless, false},
+		{RulesRFC8032, EquationCofactored, true},
+		{RulesZIP215, EquationDefault, true},
+		{RulesZIP215, EquationCofactorless, false},
+		{RulesZIP215, EquationCofactored, true},
+		{RulesStrict, EquationDefault, false},
+		{RulesStrict, EquationCofactored, true},
+	}
+	for _, tt := range tests {
+		opts := &VerifierOptions{Rules: tt.rules, Equation: tt.equation}
+		if err := opts.Verify(pub, message, sig); (err == nil) != tt.valid {
+			t.Errorf("rules %d, equation %d: got %v, want valid = %v", tt.rules, tt.equation, err, tt.valid)
+		}
+		if err := opts.Verify(pub, message, Sign(priv, message)); err != nil {
+			t.Errorf("rules %d, equation %d: valid signature rejected: %v", tt.rules, tt.equation, err)
+		}
+	}
+
+	if err := (&VerifierOptions{Equation: 42}).Verify(pub, message, sig); err == nil {
+		t.Error("unknown equation accepted")
+	}
+}
+
+func TestCanonicalizeSignature(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("canonical")
+	sig := Sign(priv, message)
+	if !IsCanonicalSignature(sig) {
+		t.Error("signature from Sign is not canonical")
+	}
+	if got, err := CanonicalizeSignature(sig); err != nil || !bytes.Equal(got, sig) {
+		t.Errorf("canonical signature changed: %x, %v", got, err)
+	}
+
+	// S + L is accepted by verifiers that skip the check on S.
+	S, _ := NewScalar().SetCanonicalBytes(sig[32:])
+	malleable := append([]byte{}, sig...)
+	copy(malleable[32:], ScalarToLittleEndian(new(big.Int).Add(S.BigInt(), Ed25519().Params().N)))
+	if IsCanonicalSignature(malleable) {
+		t.Error("S + L is canonical")
+	}
+	got, err := CanonicalizeSignature(malleable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, sig) || !Verify(pub, message, got) {
+		t.Errorf("got %x, want %x", got, sig)
+	}
+
+	// A non-canonical R can't be fixed without invalidating the signature.
+	for _, R := range [][]byte{identityPlusPBytes, identityNegZeroBytes} {
+		bad := append(append([]byte{}, R...), sig[32:]...)
+		if IsCanonicalSignature(bad) {
+			t.Errorf("R = %x is canonical", R)
+		}
+		if _, err := CanonicalizeSignature(bad); err == nil {
+			t.Errorf("R = %x was canonicalized", R)
+		}
+	}
+
+	if IsCanonicalSignature(sig[:63]) {
+		t.Error("short signature is canonical")
+	}
+	if _, err := CanonicalizeSignature(sig[:63]); err == nil {
+		t.Error("short signature was canonicalized")
+	}
+}
+
+func TestRecomputeR(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("recompute")
+	sig := Sign(priv, message)
+	R, err := RecomputeR(pub, message, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(R.Bytes(), sig[:32]) {
+		t.Errorf("R' = %x, want %x", R.Bytes(), sig[:32])
+	}
+
+	R, err = RecomputeR(pub, []byte("other"), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(R.Bytes(), sig[:32]) {
+		t.Error("R' matches R for the wrong message")
+	}
+
+	// With a small-order component in R, R' only matches up to the cofactor.
+	sig = signWithTorsion(t, priv, message)
+	R, err = RecomputeR(pub, message, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigR, _ := new(Point).SetBytes(sig[:32])
+	if R.Equal(sigR) == 1 || new(Point).MultByCofactor(R).Equal(new(Point).MultByCofactor(sigR)) != 1 {
+		t.Error("R' of a signature with torsion doesn't match R up to the cofactor")
+	}
+
+	if _, err := RecomputeR(pub, message, sig[:63]); err != errInvalidSignatureLength {
+		t.Errorf("short signature: got %v, want %v", err, errInvalidSignatureLength)
+	}
+	if _, err := RecomputeR(identityPlusPBytes, message, sig); err != errInvalidPublicKey {
+		t.Errorf("non-canonical key: got %v, want %v", err, errInvalidPublicKey)
+	}
+	S, _ := NewScalar().SetCanonicalBytes(sig[32:])
+	malleable := append([]byte{}, sig...)
+	copy(malleable[32:], ScalarToLittleEndian(new(big.Int).Add(S.BigInt(), Ed25519().Params().N)))
+	if _, err := RecomputeR(pub, message, malleable); err != errNonCanonicalS {
+		t.Errorf("S + L: got %v, want %v", err, errNonCanonicalS)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vrf implements the ECVRF-EDWARDS25519-SHA512-TAI and
+// ECVRF-EDWARDS25519-SHA512-ELL2 verifiable random functions of RFC 9381.
+//
+// A VRF maps an input alpha to a pseudorandom output beta that only the
+// holder of the private key can compute, together with a proof pi that lets
+// anyone holding the public key check that beta is the right output for
+// alpha. Unlike a signature, the output is unique: there's only one beta per
+// key and input. Keys are ordinary Ed25519 keys.
+//
+// The two suites only differ in how alpha is hashed to a point: TAI uses
+// try-and-increment, which is not constant time in alpha, and ELL2 uses the
+// Elligator 2 encoding of RFC 9380, which is. Proofs of one suite don't
+// verify under the other.
+package vrf
+
+import (
+	"crypto/sha512"
+	"errors"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+)
+
+// Sizes of proofs and outputs, in bytes.
+const (
+	// ProofSize is the size of a proof, Gamma || c || s.
+	ProofSize = 80
+	// OutputSize is the size of a VRF output beta.
+	OutputSize = 64
+)
+
+// Suite is an RFC 9381 ciphersuite.
+type Suite byte
+
+const (
+	// TAI is ECVRF-EDWARDS25519-SHA512-TAI, suite_string 0x03.
+	TAI Suite = 0x03
+	// ELL2 is ECVRF-EDWARDS25519-SHA512-ELL2, suite_string 0x04.
+	ELL2 Suite = 0x04
+)
+
+// cLen is the length of the challenge, in bytes.
+const cLen = 16
+
+var errInvalidProof = errors.New("vrf: invalid proof")
+
+// Prove returns the proof pi that beta is the VRF output of privateKey for
+// alpha, under suite. It panics if len(privateKey) is not
+// ed25519.PrivateKeySize, or if suite is not TAI or ELL2.
+func (suite Suite) Prove(privateKey ed25519.PrivateKey, alpha []byte) []byte {
+	if l := len(privateKey); l != ed25519.PrivateKeySize {
+		panic("vrf: bad private key length: " + strconv.Itoa(l))
+	}
+	publicKey := privateKey[ed25519.SeedSize:]
+
+	h := sha512.Sum512(privateKey.Seed())
+	x, _ := ed25519.NewScalar().SetBytesWithClamping(h[:32])
+
+	H := suite.encodeToCurve(publicKey, alpha)
+	hString := H.Bytes()
+	Gamma := new(ed25519.Point).ScalarMult(x.Bytes(), H)
+
+	// k = SHA-512(prefix || h_string) mod L, as in RFC 8032 signing.
+	kh := sha512.New()
+	kh.Write(h[32:])
+	kh.Write(hString)
+	k, _ := ed25519.NewScalar().SetUniformBytes(kh.Sum(nil))
+
+	U := new(ed25519.Point).ScalarBaseMult(k.Bytes())
+	V := new(ed25519.Point).ScalarMult(k.Bytes(), H)
+	c := suite.challenge(publicKey, hString, Gamma.Bytes(), U.Bytes(), V.Bytes())
+	s := ed25519.NewScalar().MultiplyAdd(challengeScalar(c), x, k)
+
+	pi := make([]byte, 0, ProofSize)
+	pi = append(pi, Gamma.Bytes()...)
+	pi = append(pi, c...)
+	return append(pi, s.Bytes()...)
+}
+
+// Verify checks pi against publicKey and alpha, under suite, and returns the
+// VRF output beta if it's valid. Public keys of small order are rejected, as
+// with validate_key set. It panics if suite is not TAI or ELL2.
+func (suite Suite) Verify(publicKey ed25519.PublicKey, alpha, pi []byte) ([]byte, error) {
+	Y, err := new(ed25519.Point).SetBytes(publicKey)
+	if err != nil {
+		return nil, errors.New("vrf: invalid public key")
+	}
+	if new(ed25519.Point).MultByCofactor(Y).IsIdentity() == 1 {
+		return nil, errors.New("vrf: public key of small order")
+	}
+	if len(pi) != ProofSize {
+		return nil, errInvalidProof
+	}
+	Gamma, err := new(ed25519.Point).SetBytes(pi[:32])
+	if err != nil {
+		return nil, errInvalidProof
+	}
+	c := pi[32 : 32+cLen]
+	s, err := ed25519.NewScalar().SetCanonicalBytes(pi[32+cLen:])
+	if err != nil {
+		return nil, errInvalidProof
+	}
+
+	H := suite.encodeToCurve(publicKey, alpha)
+	negC := ed25519.NewScalar().Negate(challengeScalar(c))
+
+	// U = s*B - c*Y, V = s*H - c*Gamma
+	U := new(ed25519.Point).VartimeMultiScalarMult(
+		[][]byte{s.Bytes(), negC.Bytes()}, []*ed25519.Point{ed25519.NewGeneratorPoint(), Y})
+	V := new(ed25519.Point).VartimeMultiScalarMult(
+		[][]byte{s.Bytes(), negC.Bytes()}, []*ed25519.Point{H, Gamma})
+	if string(suite.challenge(publicKey, H.Bytes(), pi[:32], U.Bytes(), V.Bytes())) != string(c) {
+		return nil, errInvalidProof
+	}
+	return suite.proofToHash(Gamma), nil
+}
+
+// ProofToHash returns the VRF output beta of the proof pi, without verifying
+// it. It must only be used on proofs that were produced locally or already
+// verified.
+func (suite Suite) ProofToHash(pi []byte) ([]byte, error) {
+	if len(pi) != ProofSize {
+		return nil, errInvalidProof
+	}
+	Gamma, err := new(ed25519.Point).SetBytes(pi[:32])
+	if err != nil {
+		return nil, errInvalidProof
+	}
+	return suite.proofToHash(Gamma), nil
+}
+
+// proofToHash implements RFC 9381, Section 5.2.
+func (suite Suite) proofToHash(Gamma *ed25519.Point) []byte {
+	h := sha512.New()
+	h.Write([]byte{byte(suite), 0x03})
+	h.Write(new(ed25519.Point).MultByCofactor(Gamma).Bytes())
+	h.Write([]byte{0x00})
+	return h.Sum(nil)
+}
+
+// challenge implements ECVRF_challenge_generation, RFC 9381, Section 5.4.3,
+// with the public key as the first point.
+func (suite Suite) challenge(points ...[]byte) []byte {
+	h := sha512.New()
+	h.Write([]byte{byte(suite), 0x02})
+	for _, p := range points {
+		h.Write(p)
+	}
+	h.Write([]byte{0x00})
+	return h.Sum(nil)[:cLen]
+}
+
+// challengeScalar returns the cLen-byte little-endian c as a scalar.
+func challengeScalar(c []byte) *ed25519.Scalar {
+	var b [32]byte
+	copy(b[:], c)
+	s, _ := ed25519.NewScalar().SetCanonicalBytes(b[:])
+	return s
+}
+
+// encodeToCurve hashes alpha to a point, with the public key as the salt.
+func (suite Suite) encodeToCurve(publicKey, alpha []byte) *ed25519.Point {
+	switch suite {
+	case TAI:
+		return encodeToCurveTAI(publicKey, alpha)
+	case ELL2:
+		msg := append(append([]byte{}, publicKey...), alpha...)
+		return ed25519.EncodeToPoint(msg, []byte(ell2DST))
+	default:
+		panic("vrf: unknown suite " + strconv.Itoa(int(suite)))
+	}
+}
+
+// ell2DST is the hash-to-curve domain separation tag of the ELL2 suite,
+// "ECVRF_" || h2c_suite_ID_string || suite_string.
+const ell2DST = "ECVRF_edwards25519_XMD:SHA-512_ELL2_NU_\x04"
+
+// encodeToCurveTAI implements ECVRF_encode_to_curve_try_and_increment, RFC
+// 9381, Section 5.4.1.1. Its running time depends on alpha.
+func encodeToCurveTAI(salt, alpha []byte) *ed25519.Point {
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha512.New()
+		h.Write([]byte{byte(TAI), 0x01})
+		h.Write(salt)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), 0x00})
+		if H, err := new(ed25519.Point).SetBytes(h.Sum(nil)[:32]); err == nil {
+			return H.MultByCofactor(H)
+		}
+	}
+	// Each attempt succeeds with probability about 1/2.
+	panic("vrf: try-and-increment failed")
+}
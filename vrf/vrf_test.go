@@ -0,0 +1,96 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vrf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+// rfc9381Vectors are Examples 16 and 19 of RFC 9381, Appendix B, with the
+// key of RFC 8032, Test 1, and an empty alpha.
+var rfc9381Vectors = []struct {
+	suite    Suite
+	seed     string
+	alpha    string
+	pi, beta string
+}{
+	{
+		suite: TAI,
+		seed:  "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		pi:    "8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805",
+		beta:  "90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae",
+	},
+	{
+		suite: ELL2,
+		seed:  "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		pi:    "7d9c633ffeee27349264cf5c667579fc583b4bda63ab71d001f89c10003ab46f14adf9a3cd8b8412d9038531e865c341cafa73589b023d14311c331a9ad15ff2fb37831e00f0acaa6d73bc9997b06501",
+		beta:  "9d574bf9b8302ec0fc1e21c3ec5368269527b87b462ce36dab2d14ccf80c53cccf6758f058c5b1c856b116388152bbe509ee3b9ecfe63d93c3b4346c1fbc6c54",
+	},
+}
+
+func TestRFC9381Vectors(t *testing.T) {
+	for i, tt := range rfc9381Vectors {
+		seed, _ := hex.DecodeString(tt.seed)
+		alpha, _ := hex.DecodeString(tt.alpha)
+		priv := ed25519.NewKeyFromSeed(seed)
+		pub := ed25519.PublicKey(priv[ed25519.SeedSize:])
+
+		pi := tt.suite.Prove(priv, alpha)
+		if got := hex.EncodeToString(pi); got != tt.pi {
+			t.Errorf("test %d: got pi %s, want %s", i, got, tt.pi)
+		}
+		beta, err := tt.suite.Verify(pub, alpha, pi)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if got := hex.EncodeToString(beta); got != tt.beta {
+			t.Errorf("test %d: got beta %s, want %s", i, got, tt.beta)
+		}
+		if h, _ := tt.suite.ProofToHash(pi); !bytes.Equal(h, beta) {
+			t.Errorf("test %d: ProofToHash disagrees with Verify", i)
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alpha := []byte("leader election round 7")
+
+	for _, suite := range []Suite{TAI, ELL2} {
+		pi := suite.Prove(priv, alpha)
+		if _, err := suite.Verify(pub, alpha, pi); err != nil {
+			t.Fatalf("suite %d: valid proof rejected: %v", suite, err)
+		}
+		if _, err := suite.Verify(pub, []byte("other"), pi); err == nil {
+			t.Errorf("suite %d: proof accepted for the wrong input", suite)
+		}
+		otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+		if _, err := suite.Verify(otherPub, alpha, pi); err == nil {
+			t.Errorf("suite %d: proof accepted for the wrong key", suite)
+		}
+		for _, i := range []int{0, 40, 79} {
+			bad := append([]byte{}, pi...)
+			bad[i] ^= 1
+			if _, err := suite.Verify(pub, alpha, bad); err == nil {
+				t.Errorf("suite %d: proof with byte %d flipped accepted", suite, i)
+			}
+		}
+		if _, err := suite.Verify(ed25519.NewIdentityPoint().Bytes(), alpha, pi); err == nil {
+			t.Errorf("suite %d: small-order public key accepted", suite)
+		}
+	}
+
+	if _, err := ELL2.Verify(pub, alpha, TAI.Prove(priv, alpha)); err == nil {
+		t.Error("TAI proof accepted under ELL2")
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wgkey implements WireGuard keys: X25519 private and public keys,
+// and symmetric preshared keys, in the base64 encoding of wg(8) and of
+// WireGuard configuration files. Its API follows the Key type of
+// golang.zx2c4.com/wireguard/wgctrl/wgtypes.
+package wgkey
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/gtank/ed25519"
+)
+
+// KeyLen is the size of a WireGuard key, in bytes.
+const KeyLen = 32
+
+// A Key is a WireGuard private, public or preshared key.
+type Key [KeyLen]byte
+
+// GenerateKey returns a random key, suitable as a preshared key, like
+// wg genpsk.
+func GenerateKey() (Key, error) {
+	var k Key
+	if _, err := cryptorand.Read(k[:]); err != nil {
+		return Key{}, err
+	}
+	return k, nil
+}
+
+// GeneratePrivateKey returns a random, clamped private key, like wg genkey.
+func GeneratePrivateKey() (Key, error) {
+	k, err := GenerateKey()
+	if err != nil {
+		return Key{}, err
+	}
+	return k.Clamp(), nil
+}
+
+// NewKey returns a Key from b, which must be KeyLen bytes long.
+func NewKey(b []byte) (Key, error) {
+	if l := len(b); l != KeyLen {
+		return Key{}, errors.New("wgkey: bad key length: " + strconv.Itoa(l))
+	}
+	var k Key
+	copy(k[:], b)
+	return k, nil
+}
+
+// ParseKey parses a Key from its base64 encoding, as printed by wg(8) and
+// used in configuration files.
+func ParseKey(s string) (Key, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return Key{}, errors.New("wgkey: invalid base64 key: " + err.Error())
+	}
+	return NewKey(b)
+}
+
+// Clamp returns k clamped as an X25519 scalar, as in RFC 7748, Section 5:
+// the three least significant bits and the most significant bit are cleared,
+// and the second most significant bit is set. WireGuard stores private keys
+// clamped, and X25519 computes the same results with either form.
+func (k Key) Clamp() Key {
+	k[0] &= 248
+	k[31] &= 127
+	k[31] |= 64
+	return k
+}
+
+// PublicKey returns the public key of the private key k, like wg pubkey.
+func (k Key) PublicKey() Key {
+	var pub Key
+	copy(pub[:], ed25519.X25519Base(k[:]))
+	return pub
+}
+
+// String returns the base64 encoding of k.
+func (k Key) String() string {
+	return base64.StdEncoding.EncodeToString(k[:])
+}
+
+// MarshalText implements encoding.TextMarshaler, with the encoding of String.
+func (k Key) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, like ParseKey.
+func (k *Key) UnmarshalText(text []byte) error {
+	kk, err := ParseKey(string(text))
+	if err != nil {
+		return err
+	}
+	*k = kk
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wgkey
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+func TestKey(t *testing.T) {
+	// Vectors generated with libsodium's crypto_scalarmult_base.
+	const (
+		private = "AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHyA="
+		clamped = "AAIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eH2A="
+		public  = "B6N8vBQgk8i3VdwbEOhstCY3StFqqFPtC9/AsrhtHHw="
+	)
+	k, err := ParseKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.String() != private {
+		t.Errorf("String = %s, want %s", k, private)
+	}
+	if got := k.Clamp().String(); got != clamped {
+		t.Errorf("Clamp = %s, want %s", got, clamped)
+	}
+	if got := k.PublicKey().String(); got != public {
+		t.Errorf("PublicKey = %s, want %s", got, public)
+	}
+	if got := k.Clamp().PublicKey().String(); got != public {
+		t.Errorf("PublicKey of clamped key = %s, want %s", got, public)
+	}
+
+	for _, s := range []string{
+		"",
+		"AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHw==", // 31 bytes
+		"AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHyA",  // missing padding
+		"AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHyA=!",
+	} {
+		if _, err := ParseKey(s); err == nil {
+			t.Errorf("ParseKey(%q) succeeded", s)
+		}
+	}
+	if _, err := NewKey(make([]byte, 33)); err == nil {
+		t.Error("NewKey accepted 33 bytes")
+	}
+}
+
+func TestGeneratePrivateKey(t *testing.T) {
+	a, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != a.Clamp() {
+		t.Errorf("private key %s is not clamped", a)
+	}
+	b, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Fatal("generated the same key twice")
+	}
+	aPub, bPub := a.PublicKey(), b.PublicKey()
+	ab := ed25519.X25519(a[:], bPub[:])
+	ba := ed25519.X25519(b[:], aPub[:])
+	if !bytes.Equal(ab, ba) {
+		t.Errorf("shared secrets differ: %x, %x", ab, ba)
+	}
+}
+
+func TestKeyText(t *testing.T) {
+	k, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(map[string]Key{"PresharedKey": k})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]Key
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["PresharedKey"] != k {
+		t.Errorf("round trip through %s returned %s", b, m["PresharedKey"])
+	}
+	if err := json.Unmarshal([]byte(`{"k":"bm90IGEga2V5"}`), &m); err == nil {
+		t.Error("invalid key unmarshaled")
+	}
+}
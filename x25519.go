@@ -0,0 +1,193 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"strconv"
+
+	"github.com/gtank/ed25519/internal/group"
+	"github.com/gtank/ed25519/internal/radix51"
+)
+
+// X25519Size is the size of X25519 scalars, u-coordinates and outputs.
+const X25519Size = 32
+
+// X25519Basepoint is the u-coordinate of the canonical curve25519 base
+// point, u = 9, which corresponds to the edwards25519 generator.
+var X25519Basepoint = []byte{9, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+
+// X25519 returns the X25519 function of RFC 7748, Section 5: the
+// u-coordinate of the clamped scalar times the point with u-coordinate u. As
+// specified, the top bit of u is ignored, and u may be a point on the twist
+// of curve25519. It runs in constant time, with a Montgomery ladder.
+//
+// The output is all zeroes if u is of low order. Protocols that need to
+// reject those must check for it. It panics if len(scalar) or len(u) is not
+// X25519Size.
+func X25519(scalar, u []byte) []byte {
+	if l := len(scalar); l != X25519Size {
+		panic("ed25519: bad X25519 scalar length: " + strconv.Itoa(l))
+	}
+	if l := len(u); l != X25519Size {
+		panic("ed25519: bad X25519 point length: " + strconv.Itoa(l))
+	}
+	var k, p, out [32]byte
+	copy(k[:], scalar)
+	copy(p[:], u)
+	group.X25519(&out, &k, &p)
+	return out[:]
+}
+
+// X25519Batch returns X25519(scalars[i], points[i]) for every i. It's
+// faster than calling X25519 in a loop for large batches, like the
+// handshakes a busy server terminates: the field inversion at the end of
+// each ladder is shared across the batch with Montgomery's trick, and the
+// ladders run back to back over contiguous state.
+//
+// As with X25519, outputs for low-order points are all zeroes, and don't
+// affect the other outputs. It panics if len(scalars) and len(points)
+// differ, or if any scalar or point is not X25519Size bytes long.
+func X25519Batch(scalars, points [][]byte) [][]byte {
+	if len(scalars) != len(points) {
+		panic("ed25519: mismatched X25519Batch lengths: " + strconv.Itoa(len(scalars)) + " scalars, " + strconv.Itoa(len(points)) + " points")
+	}
+	ks := make([][32]byte, len(scalars))
+	ps := make([][32]byte, len(points))
+	for i := range scalars {
+		if l := len(scalars[i]); l != X25519Size {
+			panic("ed25519: bad X25519 scalar length: " + strconv.Itoa(l))
+		}
+		if l := len(points[i]); l != X25519Size {
+			panic("ed25519: bad X25519 point length: " + strconv.Itoa(l))
+		}
+		copy(ks[i][:], scalars[i])
+		copy(ps[i][:], points[i])
+	}
+	out := make([][32]byte, len(scalars))
+	group.X25519Batch(out, ks, ps)
+
+	res := make([][]byte, len(out))
+	for i := range out {
+		res[i] = out[i][:]
+	}
+	return res
+}
+
+// X25519Base returns X25519(scalar, X25519Basepoint), the X25519 public key
+// of the private key scalar. It's computed on edwards25519, with the
+// precomputed tables of ScalarBaseMult, which is faster than the ladder. It
+// panics if len(scalar) is not X25519Size.
+func X25519Base(scalar []byte) []byte {
+	if l := len(scalar); l != X25519Size {
+		panic("ed25519: bad X25519 scalar length: " + strconv.Itoa(l))
+	}
+	// The base point has prime order, so the clamped scalar can be reduced
+	// modulo L.
+	var wide [64]byte
+	copy(wide[:], scalar)
+	wide[0] &= 248
+	wide[31] &= 127
+	wide[31] |= 64
+	s, _ := NewScalar().SetUniformBytes(wide[:])
+	return new(Point).ScalarBaseMult(s.Bytes()).BytesMontgomery()
+}
+
+// lowOrderPoints are the u-coordinates, reduced modulo p, of the points of
+// order 1, 2, 4 and 8 on curve25519 and its twist.
+var lowOrderPoints = [][]byte{
+	// 0, the point of order 2, and of the identity
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	// 1, of order 4
+	{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	// the two u-coordinates of the points of order 8
+	{0xe0, 0xeb, 0x7a, 0x7c, 0x3b, 0x41, 0xb8, 0xae, 0x16, 0x56, 0xe3, 0xfa, 0xf1, 0x9f, 0xc4, 0x6a, 0xda, 0x09, 0x8d, 0xeb, 0x9c, 0x32, 0xb1, 0xfd, 0x86, 0x62, 0x05, 0x16, 0x5f, 0x49, 0xb8, 0x00},
+	{0x5f, 0x9c, 0x95, 0xbc, 0xa3, 0x50, 0x8c, 0x24, 0xb1, 0xd0, 0xb1, 0x55, 0x9c, 0x83, 0xef, 0x5b, 0x04, 0x44, 0x5c, 0xc4, 0x58, 0x1c, 0x8e, 0x86, 0xd8, 0x22, 0x4e, 0xdd, 0xd0, 0x9f, 0x11, 0x57},
+	// -1, of order 4 on the twist
+	{0xec, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+}
+
+// IsLowOrderPoint reports whether u is the u-coordinate of a point of order
+// dividing 8, on curve25519 or its twist, like the blocklist of libsodium.
+// X25519 of such a point is all zeroes for every scalar, so a peer that sends
+// one fixes the shared secret. As in X25519, the top bit of u is ignored and
+// non-canonical values are reduced. It runs in constant time, and panics if
+// len(u) is not X25519Size.
+func IsLowOrderPoint(u []byte) bool {
+	if l := len(u); l != X25519Size {
+		panic("ed25519: bad X25519 point length: " + strconv.Itoa(l))
+	}
+	var fe radix51.FieldElement
+	var reduced [32]byte
+	fe.FromBytes(u)
+	fe.ToBytes(reduced[:])
+
+	found := 0
+	for _, p := range lowOrderPoints {
+		found |= subtle.ConstantTimeCompare(reduced[:], p)
+	}
+	return found == 1
+}
+
+// X25519Options can be used with X25519Options.X25519 to check the inputs
+// and output of X25519.
+type X25519Options struct {
+	// Contributory, if true, requires that both parties contribute to the
+	// shared secret, as protocols like Noise, TLS 1.3 and HPKE do: points
+	// of low order, and outputs of all zeroes, are rejected.
+	Contributory bool
+}
+
+var errLowOrderPoint = errors.New("ed25519: X25519 with a low order point")
+
+// X25519 is like the X25519 function, but applies the checks selected by o.
+// A nil o selects none, and never returns an error.
+func (o *X25519Options) X25519(scalar, u []byte) ([]byte, error) {
+	out := X25519(scalar, u)
+	if o == nil || !o.Contributory {
+		return out, nil
+	}
+	if IsLowOrderPoint(u) || subtle.ConstantTimeCompare(out, make([]byte, X25519Size)) == 1 {
+		return nil, errLowOrderPoint
+	}
+	return out, nil
+}
+
+// PublicKeyToX25519 returns the X25519 public key equivalent to the Ed25519
+// public key pub, the u-coordinate of the birationally equivalent point, like
+// libsodium's crypto_sign_ed25519_pk_to_curve25519. Together with
+// PrivateKeyToX25519, it lets an identity key be used for Diffie-Hellman.
+//
+// It returns an error if pub is not a canonical encoding of a point of prime
+// order other than the identity, which honestly generated keys always are.
+// It panics if len(pub) is not PublicKeySize.
+func PublicKeyToX25519(pub PublicKey) ([]byte, error) {
+	if l := len(pub); l != PublicKeySize {
+		panic("ed25519: bad public key length: " + strconv.Itoa(l))
+	}
+	A, err := new(Point).SetBytesPrimeOrder(pub)
+	if err != nil || A.IsIdentity() == 1 {
+		return nil, errInvalidPublicKey
+	}
+	return A.BytesMontgomery(), nil
+}
+
+// PrivateKeyToX25519 returns the X25519 private key equivalent to the
+// Ed25519 private key priv, the clamped secret scalar of RFC 8032, like
+// libsodium's crypto_sign_ed25519_sk_to_curve25519. Its X25519 public key is
+// PublicKeyToX25519 of the public key of priv. It panics if len(priv) is not
+// PrivateKeySize.
+func PrivateKeyToX25519(priv PrivateKey) []byte {
+	if l := len(priv); l != PrivateKeySize {
+		panic("ed25519: bad private key length: " + strconv.Itoa(l))
+	}
+	h := sha512.Sum512(priv[:SeedSize])
+	h[0] &= 248
+	h[31] &= 127
+	h[31] |= 64
+	return append([]byte{}, h[:X25519Size]...)
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed25519
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"testing"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// RFC 7748, Section 5.2.
+func TestX25519(t *testing.T) {
+	tests := []struct{ scalar, u, out string }{
+		{
+			"a546e36bf0527c9d3b16154b82465edd62144c0ac1fc5a18506a2244ba449ac4",
+			"e6db6867583030db3594c1a424b15f7c726624ec26b3353b10a903a6d0ab1c4c",
+			"c3da55379de9c6908e94ea4df28d084f32eccf03491c71f754b4075577a28552",
+		},
+		{
+			"4b66e9d4d1b4673c5ad22691957d6af5c11b6421e0ea01d42ca4169e7918ba0d",
+			"e5210f12786811d3f4b7959d0538ae2c31dbe7106fc03c3efc4cd549c715a493",
+			"95cbde9476e8907d7aade45cb4b873f88b595a68799fa152e6f8f7647aac7957",
+		},
+	}
+	for i, tt := range tests {
+		got := X25519(mustDecodeHex(t, tt.scalar), mustDecodeHex(t, tt.u))
+		if hex.EncodeToString(got) != tt.out {
+			t.Errorf("test %d: got %x, want %s", i+1, got, tt.out)
+		}
+	}
+}
+
+func TestX25519Iterated(t *testing.T) {
+	k := append([]byte{}, X25519Basepoint...)
+	u := append([]byte{}, X25519Basepoint...)
+	iterations := 1000
+	if testing.Short() {
+		iterations = 1
+	}
+	for i := 1; i <= iterations; i++ {
+		k, u = X25519(k, u), k
+		var want string
+		switch i {
+		case 1:
+			want = "422c8e7a6227d7bca1350b3e2bb7279f7897b87bb6854b783c60e80311ae3079"
+		case 1000:
+			want = "684cf59ba83309552800ef566f2f4d3c1c3887c49360e3875f2eb94d99532c51"
+		default:
+			continue
+		}
+		if got := hex.EncodeToString(k); got != want {
+			t.Errorf("after %d iterations: got %s, want %s", i, got, want)
+		}
+	}
+}
+
+// RFC 7748, Section 6.1.
+func TestX25519DiffieHellman(t *testing.T) {
+	alicePriv := mustDecodeHex(t, "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	alicePub := mustDecodeHex(t, "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a")
+	bobPriv := mustDecodeHex(t, "5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+	bobPub := mustDecodeHex(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+	shared := mustDecodeHex(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742")
+
+	if got := X25519Base(alicePriv); !bytes.Equal(got, alicePub) {
+		t.Errorf("Alice's public key: got %x, want %x", got, alicePub)
+	}
+	if got := X25519Base(bobPriv); !bytes.Equal(got, bobPub) {
+		t.Errorf("Bob's public key: got %x, want %x", got, bobPub)
+	}
+	if got := X25519(alicePriv, bobPub); !bytes.Equal(got, shared) {
+		t.Errorf("Alice's shared secret: got %x, want %x", got, shared)
+	}
+	if got := X25519(bobPriv, alicePub); !bytes.Equal(got, shared) {
+		t.Errorf("Bob's shared secret: got %x, want %x", got, shared)
+	}
+}
+
+func TestX25519Base(t *testing.T) {
+	for i := 0; i < 32; i++ {
+		scalar := make([]byte, X25519Size)
+		rand.Read(scalar)
+		want := X25519(scalar, X25519Basepoint)
+		if got := X25519Base(scalar); !bytes.Equal(got, want) {
+			t.Errorf("X25519Base(%x) = %x, want %x", scalar, got, want)
+		}
+	}
+}
+
+func TestX25519CryptoECDH(t *testing.T) {
+	for i := 0; i < 32; i++ {
+		a, _ := ecdh.X25519().GenerateKey(rand.Reader)
+		b, _ := ecdh.X25519().GenerateKey(rand.Reader)
+		want, err := a.ECDH(b.PublicKey())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := X25519(a.Bytes(), b.PublicKey().Bytes()); !bytes.Equal(got, want) {
+			t.Errorf("got %x, want %x", got, want)
+		}
+	}
+}
+
+// Vectors from libsodium's crypto_sign_ed25519_pk_to_curve25519 and
+// crypto_sign_ed25519_sk_to_curve25519.
+func TestKeyToX25519(t *testing.T) {
+	tests := []struct{ seed, edPub, xPub, xPriv string }{
+		{
+			"6465666768696a6b6c6d6e6f707172737475767778797a7b7c7d7e7f80818283",
+			"0bbc346a57667c380120bd9c7fd7e51d2c5fdfea37cd2f5bf405b2c6bf6f2d78",
+			"5bccd484527714f28eb6b6fa9e43feabae73eab192aef1e7d59d2111343d7b70",
+			"6846779755e884ab17637435bac06937155ff978cb2d2081030147535989f97d",
+		},
+		{
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29",
+			"5bf55c73b82ebe22be80f3430667af570fae2556a6415e6b30d4065300aa947d",
+			"5046adc1dba838867b2bbbfdd0c3423e58b57970b5267a90f57960924a87f156",
+		},
+	}
+	for i, tt := range tests {
+		priv := NewKeyFromSeed(mustDecodeHex(t, tt.seed))
+		if got := hex.EncodeToString(priv[SeedSize:]); got != tt.edPub {
+			t.Fatalf("test %d: Ed25519 public key %s, want %s", i+1, got, tt.edPub)
+		}
+		xPub, err := PublicKeyToX25519(PublicKey(priv[SeedSize:]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(xPub); got != tt.xPub {
+			t.Errorf("test %d: X25519 public key %s, want %s", i+1, got, tt.xPub)
+		}
+		xPriv := PrivateKeyToX25519(priv)
+		if got := hex.EncodeToString(xPriv); got != tt.xPriv {
+			t.Errorf("test %d: X25519 private key %s, want %s", i+1, got, tt.xPriv)
+		}
+		if got := X25519Base(xPriv); !bytes.Equal(got, xPub) {
+			t.Errorf("test %d: X25519Base of the converted private key = %x, want %x", i+1, got, xPub)
+		}
+	}
+
+	T, _ := new(Point).SetBytes(order8Bytes)
+	for _, pub := range [][]byte{NewIdentityPoint().Bytes(), T.Bytes(), identityPlusPBytes} {
+		if _, err := PublicKeyToX25519(pub); err == nil {
+			t.Errorf("%x converted", pub)
+		}
+	}
+}
+
+func TestIsLowOrderPoint(t *testing.T) {
+	// The canonical and non-canonical encodings of low-order points, with and
+	// without the top bit set.
+	var lowOrder [][]byte
+	for _, u := range lowOrderPoints {
+		lowOrder = append(lowOrder, u)
+		top := append([]byte{}, u...)
+		top[31] |= 0x80
+		lowOrder = append(lowOrder, top)
+	}
+	p := mustDecodeHex(t, "edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	pPlusOne := mustDecodeHex(t, "eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	lowOrder = append(lowOrder, p, pPlusOne)
+
+	scalar := make([]byte, X25519Size)
+	rand.Read(scalar)
+	for _, u := range lowOrder {
+		if !IsLowOrderPoint(u) {
+			t.Errorf("%x is not low order", u)
+		}
+		if out := X25519(scalar, u); !bytes.Equal(out, make([]byte, X25519Size)) {
+			t.Errorf("X25519 of %x = %x, want zero", u, out)
+		}
+		if _, err := (&X25519Options{Contributory: true}).X25519(scalar, u); err == nil {
+			t.Errorf("contributory X25519 accepted %x", u)
+		}
+		var opts *X25519Options
+		if _, err := opts.X25519(scalar, u); err != nil {
+			t.Errorf("default X25519 rejected %x: %v", u, err)
+		}
+	}
+
+	if IsLowOrderPoint(X25519Basepoint) {
+		t.Error("base point is low order")
+	}
+	peer := X25519Base(scalar)
+	if IsLowOrderPoint(peer) {
+		t.Error("public key is low order")
+	}
+	out, err := (&X25519Options{Contributory: true}).X25519(scalar, peer)
+	if err != nil || !bytes.Equal(out, X25519(scalar, peer)) {
+		t.Errorf("contributory X25519 got %x, %v", out, err)
+	}
+}
+
+func TestX25519Batch(t *testing.T) {
+	if out := X25519Batch(nil, nil); len(out) != 0 {
+		t.Errorf("empty batch returned %d outputs", len(out))
+	}
+
+	// Random points, interleaved with the low-order ones, which must not
+	// disturb the rest of the batch.
+	var scalars, points [][]byte
+	for i := 0; i < 2*len(lowOrderPoints); i++ {
+		k := make([]byte, X25519Size)
+		rand.Read(k)
+		u := make([]byte, X25519Size)
+		rand.Read(u)
+		if i%2 == 1 {
+			u = lowOrderPoints[i/2]
+		}
+		scalars, points = append(scalars, k), append(points, u)
+	}
+	out := X25519Batch(scalars, points)
+	for i := range out {
+		if want := X25519(scalars[i], points[i]); !bytes.Equal(out[i], want) {
+			t.Errorf("element %d: got %x, want %x", i, out[i], want)
+		}
+	}
+}
+
+func BenchmarkX25519Batch(b *testing.B) {
+	for _, n := range []int{1, 16, 256} {
+		scalars, points := make([][]byte, n), make([][]byte, n)
+		for i := range scalars {
+			scalars[i] = make([]byte, X25519Size)
+			rand.Read(scalars[i])
+			points[i] = X25519Base(scalars[i])
+		}
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				X25519Batch(scalars, points)
+			}
+		})
+	}
+}
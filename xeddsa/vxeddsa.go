@@ -0,0 +1,170 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xeddsa
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/gtank/ed25519"
+)
+
+const (
+	// VXSignatureSize is the size of a VXEdDSA signature, V || h || s.
+	VXSignatureSize = 96
+	// VXOutputSize is the size of the VXEdDSA VRF output.
+	VXOutputSize = 32
+)
+
+// VXSign computes a VXEdDSA signature of message with the X25519 private key
+// privateKey, and its VRF output, reading RandomSize bytes from rand. If rand
+// is nil, crypto/rand.Reader is used. privateKey is clamped, as X25519 does.
+//
+// The signature is randomized, but the output only depends on the key and
+// the message: VXVerify returns the same output for every valid signature,
+// so it can be used as a verifiable random value, such as a unique
+// identifier that only the key holder can compute.
+func VXSign(rand io.Reader, privateKey, message []byte) (signature, output []byte, err error) {
+	if len(privateKey) != PrivateKeySize {
+		return nil, nil, errors.New("xeddsa: bad private key length")
+	}
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	z := make([]byte, RandomSize)
+	if _, err := io.ReadFull(rand, z); err != nil {
+		return nil, nil, err
+	}
+
+	A, a := calculateKeyPair(privateKey)
+	Bv, err := hashToPoint(A, message)
+	if err != nil {
+		return nil, nil, err
+	}
+	V := new(ed25519.Point).ScalarMult(a.Bytes(), Bv).Bytes()
+
+	// r = hash3(a || V || Z) mod q
+	r := hashToScalar(3, a.Bytes(), V, z)
+	R := new(ed25519.Point).ScalarBaseMult(r.Bytes()).Bytes()
+	Rv := new(ed25519.Point).ScalarMult(r.Bytes(), Bv).Bytes()
+
+	// h = hash4(A || V || R || Rv || M) mod 2^128
+	h := truncatedChallenge(A, V, R, Rv, message)
+	hs, _ := ed25519.NewScalar().SetCanonicalBytes(h)
+
+	// s = r + h * a mod q
+	s := ed25519.NewScalar().MultiplyAdd(hs, a, r)
+
+	signature = make([]byte, 0, VXSignatureSize)
+	signature = append(signature, V...)
+	signature = append(signature, h...)
+	signature = append(signature, s.Bytes()...)
+	return signature, vrfOutput(V), nil
+}
+
+// VXVerify checks the VXEdDSA signature sig of message by the X25519 public
+// key publicKey. If it's valid, VXVerify returns the VRF output and true.
+// Otherwise, it returns nil and false.
+func VXVerify(publicKey, message, sig []byte) (output []byte, ok bool) {
+	if len(publicKey) != PublicKeySize || len(sig) != VXSignatureSize {
+		return nil, false
+	}
+	if _, err := ed25519.CoordinateFromLittleEndian(publicKey); err != nil {
+		return nil, false
+	}
+	Vb, h, s := sig[:32], sig[32:64], sig[64:]
+	if !isZero(h[16:]) || s[31]&0xe0 != 0 {
+		return nil, false
+	}
+
+	A, err := EdwardsPublicKey(publicKey)
+	if err != nil {
+		return nil, false
+	}
+	Bv, err := hashToPoint(A, message)
+	if err != nil {
+		return nil, false
+	}
+	Ap, err := new(ed25519.Point).SetBytes(A)
+	if err != nil {
+		return nil, false
+	}
+	V, err := new(ed25519.Point).SetBytes(Vb)
+	if err != nil {
+		return nil, false
+	}
+	if hasSmallOrder(Ap) || hasSmallOrder(V) || hasSmallOrder(Bv) {
+		return nil, false
+	}
+
+	// R = sB - hA and Rv = sBv - hV
+	minusA := new(ed25519.Point).Neg(Ap)
+	minusV := new(ed25519.Point).Neg(V)
+	R := new(ed25519.Point).VartimeMultiScalarMult(
+		[][]byte{s, h}, []*ed25519.Point{ed25519.NewGeneratorPoint(), minusA})
+	Rv := new(ed25519.Point).VartimeMultiScalarMult(
+		[][]byte{s, h}, []*ed25519.Point{Bv, minusV})
+
+	hcheck := truncatedChallenge(A, Vb, R.Bytes(), Rv.Bytes(), message)
+	if !bytes.Equal(hcheck, h) {
+		return nil, false
+	}
+	return vrfOutput(Vb), true
+}
+
+// truncatedChallenge returns hash4(A || V || R || Rv || M) mod 2^128, as a
+// 32-byte little-endian integer.
+func truncatedChallenge(A, V, R, Rv, message []byte) []byte {
+	h := make([]byte, 32)
+	copy(h, hashI(4, A, V, R, Rv, message)[:16])
+	return h
+}
+
+// vrfOutput returns hash5(cV) mod 2^256.
+func vrfOutput(V []byte) []byte {
+	p, err := new(ed25519.Point).SetBytes(V)
+	if err != nil {
+		panic("xeddsa: invalid V")
+	}
+	return hashI(5, p.MultByCofactor(p).Bytes())[:VXOutputSize]
+}
+
+// hashToPoint implements hash_to_point(A || M) from the specification: the
+// low 255 bits of hash2(A || M) are mapped to a u-coordinate with Elligator
+// 2, bit 255 selects the sign of the Edwards point, and the result is
+// multiplied by the cofactor.
+func hashToPoint(A, message []byte) (*ed25519.Point, error) {
+	h := hashI(2, A, message)
+	sign := int(h[31] >> 7)
+	ub, err := ed25519.RepresentativeToMontgomery(h[:32])
+	if err != nil {
+		return nil, errors.New("xeddsa: hash_to_point failed")
+	}
+	Pb, err := ed25519.MontgomeryToEdwardsBytes(ub, sign)
+	if err != nil {
+		return nil, errors.New("xeddsa: hash_to_point failed")
+	}
+	P, err := new(ed25519.Point).SetBytes(Pb)
+	if err != nil {
+		return nil, errors.New("xeddsa: hash_to_point failed")
+	}
+	return P.MultByCofactor(P), nil
+}
+
+// hasSmallOrder reports whether cP is the identity.
+func hasSmallOrder(P *ed25519.Point) bool {
+	return new(ed25519.Point).MultByCofactor(P).IsIdentity() == 1
+}
+
+func isZero(b []byte) bool {
+	for _, x := range b {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xeddsa
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVXSignVerify(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		priv, pub := newKeyPair(t)
+		message := []byte("VXEdDSA")
+
+		sig, out, err := VXSign(nil, priv, message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != VXSignatureSize || len(out) != VXOutputSize {
+			t.Fatalf("got %d-byte signature and %d-byte output", len(sig), len(out))
+		}
+		got, ok := VXVerify(pub, message, sig)
+		if !ok {
+			t.Fatal("valid signature rejected")
+		}
+		if !bytes.Equal(got, out) {
+			t.Errorf("VXVerify output %x, VXSign output %x", got, out)
+		}
+		if _, ok := VXVerify(pub, []byte("other"), sig); ok {
+			t.Error("signature accepted for the wrong message")
+		}
+
+		for _, pos := range []int{0, 32, 64} {
+			bad := append([]byte{}, sig...)
+			bad[pos] ^= 1
+			if _, ok := VXVerify(pub, message, bad); ok {
+				t.Errorf("signature corrupted at byte %d accepted", pos)
+			}
+		}
+	}
+}
+
+func TestVXOutputIsUnique(t *testing.T) {
+	priv, pub := newKeyPair(t)
+	message := []byte("message")
+
+	sig1, out1, _ := VXSign(nil, priv, message)
+	sig2, out2, _ := VXSign(nil, priv, message)
+	if bytes.Equal(sig1, sig2) {
+		t.Error("signatures are not randomized")
+	}
+	if !bytes.Equal(out1, out2) {
+		t.Error("outputs differ for the same key and message")
+	}
+	if _, out3, _ := VXSign(nil, priv, []byte("other")); bytes.Equal(out1, out3) {
+		t.Error("outputs are the same for different messages")
+	}
+	otherPriv, _ := newKeyPair(t)
+	if _, out4, _ := VXSign(nil, otherPriv, message); bytes.Equal(out1, out4) {
+		t.Error("outputs are the same for different keys")
+	}
+
+	// Every valid signature verifies to the same output.
+	if got, ok := VXVerify(pub, message, sig2); !ok || !bytes.Equal(got, out1) {
+		t.Error("second signature doesn't verify to the same output")
+	}
+}
+
+func TestVXVerifyRejects(t *testing.T) {
+	priv, pub := newKeyPair(t)
+	message := []byte("message")
+	sig, _, _ := VXSign(nil, priv, message)
+
+	bigH := append([]byte{}, sig...)
+	bigH[48] |= 1
+	if _, ok := VXVerify(pub, message, bigH); ok {
+		t.Error("h >= 2^128 accepted")
+	}
+
+	bigS := append([]byte{}, sig...)
+	bigS[95] |= 0x20
+	if _, ok := VXVerify(pub, message, bigS); ok {
+		t.Error("s >= 2^253 accepted")
+	}
+
+	// A small-order V would make the output predictable.
+	smallV := append([]byte{}, sig...)
+	copy(smallV, append([]byte{1}, make([]byte, 31)...))
+	if _, ok := VXVerify(pub, message, smallV); ok {
+		t.Error("small-order V accepted")
+	}
+
+	if _, ok := VXVerify(pub, message, sig[:95]); ok {
+		t.Error("short signature accepted")
+	}
+}
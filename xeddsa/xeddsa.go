@@ -0,0 +1,169 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xeddsa implements XEdDSA and VXEdDSA, from Signal's "The XEdDSA and
+// VXEdDSA Signature Schemes", over curve25519.
+//
+// XEdDSA signs with an X25519 private key, so a single key pair can be used
+// both for Diffie-Hellman and for signatures. An X25519 public key only has
+// the u-coordinate of a point, which determines the Edwards point up to its
+// sign, so the signer uses whichever of its private scalar k and -k has an
+// Edwards public key with sign bit zero. The signatures are then ordinary
+// Ed25519 signatures for the public key EdwardsPublicKey returns.
+//
+// VXEdDSA extends XEdDSA into a verifiable random function: each signature
+// comes with an output that is unique for the key and message, and looks
+// random to anyone without the private key. See VXSign.
+package xeddsa
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"io"
+
+	"github.com/gtank/ed25519"
+)
+
+const (
+	// PrivateKeySize is the size of an X25519 private key.
+	PrivateKeySize = 32
+	// PublicKeySize is the size of an X25519 public key, a u-coordinate.
+	PublicKeySize = 32
+	// SignatureSize is the size of an XEdDSA signature, which is an Ed25519
+	// signature.
+	SignatureSize = 64
+	// RandomSize is the size of the random value Z each signature consumes.
+	RandomSize = 64
+)
+
+// Sign signs message with the X25519 private key privateKey, reading
+// RandomSize bytes from rand for the nonce. If rand is nil,
+// crypto/rand.Reader is used. privateKey is clamped, as X25519 does.
+func Sign(rand io.Reader, privateKey, message []byte) ([]byte, error) {
+	if len(privateKey) != PrivateKeySize {
+		return nil, errors.New("xeddsa: bad private key length")
+	}
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	z := make([]byte, RandomSize)
+	if _, err := io.ReadFull(rand, z); err != nil {
+		return nil, err
+	}
+
+	A, a := calculateKeyPair(privateKey)
+
+	// r = hash1(a || M || Z) mod q
+	r := hashToScalar(1, a.Bytes(), message, z)
+	R := new(ed25519.Point).ScalarBaseMult(r.Bytes()).Bytes()
+
+	// s = r + hash(R || A || M) * a mod q
+	h := challenge(R, A, message)
+	s := ed25519.NewScalar().MultiplyAdd(h, a, r)
+
+	return append(R, s.Bytes()...), nil
+}
+
+// Verify reports whether sig is a valid XEdDSA signature of message by the
+// X25519 public key publicKey.
+//
+// It implements xeddsa_verify of the specification. The checks differ from
+// those of ed25519.Verify: publicKey must be less than p, with the top bit
+// clear, and S only needs to be less than 2^253, not L.
+func Verify(publicKey, message, sig []byte) bool {
+	if len(publicKey) != PublicKeySize || len(sig) != SignatureSize {
+		return false
+	}
+	if _, err := ed25519.CoordinateFromLittleEndian(publicKey); err != nil {
+		return false
+	}
+	R, s := sig[:32], sig[32:]
+	if s[31]&0xe0 != 0 {
+		return false
+	}
+
+	A, err := EdwardsPublicKey(publicKey)
+	if err != nil {
+		return false
+	}
+	minusA, err := new(ed25519.Point).SetBytes(A)
+	if err != nil {
+		return false
+	}
+	minusA.Neg(minusA)
+
+	// Rcheck = sB - hA, compared with R as an encoding.
+	h := challenge(R, A, message)
+	Rcheck := new(ed25519.Point).VartimeMultiScalarMult(
+		[][]byte{s, h.Bytes()},
+		[]*ed25519.Point{ed25519.NewGeneratorPoint(), minusA})
+	return bytes.Equal(Rcheck.Bytes(), R)
+}
+
+// EdwardsPublicKey returns the Ed25519 public key that XEdDSA signatures by
+// the X25519 public key publicKey are valid for: the Edwards point with the
+// same u-coordinate and sign bit zero. It returns an error if publicKey is
+// not the u-coordinate of a point on curve25519.
+func EdwardsPublicKey(publicKey []byte) (ed25519.PublicKey, error) {
+	if len(publicKey) != PublicKeySize {
+		return nil, errors.New("xeddsa: bad public key length")
+	}
+	A, err := ed25519.MontgomeryToEdwardsBytes(publicKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	return A, nil
+}
+
+// calculateKeyPair returns the Edwards public key A and the private scalar a
+// for the X25519 private key k, with a = ±k mod q chosen so that the sign bit
+// of A is zero.
+func calculateKeyPair(k []byte) (A []byte, a *ed25519.Scalar) {
+	var kb [32]byte
+	copy(kb[:], k)
+	kb = ed25519.ClampScalarBytes(kb)
+
+	E := new(ed25519.Point).ScalarBaseMult(kb[:]).Bytes()
+	sign := int(E[31] >> 7)
+	E[31] &= 0x7f
+
+	a, _ = ed25519.NewScalar().SetBytesWithClamping(k)
+	negA := ed25519.NewScalar().Negate(a)
+	a.Select(negA, a, sign)
+	return E, a
+}
+
+// hashI returns hash_i(parts...), which is SHA-512 of the 32-byte encoding
+// of 2^256 - 1 - i, followed by the parts. The prefixes separate the hashes
+// from each other and from the Ed25519 challenge hash, whose input starts with
+// R, a point encoding, which can't be 32 0xff bytes.
+func hashI(i byte, parts ...[]byte) []byte {
+	h := sha512.New()
+	prefix := bytes.Repeat([]byte{0xff}, 32)
+	prefix[0] -= i
+	h.Write(prefix)
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+// hashToScalar returns hash_i(parts...) reduced modulo q.
+func hashToScalar(i byte, parts ...[]byte) *ed25519.Scalar {
+	s, _ := ed25519.NewScalar().SetUniformBytes(hashI(i, parts...))
+	return s
+}
+
+// challenge returns hash(R || A || M) reduced modulo q, the Ed25519
+// challenge.
+func challenge(R, A, message []byte) *ed25519.Scalar {
+	h := sha512.New()
+	h.Write(R)
+	h.Write(A)
+	h.Write(message)
+	k, _ := ed25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	return k
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2019 George Tankersley. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xeddsa
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gtank/ed25519"
+)
+
+// newKeyPair returns a random X25519 key pair, generated by crypto/ecdh.
+func newKeyPair(t testing.TB) (priv, pub []byte) {
+	k, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k.Bytes(), k.PublicKey().Bytes()
+}
+
+func TestSignVerify(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		priv, pub := newKeyPair(t)
+		message := []byte("XEdDSA")
+
+		sig, err := Sign(nil, priv, message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(pub, message, sig) {
+			t.Fatal("valid signature rejected")
+		}
+		if Verify(pub, []byte("other"), sig) {
+			t.Error("signature accepted for the wrong message")
+		}
+
+		// XEdDSA signatures are Ed25519 signatures.
+		A, err := EdwardsPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if A[31]>>7 != 0 {
+			t.Error("Edwards public key has sign bit set")
+		}
+		if !ed25519.Verify(A, message, sig) {
+			t.Error("signature rejected by ed25519.Verify")
+		}
+
+		bad := append([]byte{}, sig...)
+		bad[0] ^= 1
+		if Verify(pub, message, bad) {
+			t.Error("corrupted signature accepted")
+		}
+	}
+}
+
+func TestSignDeterministicZ(t *testing.T) {
+	priv, pub := newKeyPair(t)
+	z := bytes.Repeat([]byte{7}, RandomSize)
+	sig1, _ := Sign(bytes.NewReader(z), priv, []byte("message"))
+	sig2, _ := Sign(bytes.NewReader(z), priv, []byte("message"))
+	if !bytes.Equal(sig1, sig2) {
+		t.Error("same Z gave different signatures")
+	}
+	sig3, _ := Sign(nil, priv, []byte("message"))
+	if bytes.Equal(sig1, sig3) || !Verify(pub, []byte("message"), sig3) {
+		t.Error("random Z gave an invalid or repeated signature")
+	}
+	if _, err := Sign(bytes.NewReader(z[:63]), priv, []byte("message")); err == nil {
+		t.Error("short read from rand didn't fail")
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	priv, pub := newKeyPair(t)
+	message := []byte("message")
+	sig, _ := Sign(nil, priv, message)
+
+	highBit := append([]byte{}, pub...)
+	highBit[31] |= 0x80
+	if Verify(highBit, message, sig) {
+		t.Error("public key with the top bit set accepted")
+	}
+
+	bigS := append([]byte{}, sig...)
+	bigS[63] |= 0x20
+	if Verify(pub, message, bigS) {
+		t.Error("S >= 2^253 accepted")
+	}
+
+	if Verify(pub, message, sig[:63]) || Verify(pub[:31], message, sig) {
+		t.Error("bad lengths accepted")
+	}
+}